@@ -0,0 +1,130 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/libsv/go-bk/envelope"
+	"github.com/libsv/go-dpp"
+)
+
+// AuditEventType identifies the type of traffic recorded in an audit entry.
+type AuditEventType string
+
+// Audit event types.
+const (
+	AuditPaymentRequest AuditEventType = "paymentrequest"
+	AuditPayment        AuditEventType = "payment"
+	AuditProof          AuditEventType = "proof"
+	AuditDoubleSpend    AuditEventType = "doublespend"
+)
+
+// AuditEvent is recorded by the service layer when payment traffic passes through the proxy.
+type AuditEvent struct {
+	Type      AuditEventType
+	PaymentID string
+	TxID      string
+	// Payload is the message seen or sent, it is stored as json.
+	Payload interface{}
+}
+
+// AuditPaymentPayload is the payload stored for an AuditPayment event,
+// containing the payment received and the ack returned to the customer.
+type AuditPaymentPayload struct {
+	Payment    dpp.Payment     `json:"payment"`
+	PaymentACK *dpp.PaymentACK `json:"paymentAck"`
+}
+
+// AuditProofPayload is the payload stored for an AuditProof event, containing the
+// envelope relayed to the merchant wallet.
+type AuditProofPayload struct {
+	Envelope *envelope.JSONEnvelope `json:"envelope"`
+	// Unverified is set to the reason the proof failed verification against the block
+	// headers, if it was relayed regardless.
+	Unverified string `json:"unverified,omitempty"`
+}
+
+// AuditEntry is a single hash chained record in the audit log.
+//
+// Each entry contains the hash of the previous entry, any modification,
+// removal or re-ordering of entries will break the chain.
+type AuditEntry struct {
+	Sequence  uint64          `json:"sequence"`
+	Timestamp time.Time       `json:"timestamp"`
+	Type      AuditEventType  `json:"type"`
+	PaymentID string          `json:"paymentId,omitempty"`
+	TxID      string          `json:"txId,omitempty"`
+	Payload   json.RawMessage `json:"payload"`
+	PrevHash  string          `json:"prevHash"`
+	Hash      string          `json:"hash"`
+}
+
+// ComputeHash will return the hex encoded sha256 hash of the entry, this covers
+// every field, including the PrevHash, but excludes the Hash itself.
+func (a AuditEntry) ComputeHash() string {
+	h := sha256.New()
+	for _, s := range []string{
+		strconv.FormatUint(a.Sequence, 10),
+		a.Timestamp.UTC().Format(time.RFC3339Nano),
+		string(a.Type),
+		a.PaymentID,
+		a.TxID,
+		a.PrevHash,
+	} {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	h.Write(a.Payload)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Verify checks the entry hash is correct and that it follows on from prev.
+// If prev is nil the entry is expected to be the first in the chain.
+func (a AuditEntry) Verify(prev *AuditEntry) error {
+	if a.ComputeHash() != a.Hash {
+		return fmt.Errorf("audit entry %d has been modified, hash mismatch", a.Sequence)
+	}
+	if prev == nil {
+		if a.Sequence != 0 || a.PrevHash != "" {
+			return fmt.Errorf("audit entry %d does not start the chain, previous entries are missing", a.Sequence)
+		}
+		return nil
+	}
+	if a.Sequence != prev.Sequence+1 {
+		return fmt.Errorf("audit entry %d does not follow entry %d, entries are missing or out of order", a.Sequence, prev.Sequence)
+	}
+	if a.PrevHash != prev.Hash {
+		return fmt.Errorf("audit entry %d does not link to entry %d, chain is broken", a.Sequence, prev.Sequence)
+	}
+	return nil
+}
+
+// AuditLogger is used by services to record payment traffic.
+type AuditLogger interface {
+	// AuditLog will append the event to the audit log.
+	AuditLog(ctx context.Context, evt AuditEvent) error
+}
+
+// AuditWriter will write entries to an audit sink.
+type AuditWriter interface {
+	// AuditWrite will persist the entry to the sink.
+	AuditWrite(ctx context.Context, entry AuditEntry) error
+}
+
+// AuditReader reads from an audit sink.
+type AuditReader interface {
+	// AuditLast returns the most recently written entry so the chain can be
+	// continued after a restart, nil is returned if there are no entries.
+	AuditLast(ctx context.Context) (*AuditEntry, error)
+}
+
+// AuditStore combines the reader and writer.
+type AuditStore interface {
+	AuditWriter
+	AuditReader
+}
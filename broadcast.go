@@ -0,0 +1,24 @@
+package server
+
+import (
+	"context"
+
+	"github.com/libsv/go-bt/v2"
+)
+
+// Broadcast is a payment transaction broadcast by the proxy, while the merchant wallet is offline.
+type Broadcast struct {
+	PaymentID string
+	Tx        *bt.Tx
+	// CallbackURL is where the broadcaster sends merkle proofs and double spends for the
+	// transaction, presenting CallbackToken as a bearer token if it is set.
+	CallbackURL   string
+	CallbackToken string
+}
+
+// Broadcaster submits transactions to the network, such as through an mAPI or ARC server.
+type Broadcaster interface {
+	// Broadcast submits the transaction, an unprocessable error is returned if it is rejected.
+	// Transactions already known to the network are not rejected, so broadcasts can be retried.
+	Broadcast(ctx context.Context, req Broadcast) error
+}
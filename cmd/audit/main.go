@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/bitcoin-sv/dpp-proxy/data/audit"
+)
+
+const usage = `usage: audit verify [-dir path] [file ...]
+
+Checks the hash chain of an audit log is unbroken. If files are supplied they are
+read in the order given, otherwise all audit files found in -dir are checked.
+`
+
+// main is the entry point for the audit log tooling.
+func main() {
+	if len(os.Args) < 2 || os.Args[1] != "verify" {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	fs.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	dir := fs.String("dir", "data/audit", "directory containing the audit log files")
+	_ = fs.Parse(os.Args[2:])
+
+	files := fs.Args()
+	if len(files) == 0 {
+		var err error
+		if files, err = audit.Files(*dir); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	n, err := audit.Verify(files...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "audit chain INVALID after %d valid entries: %s\n", n, err)
+		os.Exit(1)
+	}
+	fmt.Printf("audit chain valid, %d entries checked across %d files\n", n, len(files))
+}
@@ -1,15 +1,28 @@
 package internal
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
+	"net"
 	"net/http"
-	"time"
+	"os"
+	"sort"
+	"strings"
 
 	dppProxy "github.com/bitcoin-sv/dpp-proxy"
 	"github.com/bitcoin-sv/dpp-proxy/data"
+	"github.com/bitcoin-sv/dpp-proxy/data/audit"
+	"github.com/bitcoin-sv/dpp-proxy/data/broadcast"
+	"github.com/bitcoin-sv/dpp-proxy/data/fees"
+	"github.com/bitcoin-sv/dpp-proxy/data/headers"
+	"github.com/bitcoin-sv/dpp-proxy/data/memory"
 	"github.com/bitcoin-sv/dpp-proxy/data/payd"
+	"github.com/bitcoin-sv/dpp-proxy/data/paymail"
+	"github.com/bitcoin-sv/dpp-proxy/data/rates"
+	"github.com/bitcoin-sv/dpp-proxy/data/sandbox"
 	"github.com/bitcoin-sv/dpp-proxy/data/sockets"
+	"github.com/bitcoin-sv/dpp-proxy/data/xpub"
 	"github.com/bitcoin-sv/dpp-proxy/docs"
 	"github.com/bitcoin-sv/dpp-proxy/log"
 	dppHandlers "github.com/bitcoin-sv/dpp-proxy/transports/http"
@@ -18,10 +31,11 @@ import (
 	"github.com/gorilla/websocket"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"github.com/libsv/go-bc"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
-	"github.com/spf13/viper"
 	echoSwagger "github.com/swaggo/echo-swagger"
+	"github.com/theflyingcodr/lathos/errs"
 	smw "github.com/theflyingcodr/sockets/middleware"
 	"github.com/theflyingcodr/sockets/server"
 
@@ -36,12 +50,65 @@ import (
 type Deps struct {
 	PaymentService        dpp.PaymentService
 	PaymentRequestService dpp.PaymentRequestService
-	ProofsService         dpp.ProofsService
+	ProofsService         dppProxy.ProofCallbackService
+	PaymentURIService     dppProxy.PaymentURIService
+	RefundService         dppProxy.RefundService
+	PaymentStatusService  dppProxy.PaymentStatusService
+	// PeerChannelService is nil unless peer channels are enabled.
+	PeerChannelService dppProxy.PeerChannelService
+	// ProofLookupService is nil unless proofs are stored.
+	ProofLookupService dppProxy.ProofService
+	// XPubInvoiceService is nil unless the xpub data store is enabled.
+	XPubInvoiceService dppProxy.XPubInvoiceService
+	// PaymailInvoiceService is nil unless the paymail data store is enabled.
+	PaymailInvoiceService dppProxy.PaymailInvoiceService
+}
+
+// SetupAudit will setup the audit logger used to record payment traffic.
+func SetupAudit(cfg config.Config) (dppProxy.AuditLogger, error) {
+	var store dppProxy.AuditStore = noop.NewAudit()
+	if cfg.Audit.Enabled {
+		switch cfg.Audit.Sink {
+		case config.AuditSinkFile:
+			f, err := audit.NewFile(cfg.Audit)
+			if err != nil {
+				return nil, err
+			}
+			store = f
+		case config.AuditSinkStdout:
+			store = audit.NewWriter(os.Stdout)
+		}
+	}
+	return service.NewAudit(context.Background(), store)
+}
+
+// SetupProofVerifier will setup the verifier merkle proofs are checked with before being
+// relayed, nil is returned if block headers aren't enabled.
+func SetupProofVerifier(cfg config.Config, w *config.Watcher) (dppProxy.ProofVerifier, error) {
+	if !cfg.Headers.Enabled {
+		return nil, nil
+	}
+	var bhc bc.BlockHeaderChain
+	switch cfg.Headers.Source {
+	case config.HeadersSourceFile:
+		f, err := headers.NewFile(cfg.Headers.FilePath)
+		if err != nil {
+			return nil, err
+		}
+		bhc = f
+	case config.HeadersSourceHTTP:
+		client := data.NewClient(&http.Client{}, cfg.Headers.Timeout)
+		w.OnReload(func(c *config.Config) {
+			client.SetTimeout(c.Headers.Timeout)
+		})
+		bhc = headers.NewHTTP(cfg.Headers, client)
+	}
+	return service.NewProofVerifier(bhc)
 }
 
 // SetupDeps will setup all required dependent services.
-func SetupDeps(cfg config.Config, l log.Logger) *Deps {
-	httpClient := &http.Client{Timeout: 5 * time.Second}
+func SetupDeps(cfg config.Config, l log.Logger, auditLog dppProxy.AuditLogger, verifier dppProxy.ProofVerifier, w *config.Watcher) (*Deps, error) {
+	httpClient := &http.Client{}
 	if !cfg.PayD.Secure { // for testing, don't validate server cert
 		// #nosec
 		httpClient.Transport = &http.Transport{
@@ -50,27 +117,198 @@ func SetupDeps(cfg config.Config, l log.Logger) *Deps {
 		}
 	}
 	// stores
-	paydStore := payd.NewPayD(cfg.PayD, data.NewClient(httpClient))
+	paydClient := data.NewClient(httpClient, cfg.PayD.Timeout)
+	w.OnReload(func(c *config.Config) {
+		paydClient.SetTimeout(c.PayD.Timeout)
+	})
+	paydStore := payd.NewPayD(cfg.PayD, paydClient)
+	w.OnReload(func(c *config.Config) {
+		paydStore.SetAddress(c.PayD.Host, c.PayD.Port)
+	})
+	refundStore := memory.NewRefunds(cfg.Memory.Retention)
+	tokenStore := memory.NewProofTokens(cfg.Memory.Retention)
 
 	// services
-	paymentSvc := service.NewPayment(l, paydStore)
-	paymentReqSvc := service.NewPaymentRequest(paydStore)
-	if cfg.PayD.Noop {
-		noopStore := noop.NewNoOp(log.Noop{})
-		paymentSvc = service.NewPayment(log.Noop{}, noopStore)
-		paymentReqSvc = service.NewPaymentRequest(noopStore)
+	statusSvc := service.NewPaymentStatus(memory.NewPaymentStatuses(cfg.Memory.Retention))
+	channelSvc := setupPeerChannels(cfg)
+	var paymentWtr dpp.PaymentWriter = paydStore
+	var prRdr dpp.PaymentRequestReader = paydStore
+	var proofsWtr dpp.ProofsWriter = paydStore
+	var dsWtr dppProxy.DoubleSpendWriter = paydStore
+	var xpubInvoiceSvc dppProxy.XPubInvoiceService
+	var paymailInvoiceSvc dppProxy.PaymailInvoiceService
+	switch {
+	case cfg.PayD.Noop:
+		sandboxStore, err := sandbox.NewSandbox(l, cfg.Sandbox, cfg.Server, cfg.Deployment)
+		if err != nil {
+			return nil, err
+		}
+		paymentWtr, prRdr = sandboxStore, sandboxStore
+		proofsWtr, dsWtr = sandboxStore, sandboxStore
+	case cfg.Paymail.Enabled:
+		// paymail hosts are public so certs are always validated, the payd timeout is shared.
+		paymailClient := data.NewClient(&http.Client{}, cfg.PayD.Timeout)
+		w.OnReload(func(c *config.Config) {
+			paymailClient.SetTimeout(c.PayD.Timeout)
+		})
+		paymailStore := paymail.NewPaymail(cfg.Paymail, cfg.Server, cfg.Deployment, paymailClient, net.DefaultResolver,
+			memory.NewPaymailInvoices())
+		paymentWtr, prRdr = paymailStore, paymailStore
+		paymailInvoiceSvc = service.NewPaymailInvoice(paymailStore)
+	case cfg.XPub.Enabled:
+		file, err := xpub.NewFile(cfg.XPub.FilePath)
+		if err != nil {
+			return nil, err
+		}
+		rateRdr, err := setupRates(cfg, w)
+		if err != nil {
+			return nil, err
+		}
+		xpubStore, err := xpub.NewXPub(l, cfg.XPub, cfg.Rates, cfg.Server, cfg.Deployment, file, rateRdr,
+			setupBroadcaster(cfg, l, w))
+		if err != nil {
+			return nil, err
+		}
+		paymentWtr, prRdr = xpubStore, xpubStore
+		proofsWtr, dsWtr = xpubStore, xpubStore
+		xpubInvoiceSvc = service.NewXPubInvoice(xpubStore)
 	}
-	proofService := service.NewProof(paydStore)
+	paymentSvc := service.NewPayment(l, setupConflicts(cfg, l, paymentWtr, dsWtr), refundStore, tokenStore, statusSvc, channelSvc,
+		auditLog, cfg.Deployment)
+	paymentReqSvc := service.NewPaymentRequest(prRdr, setupFees(cfg, w), setupPolicy(cfg), statusSvc, auditLog, cfg.Deployment)
+	proofStore := setupProofStore(*cfg.Proofs)
+	proofService := service.NewProof(l, proofsWtr, dsWtr, tokenStore, verifier, proofStore, statusSvc, auditLog, cfg.Proofs,
+		service.DefaultProofParsers())
 
-	return &Deps{
+	deps := &Deps{
 		PaymentService:        paymentSvc,
 		PaymentRequestService: paymentReqSvc,
 		ProofsService:         proofService,
+		PaymentURIService:     service.NewPaymentURI(cfg.Server),
+		RefundService:         service.NewRefund(refundStore, cfg.Deployment),
+		PaymentStatusService:  statusSvc,
+		PeerChannelService:    channelSvc,
+		XPubInvoiceService:    xpubInvoiceSvc,
+		PaymailInvoiceService: paymailInvoiceSvc,
+	}
+	if proofStore != nil {
+		deps.ProofLookupService = service.NewProofLookup(proofStore)
+	}
+	return deps, nil
+}
+
+// setupRates returns the cached exchange rates fiat xpub invoices are converted with, nil is
+// returned if rates aren't enabled.
+func setupRates(cfg config.Config, w *config.Watcher) (dppProxy.ExchangeRateReader, error) {
+	if !cfg.Rates.Enabled {
+		return nil, nil
+	}
+	var rdr dppProxy.ExchangeRateReader
+	switch cfg.Rates.Source {
+	case config.RatesSourceFile:
+		f, err := rates.NewFile(cfg.Rates.FilePath)
+		if err != nil {
+			return nil, err
+		}
+		rdr = f
+	case config.RatesSourceHTTP:
+		client := data.NewClient(&http.Client{}, cfg.Rates.Timeout)
+		w.OnReload(func(c *config.Config) {
+			client.SetTimeout(c.Rates.Timeout)
+		})
+		rdr = rates.NewHTTP(cfg.Rates, client)
+	}
+	return service.NewExchangeRateCache(rdr, cfg.Rates.CacheTTL), nil
+}
+
+// setupFees returns the service filling and limiting payment request fees, nil is returned
+// if fees aren't enabled.
+func setupFees(cfg config.Config, w *config.Watcher) dppProxy.FeeService {
+	if !cfg.Fees.Enabled {
+		return nil
+	}
+	var rdr dppProxy.FeeQuoteReader = fees.NewStub(cfg.Fees)
+	if cfg.Fees.Source != config.FeesSourceStub {
+		client := data.NewClient(&http.Client{}, cfg.Fees.Timeout)
+		w.OnReload(func(c *config.Config) {
+			client.SetTimeout(c.Fees.Timeout)
+		})
+		switch cfg.Fees.Source {
+		case config.FeesSourceMAPI:
+			rdr = fees.NewMAPI(cfg.Fees, client)
+		case config.FeesSourceARC:
+			rdr = fees.NewARC(cfg.Fees, client)
+		}
+	}
+	return service.NewFees(service.NewFeeQuoteCache(rdr), cfg.Fees)
+}
+
+// setupPolicy returns the policy payment requests are checked against, nil is returned if
+// the policy isn't enabled.
+func setupPolicy(cfg config.Config) dppProxy.PaymentRequestPolicy {
+	if !cfg.Policy.Enabled {
+		return nil
+	}
+	return service.NewPolicy(cfg.Policy)
+}
+
+// setupBroadcaster returns the broadcaster payments queued while the merchant wallet is offline,
+// and xpub payments, are broadcast with, nil is returned if broadcasting isn't enabled.
+func setupBroadcaster(cfg config.Config, l log.Logger, w *config.Watcher) dppProxy.Broadcaster {
+	if !cfg.Broadcast.Enabled {
+		return nil
+	}
+	if cfg.Broadcast.Source == config.BroadcastSourceStub {
+		return broadcast.NewStub(l)
+	}
+	client := data.NewClient(&http.Client{}, cfg.Broadcast.Timeout)
+	w.OnReload(func(c *config.Config) {
+		client.SetTimeout(c.Broadcast.Timeout)
+	})
+	if cfg.Broadcast.Source == config.BroadcastSourceARC {
+		return broadcast.NewARC(cfg.Broadcast, client)
+	}
+	return broadcast.NewMAPI(cfg.Broadcast, client)
+}
+
+// setupConflicts returns paymentWtr checking payments for inputs spent by payments for other
+// invoices, conflicts are sent to the merchant wallet with dsWtr. paymentWtr is returned as
+// is if conflicts aren't enabled.
+func setupConflicts(cfg config.Config, l log.Logger, paymentWtr dpp.PaymentWriter, dsWtr dppProxy.DoubleSpendWriter) dpp.PaymentWriter {
+	if !cfg.Conflicts.Enabled {
+		return paymentWtr
+	}
+	return service.NewConflicts(l, paymentWtr, memory.NewSpends(), dsWtr, cfg.Conflicts)
+}
+
+// setupPeerChannels returns the service hosting peer channels for paid invoices, nil is
+// returned if peer channels aren't enabled.
+func setupPeerChannels(cfg config.Config) dppProxy.PeerChannelService {
+	if !cfg.PeerChannels.Enabled {
+		return nil
+	}
+	return service.NewPeerChannel(memory.NewPeerChannels(cfg.PeerChannels.MaxMessages), cfg.PeerChannels, cfg.Server)
+}
+
+// setupProofStore returns the store accepted proofs are kept in, nil is returned if
+// proofs aren't stored.
+func setupProofStore(cfg config.Proofs) dppProxy.ProofReaderWriter {
+	if !cfg.StoreEnabled {
+		return nil
 	}
+	return memory.NewProofs()
 }
 
-// SetupEcho will set up and return an echo server.
-func SetupEcho(cfg *config.Config, l log.Logger) *echo.Echo {
+// setupProofLookup registers the proof lookup endpoints if proofs are stored.
+func setupProofLookup(store dppProxy.ProofReader, g *echo.Group) {
+	if store == nil {
+		return
+	}
+	dppHandlers.NewProofLookup(service.NewProofLookup(store)).RegisterRoutes(g)
+}
+
+// SetupEcho will set up and return an echo server, the rate limit is updated when the config is reloaded.
+func SetupEcho(cfg *config.Config, l log.Logger, w *config.Watcher) *echo.Echo {
 	e := echo.New()
 	e.HideBanner = true
 
@@ -82,14 +320,96 @@ func SetupEcho(cfg *config.Config, l log.Logger) *echo.Echo {
 		},
 	}))
 	e.Use(middleware.RequestID())
+	e.Use(dppMiddleware.Tracing(cfg.Deployment.AppName))
+	limiter := dppMiddleware.NewRateLimiter(cfg.Server.RateLimit, cfg.Server.RateLimitBurst)
+	w.OnReload(func(c *config.Config) {
+		limiter.SetLimit(c.Server.RateLimit, c.Server.RateLimitBurst)
+	})
+	e.Use(dppMiddleware.RateLimit(limiter))
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins: []string{"*"},
-		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept},
+		AllowOrigins: cfg.Server.AllowedOrigins,
+		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization},
+	}))
+	e.Use(middleware.SecureWithConfig(middleware.SecureConfig{
+		// the swagger ui loads scripts and styles so can't be served with the content security policy.
+		Skipper: func(c echo.Context) bool {
+			return strings.HasPrefix(c.Path(), "/swagger/")
+		},
+		XSSProtection:         "1; mode=block",
+		ContentTypeNosniff:    "nosniff",
+		XFrameOptions:         "DENY",
+		HSTSMaxAge:            cfg.Server.HSTSMaxAge,
+		ContentSecurityPolicy: cfg.Server.ContentSecurityPolicy,
+		ReferrerPolicy:        "no-referrer",
 	}))
+	e.Use(dppMiddleware.BodyLimit(int64(cfg.Server.MaxBodyBytes), bodyLimits(cfg)))
 	e.HTTPErrorHandler = dppMiddleware.ErrorHandler(l)
+
+	e.Server.ReadHeaderTimeout = cfg.Server.ReadHeaderTimeout
+	e.Server.ReadTimeout = cfg.Server.ReadTimeout
+	e.Server.WriteTimeout = cfg.Server.WriteTimeout
+	e.Server.IdleTimeout = cfg.Server.IdleTimeout
 	return e
 }
 
+// bodyLimits returns the routes with a body limit other than the server max body size.
+func bodyLimits(cfg *config.Config) map[string]int64 {
+	limits := map[string]int64{
+		"/" + dppHandlers.RouteV1Payment: int64(cfg.Server.PaymentMaxBodyBytes),
+		"/" + dppHandlers.RouteV1Proofs:  int64(cfg.Server.ProofMaxBodyBytes),
+	}
+	if cfg.PeerChannels.Enabled {
+		limits["/"+dppHandlers.RouteV1PaymentMessage] = int64(cfg.PeerChannels.MaxMessageBytes)
+	}
+	return limits
+}
+
+// SetupAdmin will enable the admin endpoints, these are only enabled if an admin token is set.
+func SetupAdmin(cfg config.Admin, w *config.Watcher, e *echo.Echo) {
+	if cfg.Token == "" {
+		return
+	}
+	g := e.Group("/", dppMiddleware.BearerToken(cfg.Token))
+	dppHandlers.NewAdmin(w.Current).RegisterRoutes(g)
+}
+
+// MerchantDeps holds the services behind the merchant endpoints, endpoints are only
+// registered for the services that are not nil.
+type MerchantDeps struct {
+	RefundService         dppProxy.RefundService
+	PeerChannelService    dppProxy.PeerChannelService
+	InvoiceService        dppProxy.InvoiceService
+	PaymentQueueService   dppProxy.PaymentQueueService
+	XPubInvoiceService    dppProxy.XPubInvoiceService
+	PaymailInvoiceService dppProxy.PaymailInvoiceService
+}
+
+// SetupMerchant will enable the merchant endpoints, these are only enabled if a merchant token is set.
+func SetupMerchant(cfg config.Merchant, deps MerchantDeps, e *echo.Echo) {
+	if cfg.Token == "" {
+		return
+	}
+	g := e.Group("/", dppMiddleware.BearerToken(cfg.Token))
+	if deps.RefundService != nil {
+		dppHandlers.NewRefundHandler(deps.RefundService).RegisterRoutes(g)
+	}
+	if deps.PeerChannelService != nil {
+		dppHandlers.NewPeerChannelMessageHandler(deps.PeerChannelService).RegisterRoutes(g)
+	}
+	if deps.InvoiceService != nil {
+		dppHandlers.NewInvoiceHandler(deps.InvoiceService).RegisterRoutes(g)
+	}
+	if deps.PaymentQueueService != nil {
+		dppHandlers.NewPaymentQueueHandler(deps.PaymentQueueService).RegisterRoutes(g)
+	}
+	if deps.XPubInvoiceService != nil {
+		dppHandlers.NewXPubInvoiceHandler(deps.XPubInvoiceService).RegisterRoutes(g)
+	}
+	if deps.PaymailInvoiceService != nil {
+		dppHandlers.NewPaymailInvoiceHandler(deps.PaymailInvoiceService).RegisterRoutes(g)
+	}
+}
+
 // SetupSwagger will enable the swagger endpoints.
 func SetupSwagger(cfg config.Server, e *echo.Echo) {
 	docs.SwaggerInfo.Host = cfg.SwaggerHost
@@ -97,68 +417,142 @@ func SetupSwagger(cfg config.Server, e *echo.Echo) {
 }
 
 // SetupHTTPEndpoints will register the http endpoints.
-func SetupHTTPEndpoints(deps *Deps, e *echo.Echo) {
+func SetupHTTPEndpoints(cfg config.Server, deps *Deps, e *echo.Echo) {
 	g := e.Group("/")
 	// handlers
 	dppHandlers.NewPaymentHandler(deps.PaymentService).RegisterRoutes(g)
 	dppHandlers.NewPaymentRequestHandler(deps.PaymentRequestService).RegisterRoutes(g)
 	dppHandlers.NewProofs(deps.ProofsService).RegisterRoutes(g)
+	dppHandlers.NewPaymentURIHandler(deps.PaymentURIService).RegisterRoutes(g)
+	dppHandlers.NewPaymentStatusHandler(deps.PaymentStatusService).RegisterRoutes(g)
+	if deps.PeerChannelService != nil {
+		dppHandlers.NewPeerChannelHandler(deps.PeerChannelService, dppMiddleware.CheckOrigin(cfg.AllowedOrigins)).RegisterRoutes(g)
+	}
+	if deps.ProofLookupService != nil {
+		dppHandlers.NewProofLookup(deps.ProofLookupService).RegisterRoutes(g)
+	}
 }
 
 // SetupSockets will setup handlers and socket server.
-func SetupSockets(cfg config.Socket, e *echo.Echo) *server.SocketServer {
+func SetupSockets(cfg config.Config, l log.Logger, e *echo.Echo, auditLog dppProxy.AuditLogger, verifier dppProxy.ProofVerifier, w *config.Watcher) *server.SocketServer {
 	g := e.Group("/")
 	// create socket server
 	s := server.New(
-		server.WithMaxMessageSize(int64(cfg.MaxMessageBytes)),
-		server.WithChannelTimeout(cfg.ChannelTimeout))
+		server.WithMaxMessageSize(int64(cfg.Sockets.MaxMessageBytes)),
+		server.WithChannelTimeout(cfg.Sockets.ChannelTimeout))
 
 	// add middleware, with panic going first
 	s.WithMiddleware(smw.PanicHandler, smw.Timeout(smw.NewTimeoutConfig()), smw.Metrics())
+	s.WithErrorHandler(dppSoc.ErrorHandler(l))
 
-	dppSoc.NewPaymentRequest().Register(s)
-	dppSoc.NewPayment().Register(s)
-	dppHandlers.NewProofs(service.NewProof(sockets.NewPayd(s))).RegisterRoutes(g)
+	tokenStore := memory.NewProofTokens(cfg.Memory.Retention)
+	statusSvc := service.NewPaymentStatus(memory.NewPaymentStatuses(cfg.Memory.Retention))
+	dppSoc.NewPaymentRequest(l, setupFees(cfg, w), statusSvc).Register(s)
+	dppSoc.NewPayment(l, service.NewProofToken(tokenStore), statusSvc, cfg.Sockets.ChannelTimeout,
+		cfg.Merchant.Token).Register(s)
+	paymentStore := sockets.NewPayd(s, cfg.Sockets.AwaitTimeout)
+	w.OnReload(func(c *config.Config) {
+		paymentStore.SetTimeout(c.Sockets.AwaitTimeout)
+	})
+	proofStore := setupProofStore(*cfg.Proofs)
+	dppHandlers.NewProofs(service.NewProof(l, paymentStore, paymentStore, tokenStore, verifier, proofStore, statusSvc, auditLog,
+		cfg.Proofs, service.DefaultProofParsers())).RegisterRoutes(g)
+	dppHandlers.NewPaymentStatusHandler(statusSvc).RegisterRoutes(g)
+	setupProofLookup(proofStore, g)
 
 	// this is our websocket endpoint, clients will hit this with the channelID they wish to connect to
-	e.GET("/ws/:channelID", wsHandler(s))
+	e.GET("/ws/:channelID", wsHandler(s, dppMiddleware.CheckOrigin(cfg.Server.AllowedOrigins)))
 	return s
 }
 
 // SetupHybrid will setup handlers for http=>socket communication.
-func SetupHybrid(cfg config.Config, l log.Logger, e *echo.Echo) *server.SocketServer {
+func SetupHybrid(cfg config.Config, l log.Logger, e *echo.Echo, auditLog dppProxy.AuditLogger, verifier dppProxy.ProofVerifier,
+	w *config.Watcher) (*server.SocketServer, error) {
 	g := e.Group("/")
 	s := server.New(
 		server.WithMaxMessageSize(int64(cfg.Sockets.MaxMessageBytes)),
 		server.WithChannelTimeout(cfg.Sockets.ChannelTimeout))
 	// add middleware, with panic going first
 	s.WithMiddleware(smw.PanicHandler, smw.Timeout(smw.NewTimeoutConfig()), smw.Metrics())
+	s.WithErrorHandler(dppSoc.ErrorHandler(l))
 
-	paymentStore := socData.NewPayd(s)
-	paymentSvc := service.NewPayment(l, paymentStore)
+	paymentStore := socData.NewPayd(s, cfg.Sockets.AwaitTimeout)
+	w.OnReload(func(c *config.Config) {
+		paymentStore.SetTimeout(c.Sockets.AwaitTimeout)
+	})
+	refundStore := memory.NewRefunds(cfg.Memory.Retention)
+	tokenStore := memory.NewProofTokens(cfg.Memory.Retention)
+	statusSvc := service.NewPaymentStatus(memory.NewPaymentStatuses(cfg.Memory.Retention))
+	channelSvc := setupPeerChannels(cfg)
+	var paymentWtr dpp.PaymentWriter = paymentStore
 	if cfg.PayD.Noop {
-		noopStore := noop.NewNoOp(log.Noop{})
-		paymentSvc = service.NewPayment(log.Noop{}, noopStore)
+		sandboxStore, err := sandbox.NewSandbox(l, cfg.Sandbox, cfg.Server, cfg.Deployment)
+		if err != nil {
+			return nil, err
+		}
+		paymentWtr = sandboxStore
+	}
+	var proofsWtr dpp.ProofsWriter = paymentStore
+	var dsWtr dppProxy.DoubleSpendWriter = paymentStore
+	// registered invoices are served, and paid, while the merchant wallet is offline.
+	var invoiceStore dppProxy.InvoiceReaderWriter
+	var invoiceSvc dppProxy.InvoiceService
+	var queueSvc dppProxy.PaymentQueueService
+	if cfg.Invoices.Enabled {
+		invoiceStore = memory.NewInvoices()
+		invoiceSvc = service.NewInvoice(invoiceStore, cfg.Server, cfg.Deployment)
+		queueStore := memory.NewPaymentQueue()
+		queue := service.NewPaymentQueue(paymentWtr, invoiceStore, queueStore, statusSvc, setupBroadcaster(cfg, l, w),
+			tokenStore, cfg.Server)
+		paymentWtr, queueSvc = queue, queue
+		dppSoc.NewPaymentQueue(queue, cfg.Merchant.Token).Register(s)
+		// callbacks for queued payments are kept until the wallet collects the payment.
+		proofQueue := service.NewProofQueue(paymentStore, paymentStore, queueStore)
+		proofsWtr, dsWtr = proofQueue, proofQueue
 	}
-	paymentReqSvc := service.NewPaymentRequestProxy(paymentStore, cfg.Transports, cfg.Server)
-	proofsSvc := service.NewProof(paymentStore)
+	paymentSvc := service.NewPayment(l, setupConflicts(cfg, l, paymentWtr, dsWtr), refundStore, tokenStore, statusSvc, channelSvc,
+		auditLog, cfg.Deployment)
+	paymentReqSvc := service.NewPaymentRequestProxy(paymentStore, invoiceStore, setupFees(cfg, w), setupPolicy(cfg), statusSvc, cfg.Transports, cfg.Server,
+		cfg.Deployment, auditLog)
+	proofStore := setupProofStore(*cfg.Proofs)
+	proofsSvc := service.NewProof(l, proofsWtr, dsWtr, tokenStore, verifier, proofStore, statusSvc, auditLog, cfg.Proofs,
+		service.DefaultProofParsers())
 
 	dppHandlers.NewPaymentHandler(paymentSvc).RegisterRoutes(g)
 	dppHandlers.NewPaymentRequestHandler(paymentReqSvc).RegisterRoutes(g)
 	dppHandlers.NewProofs(proofsSvc).RegisterRoutes(g)
+	dppHandlers.NewPaymentURIHandler(service.NewPaymentURI(cfg.Server)).RegisterRoutes(g)
+	dppHandlers.NewPaymentStatusHandler(statusSvc).RegisterRoutes(g)
+	if channelSvc != nil {
+		dppHandlers.NewPeerChannelHandler(channelSvc, dppMiddleware.CheckOrigin(cfg.Server.AllowedOrigins)).RegisterRoutes(g)
+	}
+	setupProofLookup(proofStore, g)
+	SetupMerchant(*cfg.Merchant, MerchantDeps{
+		RefundService:       service.NewRefund(refundStore, cfg.Deployment),
+		PeerChannelService:  channelSvc,
+		InvoiceService:      invoiceSvc,
+		PaymentQueueService: queueSvc,
+	}, e)
 	dppSoc.NewHealthHandler().Register(s)
 
-	e.GET("/ws/:channelID", wsHandler(s))
-	return s
+	e.GET("/ws/:channelID", wsHandler(s, dppMiddleware.CheckOrigin(cfg.Server.AllowedOrigins)))
+	return s, nil
 }
 
-// wsHandler will upgrade connections to a websocket and then wait for messages.
-func wsHandler(svr *server.SocketServer) echo.HandlerFunc {
-	upgrader := websocket.Upgrader{}
+// wsHandler will upgrade connections to a websocket and then wait for messages, websockets
+// are only opened from origins allowed by checkOrigin.
+func wsHandler(svr *server.SocketServer, checkOrigin func(r *http.Request) bool) echo.HandlerFunc {
+	upgrader := websocket.Upgrader{CheckOrigin: checkOrigin}
 	return func(c echo.Context) error {
-		upgrader.CheckOrigin = func(r *http.Request) bool {
-			return true
+		if !checkOrigin(c.Request()) {
+			return errs.NewErrNotAuthorised("403", "websockets can't be opened from this origin")
+		}
+		chID := c.Param("channelID")
+		// checked before upgrading so the error is returned as an http response.
+		if c.QueryParam("internal") != "true" && !svr.HasChannel(chID) {
+			return errs.NewErrNotFoundf("404", "Connection for invoice '%s' not found", chID)
 		}
+
 		ws, err := upgrader.Upgrade(c.Response(), c.Request(), nil)
 		if err != nil {
 			return err
@@ -168,19 +562,6 @@ func wsHandler(svr *server.SocketServer) echo.HandlerFunc {
 			_ = ws.Close()
 		}()
 
-		chID := c.Param("channelID")
-
-		if c.QueryParam("internal") != "true" {
-			if !svr.HasChannel(chID) {
-				return c.JSON(http.StatusNotFound, dppProxy.ClientError{
-					ID:      "",
-					Code:    "404",
-					Message: fmt.Sprintf("Connection for invoice '%s' not found", chID),
-					Title:   "Not found",
-				})
-			}
-		}
-
 		return svr.Listen(ws, chID)
 	}
 }
@@ -218,8 +599,8 @@ func SetupSocketMetrics(s *server.SocketServer) {
 }
 
 // PrintDev outputs some useful dev information such as http routes
-// and current settings being used.
-func PrintDev(e *echo.Echo) {
+// and current settings being used, with secrets redacted.
+func PrintDev(e *echo.Echo, cfg *config.Config) {
 	fmt.Println("==================================")
 	fmt.Println("DEV mode, printing http routes:")
 	for _, r := range e.Routes() {
@@ -227,8 +608,22 @@ func PrintDev(e *echo.Echo) {
 	}
 	fmt.Println("==================================")
 	fmt.Println("DEV mode, printing settings:")
-	for _, v := range viper.AllKeys() {
-		fmt.Printf("%s: %v\n", v, viper.Get(v))
-	}
+	printSettings("", cfg.Redacted())
 	fmt.Println("==================================")
 }
+
+// printSettings prints each setting in the redacted config, sorted by its path.
+func printSettings(prefix string, settings map[string]interface{}) {
+	keys := make([]string, 0, len(settings))
+	for k := range settings {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if m, ok := settings[k].(map[string]interface{}); ok {
+			printSettings(prefix+k+".", m)
+			continue
+		}
+		fmt.Printf("%s%s: %v\n", prefix, k, settings[k])
+	}
+}
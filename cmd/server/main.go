@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"os"
 	"os/signal"
 	"time"
@@ -9,6 +11,7 @@ import (
 	"github.com/bitcoin-sv/dpp-proxy/cmd/internal"
 	"github.com/bitcoin-sv/dpp-proxy/config"
 	"github.com/bitcoin-sv/dpp-proxy/log"
+	"github.com/bitcoin-sv/dpp-proxy/tracing"
 )
 
 const appname = "payment-protocol-rest-server"
@@ -41,43 +44,94 @@ const banner = `
 //	- http
 //	- https
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == cmdValidateConfig {
+		os.Exit(validateConfig(os.Args[2:]))
+	}
+	configFile := flag.String("config", "", "path to a yaml, toml or json config file, environment variables override values in the file")
+	flag.Parse()
+
 	println("\033[32m" + banner + "\033[0m")
 	config.SetupDefaults()
-	cfg := config.NewViperConfig(appname).
-		WithServer().
-		WithDeployment(appname).
-		WithLog().
-		WithPayD().
-		WithSockets().
-		WithTransports().
-		Load()
+	if *configFile != "" {
+		if err := config.ReadFile(*configFile); err != nil {
+			log.Zero{}.Fatal(err, "config error")
+		}
+	}
+	cfg := loadConfig()
+	// validate before setting up the logger, as an invalid log level is fatal.
+	if err := cfg.Validate(); err != nil {
+		log.Zero{}.Fatal(errors.New(config.Report(err)), "config error")
+	}
 	log := log.NewZero(cfg.Logging)
 	log.Infof("\n------Environment: %#v -----\n", cfg.Server)
-	if err := cfg.Validate(); err != nil {
-		log.Fatal(err, "config error")
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing, cfg.Deployment)
+	if err != nil {
+		log.Fatal(err, "failed to setup tracing")
 	}
 
-	e := internal.SetupEcho(cfg,log)
+	watcher := config.NewWatcher(cfg, loadConfig)
+	if *configFile != "" {
+		watcher.OnReload(func(c *config.Config) {
+			if err := log.SetLevel(c.Logging.Level); err != nil {
+				log.Error(err, "failed to update log level")
+			}
+		})
+		watcher.Watch(func(c *config.Config, err error) {
+			if err != nil {
+				log.Error(err, "config reload rejected, current config kept")
+				return
+			}
+			log.Infof("config reloaded from %s, log level: %s, payd timeout: %s, socket await timeout: %s",
+				*configFile, c.Logging.Level, c.PayD.Timeout, c.Sockets.AwaitTimeout)
+		})
+	}
+
+	auditLog, err := internal.SetupAudit(*cfg)
+	if err != nil {
+		log.Fatal(err, "failed to setup audit log")
+	}
+
+	verifier, err := internal.SetupProofVerifier(*cfg, watcher)
+	if err != nil {
+		log.Fatal(err, "failed to setup proof verifier")
+	}
+
+	e := internal.SetupEcho(cfg, log, watcher)
 
 	if cfg.Server.SwaggerEnabled {
 		internal.SetupSwagger(*cfg.Server, e)
 	}
+	internal.SetupAdmin(*cfg.Admin, watcher, e)
 
 	// setup transports
 	switch cfg.Transports.Mode {
 	case config.TransportModeHTTP:
-		internal.SetupHTTPEndpoints(internal.SetupDeps(*cfg, log), e)
+		deps, err := internal.SetupDeps(*cfg, log, auditLog, verifier, watcher)
+		if err != nil {
+			log.Fatal(err, "failed to setup dependencies")
+		}
+		internal.SetupHTTPEndpoints(*cfg.Server, deps, e)
+		internal.SetupMerchant(*cfg.Merchant, internal.MerchantDeps{
+			RefundService:         deps.RefundService,
+			PeerChannelService:    deps.PeerChannelService,
+			XPubInvoiceService:    deps.XPubInvoiceService,
+			PaymailInvoiceService: deps.PaymailInvoiceService,
+		}, e)
 	case config.TransportModeSocket:
-		s := internal.SetupSockets(*cfg.Sockets, e)
+		s := internal.SetupSockets(*cfg, log, e, auditLog, verifier, watcher)
 		internal.SetupSocketMetrics(s)
 		defer s.Close()
 	case config.TransportModeHybrid:
-		s := internal.SetupHybrid(*cfg, log, e)
+		s, err := internal.SetupHybrid(*cfg, log, e, auditLog, verifier, watcher)
+		if err != nil {
+			log.Fatal(err, "failed to setup hybrid transport")
+		}
 		internal.SetupSocketMetrics(s)
 		defer s.Close()
 	}
 	if cfg.Deployment.IsDev() {
-		internal.PrintDev(e)
+		internal.PrintDev(e, cfg)
 	}
 	go func() {
 		log.Error(e.Start(cfg.Server.Port), "echo server failed")
@@ -94,5 +148,36 @@ func main() {
 	if err := e.Shutdown(ctx); err != nil {
 		log.Error(err, "")
 	}
+	if err := shutdownTracing(ctx); err != nil {
+		log.Error(err, "failed to flush traces")
+	}
+}
 
+// loadConfig reads the config from defaults, any config file and the environment.
+func loadConfig() *config.Config {
+	return config.NewViperConfig(appname).
+		WithServer().
+		WithDeployment(appname).
+		WithLog().
+		WithPayD().
+		WithSockets().
+		WithTransports().
+		WithTracing().
+		WithAudit().
+		WithAdmin().
+		WithPaymail().
+		WithMerchant().
+		WithProofs().
+		WithHeaders().
+		WithPeerChannels().
+		WithSandbox().
+		WithInvoices().
+		WithXPub().
+		WithRates().
+		WithFees().
+		WithBroadcast().
+		WithConflicts().
+		WithPolicy().
+		WithMemory().
+		Load()
 }
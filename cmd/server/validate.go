@@ -0,0 +1,35 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/bitcoin-sv/dpp-proxy/config"
+)
+
+// cmdValidateConfig is the subcommand used to check settings without starting the server.
+const cmdValidateConfig = "validate-config"
+
+// validateConfig loads the config in the same way as the server, from defaults, any config file
+// and the environment, and reports any invalid settings. The exit code is returned.
+func validateConfig(args []string) int {
+	fs := flag.NewFlagSet(cmdValidateConfig, flag.ExitOnError)
+	configFile := fs.String("config", "", "path to a yaml, toml or json config file, environment variables override values in the file")
+	_ = fs.Parse(args)
+
+	config.SetupDefaults()
+	if *configFile != "" {
+		if err := config.ReadFile(*configFile); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	cfg := loadConfig()
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprint(os.Stderr, config.Report(err))
+		return 1
+	}
+	fmt.Printf("config is valid, transport mode: %s\n", cfg.Transports.Mode)
+	return 0
+}
@@ -12,20 +12,98 @@ const (
 	EnvServerFQDN                  = "server.fqdn"
 	EnvServerSwaggerEnabled        = "server.swagger.enabled"
 	EnvServerSwaggerHost           = "server.swagger.host"
+	EnvServerReadHeaderTimeout     = "server.readheader.timeout"
+	EnvServerReadTimeout           = "server.read.timeout"
+	EnvServerWriteTimeout          = "server.write.timeout"
+	EnvServerIdleTimeout           = "server.idle.timeout"
+	EnvServerMaxBodyBytes          = "server.maxbody.bytes"
+	EnvServerPaymentMaxBodyBytes   = "server.payment.maxbody.bytes"
+	EnvServerProofMaxBodyBytes     = "server.proof.maxbody.bytes"
+	EnvServerAllowedOrigins        = "server.allowed.origins"
+	EnvServerHSTSMaxAge            = "server.hsts.maxage"
+	EnvServerCSP                   = "server.csp"
+	EnvServerRateLimit             = "server.ratelimit"
+	EnvServerRateLimitBurst        = "server.ratelimit.burst"
 	EnvEnvironment                 = "env.environment"
 	EnvRegion                      = "env.region"
 	EnvVersion                     = "env.version"
 	EnvCommit                      = "env.commit"
 	EnvBuildDate                   = "env.builddate"
+	EnvNetwork                     = "env.bitcoin.network"
 	EnvLogLevel                    = "log.level"
 	EnvPaydHost                    = "payd.host"
 	EnvPaydPort                    = "payd.port"
 	EnvPaydSecure                  = "payd.secure"
 	EnvPaydCertPath                = "payd.cert.path"
 	EnvPaydNoop                    = "payd.noop"
+	EnvPaydTimeout                 = "payd.timeout"
 	EnvSocketChannelTimeoutSeconds = "socket.channel.timeoutseconds"
 	EnvSocketMaxMessageBytes       = "socket.maxmessage.bytes"
+	EnvSocketAwaitTimeout          = "socket.await.timeout"
 	EnvTransportMode               = "transport.mode"
+	EnvTracingEnabled              = "tracing.enabled"
+	EnvTracingExporter             = "tracing.exporter"
+	EnvTracingEndpoint             = "tracing.endpoint"
+	EnvTracingInsecure             = "tracing.insecure"
+	EnvTracingSampleRatio          = "tracing.sample.ratio"
+	EnvAuditEnabled                = "audit.enabled"
+	EnvAuditSink                   = "audit.sink"
+	EnvAuditFilePath               = "audit.file.path"
+	EnvAuditFileMaxBytes           = "audit.file.maxbytes"
+	EnvAdminToken                  = "admin.token"
+	EnvPaymailEnabled              = "paymail.enabled"
+	EnvPaymailHandle               = "paymail.handle"
+	EnvPaymailExpiry               = "paymail.expiry"
+	EnvMerchantToken               = "merchant.token"
+	EnvProofsAllowUnsigned         = "proofs.allow.unsigned"
+	EnvProofsRequireToken          = "proofs.require.token"
+	EnvProofsRejectUnverified      = "proofs.reject.unverified"
+	EnvProofsStoreEnabled          = "proofs.store.enabled"
+	EnvHeadersEnabled              = "headers.enabled"
+	EnvHeadersSource               = "headers.source"
+	EnvHeadersFilePath             = "headers.file.path"
+	EnvHeadersURL                  = "headers.url"
+	EnvHeadersTimeout              = "headers.timeout"
+	EnvPeerChannelsEnabled         = "peerchannels.enabled"
+	EnvPeerChannelsMaxMessages     = "peerchannels.max.messages"
+	EnvPeerChannelsMaxMessageBytes = "peerchannels.maxmessage.bytes"
+	EnvSandboxFixturePath          = "sandbox.fixture.path"
+	EnvSandboxSlowDelay            = "sandbox.slow.delay"
+	EnvInvoicesEnabled             = "invoices.enabled"
+	EnvXPubEnabled                 = "xpub.enabled"
+	EnvXPubKey                     = "xpub.key"
+	EnvXPubFilePath                = "xpub.file.path"
+	EnvXPubExpiry                  = "xpub.expiry"
+	EnvRatesEnabled                = "rates.enabled"
+	EnvRatesSource                 = "rates.source"
+	EnvRatesFilePath               = "rates.file.path"
+	EnvRatesURL                    = "rates.url"
+	EnvRatesTimeout                = "rates.timeout"
+	EnvRatesCacheTTL               = "rates.cache.ttl"
+	EnvRatesQuoteExpiry            = "rates.quote.expiry"
+	EnvFeesEnabled                 = "fees.enabled"
+	EnvFeesSource                  = "fees.source"
+	EnvFeesURL                     = "fees.url"
+	EnvFeesTimeout                 = "fees.timeout"
+	EnvFeesCacheTTL                = "fees.cache.ttl"
+	EnvFeesFloor                   = "fees.floor"
+	EnvFeesCeiling                 = "fees.ceiling"
+	EnvBroadcastEnabled            = "broadcast.enabled"
+	EnvBroadcastSource             = "broadcast.source"
+	EnvBroadcastURL                = "broadcast.url"
+	EnvBroadcastToken              = "broadcast.token"
+	EnvBroadcastTimeout            = "broadcast.timeout"
+	EnvConflictsEnabled            = "conflicts.enabled"
+	EnvConflictsAction             = "conflicts.action"
+	EnvConflictsRetention          = "conflicts.retention"
+	EnvMemoryRetention             = "memory.retention"
+	EnvPolicyEnabled               = "policy.enabled"
+	EnvPolicyDustLimit             = "policy.dust.limit"
+	EnvPolicyScripts               = "policy.scripts"
+	EnvPolicyMaxOutputs            = "policy.max.outputs"
+	EnvPolicyMaxTotal              = "policy.max.total"
+	EnvPolicyMaxExpiry             = "policy.max.expiry"
+	EnvPolicyMerchantData          = "policy.merchantdata"
 
 	LogDebug = "debug"
 	LogInfo  = "info"
@@ -35,16 +113,67 @@ const (
 	TransportModeHybrid = "hybrid"
 	TransportModeHTTP   = "http"
 	TransportModeSocket = "socket"
+
+	TracingExporterOTLP   = "otlp"
+	TracingExporterStdout = "stdout"
+
+	AuditSinkFile   = "file"
+	AuditSinkStdout = "stdout"
+
+	HeadersSourceFile = "file"
+	HeadersSourceHTTP = "http"
+
+	RatesSourceFile = "file"
+	RatesSourceHTTP = "http"
+
+	FeesSourceMAPI = "mapi"
+	FeesSourceARC  = "arc"
+	FeesSourceStub = "stub"
+
+	BroadcastSourceMAPI = "mapi"
+	BroadcastSourceARC  = "arc"
+	BroadcastSourceStub = "stub"
+
+	ConflictsActionFlag   = "flag"
+	ConflictsActionReject = "reject"
+
+	ScriptP2PKH    = "p2pkh"
+	ScriptP2PK     = "p2pk"
+	ScriptP2SH     = "p2sh"
+	ScriptData     = "data"
+	ScriptMultiSig = "multisig"
+
+	NetworkMainnet = "mainnet"
+	NetworkTestnet = "testnet"
+	NetworkSTN     = "stn"
+	NetworkRegtest = "regtest"
 )
 
 // Config returns strongly typed config values.
 type Config struct {
-	Logging    *Logging
-	Server     *Server
-	Deployment *Deployment
-	PayD       *PayD
-	Sockets    *Socket
-	Transports *Transports
+	Logging      *Logging
+	Server       *Server
+	Deployment   *Deployment
+	PayD         *PayD
+	Sockets      *Socket
+	Transports   *Transports
+	Tracing      *Tracing
+	Audit        *Audit
+	Admin        *Admin
+	Paymail      *Paymail
+	Merchant     *Merchant
+	Proofs       *Proofs
+	Headers      *Headers
+	PeerChannels *PeerChannels
+	Sandbox      *Sandbox
+	Invoices     *Invoices
+	XPub         *XPub
+	Rates        *Rates
+	Fees         *Fees
+	Broadcast    *Broadcast
+	Conflicts    *Conflicts
+	Policy       *Policy
+	Memory       *Memory
 }
 
 // Deployment contains information relating to the current
@@ -56,6 +185,9 @@ type Deployment struct {
 	Version     string
 	Commit      string
 	BuildDate   time.Time
+	// Network is the bitcoin network served, payment requests and payments
+	// for any other network are rejected.
+	Network string
 }
 
 // IsDev determines if this app is running on a dev environment.
@@ -64,8 +196,8 @@ func (d *Deployment) IsDev() bool {
 }
 
 func (d *Deployment) String() string {
-	return fmt.Sprintf("Environment: %s \n AppName: %s\n Region: %s\n Version: %s\n Commit:%s\n BuildDate: %s\n",
-		d.Environment, d.AppName, d.Region, d.Version, d.Commit, d.BuildDate)
+	return fmt.Sprintf("Environment: %s \n AppName: %s\n Region: %s\n Version: %s\n Commit:%s\n BuildDate: %s\n Network: %s\n",
+		d.Environment, d.AppName, d.Region, d.Version, d.Commit, d.BuildDate, d.Network)
 }
 
 // Logging contains log configuration.
@@ -83,6 +215,29 @@ type Server struct {
 	// SwaggerEnabled if true we will include an endpoint to serve swagger documents.
 	SwaggerEnabled bool
 	SwaggerHost    string
+	// ReadHeaderTimeout, ReadTimeout, WriteTimeout and IdleTimeout are set on the http
+	// server, so slow clients can't hold connections open.
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	// MaxBodyBytes is the max size of a request body, payments, which can contain
+	// the ancestry of the transaction, and proof callbacks have their own limits.
+	MaxBodyBytes        int
+	PaymentMaxBodyBytes int
+	ProofMaxBodyBytes   int
+	// AllowedOrigins are the origins browsers can make cross origin requests and
+	// open websockets from, "*" allows any origin.
+	AllowedOrigins []string
+	// HSTSMaxAge is the max age in seconds of the Strict-Transport-Security header
+	// returned to https requests, it isn't returned if 0.
+	HSTSMaxAge int
+	// ContentSecurityPolicy is returned in the Content-Security-Policy header, except by the swagger docs.
+	ContentSecurityPolicy string
+	// RateLimit is the requests per second each client ip can make, with bursts of up to
+	// RateLimitBurst requests, requests aren't limited if 0.
+	RateLimit      float64
+	RateLimitBurst int
 }
 
 // PayD is used to setup connection to a payd instance.
@@ -93,13 +248,18 @@ type PayD struct {
 	Port            string
 	Secure          bool
 	CertificatePath string
-	Noop            bool
+	// Noop if true serves payments from the sandbox data store rather than payd.
+	Noop bool
+	// Timeout is the max time to wait on a response from payd.
+	Timeout time.Duration
 }
 
 // Socket contains config items for a socket server.
 type Socket struct {
 	MaxMessageBytes int
 	ChannelTimeout  time.Duration
+	// AwaitTimeout is the max time to wait on a socket wallet to respond to a request.
+	AwaitTimeout time.Duration
 }
 
 // Transports enables or disables dpp transports.
@@ -107,6 +267,219 @@ type Transports struct {
 	Mode string
 }
 
+// Tracing contains OpenTelemetry tracing settings.
+type Tracing struct {
+	Enabled bool
+	// Exporter is where spans are sent, either otlp or stdout.
+	Exporter string
+	// Endpoint is the host:port of the OTLP collector, used
+	// when the exporter is otlp.
+	Endpoint string
+	// Insecure if true will send spans to the collector without TLS.
+	Insecure bool
+	// SampleRatio is the fraction of new traces sampled, between 0 and 1.
+	SampleRatio float64
+}
+
+// Audit contains settings for the payment audit log.
+type Audit struct {
+	Enabled bool
+	// Sink is where audit entries are written, either file or stdout.
+	Sink string
+	// FilePath is the directory audit files are written to.
+	FilePath string
+	// FileMaxBytes is the size a file can reach before it is rotated,
+	// 0 disables rotation.
+	FileMaxBytes int64
+}
+
+// Admin contains settings for the admin endpoints.
+type Admin struct {
+	// Token is the bearer token required to call admin endpoints,
+	// if empty the admin endpoints are disabled.
+	Token string `secret:"true"`
+}
+
+// Paymail contains settings for the paymail data store, used in place of payd
+// to take payments to a merchant paymail.
+type Paymail struct {
+	Enabled bool
+	// Handle is the merchant paymail payments are sent to, for example merchant@example.com.
+	Handle string
+	// Expiry is how long an invoice is valid for if the merchant doesn't set an expiry.
+	Expiry time.Duration
+}
+
+// Merchant contains settings for the merchant endpoints, used by merchant wallets
+// to manage their payments.
+type Merchant struct {
+	// Token is the bearer token required to call merchant endpoints,
+	// if empty the merchant endpoints are disabled.
+	Token string `secret:"true"`
+}
+
+// Proofs contains settings for proof callbacks sent by miners and broadcasters.
+type Proofs struct {
+	// AllowUnsigned if true accepts callbacks that aren't in a signed JSON envelope,
+	// such as unsigned envelopes and bare TSC merkle proofs.
+	AllowUnsigned bool
+	// RequireToken if true rejects callbacks that don't present one of the proof
+	// callback tokens supplied with the payment as a bearer token.
+	RequireToken bool
+	// RejectUnverified if true rejects merkle proofs that fail verification against the
+	// block headers, otherwise they are relayed and flagged in the logs and audit log.
+	RejectUnverified bool
+	// StoreEnabled if true keeps accepted merkle proofs in memory, so customer wallets
+	// can collect them from the proxy.
+	StoreEnabled bool
+}
+
+// Headers contains settings for the block headers merkle proofs are verified against.
+type Headers struct {
+	// Enabled if true verifies merkle proofs against block headers before relaying them.
+	Enabled bool
+	// Source is where headers are read from, either file or http.
+	Source string
+	// FilePath is a file of raw 80 byte block headers, in chain order, used by the file source.
+	FilePath string
+	// URL is the address of the block headers service used by the http source.
+	URL string
+	// Timeout is the max time to wait on a response from the block headers service.
+	Timeout time.Duration
+}
+
+// PeerChannels contains settings for the peer channels hosted by the proxy, used to
+// relay messages from merchants to customers after a payment.
+type PeerChannels struct {
+	// Enabled if true allocates a channel to each paid invoice, returned in the PaymentACK.
+	Enabled bool
+	// MaxMessages is the number of messages kept for each channel, older messages are dropped.
+	MaxMessages int
+	// MaxMessageBytes is the largest message a merchant can send.
+	MaxMessageBytes int
+}
+
+// Sandbox contains settings for the sandbox data store, used in place of payd when
+// payd noop is set.
+type Sandbox struct {
+	// FixturePath is a json file of the invoices served, if empty a built in set is used.
+	FixturePath string
+	// SlowDelay is how long responses for slow sandbox paymentIDs are delayed.
+	SlowDelay time.Duration
+}
+
+// Invoices contains settings for invoices registered by merchants, used in hybrid
+// mode to serve payment requests and queue payments while the merchant wallet is offline.
+// Payments aren't queued in http or socket mode.
+type Invoices struct {
+	// Enabled if true enables the merchant invoice and payment queue endpoints.
+	Enabled bool
+}
+
+// XPub contains settings for standalone mode, where invoices are created by the proxy
+// and paid to outputs derived from the merchant's extended public key, in place of payd.
+type XPub struct {
+	// Enabled if true serves invoices created with the merchant endpoints instead of using payd.
+	Enabled bool
+	// Key is the extended public key outputs are derived from, at path 0/{index}.
+	Key string `secret:"true"`
+	// FilePath is the json file invoices and the next derivation index are kept in.
+	FilePath string
+	// Expiry is how long an invoice is valid for if the merchant doesn't set an expiry.
+	Expiry time.Duration
+}
+
+// Rates contains settings for the exchange rates fiat xpub invoices are converted to satoshis with.
+type Rates struct {
+	// Enabled if true lets merchants create xpub invoices priced in a fiat currency, registered
+	// invoices are always priced in satoshis.
+	Enabled bool
+	// Source is where rates are read from, either file or http.
+	Source string
+	// FilePath is a json file of the price of one BSV in each currency, used by the file source.
+	FilePath string
+	// URL is the price feed used by the http source, {currency} is replaced with the currency code.
+	URL string
+	// Timeout is the max time to wait on a response from the price feed.
+	Timeout time.Duration
+	// CacheTTL is how long a rate is reused before it is read again.
+	CacheTTL time.Duration
+	// QuoteExpiry is how long the satoshi amount of a fiat invoice is locked for once quoted.
+	QuoteExpiry time.Duration
+}
+
+// Fees contains settings for the miner fee quotes used when a merchant wallet omits fees
+// from a payment request, and the limits wallet fees are kept within, in every transport mode.
+type Fees struct {
+	// Enabled if true fills missing fees from the fee source and enforces the floor and ceiling.
+	Enabled bool
+	// Source is where fee quotes are read from, either mapi, arc or stub.
+	Source string
+	// URL is the address of the mapi or arc server.
+	URL string
+	// Timeout is the max time to wait on a response from the fee source.
+	Timeout time.Duration
+	// CacheTTL is how long quotes without an expiry, from arc or the stub, are reused.
+	CacheTTL time.Duration
+	// Floor is the lowest fee rate wallets can request, in satoshis per 1000 bytes, 0 for no floor.
+	Floor int
+	// Ceiling is the highest fee rate wallets can request, in satoshis per 1000 bytes, 0 for no ceiling.
+	Ceiling int
+}
+
+// Broadcast contains settings for broadcasting payments queued while the merchant wallet is offline,
+// which only happens for registered invoices in hybrid mode, and xpub payments.
+type Broadcast struct {
+	// Enabled if true broadcasts queued payments before they are acked.
+	Enabled bool
+	// Source is where transactions are broadcast to, either mapi, arc or stub.
+	Source string
+	// URL is the address of the mapi or arc server.
+	URL string
+	// Token is sent as a bearer token to the mapi or arc server, if set.
+	Token string `secret:"true"`
+	// Timeout is the max time to wait on a response from the broadcaster.
+	Timeout time.Duration
+}
+
+// Conflicts contains settings for detecting payments that spend outputs already spent by
+// payments for other invoices.
+type Conflicts struct {
+	// Enabled if true records the outputs spent by payments and checks new payments against them.
+	Enabled bool
+	// Action is taken on a conflicting payment, either flag, acking it with a warning, or reject.
+	Action string
+	// Retention is how long spent outputs are kept.
+	Retention time.Duration
+}
+
+// Policy contains the rules payment requests from merchant wallets must follow to be served
+// to customers, limits of 0 aren't enforced.
+type Policy struct {
+	// Enabled if true checks payment requests against the policy.
+	Enabled bool
+	// DustLimit is the smallest amount an output can pay, data outputs are exempt.
+	DustLimit uint64
+	// Scripts are the locking script templates outputs can use, any are allowed if empty.
+	Scripts []string
+	// MaxOutputs is the most outputs a payment request can have.
+	MaxOutputs int
+	// MaxTotal is the most satoshis a payment request can ask for.
+	MaxTotal uint64
+	// MaxExpiry is the furthest in the future a payment request can expire.
+	MaxExpiry time.Duration
+	// MerchantData are the merchant data fields payment requests must set, either avatar,
+	// name, email, address or extendedData.{key}.
+	MerchantData []string
+}
+
+// Memory contains settings for the payment state held in memory.
+type Memory struct {
+	// Retention is how long payment statuses, refund destinations and proof callback tokens
+	// are kept, statuses of payment requests that haven't expired are kept until they do.
+	Retention time.Duration
+}
+
 // ConfigurationLoader will load configuration items
 // into a struct that contains a configuration.
 type ConfigurationLoader interface {
@@ -116,5 +489,22 @@ type ConfigurationLoader interface {
 	WithPayD() ConfigurationLoader
 	WithSockets() ConfigurationLoader
 	WithTransports() ConfigurationLoader
+	WithTracing() ConfigurationLoader
+	WithAudit() ConfigurationLoader
+	WithAdmin() ConfigurationLoader
+	WithPaymail() ConfigurationLoader
+	WithMerchant() ConfigurationLoader
+	WithProofs() ConfigurationLoader
+	WithHeaders() ConfigurationLoader
+	WithPeerChannels() ConfigurationLoader
+	WithSandbox() ConfigurationLoader
+	WithInvoices() ConfigurationLoader
+	WithXPub() ConfigurationLoader
+	WithRates() ConfigurationLoader
+	WithFees() ConfigurationLoader
+	WithBroadcast() ConfigurationLoader
+	WithConflicts() ConfigurationLoader
+	WithPolicy() ConfigurationLoader
+	WithMemory() ConfigurationLoader
 	Load() *Config
 }
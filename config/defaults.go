@@ -16,6 +16,18 @@ func SetupDefaults() {
 	viper.SetDefault(EnvServerFQDN, "dpp:8445")
 	viper.SetDefault(EnvServerSwaggerEnabled, true)
 	viper.SetDefault(EnvServerSwaggerHost, "localhost:8445")
+	viper.SetDefault(EnvServerReadHeaderTimeout, 10*time.Second)
+	viper.SetDefault(EnvServerReadTimeout, 30*time.Second)
+	viper.SetDefault(EnvServerWriteTimeout, 60*time.Second)
+	viper.SetDefault(EnvServerIdleTimeout, 120*time.Second)
+	viper.SetDefault(EnvServerMaxBodyBytes, 1024*64)
+	viper.SetDefault(EnvServerPaymentMaxBodyBytes, 1024*1024*10)
+	viper.SetDefault(EnvServerProofMaxBodyBytes, 1024*1024)
+	viper.SetDefault(EnvServerAllowedOrigins, "*")
+	viper.SetDefault(EnvServerHSTSMaxAge, 0)
+	viper.SetDefault(EnvServerCSP, "default-src 'none'; frame-ancestors 'none'")
+	viper.SetDefault(EnvServerRateLimit, 0)
+	viper.SetDefault(EnvServerRateLimitBurst, 20)
 
 	// Environment Defaults
 	viper.SetDefault(EnvEnvironment, "dev")
@@ -23,6 +35,7 @@ func SetupDefaults() {
 	viper.SetDefault(EnvCommit, "test")
 	viper.SetDefault(EnvVersion, "v0.0.0")
 	viper.SetDefault(EnvBuildDate, time.Now().UTC())
+	viper.SetDefault(EnvNetwork, NetworkRegtest)
 
 	// Log level defaults
 	viper.SetDefault(EnvLogLevel, "info")
@@ -32,11 +45,95 @@ func SetupDefaults() {
 	viper.SetDefault(EnvPaydPort, ":8443")
 	viper.SetDefault(EnvPaydSecure, false)
 	viper.SetDefault(EnvPaydNoop, false)
+	viper.SetDefault(EnvPaydTimeout, 5*time.Second)
 
 	// Socket settings
 	viper.SetDefault(EnvSocketChannelTimeoutSeconds, 7200*time.Second) // 2 hrs in seconds
 	viper.SetDefault(EnvSocketMaxMessageBytes, 10000)
+	viper.SetDefault(EnvSocketAwaitTimeout, 10*time.Second)
 
 	// Transport settings
 	viper.SetDefault(EnvTransportMode, TransportModeHTTP)
+
+	// Tracing settings
+	viper.SetDefault(EnvTracingEnabled, false)
+	viper.SetDefault(EnvTracingExporter, TracingExporterStdout)
+	viper.SetDefault(EnvTracingEndpoint, "localhost:4318")
+	viper.SetDefault(EnvTracingInsecure, true)
+	viper.SetDefault(EnvTracingSampleRatio, 1.0)
+
+	// Audit settings
+	viper.SetDefault(EnvAuditEnabled, false)
+	viper.SetDefault(EnvAuditSink, AuditSinkFile)
+	viper.SetDefault(EnvAuditFilePath, "data/audit")
+	viper.SetDefault(EnvAuditFileMaxBytes, 100*1024*1024) // 100MB
+
+	// Proof settings, unsigned callbacks were always accepted so remain so by default.
+	viper.SetDefault(EnvProofsAllowUnsigned, true)
+	viper.SetDefault(EnvProofsRequireToken, true)
+	viper.SetDefault(EnvProofsRejectUnverified, false)
+	viper.SetDefault(EnvProofsStoreEnabled, false)
+
+	// Block header settings
+	viper.SetDefault(EnvHeadersEnabled, false)
+	viper.SetDefault(EnvHeadersSource, HeadersSourceFile)
+	viper.SetDefault(EnvHeadersFilePath, "data/headers/blockheaders")
+	viper.SetDefault(EnvHeadersTimeout, 5*time.Second)
+
+	// Peer channel settings
+	viper.SetDefault(EnvPeerChannelsEnabled, false)
+	viper.SetDefault(EnvPeerChannelsMaxMessages, 100)
+	viper.SetDefault(EnvPeerChannelsMaxMessageBytes, 64*1024) // 64KB
+
+	// Sandbox settings, the slow delay is longer than the default payd timeout.
+	viper.SetDefault(EnvSandboxSlowDelay, 30*time.Second)
+
+	// Invoice settings
+	viper.SetDefault(EnvInvoicesEnabled, false)
+
+	// Standalone xpub settings
+	viper.SetDefault(EnvXPubEnabled, false)
+	viper.SetDefault(EnvXPubFilePath, "data/xpub/invoices.json")
+	viper.SetDefault(EnvXPubExpiry, time.Hour)
+
+	// Exchange rate settings
+	viper.SetDefault(EnvRatesEnabled, false)
+	viper.SetDefault(EnvRatesSource, RatesSourceHTTP)
+	viper.SetDefault(EnvRatesFilePath, "data/rates/rates.json")
+	viper.SetDefault(EnvRatesTimeout, 5*time.Second)
+	viper.SetDefault(EnvRatesCacheTTL, time.Minute)
+	viper.SetDefault(EnvRatesQuoteExpiry, 15*time.Minute)
+
+	// Fee quote settings
+	viper.SetDefault(EnvFeesEnabled, false)
+	viper.SetDefault(EnvFeesSource, FeesSourceMAPI)
+	viper.SetDefault(EnvFeesTimeout, 5*time.Second)
+	viper.SetDefault(EnvFeesCacheTTL, 10*time.Minute)
+	viper.SetDefault(EnvFeesFloor, 0)
+	viper.SetDefault(EnvFeesCeiling, 0)
+
+	// Broadcast settings
+	viper.SetDefault(EnvBroadcastEnabled, false)
+	viper.SetDefault(EnvBroadcastSource, BroadcastSourceMAPI)
+	viper.SetDefault(EnvBroadcastTimeout, 10*time.Second)
+
+	// Conflicting input settings
+	viper.SetDefault(EnvConflictsEnabled, false)
+	viper.SetDefault(EnvConflictsAction, ConflictsActionFlag)
+	viper.SetDefault(EnvConflictsRetention, 24*time.Hour)
+
+	// Memory settings
+	viper.SetDefault(EnvMemoryRetention, 72*time.Hour)
+
+	// Payment request policy settings
+	viper.SetDefault(EnvPolicyEnabled, false)
+	viper.SetDefault(EnvPolicyDustLimit, 1)
+	viper.SetDefault(EnvPolicyScripts, "p2pkh,data")
+	viper.SetDefault(EnvPolicyMaxOutputs, 0)
+	viper.SetDefault(EnvPolicyMaxTotal, 0)
+	viper.SetDefault(EnvPolicyMaxExpiry, 0)
+
+	// Paymail settings
+	viper.SetDefault(EnvPaymailEnabled, false)
+	viper.SetDefault(EnvPaymailExpiry, time.Hour)
 }
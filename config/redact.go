@@ -0,0 +1,70 @@
+package config
+
+import (
+	"reflect"
+	"time"
+)
+
+// redacted replaces the value of any secret that has been set.
+const redacted = "*****"
+
+// Redacted returns the config as a map, suitable for encoding, with the values of
+// any fields tagged `secret:"true"` replaced.
+func (c *Config) Redacted() map[string]interface{} {
+	return redact(reflect.ValueOf(c)).(map[string]interface{})
+}
+
+func redact(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		return redact(v.Elem())
+	case reflect.Struct:
+		if v.Type() == reflect.TypeOf(time.Time{}) {
+			return v.Interface()
+		}
+		m := map[string]interface{}{}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" {
+				continue
+			}
+			if f.Tag.Get("secret") == "true" {
+				if !v.Field(i).IsZero() {
+					m[f.Name] = redacted
+				} else {
+					m[f.Name] = ""
+				}
+				continue
+			}
+			m[f.Name] = redact(v.Field(i))
+		}
+		return m
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		s := make([]interface{}, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			s[i] = redact(v.Index(i))
+		}
+		return s
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		m := map[string]interface{}{}
+		iter := v.MapRange()
+		for iter.Next() {
+			m[iter.Key().String()] = redact(iter.Value())
+		}
+		return m
+	}
+	if d, ok := v.Interface().(time.Duration); ok {
+		return d.String()
+	}
+	return v.Interface()
+}
@@ -1,13 +1,473 @@
 package config
 
-import validator "github.com/theflyingcodr/govalidator"
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
-// Validate the configuration.
+	"github.com/libsv/go-bk/bip32"
+	"github.com/libsv/go-bk/chaincfg"
+	"github.com/pkg/errors"
+	validator "github.com/theflyingcodr/govalidator"
+)
+
+// Validate the configuration, every section that has been loaded is checked and
+// all errors found are returned together, keyed by setting name.
 func (c *Config) Validate() error {
 	v := validator.New()
+	mode := ""
 	if c.Transports != nil {
-		v = v.Validate("transport.mode", validator.AnyString(c.Transports.Mode, TransportModeHTTP, TransportModeHybrid, TransportModeSocket))
+		mode = c.Transports.Mode
+		v = v.Validate(EnvTransportMode, oneOf(c.Transports.Mode, TransportModeHTTP, TransportModeHybrid, TransportModeSocket))
+	}
+	if c.Logging != nil {
+		v = v.Validate(EnvLogLevel, oneOf(c.Logging.Level, LogDebug, LogInfo, LogWarn, LogError))
+	}
+	if c.Server != nil {
+		v = v.Validate(EnvServerPort, address(c.Server.Port)).
+			Validate(EnvServerHost, validator.NotEmpty(c.Server.Hostname))
+		if mode == TransportModeHybrid || mode == TransportModeHTTP {
+			v = v.Validate(EnvServerFQDN, required(c.Server.FQDN, "it is used to build payment urls in http and hybrid mode"), host(c.Server.FQDN))
+		}
+		if c.Server.SwaggerEnabled {
+			v = v.Validate(EnvServerSwaggerHost, required(c.Server.SwaggerHost, "swagger is enabled"))
+		}
+		v = v.Validate(EnvServerReadHeaderTimeout, positiveDuration(c.Server.ReadHeaderTimeout)).
+			Validate(EnvServerReadTimeout, positiveDuration(c.Server.ReadTimeout)).
+			Validate(EnvServerWriteTimeout, positiveDuration(c.Server.WriteTimeout)).
+			Validate(EnvServerIdleTimeout, positiveDuration(c.Server.IdleTimeout)).
+			Validate(EnvServerMaxBodyBytes, validator.PositiveInt(c.Server.MaxBodyBytes)).
+			Validate(EnvServerPaymentMaxBodyBytes, validator.PositiveInt(c.Server.PaymentMaxBodyBytes)).
+			Validate(EnvServerProofMaxBodyBytes, validator.PositiveInt(c.Server.ProofMaxBodyBytes)).
+			Validate(EnvServerAllowedOrigins, origins(c.Server.AllowedOrigins)).
+			Validate(EnvServerHSTSMaxAge, validator.MinInt(c.Server.HSTSMaxAge, 0)).
+			Validate(EnvServerRateLimit, func() error {
+				if c.Server.RateLimit < 0 {
+					return fmt.Errorf("%v is not valid, must be 0 or more", c.Server.RateLimit)
+				}
+				return nil
+			})
+		if c.Server.RateLimit > 0 {
+			v = v.Validate(EnvServerRateLimitBurst, validator.PositiveInt(c.Server.RateLimitBurst))
+		}
+	}
+	if c.Deployment != nil {
+		v = v.Validate(EnvEnvironment, validator.NotEmpty(c.Deployment.Environment)).
+			Validate(EnvNetwork, oneOf(c.Deployment.Network, NetworkMainnet, NetworkTestnet, NetworkSTN, NetworkRegtest))
+	}
+	// the xpub store is used in place of payd.
+	xpubEnabled := c.XPub != nil && c.XPub.Enabled
+	// payd is only called over http when running in http mode.
+	if c.PayD != nil && mode == TransportModeHTTP && !c.PayD.Noop && !xpubEnabled {
+		v = v.Validate(EnvPaydHost, required(c.PayD.Host, "payd is called in http mode")).
+			Validate(EnvPaydPort, address(c.PayD.Port)).
+			Validate(EnvPaydTimeout, positiveDuration(c.PayD.Timeout))
+		if c.PayD.CertificatePath != "" {
+			v = v.Validate(EnvPaydCertPath, fileExists(c.PayD.CertificatePath))
+		}
+	}
+	if c.PayD != nil && c.PayD.Noop && c.Sandbox != nil {
+		v = v.Validate(EnvSandboxSlowDelay, positiveDuration(c.Sandbox.SlowDelay))
+		if c.Sandbox.FixturePath != "" {
+			v = v.Validate(EnvSandboxFixturePath, fileExists(c.Sandbox.FixturePath))
+		}
+	}
+	if c.Sockets != nil && (mode == TransportModeSocket || mode == TransportModeHybrid) {
+		v = v.Validate(EnvSocketMaxMessageBytes, validator.PositiveInt(c.Sockets.MaxMessageBytes)).
+			Validate(EnvSocketChannelTimeoutSeconds, positiveDuration(c.Sockets.ChannelTimeout)).
+			Validate(EnvSocketAwaitTimeout, positiveDuration(c.Sockets.AwaitTimeout))
+	}
+	if c.Tracing != nil && c.Tracing.Enabled {
+		v = v.Validate(EnvTracingExporter, oneOf(c.Tracing.Exporter, TracingExporterOTLP, TracingExporterStdout)).
+			Validate(EnvTracingSampleRatio, func() error {
+				if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+					return fmt.Errorf("%v is not valid, must be between 0 and 1", c.Tracing.SampleRatio)
+				}
+				return nil
+			})
+		if c.Tracing.Exporter == TracingExporterOTLP {
+			v = v.Validate(EnvTracingEndpoint, required(c.Tracing.Endpoint, "the otlp exporter is used"), host(c.Tracing.Endpoint))
+		}
+	}
+	if c.Audit != nil && c.Audit.Enabled {
+		v = v.Validate(EnvAuditSink, oneOf(c.Audit.Sink, AuditSinkFile, AuditSinkStdout))
+		if c.Audit.Sink == AuditSinkFile {
+			v = v.Validate(EnvAuditFilePath, required(c.Audit.FilePath, "the file audit sink is used")).
+				Validate(EnvAuditFileMaxBytes, validator.MinInt64(c.Audit.FileMaxBytes, 0))
+		}
+	}
+	// the paymail store is only used in http mode.
+	if c.Paymail != nil && c.Paymail.Enabled && mode == TransportModeHTTP {
+		merchantToken := ""
+		if c.Merchant != nil {
+			merchantToken = c.Merchant.Token
+		}
+		v = v.Validate(EnvPaymailHandle, paymail(c.Paymail.Handle)).
+			Validate(EnvPaymailExpiry, positiveDuration(c.Paymail.Expiry)).
+			Validate(EnvMerchantToken, required(merchantToken, "paymail invoices are created with the merchant endpoints"))
+	}
+	if c.Headers != nil && c.Headers.Enabled {
+		v = v.Validate(EnvHeadersSource, oneOf(c.Headers.Source, HeadersSourceFile, HeadersSourceHTTP))
+		switch c.Headers.Source {
+		case HeadersSourceFile:
+			v = v.Validate(EnvHeadersFilePath, required(c.Headers.FilePath, "the file headers source is used"))
+		case HeadersSourceHTTP:
+			v = v.Validate(EnvHeadersURL, required(c.Headers.URL, "the http headers source is used"), httpURL(c.Headers.URL)).
+				Validate(EnvHeadersTimeout, positiveDuration(c.Headers.Timeout))
+		}
+	}
+	if c.PeerChannels != nil && c.PeerChannels.Enabled {
+		v = v.Validate(EnvPeerChannelsMaxMessages, validator.PositiveInt(c.PeerChannels.MaxMessages)).
+			Validate(EnvPeerChannelsMaxMessageBytes, validator.PositiveInt(c.PeerChannels.MaxMessageBytes))
+	}
+	if xpubEnabled {
+		network := ""
+		if c.Deployment != nil {
+			network = c.Deployment.Network
+		}
+		v = v.Validate(EnvXPubKey, required(c.XPub.Key, "outputs are derived from it"), xpub(c.XPub.Key, network)).
+			Validate(EnvXPubFilePath, required(c.XPub.FilePath, "invoices are kept in it")).
+			Validate(EnvXPubExpiry, positiveDuration(c.XPub.Expiry)).
+			Validate(EnvXPubEnabled, func() error {
+				if mode != TransportModeHTTP {
+					return errors.New("xpub can only be used in http mode")
+				}
+				if (c.PayD != nil && c.PayD.Noop) || (c.Paymail != nil && c.Paymail.Enabled) {
+					return errors.New("xpub can't be used with payd noop or paymail")
+				}
+				return nil
+			})
+		merchantToken := ""
+		if c.Merchant != nil {
+			merchantToken = c.Merchant.Token
+		}
+		v = v.Validate(EnvMerchantToken, required(merchantToken, "xpub invoices are created with the merchant endpoints")).
+			Validate(EnvBroadcastEnabled, func() error {
+				if c.Broadcast == nil || !c.Broadcast.Enabled {
+					return errors.New("xpub payments are broadcast before the invoice is paid, broadcast must be enabled")
+				}
+				return nil
+			})
+	}
+	if c.Rates != nil && c.Rates.Enabled {
+		v = v.Validate(EnvRatesEnabled, func() error {
+			if !xpubEnabled {
+				return errors.New("rates are only used by xpub invoices, xpub must be enabled")
+			}
+			return nil
+		}).
+			Validate(EnvRatesSource, oneOf(c.Rates.Source, RatesSourceFile, RatesSourceHTTP)).
+			Validate(EnvRatesCacheTTL, positiveDuration(c.Rates.CacheTTL)).
+			Validate(EnvRatesQuoteExpiry, positiveDuration(c.Rates.QuoteExpiry))
+		switch c.Rates.Source {
+		case RatesSourceFile:
+			v = v.Validate(EnvRatesFilePath, fileExists(c.Rates.FilePath))
+		case RatesSourceHTTP:
+			v = v.Validate(EnvRatesURL, required(c.Rates.URL, "the http rates source is used"), httpURL(c.Rates.URL)).
+				Validate(EnvRatesTimeout, positiveDuration(c.Rates.Timeout))
+		}
+	}
+	if c.Fees != nil && c.Fees.Enabled {
+		v = v.Validate(EnvFeesSource, oneOf(c.Fees.Source, FeesSourceMAPI, FeesSourceARC, FeesSourceStub)).
+			Validate(EnvFeesCacheTTL, positiveDuration(c.Fees.CacheTTL)).
+			Validate(EnvFeesFloor, validator.MinInt(c.Fees.Floor, 0)).
+			Validate(EnvFeesCeiling, validator.MinInt(c.Fees.Ceiling, 0), func() error {
+				if c.Fees.Ceiling != 0 && c.Fees.Ceiling < c.Fees.Floor {
+					return fmt.Errorf("ceiling %d is below the floor %d", c.Fees.Ceiling, c.Fees.Floor)
+				}
+				return nil
+			})
+		if c.Fees.Source != FeesSourceStub {
+			v = v.Validate(EnvFeesURL, required(c.Fees.URL, "fees are read from the "+c.Fees.Source+" server"), httpURL(c.Fees.URL)).
+				Validate(EnvFeesTimeout, positiveDuration(c.Fees.Timeout))
+		}
+	}
+	if c.Invoices != nil && c.Invoices.Enabled {
+		v = v.Validate(EnvInvoicesEnabled, func() error {
+			if mode != TransportModeHybrid {
+				return errors.New("invoices can only be registered in hybrid mode")
+			}
+			return nil
+		})
+		merchantToken := ""
+		if c.Merchant != nil {
+			merchantToken = c.Merchant.Token
+		}
+		v = v.Validate(EnvMerchantToken, required(merchantToken, "invoices are registered with the merchant endpoints"))
+	}
+	if c.Broadcast != nil && c.Broadcast.Enabled {
+		v = v.Validate(EnvBroadcastEnabled, func() error {
+			if (c.Invoices == nil || !c.Invoices.Enabled) && !xpubEnabled {
+				return errors.New("only payments for registered invoices and xpub invoices are broadcast, invoices or xpub must be enabled")
+			}
+			return nil
+		}).
+			Validate(EnvBroadcastSource, oneOf(c.Broadcast.Source, BroadcastSourceMAPI, BroadcastSourceARC, BroadcastSourceStub))
+		if c.Broadcast.Source != BroadcastSourceStub {
+			v = v.Validate(EnvBroadcastURL, required(c.Broadcast.URL, "transactions are broadcast to the "+c.Broadcast.Source+" server"),
+				httpURL(c.Broadcast.URL)).
+				Validate(EnvBroadcastTimeout, positiveDuration(c.Broadcast.Timeout))
+		}
+	}
+	if c.Conflicts != nil && c.Conflicts.Enabled {
+		v = v.Validate(EnvConflictsEnabled, func() error {
+			if mode == TransportModeSocket {
+				return errors.New("conflicting inputs are only checked in http and hybrid mode")
+			}
+			return nil
+		}).
+			Validate(EnvConflictsAction, oneOf(c.Conflicts.Action, ConflictsActionFlag, ConflictsActionReject)).
+			Validate(EnvConflictsRetention, positiveDuration(c.Conflicts.Retention))
+	}
+	if c.Policy != nil && c.Policy.Enabled {
+		v = v.Validate(EnvPolicyEnabled, func() error {
+			if mode == TransportModeSocket {
+				return errors.New("the policy is only checked in http and hybrid mode")
+			}
+			return nil
+		}).
+			Validate(EnvPolicyScripts, scripts(c.Policy.Scripts)).
+			Validate(EnvPolicyMaxOutputs, validator.MinInt(c.Policy.MaxOutputs, 0)).
+			Validate(EnvPolicyMaxExpiry, func() error {
+				if c.Policy.MaxExpiry < 0 {
+					return fmt.Errorf("'%s' is not valid, must be 0 or greater", c.Policy.MaxExpiry)
+				}
+				return nil
+			}).
+			Validate(EnvPolicyMerchantData, merchantData(c.Policy.MerchantData))
+	}
+	if c.Memory != nil {
+		v = v.Validate(EnvMemoryRetention, positiveDuration(c.Memory.Retention))
+	}
+	if c.Admin != nil && c.Admin.Token != "" {
+		v = v.Validate(EnvAdminToken, token(c.Admin.Token))
+	}
+	if c.Merchant != nil && c.Merchant.Token != "" {
+		v = v.Validate(EnvMerchantToken, token(c.Merchant.Token))
 	}
 
 	return v.Err()
 }
+
+// EnvVar returns the environment variable used to set a config key.
+func EnvVar(key string) string {
+	return strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// Report formats a validation error into a human readable report, listing each
+// setting with its environment variable and the problems found.
+func Report(err error) string {
+	var vErr validator.ErrValidation
+	if !errors.As(err, &vErr) {
+		return err.Error()
+	}
+	keys := make([]string, 0, len(vErr))
+	for k := range vErr {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, "%d invalid setting(s) found:\n", len(keys))
+	for _, k := range keys {
+		fmt.Fprintf(sb, "  %s (%s): %s\n", k, EnvVar(k), strings.Join(vErr[k], ", "))
+	}
+	return sb.String()
+}
+
+// oneOf checks val is one of the allowed values, listing them if not.
+func oneOf(val string, vv ...string) validator.ValidationFunc {
+	return func() error {
+		for _, v := range vv {
+			if val == v {
+				return nil
+			}
+		}
+		return fmt.Errorf("'%s' is not valid, must be one of: %s", val, strings.Join(vv, ", "))
+	}
+}
+
+// token checks a bearer token is long enough to not be guessed.
+func token(val string) validator.ValidationFunc {
+	return func() error {
+		if len(val) < 16 {
+			return errors.New("token is too short, it must be at least 16 characters")
+		}
+		return nil
+	}
+}
+
+// required checks val is set, reason explains why it is needed.
+func required(val, reason string) validator.ValidationFunc {
+	return func() error {
+		if val == "" {
+			return fmt.Errorf("value is required as %s", reason)
+		}
+		return nil
+	}
+}
+
+// address checks val is a listen address in the format ':port' or 'host:port'.
+func address(val string) validator.ValidationFunc {
+	return func() error {
+		_, port, err := net.SplitHostPort(val)
+		if err != nil {
+			return fmt.Errorf("'%s' is not valid, expected the format ':8443' or 'host:8443'", val)
+		}
+		return validPort(val, port)
+	}
+}
+
+// host checks val is a host with an optional port and no scheme or path, for example 'dpp.example.com:8445'.
+func host(val string) validator.ValidationFunc {
+	return func() error {
+		if val == "" {
+			return nil
+		}
+		if strings.Contains(val, "/") {
+			return fmt.Errorf("'%s' is not valid, expected a host and optional port without a scheme or path, for example 'dpp.example.com:8445'", val)
+		}
+		h, port, err := net.SplitHostPort(val)
+		if err != nil {
+			// no port supplied.
+			h, port = val, ""
+		}
+		if h == "" {
+			return fmt.Errorf("'%s' is not valid, a host is required", val)
+		}
+		if port == "" {
+			return nil
+		}
+		return validPort(val, port)
+	}
+}
+
+func validPort(val, port string) error {
+	p, err := strconv.Atoi(port)
+	if err != nil || p < 1 || p > 65535 {
+		return fmt.Errorf("'%s' is not valid, port must be a number between 1 and 65535", val)
+	}
+	return nil
+}
+
+// httpURL checks val is an absolute http or https url, for example 'https://headers.example.com'.
+func httpURL(val string) validator.ValidationFunc {
+	return func() error {
+		if val == "" {
+			return nil
+		}
+		u, err := url.Parse(val)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("'%s' is not valid, expected an http or https url, for example 'https://headers.example.com'", val)
+		}
+		return nil
+	}
+}
+
+// origins checks each of vals is '*' or an origin with a scheme, host and optional port, for
+// example 'https://wallet.example.com'.
+func origins(vals []string) validator.ValidationFunc {
+	return func() error {
+		for _, val := range vals {
+			if val == "*" {
+				continue
+			}
+			u, err := url.Parse(val)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Path != "" {
+				return fmt.Errorf("'%s' is not valid, expected '*' or an origin, for example 'https://wallet.example.com'", val)
+			}
+		}
+		return nil
+	}
+}
+
+// scripts checks each of vals is a supported locking script template.
+func scripts(vals []string) validator.ValidationFunc {
+	return func() error {
+		for _, val := range vals {
+			if err := oneOf(val, ScriptData, ScriptMultiSig, ScriptP2PK, ScriptP2PKH, ScriptP2SH)(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// merchantData checks each of vals is a merchant data field, or a key of its extended data.
+func merchantData(vals []string) validator.ValidationFunc {
+	return func() error {
+		for _, val := range vals {
+			switch {
+			case val == "avatar", val == "name", val == "email", val == "address":
+			case strings.HasPrefix(val, "extendedData.") && len(val) > len("extendedData."):
+			default:
+				return fmt.Errorf("'%s' is not valid, must be one of: avatar, name, email, address or extendedData.{key}", val)
+			}
+		}
+		return nil
+	}
+}
+
+// paymail checks val is a paymail handle in the format 'alias@domain.tld'.
+func paymail(val string) validator.ValidationFunc {
+	return func() error {
+		parts := strings.Split(val, "@")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" || strings.ContainsAny(val, "/ ") {
+			return fmt.Errorf("'%s' is not valid, expected a paymail in the format 'merchant@example.com'", val)
+		}
+		return nil
+	}
+}
+
+// positiveDuration checks d is greater than 0.
+func positiveDuration(d time.Duration) validator.ValidationFunc {
+	return func() error {
+		if d <= 0 {
+			return fmt.Errorf("'%s' is not valid, must be greater than 0, for example '10s'", d)
+		}
+		return nil
+	}
+}
+
+// xpub checks val is an extended public key for the network, the key isn't included in
+// errors as it identifies every payment to the merchant.
+func xpub(val, network string) validator.ValidationFunc {
+	return func() error {
+		if val == "" {
+			return nil
+		}
+		key, err := bip32.NewKeyFromString(val)
+		if err != nil {
+			return errors.New("value is not a valid extended key")
+		}
+		if key.IsPrivate() {
+			return errors.New("value is an extended private key, only the extended public key should be configured")
+		}
+		params := &chaincfg.TestNet
+		if network == NetworkMainnet {
+			params = &chaincfg.MainNet
+		}
+		if !key.IsForNet(params) {
+			return fmt.Errorf("value is not an extended public key for %s", network)
+		}
+		return nil
+	}
+}
+
+// fileExists checks a file can be found at path.
+func fileExists(path string) validator.ValidationFunc {
+	return func() error {
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("file '%s' cannot be read: %s", path, err)
+		}
+		if info.IsDir() {
+			return fmt.Errorf("'%s' is a directory, expected a file", path)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,500 @@
+package config_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bitcoin-sv/dpp-proxy/config"
+)
+
+func validConfig() *config.Config {
+	return &config.Config{
+		Logging: &config.Logging{Level: config.LogInfo},
+		Server: &config.Server{
+			Port:                ":8445",
+			Hostname:            "dpp-proxy",
+			FQDN:                "dpp.example.com:8445",
+			ReadHeaderTimeout:   10 * time.Second,
+			ReadTimeout:         30 * time.Second,
+			WriteTimeout:        time.Minute,
+			IdleTimeout:         2 * time.Minute,
+			MaxBodyBytes:        65536,
+			PaymentMaxBodyBytes: 10485760,
+			ProofMaxBodyBytes:   1048576,
+			AllowedOrigins:      []string{"*"},
+		},
+		Deployment: &config.Deployment{Environment: "local", Network: config.NetworkRegtest},
+		PayD:       &config.PayD{Host: "payd", Port: ":8443", Timeout: 5 * time.Second},
+		Sockets: &config.Socket{
+			MaxMessageBytes: 10000,
+			ChannelTimeout:  time.Hour,
+			AwaitTimeout:    10 * time.Second,
+		},
+		Transports:   &config.Transports{Mode: config.TransportModeHybrid},
+		Tracing:      &config.Tracing{},
+		Audit:        &config.Audit{},
+		Admin:        &config.Admin{},
+		Paymail:      &config.Paymail{},
+		Merchant:     &config.Merchant{},
+		Proofs:       &config.Proofs{},
+		Headers:      &config.Headers{},
+		PeerChannels: &config.PeerChannels{},
+		Sandbox:      &config.Sandbox{SlowDelay: 30 * time.Second},
+		Invoices:     &config.Invoices{},
+		XPub:         &config.XPub{FilePath: "data/xpub/invoices.json", Expiry: time.Hour},
+		Rates: &config.Rates{Source: config.RatesSourceHTTP, FilePath: "../data/rates/rates.json", Timeout: 5 * time.Second,
+			CacheTTL: time.Minute, QuoteExpiry: 15 * time.Minute},
+		Fees:      &config.Fees{Source: config.FeesSourceMAPI, Timeout: 5 * time.Second, CacheTTL: 10 * time.Minute},
+		Broadcast: &config.Broadcast{Source: config.BroadcastSourceMAPI, Timeout: 10 * time.Second},
+		Conflicts: &config.Conflicts{Action: config.ConflictsActionFlag, Retention: 24 * time.Hour},
+		Policy:    &config.Policy{DustLimit: 1, Scripts: []string{config.ScriptP2PKH, config.ScriptData}},
+		Memory:    &config.Memory{Retention: 72 * time.Hour},
+	}
+}
+
+// xpubConfig enables the xpub store with a valid testnet key.
+func xpubConfig(c *config.Config) {
+	c.Transports.Mode = config.TransportModeHTTP
+	c.Merchant.Token = "abcdefghijklmnopqrstuvwxyz"
+	c.XPub.Enabled = true
+	c.XPub.Key = "tpubD6NzVbkrYhZ4Wgf68pWWfTRzdXMoepBvepfiAKtNoE7RvbAbWVfWzQxH1jbkfNk3iJ9zR65Yw6u3B2QZzpkMSTN4y8Lfm1t44HbpZX7efhZ"
+	c.Broadcast.Enabled = true
+	c.Broadcast.Source = config.BroadcastSourceStub
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := map[string]struct {
+		cfgFn  func(c *config.Config)
+		expErr error
+	}{
+		"valid config should pass": {
+			cfgFn: func(c *config.Config) {},
+		},
+		"unknown transport mode should fail": {
+			cfgFn: func(c *config.Config) {
+				c.Transports.Mode = "carrier-pigeon"
+			},
+			expErr: errors.New("[transport.mode: 'carrier-pigeon' is not valid, must be one of: http, hybrid, socket]"),
+		},
+		"unknown network should fail": {
+			cfgFn: func(c *config.Config) {
+				c.Deployment.Network = "bitcoin"
+			},
+			expErr: errors.New("[env.bitcoin.network: 'bitcoin' is not valid, must be one of: mainnet, testnet, stn, regtest]"),
+		},
+		"unknown log level should fail": {
+			cfgFn: func(c *config.Config) {
+				c.Logging.Level = "loud"
+			},
+			expErr: errors.New("[log.level: 'loud' is not valid, must be one of: debug, info, warn, error]"),
+		},
+		"invalid server port should fail": {
+			cfgFn: func(c *config.Config) {
+				c.Server.Port = "8445"
+			},
+			expErr: errors.New("[server.port: '8445' is not valid, expected the format ':8443' or 'host:8443']"),
+		},
+		"missing fqdn in hybrid mode should fail": {
+			cfgFn: func(c *config.Config) {
+				c.Server.FQDN = ""
+			},
+			expErr: errors.New("[server.fqdn: value is required as it is used to build payment urls in http and hybrid mode]"),
+		},
+		"fqdn with scheme should fail": {
+			cfgFn: func(c *config.Config) {
+				c.Server.FQDN = "https://dpp.example.com"
+			},
+			expErr: errors.New("[server.fqdn: 'https://dpp.example.com' is not valid, expected a host and optional port without a scheme or path, for example 'dpp.example.com:8445']"),
+		},
+		"zero server write timeout should fail": {
+			cfgFn: func(c *config.Config) {
+				c.Server.WriteTimeout = 0
+			},
+			expErr: errors.New("[server.write.timeout: '0s' is not valid, must be greater than 0, for example '10s']"),
+		},
+		"zero payment body limit should fail": {
+			cfgFn: func(c *config.Config) {
+				c.Server.PaymentMaxBodyBytes = 0
+			},
+			expErr: errors.New("[server.payment.maxbody.bytes: value 0 should be greater than 0]"),
+		},
+		"negative rate limit should fail": {
+			cfgFn: func(c *config.Config) {
+				c.Server.RateLimit = -1
+			},
+			expErr: errors.New("[server.ratelimit: -1 is not valid, must be 0 or more]"),
+		},
+		"rate limit without a burst should fail": {
+			cfgFn: func(c *config.Config) {
+				c.Server.RateLimit = 5
+			},
+			expErr: errors.New("[server.ratelimit.burst: value 0 should be greater than 0]"),
+		},
+		"rate limit with a burst should pass": {
+			cfgFn: func(c *config.Config) {
+				c.Server.RateLimit = 0.5
+				c.Server.RateLimitBurst = 10
+			},
+		},
+		"allowed origins should pass": {
+			cfgFn: func(c *config.Config) {
+				c.Server.AllowedOrigins = []string{"https://wallet.example.com", "http://localhost:3000"}
+			},
+		},
+		"allowed origin with a path should fail": {
+			cfgFn: func(c *config.Config) {
+				c.Server.AllowedOrigins = []string{"https://wallet.example.com/pay"}
+			},
+			expErr: errors.New("[server.allowed.origins: 'https://wallet.example.com/pay' is not valid, expected '*' or an origin, for example 'https://wallet.example.com']"),
+		},
+		"missing fqdn in socket mode should pass": {
+			cfgFn: func(c *config.Config) {
+				c.Transports.Mode = config.TransportModeSocket
+				c.Server.FQDN = ""
+			},
+		},
+		"invalid payd port in http mode should fail": {
+			cfgFn: func(c *config.Config) {
+				c.Transports.Mode = config.TransportModeHTTP
+				c.PayD.Port = ":99999"
+			},
+			expErr: errors.New("[payd.port: ':99999' is not valid, port must be a number between 1 and 65535]"),
+		},
+		"invalid payd settings should be ignored when noop": {
+			cfgFn: func(c *config.Config) {
+				c.Transports.Mode = config.TransportModeHTTP
+				c.PayD.Noop = true
+				c.PayD.Port = ""
+			},
+		},
+		"missing payd cert should fail": {
+			cfgFn: func(c *config.Config) {
+				c.Transports.Mode = config.TransportModeHTTP
+				c.PayD.CertificatePath = "/does/not/exist.pem"
+			},
+			expErr: errors.New("[payd.cert.path: file '/does/not/exist.pem' cannot be read: stat /does/not/exist.pem: no such file or directory]"),
+		},
+		"non positive socket timeouts should fail": {
+			cfgFn: func(c *config.Config) {
+				c.Sockets.AwaitTimeout = 0
+				c.Sockets.ChannelTimeout = -time.Second
+			},
+			expErr: errors.New("[socket.await.timeout: '0s' is not valid, must be greater than 0, for example '10s'], " +
+				"[socket.channel.timeoutseconds: '-1s' is not valid, must be greater than 0, for example '10s']"),
+		},
+		"otlp tracing without endpoint should fail": {
+			cfgFn: func(c *config.Config) {
+				c.Tracing = &config.Tracing{Enabled: true, Exporter: config.TracingExporterOTLP, SampleRatio: 1}
+			},
+			expErr: errors.New("[tracing.endpoint: value is required as the otlp exporter is used]"),
+		},
+		"enabled paymail store should pass": {
+			cfgFn: func(c *config.Config) {
+				c.Transports.Mode = config.TransportModeHTTP
+				c.Paymail = &config.Paymail{Enabled: true, Handle: "merchant@example.com", Expiry: time.Hour}
+				c.Merchant.Token = "abcdefghijklmnopqrstuvwxyz"
+			},
+		},
+		"invalid paymail store settings should fail": {
+			cfgFn: func(c *config.Config) {
+				c.Transports.Mode = config.TransportModeHTTP
+				c.Paymail = &config.Paymail{Enabled: true, Handle: "merchant", Expiry: time.Hour}
+			},
+			expErr: errors.New("[merchant.token: value is required as paymail invoices are created with the merchant endpoints], " +
+				"[paymail.handle: 'merchant' is not valid, expected a paymail in the format 'merchant@example.com']"),
+		},
+		"http headers source should pass": {
+			cfgFn: func(c *config.Config) {
+				c.Headers = &config.Headers{Enabled: true, Source: config.HeadersSourceHTTP, URL: "https://headers.example.com", Timeout: time.Second}
+			},
+		},
+		"http headers source without a url scheme should fail": {
+			cfgFn: func(c *config.Config) {
+				c.Headers = &config.Headers{Enabled: true, Source: config.HeadersSourceHTTP, URL: "headers.example.com", Timeout: time.Second}
+			},
+			expErr: errors.New("[headers.url: 'headers.example.com' is not valid, expected an http or https url, for example 'https://headers.example.com']"),
+		},
+		"file headers source without a path should fail": {
+			cfgFn: func(c *config.Config) {
+				c.Headers = &config.Headers{Enabled: true, Source: config.HeadersSourceFile}
+			},
+			expErr: errors.New("[headers.file.path: value is required as the file headers source is used]"),
+		},
+		"peer channels without a message limit should fail": {
+			cfgFn: func(c *config.Config) {
+				c.PeerChannels = &config.PeerChannels{Enabled: true, MaxMessageBytes: 1024}
+			},
+			expErr: errors.New("[peerchannels.max.messages: value 0 should be greater than 0]"),
+		},
+		"sandbox with a missing fixture file should fail": {
+			cfgFn: func(c *config.Config) {
+				c.PayD.Noop = true
+				c.Sandbox = &config.Sandbox{FixturePath: "testdata/nope.json", SlowDelay: time.Second}
+			},
+			expErr: errors.New("[sandbox.fixture.path: file 'testdata/nope.json' cannot be read: stat testdata/nope.json: no such file or directory]"),
+		},
+		"sandbox without a slow delay should fail": {
+			cfgFn: func(c *config.Config) {
+				c.PayD.Noop = true
+				c.Sandbox.SlowDelay = 0
+			},
+			expErr: errors.New("[sandbox.slow.delay: '0s' is not valid, must be greater than 0, for example '10s']"),
+		},
+		"invoices without a merchant token should fail": {
+			cfgFn: func(c *config.Config) {
+				c.Invoices.Enabled = true
+			},
+			expErr: errors.New("[merchant.token: value is required as invoices are registered with the merchant endpoints]"),
+		},
+		"invoices outside hybrid mode should fail": {
+			cfgFn: func(c *config.Config) {
+				c.Transports.Mode = config.TransportModeHTTP
+				c.Merchant.Token = "abcdefghijklmnopqrstuvwxyz"
+				c.Invoices.Enabled = true
+			},
+			expErr: errors.New("[invoices.enabled: invoices can only be registered in hybrid mode]"),
+		},
+		"invoices in hybrid mode with a merchant token should pass": {
+			cfgFn: func(c *config.Config) {
+				c.Merchant.Token = "abcdefghijklmnopqrstuvwxyz"
+				c.Invoices.Enabled = true
+			},
+		},
+		"xpub in http mode with a merchant token should pass": {
+			cfgFn: func(c *config.Config) {
+				xpubConfig(c)
+				c.PayD.Host = ""
+			},
+		},
+		"xpub without a key or merchant token should fail": {
+			cfgFn: func(c *config.Config) {
+				xpubConfig(c)
+				c.XPub.Key = ""
+				c.Merchant.Token = ""
+			},
+			expErr: errors.New("[merchant.token: value is required as xpub invoices are created with the merchant endpoints], [xpub.key: value is required as outputs are derived from it]"),
+		},
+		"xpub without broadcast should fail": {
+			cfgFn: func(c *config.Config) {
+				xpubConfig(c)
+				c.Broadcast.Enabled = false
+			},
+			expErr: errors.New("[broadcast.enabled: xpub payments are broadcast before the invoice is paid, broadcast must be enabled]"),
+		},
+		"xpub with an invalid key should fail": {
+			cfgFn: func(c *config.Config) {
+				xpubConfig(c)
+				c.XPub.Key = "tpubnope"
+			},
+			expErr: errors.New("[xpub.key: value is not a valid extended key]"),
+		},
+		"xpub with an extended private key should fail": {
+			cfgFn: func(c *config.Config) {
+				xpubConfig(c)
+				c.XPub.Key = "tprv8ZgxMBicQKsPdDdJFAqvG3mt4VqsVV125X4vsor5NxK366upt6qvovLQqaCi5SJiCE1aLkt3HtxsnTpzeGu27kPC5RUCr4h3oPBPYnAvhdE"
+			},
+			expErr: errors.New("[xpub.key: value is an extended private key, only the extended public key should be configured]"),
+		},
+		"xpub for another network should fail": {
+			cfgFn: func(c *config.Config) {
+				xpubConfig(c)
+				c.Deployment.Network = config.NetworkMainnet
+			},
+			expErr: errors.New("[xpub.key: value is not an extended public key for mainnet]"),
+		},
+		"xpub outside http mode should fail": {
+			cfgFn: func(c *config.Config) {
+				xpubConfig(c)
+				c.Transports.Mode = config.TransportModeHybrid
+			},
+			expErr: errors.New("[xpub.enabled: xpub can only be used in http mode]"),
+		},
+		"rates without xpub should fail": {
+			cfgFn: func(c *config.Config) {
+				c.Rates.Enabled = true
+				c.Rates.URL = "https://rates.example.com/{currency}"
+			},
+			expErr: errors.New("[rates.enabled: rates are only used by xpub invoices, xpub must be enabled]"),
+		},
+		"http rates with xpub should pass": {
+			cfgFn: func(c *config.Config) {
+				xpubConfig(c)
+				c.Rates.Enabled = true
+				c.Rates.URL = "https://rates.example.com/api/v1/rate/{currency}"
+			},
+		},
+		"http rates without a url should fail": {
+			cfgFn: func(c *config.Config) {
+				xpubConfig(c)
+				c.Rates.Enabled = true
+				c.Rates.QuoteExpiry = 0
+			},
+			expErr: errors.New("[rates.quote.expiry: '0s' is not valid, must be greater than 0, for example '10s'], [rates.url: value is required as the http rates source is used]"),
+		},
+		"file rates with xpub should pass": {
+			cfgFn: func(c *config.Config) {
+				xpubConfig(c)
+				c.Rates.Enabled = true
+				c.Rates.Source = config.RatesSourceFile
+			},
+		},
+		"unknown rates source should fail": {
+			cfgFn: func(c *config.Config) {
+				xpubConfig(c)
+				c.Rates.Enabled = true
+				c.Rates.Source = "feed"
+			},
+			expErr: errors.New("[rates.source: 'feed' is not valid, must be one of: file, http]"),
+		},
+		"mapi fees should pass": {
+			cfgFn: func(c *config.Config) {
+				c.Fees.Enabled = true
+				c.Fees.URL = "https://mapi.example.com"
+				c.Fees.Floor = 1
+				c.Fees.Ceiling = 500
+			},
+		},
+		"stub fees without a url should pass": {
+			cfgFn: func(c *config.Config) {
+				c.Fees.Enabled = true
+				c.Fees.Source = config.FeesSourceStub
+			},
+		},
+		"arc fees without a url should fail": {
+			cfgFn: func(c *config.Config) {
+				c.Fees.Enabled = true
+				c.Fees.Source = config.FeesSourceARC
+			},
+			expErr: errors.New("[fees.url: value is required as fees are read from the arc server]"),
+		},
+		"fees in socket mode should pass": {
+			cfgFn: func(c *config.Config) {
+				c.Transports.Mode = config.TransportModeSocket
+				c.Fees.Enabled = true
+				c.Fees.Source = config.FeesSourceStub
+			},
+		},
+		"fee ceiling below the floor should fail": {
+			cfgFn: func(c *config.Config) {
+				c.Fees.Enabled = true
+				c.Fees.Source = config.FeesSourceStub
+				c.Fees.Floor = 100
+				c.Fees.Ceiling = 50
+			},
+			expErr: errors.New("[fees.ceiling: ceiling 50 is below the floor 100]"),
+		},
+		"broadcast with invoices should pass": {
+			cfgFn: func(c *config.Config) {
+				c.Invoices.Enabled = true
+				c.Merchant.Token = "abcdefghijklmnopqrstuvwxyz"
+				c.Broadcast.Enabled = true
+				c.Broadcast.URL = "https://mapi.example.com"
+			},
+		},
+		"broadcast without invoices should fail": {
+			cfgFn: func(c *config.Config) {
+				c.Broadcast.Enabled = true
+				c.Broadcast.Source = config.BroadcastSourceStub
+			},
+			expErr: errors.New("[broadcast.enabled: only payments for registered invoices and xpub invoices are broadcast, invoices or xpub must be enabled]"),
+		},
+		"arc broadcast without a url should fail": {
+			cfgFn: func(c *config.Config) {
+				c.Invoices.Enabled = true
+				c.Merchant.Token = "abcdefghijklmnopqrstuvwxyz"
+				c.Broadcast.Enabled = true
+				c.Broadcast.Source = config.BroadcastSourceARC
+			},
+			expErr: errors.New("[broadcast.url: value is required as transactions are broadcast to the arc server]"),
+		},
+		"conflicts should pass": {
+			cfgFn: func(c *config.Config) {
+				c.Conflicts.Enabled = true
+				c.Conflicts.Action = config.ConflictsActionReject
+			},
+		},
+		"conflicts in socket mode should fail": {
+			cfgFn: func(c *config.Config) {
+				c.Transports.Mode = config.TransportModeSocket
+				c.Conflicts.Enabled = true
+			},
+			expErr: errors.New("[conflicts.enabled: conflicting inputs are only checked in http and hybrid mode]"),
+		},
+		"unknown conflicts action should fail": {
+			cfgFn: func(c *config.Config) {
+				c.Conflicts.Enabled = true
+				c.Conflicts.Action = "ignore"
+				c.Conflicts.Retention = 0
+			},
+			expErr: errors.New("[conflicts.action: 'ignore' is not valid, must be one of: flag, reject], " +
+				"[conflicts.retention: '0s' is not valid, must be greater than 0, for example '10s']"),
+		},
+		"policy should pass": {
+			cfgFn: func(c *config.Config) {
+				c.Policy.Enabled = true
+				c.Policy.MaxOutputs = 10
+				c.Policy.MaxExpiry = time.Hour
+				c.Policy.MerchantData = []string{"name", "extendedData.orderID"}
+			},
+		},
+		"policy in socket mode should fail": {
+			cfgFn: func(c *config.Config) {
+				c.Transports.Mode = config.TransportModeSocket
+				c.Policy.Enabled = true
+			},
+			expErr: errors.New("[policy.enabled: the policy is only checked in http and hybrid mode]"),
+		},
+		"unknown policy scripts and merchant data should fail": {
+			cfgFn: func(c *config.Config) {
+				c.Policy.Enabled = true
+				c.Policy.Scripts = []string{"p2tr"}
+				c.Policy.MerchantData = []string{"extendedData."}
+			},
+			expErr: errors.New("[policy.merchantdata: 'extendedData.' is not valid, must be one of: avatar, name, email, address or extendedData.{key}], " +
+				"[policy.scripts: 'p2tr' is not valid, must be one of: data, multisig, p2pk, p2pkh, p2sh]"),
+		},
+		"memory retention of 0 should fail": {
+			cfgFn: func(c *config.Config) {
+				c.Memory.Retention = 0
+			},
+			expErr: errors.New("[memory.retention: '0s' is not valid, must be greater than 0, for example '10s']"),
+		},
+		"short admin token should fail": {
+			cfgFn: func(c *config.Config) {
+				c.Admin.Token = "abc"
+			},
+			expErr: errors.New("[admin.token: token is too short, it must be at least 16 characters]"),
+		},
+		"short merchant token should fail": {
+			cfgFn: func(c *config.Config) {
+				c.Merchant.Token = "abc"
+			},
+			expErr: errors.New("[merchant.token: token is too short, it must be at least 16 characters]"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg := validConfig()
+			test.cfgFn(cfg)
+			err := cfg.Validate()
+			if test.expErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, test.expErr.Error())
+		})
+	}
+}
+
+func TestReport(t *testing.T) {
+	cfg := validConfig()
+	cfg.Logging.Level = "loud"
+	cfg.Sockets.AwaitTimeout = 0
+	exp := "2 invalid setting(s) found:\n" +
+		"  log.level (LOG_LEVEL): 'loud' is not valid, must be one of: debug, info, warn, error\n" +
+		"  socket.await.timeout (SOCKET_AWAIT_TIMEOUT): '0s' is not valid, must be greater than 0, for example '10s'\n"
+	assert.Equal(t, exp, config.Report(cfg.Validate()))
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"strings"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/viper"
 )
 
@@ -20,6 +21,14 @@ func NewViperConfig(appname string) *ViperConfig {
 	}
 }
 
+// ReadFile will read configuration from a yaml, toml or json file, the format
+// is taken from the file extension. Environment variables still take precedence
+// over values set in the file.
+func ReadFile(path string) error {
+	viper.SetConfigFile(path)
+	return errors.Wrapf(viper.ReadInConfig(), "failed to read config file %s", path)
+}
+
 // WithServer will setup the web server configuration if required.
 func (v *ViperConfig) WithServer() ConfigurationLoader {
 	v.Server = &Server{
@@ -28,10 +37,44 @@ func (v *ViperConfig) WithServer() ConfigurationLoader {
 		SwaggerEnabled: viper.GetBool(EnvServerSwaggerEnabled),
 		SwaggerHost:    viper.GetString(EnvServerSwaggerHost),
 		FQDN:           viper.GetString(EnvServerFQDN),
+
+		ReadHeaderTimeout:     viper.GetDuration(EnvServerReadHeaderTimeout),
+		ReadTimeout:           viper.GetDuration(EnvServerReadTimeout),
+		WriteTimeout:          viper.GetDuration(EnvServerWriteTimeout),
+		IdleTimeout:           viper.GetDuration(EnvServerIdleTimeout),
+		MaxBodyBytes:          viper.GetInt(EnvServerMaxBodyBytes),
+		PaymentMaxBodyBytes:   viper.GetInt(EnvServerPaymentMaxBodyBytes),
+		ProofMaxBodyBytes:     viper.GetInt(EnvServerProofMaxBodyBytes),
+		AllowedOrigins:        stringList(EnvServerAllowedOrigins),
+		HSTSMaxAge:            viper.GetInt(EnvServerHSTSMaxAge),
+		ContentSecurityPolicy: viper.GetString(EnvServerCSP),
+		RateLimit:             viper.GetFloat64(EnvServerRateLimit),
+		RateLimitBurst:        viper.GetInt(EnvServerRateLimitBurst),
 	}
 	return v
 }
 
+// list splits a comma separated value, ignoring empty values.
+func list(val string) []string {
+	vv := make([]string, 0)
+	for _, v := range strings.Split(val, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			vv = append(vv, v)
+		}
+	}
+	return vv
+}
+
+// stringList reads a list set either as a comma separated value, such as an environment
+// variable, or as a list in a config file.
+func stringList(key string) []string {
+	vv := make([]string, 0)
+	for _, v := range viper.GetStringSlice(key) {
+		vv = append(vv, list(v)...)
+	}
+	return vv
+}
+
 // WithDeployment sets up the deployment configuration if required.
 func (v *ViperConfig) WithDeployment(appName string) ConfigurationLoader {
 	v.Deployment = &Deployment{
@@ -41,6 +84,7 @@ func (v *ViperConfig) WithDeployment(appName string) ConfigurationLoader {
 		Commit:      viper.GetString(EnvCommit),
 		BuildDate:   viper.GetTime(EnvBuildDate),
 		AppName:     appName,
+		Network:     viper.GetString(EnvNetwork),
 	}
 	return v
 }
@@ -59,6 +103,7 @@ func (v *ViperConfig) WithPayD() ConfigurationLoader {
 		Secure:          viper.GetBool(EnvPaydSecure),
 		CertificatePath: viper.GetString(EnvPaydCertPath),
 		Noop:            viper.GetBool(EnvPaydNoop),
+		Timeout:         viper.GetDuration(EnvPaydTimeout),
 	}
 	return v
 }
@@ -68,6 +113,7 @@ func (v *ViperConfig) WithSockets() ConfigurationLoader {
 	v.Sockets = &Socket{
 		ChannelTimeout:  viper.GetDuration(EnvSocketChannelTimeoutSeconds),
 		MaxMessageBytes: viper.GetInt(EnvSocketMaxMessageBytes),
+		AwaitTimeout:    viper.GetDuration(EnvSocketAwaitTimeout),
 	}
 	return v
 }
@@ -80,6 +126,188 @@ func (v *ViperConfig) WithTransports() ConfigurationLoader {
 	return v
 }
 
+// WithTracing reads tracing config.
+func (v *ViperConfig) WithTracing() ConfigurationLoader {
+	v.Tracing = &Tracing{
+		Enabled:     viper.GetBool(EnvTracingEnabled),
+		Exporter:    viper.GetString(EnvTracingExporter),
+		Endpoint:    viper.GetString(EnvTracingEndpoint),
+		Insecure:    viper.GetBool(EnvTracingInsecure),
+		SampleRatio: viper.GetFloat64(EnvTracingSampleRatio),
+	}
+	return v
+}
+
+// WithAudit reads audit log config.
+func (v *ViperConfig) WithAudit() ConfigurationLoader {
+	v.Audit = &Audit{
+		Enabled:      viper.GetBool(EnvAuditEnabled),
+		Sink:         viper.GetString(EnvAuditSink),
+		FilePath:     viper.GetString(EnvAuditFilePath),
+		FileMaxBytes: viper.GetInt64(EnvAuditFileMaxBytes),
+	}
+	return v
+}
+
+// WithAdmin reads admin config.
+func (v *ViperConfig) WithAdmin() ConfigurationLoader {
+	v.Admin = &Admin{
+		Token: viper.GetString(EnvAdminToken),
+	}
+	return v
+}
+
+// WithPaymail reads paymail data store config.
+func (v *ViperConfig) WithPaymail() ConfigurationLoader {
+	v.Paymail = &Paymail{
+		Enabled: viper.GetBool(EnvPaymailEnabled),
+		Handle:  viper.GetString(EnvPaymailHandle),
+		Expiry:  viper.GetDuration(EnvPaymailExpiry),
+	}
+	return v
+}
+
+// WithMerchant reads merchant endpoint config.
+func (v *ViperConfig) WithMerchant() ConfigurationLoader {
+	v.Merchant = &Merchant{
+		Token: viper.GetString(EnvMerchantToken),
+	}
+	return v
+}
+
+// WithProofs reads proof callback config.
+func (v *ViperConfig) WithProofs() ConfigurationLoader {
+	v.Proofs = &Proofs{
+		AllowUnsigned:    viper.GetBool(EnvProofsAllowUnsigned),
+		RequireToken:     viper.GetBool(EnvProofsRequireToken),
+		RejectUnverified: viper.GetBool(EnvProofsRejectUnverified),
+		StoreEnabled:     viper.GetBool(EnvProofsStoreEnabled),
+	}
+	return v
+}
+
+// WithHeaders reads block header source config.
+func (v *ViperConfig) WithHeaders() ConfigurationLoader {
+	v.Headers = &Headers{
+		Enabled:  viper.GetBool(EnvHeadersEnabled),
+		Source:   viper.GetString(EnvHeadersSource),
+		FilePath: viper.GetString(EnvHeadersFilePath),
+		URL:      viper.GetString(EnvHeadersURL),
+		Timeout:  viper.GetDuration(EnvHeadersTimeout),
+	}
+	return v
+}
+
+// WithPeerChannels reads peer channel hosting config.
+func (v *ViperConfig) WithPeerChannels() ConfigurationLoader {
+	v.PeerChannels = &PeerChannels{
+		Enabled:         viper.GetBool(EnvPeerChannelsEnabled),
+		MaxMessages:     viper.GetInt(EnvPeerChannelsMaxMessages),
+		MaxMessageBytes: viper.GetInt(EnvPeerChannelsMaxMessageBytes),
+	}
+	return v
+}
+
+// WithSandbox reads sandbox data store config.
+func (v *ViperConfig) WithSandbox() ConfigurationLoader {
+	v.Sandbox = &Sandbox{
+		FixturePath: viper.GetString(EnvSandboxFixturePath),
+		SlowDelay:   viper.GetDuration(EnvSandboxSlowDelay),
+	}
+	return v
+}
+
+// WithInvoices reads merchant invoice config.
+func (v *ViperConfig) WithInvoices() ConfigurationLoader {
+	v.Invoices = &Invoices{
+		Enabled: viper.GetBool(EnvInvoicesEnabled),
+	}
+	return v
+}
+
+// WithXPub reads standalone xpub config.
+func (v *ViperConfig) WithXPub() ConfigurationLoader {
+	v.XPub = &XPub{
+		Enabled:  viper.GetBool(EnvXPubEnabled),
+		Key:      viper.GetString(EnvXPubKey),
+		FilePath: viper.GetString(EnvXPubFilePath),
+		Expiry:   viper.GetDuration(EnvXPubExpiry),
+	}
+	return v
+}
+
+// WithRates reads exchange rate config.
+func (v *ViperConfig) WithRates() ConfigurationLoader {
+	v.Rates = &Rates{
+		Enabled:     viper.GetBool(EnvRatesEnabled),
+		Source:      viper.GetString(EnvRatesSource),
+		FilePath:    viper.GetString(EnvRatesFilePath),
+		URL:         viper.GetString(EnvRatesURL),
+		Timeout:     viper.GetDuration(EnvRatesTimeout),
+		CacheTTL:    viper.GetDuration(EnvRatesCacheTTL),
+		QuoteExpiry: viper.GetDuration(EnvRatesQuoteExpiry),
+	}
+	return v
+}
+
+// WithFees reads fee quote config.
+func (v *ViperConfig) WithFees() ConfigurationLoader {
+	v.Fees = &Fees{
+		Enabled:  viper.GetBool(EnvFeesEnabled),
+		Source:   viper.GetString(EnvFeesSource),
+		URL:      viper.GetString(EnvFeesURL),
+		Timeout:  viper.GetDuration(EnvFeesTimeout),
+		CacheTTL: viper.GetDuration(EnvFeesCacheTTL),
+		Floor:    viper.GetInt(EnvFeesFloor),
+		Ceiling:  viper.GetInt(EnvFeesCeiling),
+	}
+	return v
+}
+
+// WithBroadcast reads broadcaster config.
+func (v *ViperConfig) WithBroadcast() ConfigurationLoader {
+	v.Broadcast = &Broadcast{
+		Enabled: viper.GetBool(EnvBroadcastEnabled),
+		Source:  viper.GetString(EnvBroadcastSource),
+		URL:     viper.GetString(EnvBroadcastURL),
+		Token:   viper.GetString(EnvBroadcastToken),
+		Timeout: viper.GetDuration(EnvBroadcastTimeout),
+	}
+	return v
+}
+
+// WithConflicts reads conflicting input config.
+func (v *ViperConfig) WithConflicts() ConfigurationLoader {
+	v.Conflicts = &Conflicts{
+		Enabled:   viper.GetBool(EnvConflictsEnabled),
+		Action:    viper.GetString(EnvConflictsAction),
+		Retention: viper.GetDuration(EnvConflictsRetention),
+	}
+	return v
+}
+
+// WithPolicy reads payment request policy config.
+func (v *ViperConfig) WithPolicy() ConfigurationLoader {
+	v.Policy = &Policy{
+		Enabled:      viper.GetBool(EnvPolicyEnabled),
+		DustLimit:    viper.GetUint64(EnvPolicyDustLimit),
+		Scripts:      stringList(EnvPolicyScripts),
+		MaxOutputs:   viper.GetInt(EnvPolicyMaxOutputs),
+		MaxTotal:     viper.GetUint64(EnvPolicyMaxTotal),
+		MaxExpiry:    viper.GetDuration(EnvPolicyMaxExpiry),
+		MerchantData: stringList(EnvPolicyMerchantData),
+	}
+	return v
+}
+
+// WithMemory reads the settings of the payment state held in memory.
+func (v *ViperConfig) WithMemory() ConfigurationLoader {
+	v.Memory = &Memory{
+		Retention: viper.GetDuration(EnvMemoryRetention),
+	}
+	return v
+}
+
 // Load will return the underlying config setup.
 func (v *ViperConfig) Load() *Config {
 	return v.Config
@@ -0,0 +1,38 @@
+package config_test
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bitcoin-sv/dpp-proxy/config"
+)
+
+func TestViperConfig_WithServer_AllowedOrigins(t *testing.T) {
+	tests := map[string]struct {
+		val        interface{}
+		expOrigins []string
+	}{
+		"comma separated env value is split": {
+			val:        "https://a.example.com, https://b.example.com",
+			expOrigins: []string{"https://a.example.com", "https://b.example.com"},
+		},
+		"config file list is read": {
+			val:        []interface{}{"https://a.example.com", "https://b.example.com"},
+			expOrigins: []string{"https://a.example.com", "https://b.example.com"},
+		},
+		"empty values are ignored": {
+			val:        ",",
+			expOrigins: []string{},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			viper.Set(config.EnvServerAllowedOrigins, test.val)
+			defer viper.Set(config.EnvServerAllowedOrigins, nil)
+			cfg := config.NewViperConfig("test").WithServer().Load()
+			assert.Equal(t, test.expOrigins, cfg.Server.AllowedOrigins)
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// Watcher will watch a config file for changes and apply the settings that
+// are safe to change while running, these are:
+//
+// * log level
+// * server rate limit
+// * payd host, port and timeout, the route wallet requests take
+// * socket await timeout
+// * headers, rates, fees and broadcast timeouts
+//
+// All other settings require a restart and are ignored on reload.
+type Watcher struct {
+	mu     sync.RWMutex
+	cfg    *Config
+	load   func() *Config
+	onLoad []func(cfg *Config)
+}
+
+// NewWatcher will setup and return a new config watcher, cfg is the config in use and load
+// is called to build a new config from the updated file.
+func NewWatcher(cfg *Config, load func() *Config) *Watcher {
+	return &Watcher{
+		cfg:  cfg,
+		load: load,
+	}
+}
+
+// Current returns the config currently in effect.
+func (w *Watcher) Current() *Config {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.cfg
+}
+
+// OnReload registers fn to be called with the effective config after each successful reload.
+func (w *Watcher) OnReload(fn func(cfg *Config)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onLoad = append(w.onLoad, fn)
+}
+
+// Watch will start watching the config file, res is called with the result of each reload.
+func (w *Watcher) Watch(res func(cfg *Config, err error)) {
+	viper.OnConfigChange(func(fsnotify.Event) {
+		cfg, err := w.Reload()
+		res(cfg, err)
+	})
+	viper.WatchConfig()
+}
+
+// Reload will load and validate the config, if valid the reloadable settings are
+// applied and the effective config returned. If invalid, the current config is kept.
+func (w *Watcher) Reload() (*Config, error) {
+	next := w.load()
+	if err := next.Validate(); err != nil {
+		return nil, err
+	}
+	w.mu.Lock()
+	cfg := w.cfg.reloadable(next)
+	w.cfg = cfg
+	fns := w.onLoad
+	w.mu.Unlock()
+	for _, fn := range fns {
+		fn(cfg)
+	}
+	return cfg, nil
+}
+
+// reloadable returns a copy of c with the safe settings taken from next.
+func (c *Config) reloadable(next *Config) *Config {
+	cfg := *c
+	if c.Logging != nil && next.Logging != nil {
+		l := *c.Logging
+		l.Level = next.Logging.Level
+		cfg.Logging = &l
+	}
+	if c.Server != nil && next.Server != nil {
+		s := *c.Server
+		s.RateLimit, s.RateLimitBurst = next.Server.RateLimit, next.Server.RateLimitBurst
+		cfg.Server = &s
+	}
+	if c.PayD != nil && next.PayD != nil {
+		p := *c.PayD
+		p.Host, p.Port, p.Timeout = next.PayD.Host, next.PayD.Port, next.PayD.Timeout
+		cfg.PayD = &p
+	}
+	if c.Sockets != nil && next.Sockets != nil {
+		s := *c.Sockets
+		s.AwaitTimeout = next.Sockets.AwaitTimeout
+		cfg.Sockets = &s
+	}
+	if c.Headers != nil && next.Headers != nil {
+		h := *c.Headers
+		h.Timeout = next.Headers.Timeout
+		cfg.Headers = &h
+	}
+	if c.Rates != nil && next.Rates != nil {
+		r := *c.Rates
+		r.Timeout = next.Rates.Timeout
+		cfg.Rates = &r
+	}
+	if c.Fees != nil && next.Fees != nil {
+		f := *c.Fees
+		f.Timeout = next.Fees.Timeout
+		cfg.Fees = &f
+	}
+	if c.Broadcast != nil && next.Broadcast != nil {
+		b := *c.Broadcast
+		b.Timeout = next.Broadcast.Timeout
+		cfg.Broadcast = &b
+	}
+	return &cfg
+}
@@ -0,0 +1,135 @@
+package config_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bitcoin-sv/dpp-proxy/config"
+)
+
+func newServer(port string) *config.Server {
+	return &config.Server{
+		Port:                port,
+		Hostname:            "localhost",
+		FQDN:                "dpp.example.com",
+		ReadHeaderTimeout:   10 * time.Second,
+		ReadTimeout:         30 * time.Second,
+		WriteTimeout:        time.Minute,
+		IdleTimeout:         2 * time.Minute,
+		MaxBodyBytes:        65536,
+		PaymentMaxBodyBytes: 10485760,
+		ProofMaxBodyBytes:   1048576,
+	}
+}
+
+func newRateLimitedServer(port string, limit float64, burst int) *config.Server {
+	s := newServer(port)
+	s.RateLimit, s.RateLimitBurst = limit, burst
+	return s
+}
+
+func TestWatcher_Reload(t *testing.T) {
+	current := func() *config.Config {
+		return &config.Config{
+			Logging:    &config.Logging{Level: config.LogInfo},
+			Server:     newServer(":8445"),
+			PayD:       &config.PayD{Host: "payd", Port: ":8443", Timeout: 5 * time.Second},
+			Sockets:    &config.Socket{MaxMessageBytes: 1000, AwaitTimeout: 10 * time.Second},
+			Transports: &config.Transports{Mode: config.TransportModeHTTP},
+			Headers:    &config.Headers{Source: config.HeadersSourceHTTP, Timeout: 5 * time.Second},
+			Rates:      &config.Rates{Source: config.RatesSourceHTTP, Timeout: 5 * time.Second},
+			Fees:       &config.Fees{Source: config.FeesSourceMAPI, Timeout: 5 * time.Second},
+			Broadcast:  &config.Broadcast{Source: config.BroadcastSourceMAPI, Timeout: 10 * time.Second},
+		}
+	}
+	tests := map[string]struct {
+		next   *config.Config
+		expCfg *config.Config
+		expErr error
+	}{
+		"safe settings are applied": {
+			next: &config.Config{
+				Logging:    &config.Logging{Level: config.LogDebug},
+				Server:     newRateLimitedServer(":8445", 5, 10),
+				PayD:       &config.PayD{Host: "otherpayd", Port: ":9443", Timeout: time.Second},
+				Sockets:    &config.Socket{MaxMessageBytes: 1000, AwaitTimeout: 20 * time.Second},
+				Transports: &config.Transports{Mode: config.TransportModeHTTP},
+				Headers:    &config.Headers{Source: config.HeadersSourceHTTP, Timeout: time.Second},
+				Rates:      &config.Rates{Source: config.RatesSourceHTTP, Timeout: 2 * time.Second},
+				Fees:       &config.Fees{Source: config.FeesSourceMAPI, Timeout: 3 * time.Second},
+				Broadcast:  &config.Broadcast{Source: config.BroadcastSourceMAPI, Timeout: 4 * time.Second},
+			},
+			expCfg: &config.Config{
+				Logging:    &config.Logging{Level: config.LogDebug},
+				Server:     newRateLimitedServer(":8445", 5, 10),
+				PayD:       &config.PayD{Host: "otherpayd", Port: ":9443", Timeout: time.Second},
+				Sockets:    &config.Socket{MaxMessageBytes: 1000, AwaitTimeout: 20 * time.Second},
+				Transports: &config.Transports{Mode: config.TransportModeHTTP},
+				Headers:    &config.Headers{Source: config.HeadersSourceHTTP, Timeout: time.Second},
+				Rates:      &config.Rates{Source: config.RatesSourceHTTP, Timeout: 2 * time.Second},
+				Fees:       &config.Fees{Source: config.FeesSourceMAPI, Timeout: 3 * time.Second},
+				Broadcast:  &config.Broadcast{Source: config.BroadcastSourceMAPI, Timeout: 4 * time.Second},
+			},
+		},
+		"settings requiring a restart are ignored": {
+			next: &config.Config{
+				Logging:    &config.Logging{Level: config.LogInfo},
+				Server:     newServer(":9000"),
+				PayD:       &config.PayD{Host: "payd", Port: ":8443", Secure: true, Timeout: 5 * time.Second},
+				Sockets:    &config.Socket{MaxMessageBytes: 5, ChannelTimeout: time.Hour, AwaitTimeout: 10 * time.Second},
+				Transports: &config.Transports{Mode: config.TransportModeHybrid},
+				Headers:    &config.Headers{Source: config.HeadersSourceFile, Timeout: 5 * time.Second},
+				Rates:      &config.Rates{Source: config.RatesSourceFile, Timeout: 5 * time.Second},
+				Fees:       &config.Fees{Source: config.FeesSourceStub, Timeout: 5 * time.Second},
+				Broadcast:  &config.Broadcast{Source: config.BroadcastSourceStub, Timeout: 10 * time.Second},
+			},
+			expCfg: current(),
+		},
+		"invalid config is rejected": {
+			next: &config.Config{
+				Logging:    &config.Logging{Level: "loud"},
+				PayD:       &config.PayD{Host: "payd", Port: ":8443", Timeout: time.Second},
+				Transports: &config.Transports{Mode: config.TransportModeHTTP},
+			},
+			expErr: errors.New("[log.level: 'loud' is not valid, must be one of: debug, info, warn, error]"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			w := config.NewWatcher(current(), func() *config.Config {
+				return test.next
+			})
+			var reloaded *config.Config
+			w.OnReload(func(cfg *config.Config) {
+				reloaded = cfg
+			})
+			cfg, err := w.Reload()
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+				assert.Nil(t, reloaded)
+				assert.Equal(t, current(), w.Current())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expCfg, cfg)
+			assert.Equal(t, test.expCfg, reloaded)
+			assert.Equal(t, test.expCfg, w.Current())
+		})
+	}
+}
+
+func TestConfig_Redacted(t *testing.T) {
+	cfg := &config.Config{
+		PayD:    &config.PayD{Host: "payd", Port: ":8443", Timeout: 5 * time.Second},
+		Admin:   &config.Admin{Token: "supersecret"},
+		Logging: &config.Logging{Level: config.LogInfo},
+	}
+	r := cfg.Redacted()
+	assert.Equal(t, map[string]interface{}{"Token": "*****"}, r["Admin"])
+	assert.Equal(t, "5s", r["PayD"].(map[string]interface{})["Timeout"])
+	assert.Equal(t, "payd", r["PayD"].(map[string]interface{})["Host"])
+	assert.Nil(t, r["Server"])
+}
@@ -0,0 +1,48 @@
+package server
+
+import (
+	"context"
+	"time"
+)
+
+// CallbackConflictingInputs is the reason of double spends raised by the proxy, when a payment
+// spends outputs already spent by a payment for another invoice.
+const CallbackConflictingInputs = "conflictingInputs"
+
+// Spend is an output spent by a payment transaction accepted by the proxy.
+type Spend struct {
+	// Outpoint is the output spent, as txid:vout.
+	Outpoint  string
+	PaymentID string
+	TxID      string
+	SpentAt   time.Time
+}
+
+// SpendsReserveArgs are the spends of a payment to reserve before it is sent to the wallet.
+type SpendsReserveArgs struct {
+	Spends []Spend
+	// Since excludes spends recorded before it, their outpoints can be reserved again.
+	Since time.Time
+	// Exclusive reserves nothing if any outpoint is spent by a payment for another invoice.
+	Exclusive bool
+}
+
+// SpendsReservation is the result of a reservation.
+type SpendsReservation struct {
+	// Conflicting are the spends of the outpoints already recorded for other invoices.
+	Conflicting []Spend
+	// Reserved are the spends recorded by the reservation, to be released if the payment fails.
+	Reserved []Spend
+}
+
+// SpendStore keeps the outputs spent by accepted payments, so conflicting payments can be found.
+type SpendStore interface {
+	// SpendsReserve finds the spends conflicting with the request and records the request's
+	// spends of outpoints not already recorded, in one step so concurrent payments spending the
+	// same output can't both reserve it.
+	SpendsReserve(ctx context.Context, args SpendsReserveArgs) (*SpendsReservation, error)
+	// SpendsRelease removes reserved spends, spends recorded since by other payments are kept.
+	SpendsRelease(ctx context.Context, req []Spend) error
+	// SpendsPrune removes spends recorded before the time.
+	SpendsPrune(ctx context.Context, before time.Time) error
+}
@@ -0,0 +1,205 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/config"
+)
+
+// Audit log file naming, the active file is always named activeFile, once
+// it reaches the max size it is renamed with the rotation time appended.
+const (
+	activeFile    = "audit.log"
+	rotatedPrefix = "audit-"
+	rotatedSuffix = ".log"
+	rotatedLayout = "20060102T150405.000000000"
+)
+
+type file struct {
+	mu       sync.Mutex
+	dir      string
+	maxBytes int64
+	f        *os.File
+	size     int64
+}
+
+// NewFile will setup and return a new audit store that appends entries as json
+// lines to a file in the configured directory, rotating the file once it grows
+// past the configured max size.
+func NewFile(cfg *config.Audit) (*file, error) {
+	if err := os.MkdirAll(cfg.FilePath, 0o750); err != nil {
+		return nil, errors.Wrapf(err, "failed to create audit directory %s", cfg.FilePath)
+	}
+	a := &file{
+		dir:      cfg.FilePath,
+		maxBytes: cfg.FileMaxBytes,
+	}
+	if err := a.open(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// AuditWrite will append the entry to the active audit file.
+func (a *file) AuditWrite(ctx context.Context, entry server.AuditEntry) error {
+	bb, err := json.Marshal(entry)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode audit entry")
+	}
+	bb = append(bb, '\n')
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.maxBytes > 0 && a.size > 0 && a.size+int64(len(bb)) > a.maxBytes {
+		if err := a.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := a.f.Write(bb)
+	a.size += int64(n)
+	if err != nil {
+		return errors.Wrap(err, "failed to write audit entry")
+	}
+	return errors.Wrap(a.f.Sync(), "failed to sync audit file")
+}
+
+// AuditLast will return the last entry written to the audit files, or nil if
+// no entries have yet been written.
+func (a *file) AuditLast(ctx context.Context) (*server.AuditEntry, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	files, err := Files(a.dir)
+	if err != nil {
+		return nil, err
+	}
+	// rotated files are never empty, but the active file can be.
+	for i := len(files) - 1; i >= 0; i-- {
+		var last *server.AuditEntry
+		if err := readEntries(files[i], func(e server.AuditEntry) error {
+			last = &e
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+		if last != nil {
+			return last, nil
+		}
+	}
+	return nil, nil
+}
+
+// Close will close the active audit file.
+func (a *file) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.f.Close()
+}
+
+func (a *file) open() error {
+	f, err := os.OpenFile(filepath.Join(a.dir, activeFile), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o640)
+	if err != nil {
+		return errors.Wrap(err, "failed to open audit file")
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return errors.Wrap(err, "failed to stat audit file")
+	}
+	a.f = f
+	a.size = info.Size()
+	return nil
+}
+
+func (a *file) rotate() error {
+	if err := a.f.Close(); err != nil {
+		return errors.Wrap(err, "failed to close audit file for rotation")
+	}
+	name := fmt.Sprintf("%s%s%s", rotatedPrefix, time.Now().UTC().Format(rotatedLayout), rotatedSuffix)
+	if err := os.Rename(filepath.Join(a.dir, activeFile), filepath.Join(a.dir, name)); err != nil {
+		return errors.Wrap(err, "failed to rotate audit file")
+	}
+	return a.open()
+}
+
+// Files returns the audit files found in dir, oldest first, with the active file last.
+func Files(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read audit directory %s", dir)
+	}
+	var rotated []string
+	var active string
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		switch name := e.Name(); {
+		case name == activeFile:
+			active = filepath.Join(dir, name)
+		case strings.HasPrefix(name, rotatedPrefix) && strings.HasSuffix(name, rotatedSuffix):
+			rotated = append(rotated, filepath.Join(dir, name))
+		}
+	}
+	sort.Strings(rotated)
+	if active != "" {
+		rotated = append(rotated, active)
+	}
+	return rotated, nil
+}
+
+// Verify will read every entry in the supplied files, in order, and check that
+// the hash chain is unbroken. The number of entries checked is returned.
+func Verify(files ...string) (int, error) {
+	var prev *server.AuditEntry
+	var n int
+	for _, f := range files {
+		if err := readEntries(f, func(e server.AuditEntry) error {
+			if err := e.Verify(prev); err != nil {
+				return errors.Wrapf(err, "file %s", f)
+			}
+			prev = &e
+			n++
+			return nil
+		}); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// readEntries calls fn for each entry found in the file.
+func readEntries(path string, fn func(e server.AuditEntry) error) error {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return errors.Wrapf(err, "failed to open audit file %s", path)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var e server.AuditEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			return errors.Wrapf(err, "failed to decode audit entry at %s:%d", path, line)
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return errors.Wrapf(sc.Err(), "failed to read audit file %s", path)
+}
@@ -0,0 +1,138 @@
+package audit_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/config"
+	"github.com/bitcoin-sv/dpp-proxy/data/audit"
+	"github.com/bitcoin-sv/dpp-proxy/service"
+)
+
+func TestFile_Verify(t *testing.T) {
+	tests := map[string]struct {
+		maxBytes int64
+		events   int
+		tamperFn func(t *testing.T, files []string)
+		expFiles int
+		expN     int
+		expErr   string
+	}{
+		"unbroken chain is valid": {
+			events:   5,
+			expFiles: 1,
+			expN:     5,
+		},
+		"chain is valid across rotated files": {
+			maxBytes: 600,
+			events:   6,
+			expFiles: 3,
+			expN:     6,
+		},
+		"modified entry is detected": {
+			events:   3,
+			expFiles: 1,
+			tamperFn: func(t *testing.T, files []string) {
+				bb, err := ioutil.ReadFile(files[0])
+				assert.NoError(t, err)
+				bb = []byte(strings.Replace(string(bb), `"paymentId":"abc1"`, `"paymentId":"xyz1"`, 1))
+				assert.NoError(t, ioutil.WriteFile(files[0], bb, 0o600))
+			},
+			expN:   1,
+			expErr: "audit entry 1 has been modified, hash mismatch",
+		},
+		"removed entry is detected": {
+			events:   3,
+			expFiles: 1,
+			tamperFn: func(t *testing.T, files []string) {
+				bb, err := ioutil.ReadFile(files[0])
+				assert.NoError(t, err)
+				lines := strings.SplitAfter(string(bb), "\n")
+				assert.NoError(t, ioutil.WriteFile(files[0], []byte(lines[0]+lines[2]), 0o600))
+			},
+			expN:   1,
+			expErr: "audit entry 2 does not follow entry 0, entries are missing or out of order",
+		},
+		"removed rotated file is detected": {
+			maxBytes: 600,
+			events:   6,
+			expFiles: 3,
+			tamperFn: func(t *testing.T, files []string) {
+				assert.NoError(t, os.Remove(files[0]))
+			},
+			expErr: "does not start the chain, previous entries are missing",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			f, err := audit.NewFile(&config.Audit{FilePath: dir, FileMaxBytes: test.maxBytes})
+			assert.NoError(t, err)
+			defer f.Close()
+			a, err := service.NewAudit(context.Background(), f)
+			assert.NoError(t, err)
+			for i := 0; i < test.events; i++ {
+				assert.NoError(t, a.AuditLog(context.Background(), server.AuditEvent{
+					Type:      server.AuditPaymentRequest,
+					PaymentID: "abc" + string(rune('0'+i)),
+					Payload:   map[string]interface{}{"memo": "invoice", "amount": 1000},
+				}))
+			}
+
+			files, err := audit.Files(dir)
+			assert.NoError(t, err)
+			assert.Len(t, files, test.expFiles)
+			assert.Equal(t, filepath.Join(dir, "audit.log"), files[len(files)-1])
+			if test.tamperFn != nil {
+				test.tamperFn(t, files)
+				files, err = audit.Files(dir)
+				assert.NoError(t, err)
+			}
+
+			n, err := audit.Verify(files...)
+			assert.Equal(t, test.expN, n)
+			if test.expErr != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.expErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestFile_AuditLast(t *testing.T) {
+	dir := t.TempDir()
+	f, err := audit.NewFile(&config.Audit{FilePath: dir})
+	assert.NoError(t, err)
+	a, err := service.NewAudit(context.Background(), f)
+	assert.NoError(t, err)
+	assert.NoError(t, a.AuditLog(context.Background(), server.AuditEvent{Type: server.AuditProof, TxID: "tx1"}))
+	assert.NoError(t, f.Close())
+
+	// restarting should continue the existing chain.
+	f, err = audit.NewFile(&config.Audit{FilePath: dir})
+	assert.NoError(t, err)
+	defer f.Close()
+	last, err := f.AuditLast(context.Background())
+	assert.NoError(t, err)
+	assert.NotNil(t, last)
+	assert.Equal(t, "tx1", last.TxID)
+
+	a, err = service.NewAudit(context.Background(), f)
+	assert.NoError(t, err)
+	assert.NoError(t, a.AuditLog(context.Background(), server.AuditEvent{Type: server.AuditProof, TxID: "tx2"}))
+
+	files, err := audit.Files(dir)
+	assert.NoError(t, err)
+	n, err := audit.Verify(files...)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+}
@@ -0,0 +1,42 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+)
+
+type writer struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriter will setup and return a new audit store that writes entries as json lines
+// to w, this is useful for writing to stdout where logs are shipped elsewhere.
+//
+// The writer cannot be read from, so the chain restarts each time the server starts.
+func NewWriter(w io.Writer) *writer {
+	return &writer{w: w}
+}
+
+// AuditWrite will write the entry to the underlying writer.
+func (a *writer) AuditWrite(ctx context.Context, entry server.AuditEntry) error {
+	bb, err := json.Marshal(entry)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode audit entry")
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	_, err = a.w.Write(append(bb, '\n'))
+	return errors.Wrap(err, "failed to write audit entry")
+}
+
+// AuditLast always returns nil as previous entries cannot be read back.
+func (a *writer) AuditLast(ctx context.Context) (*server.AuditEntry, error) {
+	return nil, nil
+}
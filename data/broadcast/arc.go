@@ -0,0 +1,53 @@
+package broadcast
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/theflyingcodr/lathos/errs"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/config"
+	"github.com/bitcoin-sv/dpp-proxy/data"
+	"github.com/bitcoin-sv/dpp-proxy/data/broadcast/models"
+)
+
+const (
+	// urlARCSubmitTx is the ARC endpoint transactions are broadcast to.
+	urlARCSubmitTx = "%s/v1/tx"
+
+	arcRejected = "REJECTED"
+)
+
+type arc struct {
+	client data.HTTPClient
+	cfg    *config.Broadcast
+}
+
+// NewARC will setup and return a broadcaster submitting transactions to an ARC server.
+//
+// ARC callbacks aren't in a format accepted by the proof callback endpoint, so callbacks
+// aren't requested and merkle proofs must be collected by the merchant wallet.
+func NewARC(cfg *config.Broadcast, client data.HTTPClient) *arc {
+	return &arc{
+		client: client,
+		cfg:    cfg,
+	}
+}
+
+// Broadcast will submit the transaction, rejected transactions are returned as an unprocessable error.
+func (a *arc) Broadcast(ctx context.Context, req server.Broadcast) error {
+	var resp models.ARCSubmitTxResponse
+	if err := a.client.Do(withToken(ctx, a.cfg.Token), http.MethodPost,
+		fmt.Sprintf(urlARCSubmitTx, strings.TrimSuffix(a.cfg.URL, "/")), http.StatusOK,
+		models.ARCSubmitTx{RawTx: req.Tx.String()}, &resp); err != nil {
+		return errors.Wrap(err, "failed to submit transaction to arc")
+	}
+	if resp.TxStatus == arcRejected {
+		return errs.NewErrUnprocessablef("422", "transaction was rejected by arc: %s", resp.ExtraInfo)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package broadcast_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/libsv/go-bt/v2"
+	"github.com/stretchr/testify/assert"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/config"
+	"github.com/bitcoin-sv/dpp-proxy/data/broadcast"
+	"github.com/bitcoin-sv/dpp-proxy/data/broadcast/models"
+	"github.com/bitcoin-sv/dpp-proxy/mocks"
+)
+
+func TestARC_Broadcast(t *testing.T) {
+	tests := map[string]struct {
+		resp   models.ARCSubmitTxResponse
+		expErr error
+	}{
+		"accepted transaction is broadcast": {
+			resp: models.ARCSubmitTxResponse{TxStatus: "SEEN_ON_NETWORK"},
+		},
+		"rejected transaction is returned": {
+			resp:   models.ARCSubmitTxResponse{TxStatus: "REJECTED", ExtraInfo: "fee too low"},
+			expErr: errors.New("Unprocessable: transaction was rejected by arc: fee too low"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tx := bt.NewTx()
+			client := &mocks.HTTPClientMock{
+				DoFunc: func(ctx context.Context, method, endpoint string, expStatus int, req, out interface{}) error {
+					assert.Equal(t, "https://arc.example.com/v1/tx", endpoint)
+					assert.Equal(t, models.ARCSubmitTx{RawTx: tx.String()}, req)
+					*out.(*models.ARCSubmitTxResponse) = test.resp
+					return nil
+				},
+			}
+			err := broadcast.NewARC(&config.Broadcast{URL: "https://arc.example.com"}, client).
+				Broadcast(context.Background(), server.Broadcast{PaymentID: "abc123", Tx: tx})
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package broadcast
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/libsv/go-bk/envelope"
+	"github.com/pkg/errors"
+	"github.com/theflyingcodr/lathos/errs"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/config"
+	"github.com/bitcoin-sv/dpp-proxy/data"
+	"github.com/bitcoin-sv/dpp-proxy/data/broadcast/models"
+)
+
+const (
+	// urlMAPISubmitTx is the mAPI endpoint transactions are broadcast to.
+	urlMAPISubmitTx = "%s/mapi/tx"
+
+	mapiSuccess = "success"
+)
+
+type mapi struct {
+	client data.HTTPClient
+	cfg    *config.Broadcast
+}
+
+// NewMAPI will setup and return a broadcaster submitting transactions to an mAPI server,
+// merkle proofs and double spends are requested to the callback url of the broadcast.
+func NewMAPI(cfg *config.Broadcast, client data.HTTPClient) *mapi {
+	return &mapi{
+		client: client,
+		cfg:    cfg,
+	}
+}
+
+// Broadcast will submit the transaction, it is rejected if the miner returns a failure
+// other than the transaction already being known. Signed responses are verified.
+func (m *mapi) Broadcast(ctx context.Context, req server.Broadcast) error {
+	var env envelope.JSONEnvelope
+	if err := m.client.Do(withToken(ctx, m.cfg.Token), http.MethodPost,
+		fmt.Sprintf(urlMAPISubmitTx, strings.TrimSuffix(m.cfg.URL, "/")), http.StatusOK, models.MAPISubmitTx{
+			RawTx:         req.Tx.String(),
+			CallbackURL:   req.CallbackURL,
+			CallbackToken: req.CallbackToken,
+			MerkleProof:   req.CallbackURL != "",
+			DsCheck:       req.CallbackURL != "",
+		}, &env); err != nil {
+		return errors.Wrap(err, "failed to submit transaction to mapi")
+	}
+	if ok, err := env.IsValid(); err != nil || !ok {
+		return errors.New("mapi submit response signature is invalid")
+	}
+	var resp models.MAPISubmitTxResponse
+	if err := json.Unmarshal([]byte(env.Payload), &resp); err != nil {
+		return errors.Wrap(err, "failed to read mapi submit response")
+	}
+	if resp.ReturnResult == mapiSuccess || alreadyKnown(resp.ResultDescription) {
+		return nil
+	}
+	return errs.NewErrUnprocessablef("422", "transaction was rejected by the miner: %s", resp.ResultDescription)
+}
+
+// alreadyKnown returns true if the mAPI result description reports the transaction was
+// broadcast before, which mAPI returns as a failure.
+func alreadyKnown(desc string) bool {
+	desc = strings.ToLower(desc)
+	return strings.Contains(desc, "already known") || strings.Contains(desc, "already in the mempool")
+}
+
+// withToken returns ctx with token added as a bearer token, if set.
+func withToken(ctx context.Context, token string) context.Context {
+	if token == "" {
+		return ctx
+	}
+	return data.WithHeaders(ctx, http.Header{"Authorization": []string{"Bearer " + token}})
+}
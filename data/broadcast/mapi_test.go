@@ -0,0 +1,76 @@
+package broadcast_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/libsv/go-bk/envelope"
+	"github.com/libsv/go-bt/v2"
+	"github.com/stretchr/testify/assert"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/config"
+	"github.com/bitcoin-sv/dpp-proxy/data/broadcast"
+	"github.com/bitcoin-sv/dpp-proxy/data/broadcast/models"
+	"github.com/bitcoin-sv/dpp-proxy/mocks"
+)
+
+func TestMAPI_Broadcast(t *testing.T) {
+	tests := map[string]struct {
+		resp   models.MAPISubmitTxResponse
+		doErr  error
+		expErr error
+	}{
+		"accepted transaction is broadcast": {
+			resp: models.MAPISubmitTxResponse{ReturnResult: "success"},
+		},
+		"known transaction is broadcast": {
+			resp: models.MAPISubmitTxResponse{ReturnResult: "failure", ResultDescription: "Transaction already known"},
+		},
+		"rejected transaction is returned": {
+			resp:   models.MAPISubmitTxResponse{ReturnResult: "failure", ResultDescription: "Missing inputs"},
+			expErr: errors.New("Unprocessable: transaction was rejected by the miner: Missing inputs"),
+		},
+		"server error is returned": {
+			doErr:  errors.New("connection refused"),
+			expErr: errors.New("failed to submit transaction to mapi: connection refused"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tx := bt.NewTx()
+			client := &mocks.HTTPClientMock{
+				DoFunc: func(ctx context.Context, method, endpoint string, expStatus int, req, out interface{}) error {
+					assert.Equal(t, "https://mapi.example.com/mapi/tx", endpoint)
+					assert.Equal(t, models.MAPISubmitTx{
+						RawTx:         tx.String(),
+						CallbackURL:   "http://dpp.example.com/api/v1/proofs/abc?i=abc123",
+						CallbackToken: "abc",
+						MerkleProof:   true,
+						DsCheck:       true,
+					}, req)
+					if test.doErr != nil {
+						return test.doErr
+					}
+					env, err := envelope.NewJSONEnvelope(test.resp)
+					assert.NoError(t, err)
+					*out.(*envelope.JSONEnvelope) = *env
+					return nil
+				},
+			}
+			err := broadcast.NewMAPI(&config.Broadcast{URL: "https://mapi.example.com/"}, client).
+				Broadcast(context.Background(), server.Broadcast{
+					PaymentID:     "abc123",
+					Tx:            tx,
+					CallbackURL:   "http://dpp.example.com/api/v1/proofs/abc?i=abc123",
+					CallbackToken: "abc",
+				})
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
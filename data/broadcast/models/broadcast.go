@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// MAPISubmitTx is sent to an mAPI server to broadcast a transaction.
+type MAPISubmitTx struct {
+	RawTx         string `json:"rawtx"`
+	CallbackURL   string `json:"callbackUrl,omitempty"`
+	CallbackToken string `json:"callbackToken,omitempty"`
+	MerkleProof   bool   `json:"merkleProof"`
+	DsCheck       bool   `json:"dsCheck"`
+}
+
+// MAPISubmitTxResponse is the payload of the envelope returned by an mAPI server for a broadcast.
+type MAPISubmitTxResponse struct {
+	APIVersion        string    `json:"apiVersion"`
+	Timestamp         time.Time `json:"timestamp"`
+	TxID              string    `json:"txid"`
+	ReturnResult      string    `json:"returnResult"`
+	ResultDescription string    `json:"resultDescription"`
+	MinerID           string    `json:"minerId"`
+}
+
+// ARCSubmitTx is sent to an ARC server to broadcast a transaction.
+type ARCSubmitTx struct {
+	RawTx string `json:"rawTx"`
+}
+
+// ARCSubmitTxResponse is returned by an ARC server for a broadcast.
+type ARCSubmitTxResponse struct {
+	TxID      string    `json:"txid"`
+	TxStatus  string    `json:"txStatus"`
+	ExtraInfo string    `json:"extraInfo"`
+	Timestamp time.Time `json:"timestamp"`
+}
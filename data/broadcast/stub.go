@@ -0,0 +1,24 @@
+package broadcast
+
+import (
+	"context"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/log"
+)
+
+type stub struct {
+	l log.Logger
+}
+
+// NewStub will setup and return a broadcaster that logs transactions rather than
+// broadcasting them, useful for testing without a miner.
+func NewStub(l log.Logger) *stub {
+	return &stub{l: l}
+}
+
+// Broadcast will log the transaction and accept it.
+func (s *stub) Broadcast(ctx context.Context, req server.Broadcast) error {
+	s.l.Infof("stub broadcast of txid %s for paymentID %s", req.Tx.TxID(), req.PaymentID)
+	return nil
+}
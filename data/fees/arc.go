@@ -0,0 +1,52 @@
+package fees
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/libsv/go-bt/v2"
+	"github.com/pkg/errors"
+
+	"github.com/bitcoin-sv/dpp-proxy/config"
+	"github.com/bitcoin-sv/dpp-proxy/data"
+	"github.com/bitcoin-sv/dpp-proxy/data/fees/models"
+)
+
+// urlARCPolicy is the ARC endpoint returning the policy of the node, including its mining fee.
+const urlARCPolicy = "%s/v1/policy"
+
+type arc struct {
+	client data.HTTPClient
+	cfg    *config.Fees
+}
+
+// NewARC will setup and return a fee source reading the mining fee from the policy of an ARC server.
+func NewARC(cfg *config.Fees, client data.HTTPClient) *arc {
+	return &arc{
+		client: client,
+		cfg:    cfg,
+	}
+}
+
+// FeeQuote will return the mining fee of the ARC policy for both standard and data fees. ARC
+// policies don't expire, so the quote expires after the configured cache ttl.
+func (a *arc) FeeQuote(ctx context.Context) (*bt.FeeQuote, error) {
+	var resp models.ARCPolicy
+	if err := a.client.Do(ctx, http.MethodGet, fmt.Sprintf(urlARCPolicy, strings.TrimSuffix(a.cfg.URL, "/")),
+		http.StatusOK, nil, &resp); err != nil {
+		return nil, errors.Wrap(err, "failed to get arc policy")
+	}
+	if resp.Policy.MiningFee.Bytes <= 0 {
+		return nil, errors.New("arc policy has no mining fee")
+	}
+	fq := bt.NewFeeQuote()
+	for _, ft := range []bt.FeeType{bt.FeeTypeStandard, bt.FeeTypeData} {
+		// ARC has no relay fee, the mining fee is used so transactions are relayed.
+		fq.AddQuote(ft, &bt.Fee{FeeType: ft, MiningFee: resp.Policy.MiningFee, RelayFee: resp.Policy.MiningFee})
+	}
+	fq.UpdateExpiry(time.Now().UTC().Add(a.cfg.CacheTTL))
+	return fq, nil
+}
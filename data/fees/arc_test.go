@@ -0,0 +1,55 @@
+package fees_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/libsv/go-bt/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bitcoin-sv/dpp-proxy/config"
+	"github.com/bitcoin-sv/dpp-proxy/data/fees"
+	"github.com/bitcoin-sv/dpp-proxy/data/fees/models"
+	"github.com/bitcoin-sv/dpp-proxy/mocks"
+)
+
+func TestARC_FeeQuote(t *testing.T) {
+	tests := map[string]struct {
+		fee    bt.FeeUnit
+		expErr error
+	}{
+		"policy mining fee is returned": {
+			fee: bt.FeeUnit{Satoshis: 1, Bytes: 1000},
+		},
+		"policy without a mining fee is rejected": {
+			expErr: errors.New("arc policy has no mining fee"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := &mocks.HTTPClientMock{
+				DoFunc: func(ctx context.Context, method, endpoint string, expStatus int, req, out interface{}) error {
+					assert.Equal(t, "https://arc.example.com/v1/policy", endpoint)
+					out.(*models.ARCPolicy).Policy.MiningFee = test.fee
+					return nil
+				},
+			}
+			fq, err := fees.NewARC(&config.Fees{URL: "https://arc.example.com", CacheTTL: time.Minute}, client).
+				FeeQuote(context.Background())
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.WithinDuration(t, time.Now().Add(time.Minute), fq.Expiry(), time.Second)
+			for _, ft := range []bt.FeeType{bt.FeeTypeStandard, bt.FeeTypeData} {
+				fee, err := fq.Fee(ft)
+				assert.NoError(t, err)
+				assert.Equal(t, test.fee, fee.MiningFee)
+				assert.Equal(t, test.fee, fee.RelayFee)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package fees
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/libsv/go-bk/envelope"
+	"github.com/libsv/go-bt/v2"
+	"github.com/pkg/errors"
+
+	"github.com/bitcoin-sv/dpp-proxy/config"
+	"github.com/bitcoin-sv/dpp-proxy/data"
+	"github.com/bitcoin-sv/dpp-proxy/data/fees/models"
+)
+
+// urlMAPIFeeQuote is the mAPI endpoint returning a signed fee quote.
+const urlMAPIFeeQuote = "%s/mapi/feeQuote"
+
+type mapi struct {
+	client data.HTTPClient
+	cfg    *config.Fees
+}
+
+// NewMAPI will setup and return a fee source reading fee quotes from an mAPI server.
+func NewMAPI(cfg *config.Fees, client data.HTTPClient) *mapi {
+	return &mapi{
+		client: client,
+		cfg:    cfg,
+	}
+}
+
+// FeeQuote will return the current fee quote of the miner, it expires at the quote
+// expiry, or after the configured cache ttl if it has none. Signed quotes are verified.
+func (m *mapi) FeeQuote(ctx context.Context) (*bt.FeeQuote, error) {
+	var env envelope.JSONEnvelope
+	if err := m.client.Do(ctx, http.MethodGet, fmt.Sprintf(urlMAPIFeeQuote, strings.TrimSuffix(m.cfg.URL, "/")),
+		http.StatusOK, nil, &env); err != nil {
+		return nil, errors.Wrap(err, "failed to get mapi fee quote")
+	}
+	if ok, err := env.IsValid(); err != nil || !ok {
+		return nil, errors.New("mapi fee quote signature is invalid")
+	}
+	var payload models.MAPIFeeQuote
+	if err := json.Unmarshal([]byte(env.Payload), &payload); err != nil {
+		return nil, errors.Wrap(err, "failed to read mapi fee quote")
+	}
+	if len(payload.Fees) == 0 {
+		return nil, errors.New("mapi fee quote has no fees")
+	}
+	fq := bt.NewFeeQuote()
+	for _, f := range payload.Fees {
+		if f.FeeType != bt.FeeTypeStandard && f.FeeType != bt.FeeTypeData {
+			continue
+		}
+		fq.AddQuote(f.FeeType, &bt.Fee{FeeType: f.FeeType, MiningFee: f.MiningFee, RelayFee: f.RelayFee})
+	}
+	expiry := payload.ExpiryTime
+	if expiry.IsZero() {
+		expiry = time.Now().UTC().Add(m.cfg.CacheTTL)
+	}
+	fq.UpdateExpiry(expiry)
+	return fq, nil
+}
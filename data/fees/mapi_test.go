@@ -0,0 +1,86 @@
+package fees_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/libsv/go-bk/envelope"
+	"github.com/libsv/go-bt/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bitcoin-sv/dpp-proxy/config"
+	"github.com/bitcoin-sv/dpp-proxy/data/fees"
+	"github.com/bitcoin-sv/dpp-proxy/data/fees/models"
+	"github.com/bitcoin-sv/dpp-proxy/mocks"
+)
+
+func TestMAPI_FeeQuote(t *testing.T) {
+	expiry := time.Now().Add(10 * time.Minute).UTC().Truncate(time.Second)
+	quote := models.MAPIFeeQuote{
+		ExpiryTime: expiry,
+		Fees: []models.MAPIFee{{
+			FeeType:   bt.FeeTypeStandard,
+			MiningFee: bt.FeeUnit{Satoshis: 50, Bytes: 1000},
+			RelayFee:  bt.FeeUnit{Satoshis: 25, Bytes: 1000},
+		}},
+	}
+	tests := map[string]struct {
+		envFn  func(t *testing.T) envelope.JSONEnvelope
+		doErr  error
+		expErr error
+	}{
+		"signed quote is returned": {
+			envFn: func(t *testing.T) envelope.JSONEnvelope {
+				env, err := envelope.NewJSONEnvelope(quote)
+				assert.NoError(t, err)
+				return *env
+			},
+		},
+		"tampered quote is rejected": {
+			envFn: func(t *testing.T) envelope.JSONEnvelope {
+				env, err := envelope.NewJSONEnvelope(quote)
+				assert.NoError(t, err)
+				env.Payload = `{"fees":[{"feeType":"standard","miningFee":{"satoshis":0,"bytes":1000}}]}`
+				return *env
+			},
+			expErr: errors.New("mapi fee quote signature is invalid"),
+		},
+		"quote without fees is rejected": {
+			envFn: func(t *testing.T) envelope.JSONEnvelope {
+				return envelope.JSONEnvelope{Payload: `{"fees":[]}`}
+			},
+			expErr: errors.New("mapi fee quote has no fees"),
+		},
+		"server error is returned": {
+			doErr:  errors.New("connection refused"),
+			expErr: errors.New("failed to get mapi fee quote: connection refused"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := &mocks.HTTPClientMock{
+				DoFunc: func(ctx context.Context, method, endpoint string, expStatus int, req, out interface{}) error {
+					assert.Equal(t, "https://mapi.example.com/mapi/feeQuote", endpoint)
+					if test.doErr != nil {
+						return test.doErr
+					}
+					*out.(*envelope.JSONEnvelope) = test.envFn(t)
+					return nil
+				},
+			}
+			fq, err := fees.NewMAPI(&config.Fees{URL: "https://mapi.example.com/"}, client).FeeQuote(context.Background())
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, expiry, fq.Expiry())
+			fee, err := fq.Fee(bt.FeeTypeStandard)
+			assert.NoError(t, err)
+			assert.Equal(t, bt.FeeUnit{Satoshis: 50, Bytes: 1000}, fee.MiningFee)
+			assert.Equal(t, bt.FeeUnit{Satoshis: 25, Bytes: 1000}, fee.RelayFee)
+		})
+	}
+}
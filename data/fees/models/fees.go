@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/libsv/go-bt/v2"
+)
+
+// MAPIFeeQuote is the payload of the fee quote envelope returned by an mAPI server.
+type MAPIFeeQuote struct {
+	APIVersion string    `json:"apiVersion"`
+	Timestamp  time.Time `json:"timestamp"`
+	ExpiryTime time.Time `json:"expiryTime"`
+	MinerID    string    `json:"minerId"`
+	Fees       []MAPIFee `json:"fees"`
+}
+
+// MAPIFee is the fee of a fee type in an mAPI fee quote.
+type MAPIFee struct {
+	FeeType   bt.FeeType `json:"feeType"`
+	MiningFee bt.FeeUnit `json:"miningFee"`
+	RelayFee  bt.FeeUnit `json:"relayFee"`
+}
+
+// ARCPolicy is returned by the policy endpoint of an ARC server.
+type ARCPolicy struct {
+	Timestamp time.Time `json:"timestamp"`
+	Policy    struct {
+		MiningFee bt.FeeUnit `json:"miningFee"`
+	} `json:"policy"`
+}
@@ -0,0 +1,29 @@
+package fees
+
+import (
+	"context"
+	"time"
+
+	"github.com/libsv/go-bt/v2"
+
+	"github.com/bitcoin-sv/dpp-proxy/config"
+)
+
+type stub struct {
+	cfg *config.Fees
+}
+
+// NewStub will setup and return a fee source serving the default go-bt fees, useful
+// for testing without a miner.
+func NewStub(cfg *config.Fees) *stub {
+	return &stub{
+		cfg: cfg,
+	}
+}
+
+// FeeQuote will return the default fees, expiring after the configured cache ttl.
+func (s *stub) FeeQuote(ctx context.Context) (*bt.FeeQuote, error) {
+	fq := bt.NewFeeQuote()
+	fq.UpdateExpiry(time.Now().UTC().Add(s.cfg.CacheTTL))
+	return fq, nil
+}
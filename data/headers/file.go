@@ -0,0 +1,100 @@
+// Package headers contains the block header sources merkle proofs are verified against.
+package headers
+
+import (
+	"context"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/libsv/go-bc"
+	"github.com/libsv/go-bk/crypto"
+	"github.com/libsv/go-bt/v2"
+	"github.com/pkg/errors"
+)
+
+// headerSize is the length of a serialised block header.
+const headerSize = 80
+
+type file struct {
+	path string
+
+	mu      sync.RWMutex
+	size    int64
+	headers map[string]*bc.BlockHeader
+}
+
+// NewFile will setup and return a header source reading a local file of raw 80 byte
+// block headers, in chain order, as written by header sync tools. Every header in the
+// file must link to the one before it, so all headers in the file are on the best chain.
+//
+// The file is re-read if a header isn't found and the file has grown.
+func NewFile(path string) (*file, error) {
+	f := &file{path: path}
+	if err := f.load(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// BlockHeader will return the header for the block hash, bc.ErrHeaderNotFound is
+// returned if it isn't in the file.
+func (f *file) BlockHeader(ctx context.Context, blockHash string) (*bc.BlockHeader, error) {
+	if h, ok := f.header(blockHash); ok {
+		return h, nil
+	}
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read headers file '%s'", f.path)
+	}
+	f.mu.RLock()
+	grown := info.Size() != f.size
+	f.mu.RUnlock()
+	if !grown {
+		return nil, bc.ErrHeaderNotFound
+	}
+	if err := f.load(); err != nil {
+		return nil, err
+	}
+	if h, ok := f.header(blockHash); ok {
+		return h, nil
+	}
+	return nil, bc.ErrHeaderNotFound
+}
+
+func (f *file) header(blockHash string) (*bc.BlockHeader, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	h, ok := f.headers[blockHash]
+	return h, ok
+}
+
+// load reads and indexes every header in the file, checking they form a chain.
+func (f *file) load() error {
+	bb, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read headers file '%s'", f.path)
+	}
+	// a partially written header is ignored until the write completes.
+	n := len(bb) / headerSize
+	headers := make(map[string]*bc.BlockHeader, n)
+	prev := ""
+	for i := 0; i < n; i++ {
+		raw := bb[i*headerSize : (i+1)*headerSize]
+		h, err := bc.NewBlockHeaderFromBytes(raw)
+		if err != nil {
+			return errors.Wrapf(err, "invalid header %d in headers file '%s'", i, f.path)
+		}
+		if prev != "" && h.HashPrevBlockStr() != prev {
+			return errors.Errorf("header %d in headers file '%s' does not follow block %s", i, f.path, prev)
+		}
+		prev = hex.EncodeToString(bt.ReverseBytes(crypto.Sha256d(raw)))
+		headers[prev] = h
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.headers = headers
+	f.size = int64(len(bb))
+	return nil
+}
@@ -0,0 +1,71 @@
+package headers_test
+
+import (
+	"context"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/libsv/go-bc"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bitcoin-sv/dpp-proxy/data/headers"
+)
+
+const (
+	genesisHeader = "0100000000000000000000000000000000000000000000000000000000000000000000003ba3edfd7a7b12b27ac72c3e67768f617fc81bc3888a51323a9fb8aa4b1e5e4a29ab5f49ffff001d1dac2b7c"
+	genesisHash   = "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"
+	block1Header  = "010000006fe28c0ab6f1b372c1a6a246ae63f74f931e8365e15a089c68d6190000000000982051fd1e4ba744bbbe680e1fee14677ba1a3c3540bf7b1cdb606e857233e0e61bc6649ffff001d01e36299"
+	block1Hash    = "00000000839a8e6886ab5951d76f411475428afc90947ee320161bbf18eb6048"
+	block2Header  = "010000004860eb18bf1b1620e37e9490fc8a427514416fd75159ab86688e9a8300000000d5fdcc541e25de1c7a5addedf24858b8bb665c9f36ef744ee42c316022c90f9bb0bc6649ffff001d08d2bd61"
+	block2Hash    = "000000006a625f06636b8bb6ac7b960a8d03705d1ace08b1a19da3fdcc99ddbd"
+)
+
+func writeHeaders(t *testing.T, path string, hh ...string) {
+	var bb []byte
+	for _, h := range hh {
+		b, err := hex.DecodeString(h)
+		assert.NoError(t, err)
+		bb = append(bb, b...)
+	}
+	assert.NoError(t, ioutil.WriteFile(path, bb, 0600))
+}
+
+func TestFile_BlockHeader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "headers")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "blockheaders")
+	writeHeaders(t, path, genesisHeader, block1Header)
+
+	f, err := headers.NewFile(path)
+	assert.NoError(t, err)
+	h, err := f.BlockHeader(context.Background(), block1Hash)
+	assert.NoError(t, err)
+	assert.Equal(t, block1Header, h.String())
+	h, err = f.BlockHeader(context.Background(), genesisHash)
+	assert.NoError(t, err)
+	assert.Equal(t, genesisHeader, h.String())
+
+	_, err = f.BlockHeader(context.Background(), block2Hash)
+	assert.ErrorIs(t, err, bc.ErrHeaderNotFound)
+
+	// headers appended to the file are read.
+	writeHeaders(t, path, genesisHeader, block1Header, block2Header)
+	h, err = f.BlockHeader(context.Background(), block2Hash)
+	assert.NoError(t, err)
+	assert.Equal(t, block2Header, h.String())
+}
+
+func TestFile_BrokenChain(t *testing.T) {
+	dir, err := ioutil.TempDir("", "headers")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "blockheaders")
+	writeHeaders(t, path, genesisHeader, block2Header)
+
+	_, err = headers.NewFile(path)
+	assert.EqualError(t, err, "header 1 in headers file '"+path+"' does not follow block "+genesisHash)
+}
@@ -0,0 +1,84 @@
+package headers
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/libsv/go-bc"
+	"github.com/libsv/go-bk/crypto"
+	"github.com/libsv/go-bt/v2"
+	"github.com/pkg/errors"
+	"github.com/theflyingcodr/lathos"
+
+	"github.com/bitcoin-sv/dpp-proxy/config"
+	"github.com/bitcoin-sv/dpp-proxy/data"
+	"github.com/bitcoin-sv/dpp-proxy/data/headers/models"
+)
+
+// urlHeaderState is the block headers service endpoint returning a header and its chain state.
+const urlHeaderState = "%s/api/v1/chain/header/state/%s"
+
+type headersClient struct {
+	client data.HTTPClient
+	cfg    *config.Headers
+}
+
+// NewHTTP will setup and return a header source reading headers from a block headers
+// service, such as the bitcoin-sv block-headers-service.
+func NewHTTP(cfg *config.Headers, client data.HTTPClient) *headersClient {
+	return &headersClient{
+		client: client,
+		cfg:    cfg,
+	}
+}
+
+// BlockHeader will return the header for the block hash, bc.ErrHeaderNotFound is returned
+// if the service doesn't know the block and bc.ErrNotOnLongestChain if it is stale.
+func (h *headersClient) BlockHeader(ctx context.Context, blockHash string) (*bc.BlockHeader, error) {
+	var resp models.HeaderState
+	if err := h.client.Do(ctx, http.MethodGet, fmt.Sprintf(urlHeaderState, strings.TrimSuffix(h.cfg.URL, "/"), blockHash),
+		http.StatusOK, nil, &resp); err != nil {
+		if lathos.IsNotFound(err) {
+			return nil, bc.ErrHeaderNotFound
+		}
+		return nil, errors.Wrapf(err, "failed to get header for block %s", blockHash)
+	}
+	if resp.State != models.StateLongestChain {
+		return nil, bc.ErrNotOnLongestChain
+	}
+	header, err := newBlockHeader(resp.Header)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid header returned for block %s", blockHash)
+	}
+	// the header is hashed so a faulty service can't vouch for a merkle root.
+	if hash := hex.EncodeToString(bt.ReverseBytes(crypto.Sha256d(header.Bytes()))); hash != blockHash {
+		return nil, errors.Errorf("header returned for block %s has hash %s", blockHash, hash)
+	}
+	return header, nil
+}
+
+// newBlockHeader maps a header returned by the service, hashes are in display order.
+func newBlockHeader(m models.Header) (*bc.BlockHeader, error) {
+	prev, err := hex.DecodeString(m.PrevBlockHash)
+	if err != nil || len(prev) != 32 {
+		return nil, errors.New("invalid prevBlockHash")
+	}
+	root, err := hex.DecodeString(m.MerkleRoot)
+	if err != nil || len(root) != 32 {
+		return nil, errors.New("invalid merkleRoot")
+	}
+	bits := make([]byte, 4)
+	binary.BigEndian.PutUint32(bits, m.DifficultyTarget)
+	return &bc.BlockHeader{
+		Version:        m.Version,
+		Time:           m.CreationTime,
+		Nonce:          m.Nonce,
+		HashPrevBlock:  prev,
+		HashMerkleRoot: root,
+		Bits:           bits,
+	}, nil
+}
@@ -0,0 +1,85 @@
+package headers_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/libsv/go-bc"
+	"github.com/stretchr/testify/assert"
+	"github.com/theflyingcodr/lathos/errs"
+
+	"github.com/bitcoin-sv/dpp-proxy/config"
+	"github.com/bitcoin-sv/dpp-proxy/data/headers"
+	"github.com/bitcoin-sv/dpp-proxy/data/headers/models"
+	"github.com/bitcoin-sv/dpp-proxy/mocks"
+)
+
+func TestHTTP_BlockHeader(t *testing.T) {
+	block1 := models.Header{
+		Hash:             block1Hash,
+		Version:          1,
+		PrevBlockHash:    genesisHash,
+		MerkleRoot:       "0e3e2357e806b6cdb1f70b54c3a3a17b6714ee1f0e68bebb44a74b1efd512098",
+		CreationTime:     1231469665,
+		DifficultyTarget: 486604799,
+		Nonce:            2573394689,
+	}
+	tests := map[string]struct {
+		resp      models.HeaderState
+		doErr     error
+		expHeader string
+		expErr    error
+	}{
+		"header on the longest chain is returned": {
+			resp:      models.HeaderState{Header: block1, State: models.StateLongestChain, Height: 1},
+			expHeader: block1Header,
+		},
+		"stale header is rejected": {
+			resp:   models.HeaderState{Header: block1, State: models.StateStale, Height: 1},
+			expErr: bc.ErrNotOnLongestChain,
+		},
+		"unknown header is not found": {
+			doErr:  errs.NewErrNotFound("404", "header not found"),
+			expErr: bc.ErrHeaderNotFound,
+		},
+		"header not matching the block hash is rejected": {
+			resp: models.HeaderState{Header: func() models.Header {
+				h := block1
+				h.Nonce++
+				return h
+			}(), State: models.StateLongestChain},
+			expErr: errors.New("header returned for block " + block1Hash + " has hash "),
+		},
+		"service error is returned": {
+			doErr:  errors.New("connection refused"),
+			expErr: errors.New("failed to get header for block " + block1Hash + ": connection refused"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := &mocks.HTTPClientMock{
+				DoFunc: func(ctx context.Context, method, endpoint string, expStatus int, req, out interface{}) error {
+					assert.Equal(t, "https://headers.example.com/api/v1/chain/header/state/"+block1Hash, endpoint)
+					if test.doErr != nil {
+						return test.doErr
+					}
+					*out.(*models.HeaderState) = test.resp
+					return nil
+				},
+			}
+			h, err := headers.NewHTTP(&config.Headers{URL: "https://headers.example.com/"}, client).
+				BlockHeader(context.Background(), block1Hash)
+			switch {
+			case errors.Is(test.expErr, bc.ErrNotOnLongestChain), errors.Is(test.expErr, bc.ErrHeaderNotFound):
+				assert.ErrorIs(t, err, test.expErr)
+			case test.expErr != nil:
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.expErr.Error())
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, test.expHeader, h.String())
+			}
+		})
+	}
+}
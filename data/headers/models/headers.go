@@ -0,0 +1,27 @@
+package models
+
+// Chain states returned by the block headers service.
+const (
+	StateLongestChain = "LONGEST_CHAIN"
+	StateStale        = "STALE"
+	StateOrphan       = "ORPHAN"
+	StateRejected     = "REJECTED"
+)
+
+// Header is a block header returned by the block headers service.
+type Header struct {
+	Hash             string `json:"hash"`
+	Version          uint32 `json:"version"`
+	PrevBlockHash    string `json:"prevBlockHash"`
+	MerkleRoot       string `json:"merkleRoot"`
+	CreationTime     uint32 `json:"creationTimestamp"`
+	DifficultyTarget uint32 `json:"difficultyTarget"`
+	Nonce            uint32 `json:"nonce"`
+}
+
+// HeaderState is a block header and the state of the chain it is on.
+type HeaderState struct {
+	Header Header `json:"header"`
+	State  string `json:"state"`
+	Height uint32 `json:"height"`
+}
@@ -7,11 +7,17 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
 
 	server "github.com/bitcoin-sv/dpp-proxy"
 	"github.com/pkg/errors"
 	validator "github.com/theflyingcodr/govalidator"
-	"github.com/theflyingcodr/lathos/errs"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+
+	"github.com/bitcoin-sv/dpp-proxy/tracing"
 )
 
 // HTTPClient defines a simple interface to execute an http request and map the request and response objects.
@@ -20,21 +26,49 @@ type HTTPClient interface {
 	Do(ctx context.Context, method, endpoint string, expStatus int, req interface{}, out interface{}) error
 }
 
+// headersKey is the context key of headers added to requests.
+type headersKey struct{}
+
+// WithHeaders returns a copy of ctx, requests sent with it by the client include the headers,
+// such as an authorization token.
+func WithHeaders(ctx context.Context, h http.Header) context.Context {
+	return context.WithValue(ctx, headersKey{}, h)
+}
+
 type client struct {
-	c *http.Client
+	// timeout is accessed atomically so is kept first for 64 bit alignment.
+	timeout int64
+	c       *http.Client
 }
 
-// NewClient will setup and return a new http client.
-func NewClient(c *http.Client) *client {
+// NewClient will setup and return a new http client, each request will be
+// cancelled if a response isn't received within the timeout.
+func NewClient(c *http.Client, timeout time.Duration) *client {
 	return &client{
-		c: c,
+		c:       c,
+		timeout: int64(timeout),
 	}
 }
 
+// SetTimeout will update the request timeout, this is safe to call while requests are in flight.
+func (c *client) SetTimeout(timeout time.Duration) {
+	atomic.StoreInt64(&c.timeout, int64(timeout))
+}
+
 // Do will execute an http request and validate the status matches expStatus.
 //
 // if req is empty no request body will be added, if out is empty, the response will not be mapped.
-func (c *client) Do(ctx context.Context, method, endpoint string, expStatus int, req interface{}, out interface{}) error {
+func (c *client) Do(ctx context.Context, method, endpoint string, expStatus int, req interface{}, out interface{}) (err error) {
+	ctx, span := tracing.StartClientSpan(ctx, "HTTP "+method)
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
+	if timeout := time.Duration(atomic.LoadInt64(&c.timeout)); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
 	rdr := &bytes.Buffer{}
 	if req != nil {
 		if err := json.NewEncoder(rdr).Encode(req); err != nil {
@@ -46,6 +80,15 @@ func (c *client) Do(ctx context.Context, method, endpoint string, expStatus int,
 		return errors.Wrapf(err, "failed to create http request for '%s' '%s'", method, endpoint)
 	}
 	httpReq.Header.Add("Content-Type", "application/json")
+	if h, ok := ctx.Value(headersKey{}).(http.Header); ok {
+		for k, vv := range h {
+			for _, v := range vv {
+				httpReq.Header.Add(k, v)
+			}
+		}
+	}
+	span.SetAttributes(semconv.HTTPClientAttributesFromHTTPRequest(httpReq)...)
+	tracing.Inject(ctx, propagation.HeaderCarrier(httpReq.Header))
 
 	resp, err := c.c.Do(httpReq)
 	if err != nil {
@@ -54,6 +97,7 @@ func (c *client) Do(ctx context.Context, method, endpoint string, expStatus int,
 	defer func() {
 		_ = resp.Body.Close()
 	}()
+	span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(resp.StatusCode)...)
 	if resp.StatusCode != expStatus {
 		return c.handleErr(resp, expStatus)
 	}
@@ -65,38 +109,50 @@ func (c *client) Do(ctx context.Context, method, endpoint string, expStatus int,
 	return nil
 }
 
+// errorBody is the body of an error response, payd returns a ClientError with a message
+// and problems have a detail, so both are read.
+type errorBody struct {
+	server.Problem
+	Message string `json:"message"`
+}
+
+// handleErr returns the error matching the response status, so errors from payd are
+// returned to clients with the same status. Bodies that aren't json, for example from a
+// proxy in front of payd, are ignored.
 func (c *client) handleErr(resp *http.Response, expStatus int) error {
+	body, _ := ioutil.ReadAll(resp.Body)
 	if resp.StatusCode == http.StatusBadRequest {
 		brErr := server.BadRequestError{
 			Errors: make(validator.ErrValidation),
 		}
-		if err := json.NewDecoder(resp.Body).Decode(&brErr); err != nil {
-			return errors.WithStack(err)
+		if err := json.Unmarshal(body, &brErr); err == nil && len(brErr.Errors) > 0 {
+			return brErr.Errors
 		}
-		return brErr.Errors
 	}
+	var msg errorBody
+	_ = json.Unmarshal(body, &msg)
+	code := msg.Code
+	if code == "" {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	detail := msg.Message
+	if detail == "" {
+		detail = msg.Detail
+	}
+	if detail == "" {
+		detail = http.StatusText(resp.StatusCode)
+	}
+	if err := server.StatusError(resp.StatusCode, code, detail, retryAfter(resp.Header)); err != nil {
+		return err
+	}
+	return fmt.Errorf("error for '%s' '%s'. Status Received : '%d', Status Expected : '%d'. \nBody: %s", resp.Request.Method, resp.Request.RequestURI, resp.StatusCode, expStatus, body)
+}
 
-	switch resp.StatusCode {
-	case http.StatusNotFound:
-		var msg server.ClientError
-		if err := json.NewDecoder(resp.Body).Decode(&msg); err != nil {
-			return errors.WithStack(err)
-		}
-		return errs.NewErrNotFound(msg.Code, msg.Message)
-	case http.StatusConflict:
-		var msg server.ClientError
-		if err := json.NewDecoder(resp.Body).Decode(&msg); err != nil {
-			return errors.WithStack(err)
-		}
-		return errs.NewErrDuplicate(msg.Code, msg.Message)
-	case http.StatusUnprocessableEntity:
-		var msg server.ClientError
-		if err := json.NewDecoder(resp.Body).Decode(&msg); err != nil {
-			return errors.WithStack(err)
-		}
-		return errs.NewErrUnprocessable(msg.Code, msg.Message)
-	default:
-		body, _ := ioutil.ReadAll(resp.Body)
-		return fmt.Errorf("error for '%s' '%s'. Status Received : '%d', Status Expected : '%d'. \nBody: %s", resp.Request.Method, resp.Request.RequestURI, resp.StatusCode, expStatus, body)
+// retryAfter returns the delay in the Retry-After header, only the seconds form is supported.
+func retryAfter(h http.Header) time.Duration {
+	secs, err := strconv.Atoi(h.Get("Retry-After"))
+	if err != nil || secs < 0 {
+		return 0
 	}
+	return time.Duration(secs) * time.Second
 }
@@ -0,0 +1,114 @@
+package data_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	validator "github.com/theflyingcodr/govalidator"
+	"github.com/theflyingcodr/lathos"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/data"
+)
+
+func TestClient_Do_Errors(t *testing.T) {
+	tests := map[string]struct {
+		status     int
+		body       string
+		retryAfter string
+		errFn      func(error) bool
+		expErr     error
+	}{
+		"validation errors are returned": {
+			status: http.StatusBadRequest,
+			body:   `{"errors": {"paymentID": ["value cannot be empty"]}}`,
+			errFn: func(err error) bool {
+				var valErr validator.ErrValidation
+				return errors.As(err, &valErr)
+			},
+			expErr: errors.New("[paymentID: value cannot be empty]"),
+		},
+		"bad request without field errors": {
+			status: http.StatusBadRequest,
+			body:   `{"code": "B01", "message": "invalid payment"}`,
+			errFn:  lathos.IsBadRequest,
+			expErr: errors.New("Bad request: invalid payment"),
+		},
+		"not authenticated": {
+			status: http.StatusUnauthorized,
+			body:   `{"code": "401", "message": "invalid token"}`,
+			errFn:  lathos.IsNotAuthenticated,
+			expErr: errors.New("Not authenticated: invalid token"),
+		},
+		"permission denied": {
+			status: http.StatusForbidden,
+			body:   `{"code": "403", "message": "not your invoice"}`,
+			errFn:  lathos.IsNotAuthorised,
+			expErr: errors.New("Permission denied: not your invoice"),
+		},
+		"not found problem": {
+			status: http.StatusNotFound,
+			body:   `{"type": "urn:dpp-proxy:error:not_found", "code": "not_found", "detail": "invoice not found"}`,
+			errFn:  lathos.IsNotFound,
+			expErr: errors.New("Not found: invoice not found"),
+		},
+		"rate limited with retry after": {
+			status:     http.StatusTooManyRequests,
+			retryAfter: "30",
+			errFn: func(err error) bool {
+				var rlErr server.ErrRateLimited
+				return errors.As(err, &rlErr) && rlErr.RetryAfter == 30*time.Second
+			},
+			expErr: errors.New("Too many requests: Too Many Requests"),
+		},
+		"unavailable without a json body": {
+			status: http.StatusServiceUnavailable,
+			body:   `<html>down for maintenance</html>`,
+			errFn:  lathos.IsUnavailable,
+			expErr: errors.New("Not available: Service Unavailable"),
+		},
+		"unexpected status is an internal error": {
+			status: http.StatusInternalServerError,
+			body:   `oops`,
+			errFn: func(err error) bool {
+				return !lathos.IsClientError(err)
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if test.retryAfter != "" {
+					w.Header().Set("Retry-After", test.retryAfter)
+				}
+				w.WriteHeader(test.status)
+				_, _ = w.Write([]byte(test.body))
+			}))
+			defer svr.Close()
+
+			err := data.NewClient(svr.Client(), time.Second).Do(context.Background(), http.MethodGet, svr.URL, http.StatusOK, nil, nil)
+			assert.Error(t, err)
+			assert.True(t, test.errFn(err))
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+			}
+		})
+	}
+}
+
+func TestClient_Do_Headers(t *testing.T) {
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer abc", r.Header.Get("Authorization"))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer svr.Close()
+
+	ctx := data.WithHeaders(context.Background(), http.Header{"Authorization": []string{"Bearer abc"}})
+	assert.NoError(t, data.NewClient(svr.Client(), time.Second).Do(ctx, http.MethodGet, svr.URL, http.StatusOK, nil, nil))
+}
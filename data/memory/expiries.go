@@ -0,0 +1,42 @@
+package memory
+
+import (
+	"container/heap"
+	"time"
+)
+
+// expiry is the time the entry stored under key expires.
+type expiry struct {
+	key string
+	at  time.Time
+}
+
+// expiries is a heap of entry expiry times, soonest first, so stores can remove expired
+// entries without scanning every entry. Replaced entries keep their old expiry in the
+// heap, so stores must check an entry has expired before removing it.
+type expiries []expiry
+
+func (e expiries) Len() int           { return len(e) }
+func (e expiries) Less(i, j int) bool { return e[i].at.Before(e[j].at) }
+func (e expiries) Swap(i, j int)      { e[i], e[j] = e[j], e[i] }
+
+func (e *expiries) Push(x interface{}) { *e = append(*e, x.(expiry)) }
+
+func (e *expiries) Pop() interface{} {
+	old := *e
+	x := old[len(old)-1]
+	*e = old[:len(old)-1]
+	return x
+}
+
+// add records that the entry stored under key expires at the time.
+func (e *expiries) add(key string, at time.Time) {
+	heap.Push(e, expiry{key: key, at: at})
+}
+
+// expire calls remove with the key of each entry that expired before now.
+func (e *expiries) expire(now time.Time, remove func(key string)) {
+	for e.Len() > 0 && (*e)[0].at.Before(now) {
+		remove(heap.Pop(e).(expiry).key)
+	}
+}
@@ -0,0 +1,53 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/theflyingcodr/lathos/errs"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+)
+
+type invoices struct {
+	mu       sync.RWMutex
+	invoices map[string]server.Invoice
+}
+
+// NewInvoices will setup and return a new in memory invoice store, keyed by paymentID.
+func NewInvoices() *invoices {
+	return &invoices{invoices: map[string]server.Invoice{}}
+}
+
+// InvoiceCreate records the invoice, unless one is already recorded for the paymentID.
+func (i *invoices) InvoiceCreate(ctx context.Context, req server.Invoice) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if _, ok := i.invoices[req.PaymentID]; ok {
+		return errs.NewErrDuplicate("409", "an invoice is already registered for the payment")
+	}
+	i.invoices[req.PaymentID] = req
+	return nil
+}
+
+// Invoice returns the invoice recorded for a paymentID.
+func (i *invoices) Invoice(ctx context.Context, args server.InvoiceArgs) (*server.Invoice, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	invoice, ok := i.invoices[args.PaymentID]
+	if !ok {
+		return nil, errs.NewErrNotFound("404", "invoice not found")
+	}
+	return &invoice, nil
+}
+
+// InvoiceDelete removes the invoice recorded for a paymentID.
+func (i *invoices) InvoiceDelete(ctx context.Context, args server.InvoiceArgs) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if _, ok := i.invoices[args.PaymentID]; !ok {
+		return errs.NewErrNotFound("404", "invoice not found")
+	}
+	delete(i.invoices, args.PaymentID)
+	return nil
+}
@@ -0,0 +1,42 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/theflyingcodr/lathos/errs"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+)
+
+type paymailInvoices struct {
+	mu       sync.RWMutex
+	invoices map[string]server.PaymailInvoice
+}
+
+// NewPaymailInvoices will setup and return a new in memory paymail invoice store, keyed by paymentID.
+func NewPaymailInvoices() *paymailInvoices {
+	return &paymailInvoices{invoices: map[string]server.PaymailInvoice{}}
+}
+
+// PaymailInvoiceCreate records the invoice, unless one is already recorded for the paymentID.
+func (p *paymailInvoices) PaymailInvoiceCreate(ctx context.Context, req server.PaymailInvoice) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.invoices[req.PaymentID]; ok {
+		return errs.NewErrDuplicate("409", "an invoice already exists for the payment")
+	}
+	p.invoices[req.PaymentID] = req
+	return nil
+}
+
+// PaymailInvoice returns the invoice recorded for a paymentID.
+func (p *paymailInvoices) PaymailInvoice(ctx context.Context, args server.PaymailInvoiceArgs) (*server.PaymailInvoice, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	inv, ok := p.invoices[args.PaymentID]
+	if !ok {
+		return nil, errs.NewErrNotFound("404", "invoice not found")
+	}
+	return &inv, nil
+}
@@ -0,0 +1,84 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/theflyingcodr/lathos/errs"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+)
+
+type paymentQueue struct {
+	mu       sync.RWMutex
+	payments map[string]server.QueuedPayment
+}
+
+// NewPaymentQueue will setup and return a new in memory payment queue, holding
+// a payment for each paymentID.
+func NewPaymentQueue() *paymentQueue {
+	return &paymentQueue{payments: map[string]server.QueuedPayment{}}
+}
+
+// PaymentEnqueue adds the payment to the queue, unless one is already queued for the paymentID.
+func (p *paymentQueue) PaymentEnqueue(ctx context.Context, req server.QueuedPayment) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.payments[req.PaymentID]; ok {
+		return errs.NewErrDuplicate("409", "a payment has already been received for the invoice")
+	}
+	p.payments[req.PaymentID] = req
+	return nil
+}
+
+// QueuedPaymentUpdate applies fn to the payment queued for a paymentID while holding the lock.
+func (p *paymentQueue) QueuedPaymentUpdate(ctx context.Context, args server.QueuedPaymentArgs,
+	fn func(qp *server.QueuedPayment) bool) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	payment, ok := p.payments[args.PaymentID]
+	if !ok {
+		return errs.NewErrNotFound("404", "no payment queued for invoice")
+	}
+	if fn(&payment) {
+		p.payments[args.PaymentID] = payment
+	}
+	return nil
+}
+
+// QueuedPayments returns the queued payments, oldest first.
+func (p *paymentQueue) QueuedPayments(ctx context.Context) ([]server.QueuedPayment, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	pp := make([]server.QueuedPayment, 0, len(p.payments))
+	for _, payment := range p.payments {
+		pp = append(pp, payment)
+	}
+	sort.Slice(pp, func(i, j int) bool {
+		return pp[i].ReceivedAt.Before(pp[j].ReceivedAt)
+	})
+	return pp, nil
+}
+
+// QueuedPayment returns the payment queued for a paymentID.
+func (p *paymentQueue) QueuedPayment(ctx context.Context, args server.QueuedPaymentArgs) (*server.QueuedPayment, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	payment, ok := p.payments[args.PaymentID]
+	if !ok {
+		return nil, errs.NewErrNotFound("404", "no payment queued for invoice")
+	}
+	return &payment, nil
+}
+
+// PaymentDequeue removes the payment queued for a paymentID.
+func (p *paymentQueue) PaymentDequeue(ctx context.Context, args server.QueuedPaymentArgs) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.payments[args.PaymentID]; !ok {
+		return errs.NewErrNotFound("404", "no payment queued for invoice")
+	}
+	delete(p.payments, args.PaymentID)
+	return nil
+}
@@ -0,0 +1,61 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/theflyingcodr/lathos/errs"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+)
+
+type paymentStatuses struct {
+	mu        sync.RWMutex
+	statuses  map[string]server.PaymentStatus
+	expiries  expiries
+	retention time.Duration
+}
+
+// NewPaymentStatuses will setup and return a new in memory payment status store, keyed by paymentID.
+// Statuses last updated longer than retention ago are removed as new statuses are recorded,
+// unless the payment request they belong to hasn't expired yet.
+func NewPaymentStatuses(retention time.Duration) *paymentStatuses {
+	return &paymentStatuses{statuses: map[string]server.PaymentStatus{}, retention: retention}
+}
+
+// PaymentStatusUpdate records the status, replacing any already recorded for the paymentID.
+func (p *paymentStatuses) PaymentStatusUpdate(ctx context.Context, req server.PaymentStatus) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	p.expiries.expire(now, func(paymentID string) {
+		if status, ok := p.statuses[paymentID]; ok && p.expiresAt(status).Before(now) {
+			delete(p.statuses, paymentID)
+		}
+	})
+	p.statuses[req.PaymentID] = req
+	p.expiries.add(req.PaymentID, p.expiresAt(req))
+	return nil
+}
+
+// expiresAt returns the time the status is removed, which is retention after it was last
+// updated, or when its payment request expires if that is later.
+func (p *paymentStatuses) expiresAt(status server.PaymentStatus) time.Time {
+	at := status.UpdatedAt.Add(p.retention)
+	if status.ExpiresAt != nil && status.ExpiresAt.After(at) {
+		return *status.ExpiresAt
+	}
+	return at
+}
+
+// PaymentStatus returns the status recorded for a paymentID.
+func (p *paymentStatuses) PaymentStatus(ctx context.Context, args server.PaymentStatusArgs) (*server.PaymentStatus, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	status, ok := p.statuses[args.PaymentID]
+	if !ok {
+		return nil, errs.NewErrNotFound("404", "no status found for payment")
+	}
+	return &status, nil
+}
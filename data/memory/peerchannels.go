@@ -0,0 +1,102 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/theflyingcodr/lathos/errs"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+)
+
+type peerChannels struct {
+	mu          sync.RWMutex
+	maxMessages int
+	channels    map[string]server.PeerChannel
+	payments    map[string]string
+	messages    map[string][]server.PeerChannelMessage
+	sequences   map[string]uint64
+}
+
+// NewPeerChannels will setup and return a new in memory peer channel store, keyed by channel id
+// and indexed by paymentID. Each channel keeps its latest maxMessages messages, older messages
+// are dropped.
+func NewPeerChannels(maxMessages int) *peerChannels {
+	return &peerChannels{
+		maxMessages: maxMessages,
+		channels:    map[string]server.PeerChannel{},
+		payments:    map[string]string{},
+		messages:    map[string][]server.PeerChannelMessage{},
+		sequences:   map[string]uint64{},
+	}
+}
+
+// PeerChannelCreate records the channel, replacing any channel already recorded for the payment.
+func (p *peerChannels) PeerChannelCreate(ctx context.Context, req server.PeerChannel) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if old, ok := p.payments[req.PaymentID]; ok && old != req.ID {
+		delete(p.channels, old)
+		delete(p.messages, old)
+		delete(p.sequences, old)
+	}
+	p.channels[req.ID] = req
+	p.payments[req.PaymentID] = req.ID
+	return nil
+}
+
+// PeerChannel returns the channel recorded with the id.
+func (p *peerChannels) PeerChannel(ctx context.Context, channelID string) (*server.PeerChannel, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	channel, ok := p.channels[channelID]
+	if !ok {
+		return nil, errs.NewErrNotFound("404", "peer channel not found")
+	}
+	return &channel, nil
+}
+
+// PaymentPeerChannel returns the channel recorded for a paymentID.
+func (p *peerChannels) PaymentPeerChannel(ctx context.Context, paymentID string) (*server.PeerChannel, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	channel, ok := p.channels[p.payments[paymentID]]
+	if !ok {
+		return nil, errs.NewErrNotFound("404", "no peer channel found for payment")
+	}
+	return &channel, nil
+}
+
+// PeerChannelMessageCreate adds the message to the channel with the next sequence.
+func (p *peerChannels) PeerChannelMessageCreate(ctx context.Context, channelID string, req server.PeerChannelMessage) (*server.PeerChannelMessage, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.channels[channelID]; !ok {
+		return nil, errs.NewErrNotFound("404", "peer channel not found")
+	}
+	p.sequences[channelID]++
+	req.Sequence = p.sequences[channelID]
+	mm := append(p.messages[channelID], req)
+	if p.maxMessages > 0 && len(mm) > p.maxMessages {
+		// copied so the dropped messages can be freed.
+		mm = append([]server.PeerChannelMessage(nil), mm[len(mm)-p.maxMessages:]...)
+	}
+	p.messages[channelID] = mm
+	return &req, nil
+}
+
+// PeerChannelMessages returns the messages on the channel with a sequence greater than after.
+func (p *peerChannels) PeerChannelMessages(ctx context.Context, channelID string, after uint64) ([]server.PeerChannelMessage, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if _, ok := p.channels[channelID]; !ok {
+		return nil, errs.NewErrNotFound("404", "peer channel not found")
+	}
+	resp := make([]server.PeerChannelMessage, 0, len(p.messages[channelID]))
+	for _, m := range p.messages[channelID] {
+		if m.Sequence > after {
+			resp = append(resp, m)
+		}
+	}
+	return resp, nil
+}
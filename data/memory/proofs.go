@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/theflyingcodr/lathos/errs"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+)
+
+type proofs struct {
+	mu       sync.RWMutex
+	proofs   map[string]server.Proof
+	payments map[string]map[string]struct{}
+}
+
+// NewProofs will setup and return a new in memory proof store, keyed by txid
+// and indexed by paymentID.
+func NewProofs() *proofs {
+	return &proofs{
+		proofs:   map[string]server.Proof{},
+		payments: map[string]map[string]struct{}{},
+	}
+}
+
+// ProofCreate records the proof, replacing any already recorded for the txid.
+func (p *proofs) ProofCreate(ctx context.Context, req server.Proof) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if old, ok := p.proofs[req.TxID]; ok && old.PaymentID != req.PaymentID {
+		delete(p.payments[old.PaymentID], req.TxID)
+	}
+	p.proofs[req.TxID] = req
+	if req.PaymentID == "" {
+		return nil
+	}
+	if _, ok := p.payments[req.PaymentID]; !ok {
+		p.payments[req.PaymentID] = map[string]struct{}{}
+	}
+	p.payments[req.PaymentID][req.TxID] = struct{}{}
+	return nil
+}
+
+// Proof returns the proof recorded for a txid.
+func (p *proofs) Proof(ctx context.Context, args server.ProofArgs) (*server.Proof, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	proof, ok := p.proofs[args.TxID]
+	if !ok {
+		return nil, errs.NewErrNotFound("404", "no proof found for txid")
+	}
+	return &proof, nil
+}
+
+// PaymentProofs returns the proofs recorded for the transactions of a payment, oldest first.
+func (p *proofs) PaymentProofs(ctx context.Context, args server.PaymentProofsArgs) ([]server.Proof, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	resp := make([]server.Proof, 0, len(p.payments[args.PaymentID]))
+	for txID := range p.payments[args.PaymentID] {
+		resp = append(resp, p.proofs[txID])
+	}
+	sort.Slice(resp, func(i, j int) bool {
+		return resp[i].CreatedAt.Before(resp[j].CreatedAt)
+	})
+	return resp, nil
+}
@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/theflyingcodr/lathos/errs"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+)
+
+type proofTokens struct {
+	mu        sync.RWMutex
+	tokens    map[string]server.ProofToken
+	expiries  expiries
+	retention time.Duration
+}
+
+// NewProofTokens will setup and return a new in memory proof token store, keyed by txid.
+// Tokens recorded longer than retention ago are removed as new tokens are recorded.
+func NewProofTokens(retention time.Duration) *proofTokens {
+	return &proofTokens{tokens: map[string]server.ProofToken{}, retention: retention}
+}
+
+// ProofTokenCreate records the tokens, unless tokens of another payment, or other tokens,
+// are already recorded for the txid. Recording tokens that are already recorded is ignored.
+func (p *proofTokens) ProofTokenCreate(ctx context.Context, req server.ProofToken) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	p.expiries.expire(now, func(txID string) {
+		if t, ok := p.tokens[txID]; ok && p.expiresAt(t).Before(now) {
+			delete(p.tokens, txID)
+		}
+	})
+	existing, ok := p.tokens[req.TxID]
+	if !ok {
+		p.tokens[req.TxID] = req
+		p.expiries.add(req.TxID, p.expiresAt(req))
+		return nil
+	}
+	if existing.PaymentID != req.PaymentID || !hasTokens(existing.Tokens, req.Tokens) {
+		return errs.NewErrDuplicate("409", "proof tokens are already recorded for the transaction")
+	}
+	return nil
+}
+
+// expiresAt returns the time the tokens are removed.
+func (p *proofTokens) expiresAt(t server.ProofToken) time.Time {
+	return t.CreatedAt.Add(p.retention)
+}
+
+// hasTokens returns true if every token in tokens is in recorded.
+func hasTokens(recorded, tokens []string) bool {
+	seen := make(map[string]struct{}, len(recorded))
+	for _, t := range recorded {
+		seen[t] = struct{}{}
+	}
+	for _, t := range tokens {
+		if _, ok := seen[t]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// ProofToken returns the tokens recorded for a txid.
+func (p *proofTokens) ProofToken(ctx context.Context, txID string) (*server.ProofToken, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	token, ok := p.tokens[txID]
+	if !ok {
+		return nil, errs.NewErrNotFound("404", "no payment found for txid")
+	}
+	return &token, nil
+}
@@ -0,0 +1,58 @@
+// Package memory contains data stores that keep state in the proxy process,
+// state is lost when the proxy restarts.
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+)
+
+type refunds struct {
+	mu        sync.RWMutex
+	refunds   map[string]server.Refund
+	expiries  expiries
+	retention time.Duration
+}
+
+// NewRefunds will setup and return a new in memory refund store, keyed by paymentID.
+// Refunds recorded longer than retention ago expire, they are removed as new refunds are recorded.
+func NewRefunds(retention time.Duration) *refunds {
+	return &refunds{refunds: map[string]server.Refund{}, retention: retention}
+}
+
+// RefundCreate records the refund, replacing any already recorded for the paymentID.
+func (r *refunds) RefundCreate(ctx context.Context, req server.Refund) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	r.expiries.expire(now, func(paymentID string) {
+		if refund, ok := r.refunds[paymentID]; ok && r.expiresAt(refund).Before(now) {
+			delete(r.refunds, paymentID)
+		}
+	})
+	r.refunds[req.PaymentID] = req
+	r.expiries.add(req.PaymentID, r.expiresAt(req))
+	return nil
+}
+
+// expiresAt returns the time the refund is removed.
+func (r *refunds) expiresAt(refund server.Refund) time.Time {
+	return refund.CreatedAt.Add(r.retention)
+}
+
+// Refund returns the refund recorded for a paymentID. Refunds aren't kept across restarts,
+// so a missing refund can't be told apart from an expired one and both return an Expired error.
+func (r *refunds) Refund(ctx context.Context, args server.RefundArgs) (*server.Refund, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	refund, ok := r.refunds[args.PaymentID]
+	if !ok || r.expiresAt(refund).Before(time.Now()) {
+		return nil, server.NewErrExpired("410", fmt.Sprintf(
+			"refund destination expired or wasn't supplied, destinations are kept for %s and not across restarts", r.retention))
+	}
+	return &refund, nil
+}
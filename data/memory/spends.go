@@ -0,0 +1,73 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+)
+
+type spends struct {
+	mu     sync.RWMutex
+	spends map[string]server.Spend
+}
+
+// NewSpends will setup and return a new in memory spend store, keyed by outpoint.
+func NewSpends() *spends {
+	return &spends{spends: map[string]server.Spend{}}
+}
+
+// SpendsReserve returns the spends of the outpoints recorded since args.Since for other payments
+// and records the spends of outpoints without one. If args.Exclusive is set and there are
+// conflicting spends, nothing is recorded.
+func (s *spends) SpendsReserve(ctx context.Context, args server.SpendsReserveArgs) (*server.SpendsReservation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := &server.SpendsReservation{
+		Conflicting: make([]server.Spend, 0),
+		Reserved:    make([]server.Spend, 0, len(args.Spends)),
+	}
+	for _, spend := range args.Spends {
+		recorded, ok := s.spends[spend.Outpoint]
+		if !ok || recorded.SpentAt.Before(args.Since) {
+			res.Reserved = append(res.Reserved, spend)
+			continue
+		}
+		if recorded.PaymentID != spend.PaymentID {
+			res.Conflicting = append(res.Conflicting, recorded)
+		}
+	}
+	if args.Exclusive && len(res.Conflicting) > 0 {
+		res.Reserved = res.Reserved[:0]
+		return res, nil
+	}
+	for _, spend := range res.Reserved {
+		s.spends[spend.Outpoint] = spend
+	}
+	return res, nil
+}
+
+// SpendsRelease removes the spends, if they are still the spends recorded of their outpoints.
+func (s *spends) SpendsRelease(ctx context.Context, req []server.Spend) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, spend := range req {
+		if recorded, ok := s.spends[spend.Outpoint]; ok && recorded == spend {
+			delete(s.spends, spend.Outpoint)
+		}
+	}
+	return nil
+}
+
+// SpendsPrune removes spends recorded before the time.
+func (s *spends) SpendsPrune(ctx context.Context, before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for o, spend := range s.spends {
+		if spend.SpentAt.Before(before) {
+			delete(s.spends, o)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package noop
+
+import (
+	"context"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+)
+
+type audit struct{}
+
+// NewAudit will setup and return an audit store that discards all entries,
+// used when auditing is disabled.
+func NewAudit() *audit {
+	return &audit{}
+}
+
+// AuditWrite discards the entry.
+func (a audit) AuditWrite(ctx context.Context, entry server.AuditEntry) error {
+	return nil
+}
+
+// AuditLast always returns nil.
+func (a audit) AuditLast(ctx context.Context) (*server.AuditEntry, error) {
+	return nil, nil
+}
@@ -13,6 +13,8 @@ type PayDPaymentRequest struct {
 	Ancestry       *string                      `json:"ancestry"`
 	RawTx          *string                      `json:"rawTx"`
 	ProofCallbacks map[string]dpp.ProofCallback `json:"proofCallbacks"`
+	// RefundTo is the paymail, address or hex locking script the customer wants refunds paid to.
+	RefundTo *string `json:"refundTo,omitempty"`
 }
 
 // Destination is a payment output with locking script.
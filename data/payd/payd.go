@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"sync"
 
 	"github.com/libsv/go-bk/envelope"
 	"github.com/pkg/errors"
 
+	server "github.com/bitcoin-sv/dpp-proxy"
 	"github.com/bitcoin-sv/dpp-proxy/config"
 	"github.com/bitcoin-sv/dpp-proxy/data"
 	"github.com/bitcoin-sv/dpp-proxy/data/payd/models"
@@ -18,6 +20,7 @@ import (
 const (
 	urlPayments      = "%s/api/v1/payments/%s"
 	urlProofs        = "%s/api/v1/proofs/%s"
+	urlDoubleSpends  = "%s/api/v1/doublespends/%s"
 	protocolInsecure = "http"
 	protocolSecure   = "https"
 )
@@ -25,6 +28,11 @@ const (
 type payd struct {
 	client data.HTTPClient
 	cfg    *config.PayD
+
+	// mu guards the address of payd, which can be changed while running.
+	mu   sync.RWMutex
+	host string
+	port string
 }
 
 // NewPayD will setup a new store that can interface with a payd wallet implementing
@@ -33,9 +41,18 @@ func NewPayD(cfg *config.PayD, client data.HTTPClient) *payd {
 	return &payd{
 		cfg:    cfg,
 		client: client,
+		host:   cfg.Host,
+		port:   cfg.Port,
 	}
 }
 
+// SetAddress changes the host and port of the payd instance requests are sent to.
+func (p *payd) SetAddress(host, port string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.host, p.port = host, port
+}
+
 // PaymentRequest will fetch a payment request message from payd for a given payment.
 func (p *payd) PaymentRequest(ctx context.Context, args dpp.PaymentRequestArgs) (*dpp.PaymentRequest, error) {
 	var resp dpp.PaymentRequest
@@ -54,6 +71,7 @@ func (p *payd) PaymentCreate(ctx context.Context, args dpp.PaymentCreateArgs, re
 		RawTx:          req.RawTx,
 		Ancestry:       req.Ancestry,
 		ProofCallbacks: req.ProofCallbacks,
+		RefundTo:       req.RefundTo,
 	}
 	var ack dpp.PaymentACK
 	if err := p.client.Do(ctx, http.MethodPost, fmt.Sprintf(urlPayments, p.baseURL(), args.PaymentID), http.StatusNoContent, paymentReq, &ack); err != nil {
@@ -67,10 +85,17 @@ func (p *payd) ProofCreate(ctx context.Context, args dpp.ProofCreateArgs, req en
 	return errors.WithStack(p.client.Do(ctx, http.MethodPost, fmt.Sprintf(urlProofs, p.baseURL(), args.TxID), http.StatusCreated, req, nil))
 }
 
+// DoubleSpendCreate will notify payd that a payment transaction has been double spent.
+func (p *payd) DoubleSpendCreate(ctx context.Context, args dpp.ProofCreateArgs, req server.DoubleSpend) error {
+	return errors.WithStack(p.client.Do(ctx, http.MethodPost, fmt.Sprintf(urlDoubleSpends, p.baseURL(), args.TxID), http.StatusCreated, req, nil))
+}
+
 // baseURL will return http or https depending on if we're using TLS.
 func (p *payd) baseURL() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
 	if p.cfg.Secure {
-		return fmt.Sprintf("%s://%s%s", protocolSecure, p.cfg.Host, p.cfg.Port)
+		return fmt.Sprintf("%s://%s%s", protocolSecure, p.host, p.port)
 	}
-	return fmt.Sprintf("%s://%s%s", protocolInsecure, p.cfg.Host, p.cfg.Port)
+	return fmt.Sprintf("%s://%s%s", protocolInsecure, p.host, p.port)
 }
@@ -35,13 +35,15 @@ func TestPayd_PaymentCreate(t *testing.T) {
 				PaymentID: "qwe123",
 			},
 			req: dpp.Payment{
-				RawTx: func() *string { s := "rawrawraw"; return &s }(),
+				RawTx:    func() *string { s := "rawrawraw"; return &s }(),
+				RefundTo: func() *string { s := "me@paymail.com"; return &s }(),
 				ProofCallbacks: map[string]dpp.ProofCallback{
 					"abc.com": {Token: "mYtOkEn"},
 				},
 			},
 			expReq: models.PayDPaymentRequest{
-				RawTx: func() *string { s := "rawrawraw"; return &s }(),
+				RawTx:    func() *string { s := "rawrawraw"; return &s }(),
+				RefundTo: func() *string { s := "me@paymail.com"; return &s }(),
 				ProofCallbacks: map[string]dpp.ProofCallback{
 					"abc.com": {Token: "mYtOkEn"},
 				},
@@ -394,3 +396,25 @@ func TestPayd_PaymentRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestPayd_SetAddress(t *testing.T) {
+	var urls []string
+	cfg := &config.PayD{Host: "payd", Port: ":8443"}
+	pd := payd.NewPayD(cfg, &mocks.HTTPClientMock{
+		DoFunc: func(ctx context.Context, method string, url string, statusCode int, req, out interface{}) error {
+			urls = append(urls, url)
+			return errors.New("yikes")
+		},
+	})
+	args := dpp.PaymentRequestArgs{PaymentID: "abc123"}
+
+	_, _ = pd.PaymentRequest(context.Background(), args)
+	pd.SetAddress("otherpayd", ":9443")
+	_, _ = pd.PaymentRequest(context.Background(), args)
+
+	assert.Equal(t, []string{
+		"http://payd:8443/api/v1/payments/abc123",
+		"http://otherpayd:9443/api/v1/payments/abc123",
+	}, urls)
+	assert.Equal(t, "payd", cfg.Host)
+}
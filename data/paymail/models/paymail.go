@@ -0,0 +1,47 @@
+package models
+
+// Capabilities is returned from a paymail host's .well-known/bsvalias document.
+type Capabilities struct {
+	BsvAlias string `json:"bsvalias"`
+	// Capabilities maps BRFC ids to endpoint templates, or flags for capabilities that don't need an endpoint.
+	Capabilities map[string]interface{} `json:"capabilities"`
+}
+
+// DestinationRequest is sent to the P2P payment destination endpoint.
+type DestinationRequest struct {
+	Satoshis uint64 `json:"satoshis"`
+}
+
+// DestinationOutput is a single output returned by the P2P payment destination endpoint.
+type DestinationOutput struct {
+	Script   string `json:"script"`
+	Satoshis uint64 `json:"satoshis"`
+}
+
+// DestinationResponse is returned by the P2P payment destination endpoint.
+type DestinationResponse struct {
+	Outputs []DestinationOutput `json:"outputs"`
+	// Reference identifies the destination, it must be supplied when the transaction is submitted.
+	Reference string `json:"reference"`
+}
+
+// TransactionMetadata is optional information sent with a P2P transaction.
+type TransactionMetadata struct {
+	Sender    string `json:"sender,omitempty"`
+	PubKey    string `json:"pubkey,omitempty"`
+	Signature string `json:"signature,omitempty"`
+	Note      string `json:"note,omitempty"`
+}
+
+// TransactionRequest is sent to the P2P transactions endpoint.
+type TransactionRequest struct {
+	Hex       string              `json:"hex"`
+	Metadata  TransactionMetadata `json:"metadata"`
+	Reference string              `json:"reference"`
+}
+
+// TransactionResponse is returned by the P2P transactions endpoint.
+type TransactionResponse struct {
+	TxID string `json:"txid"`
+	Note string `json:"note"`
+}
@@ -0,0 +1,244 @@
+package paymail
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/libsv/go-dpp"
+	"github.com/pkg/errors"
+	validator "github.com/theflyingcodr/govalidator"
+	"github.com/theflyingcodr/lathos/errs"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/config"
+	"github.com/bitcoin-sv/dpp-proxy/data"
+	"github.com/bitcoin-sv/dpp-proxy/data/paymail/models"
+)
+
+// Paymail endpoints and the BRFC ids of the capabilities used.
+const (
+	urlWellKnown = "https://%s/.well-known/bsvalias"
+
+	// srvService and srvProto name the SRV record paymail hosts can delegate their
+	// capabilities to another host with.
+	srvService = "bsvalias"
+	srvProto   = "tcp"
+
+	brfcP2PPaymentDestination = "2a40af698840"
+	brfcP2PTransactions       = "5f1323cddf31"
+
+	// extendedDataReference is the key the P2P destination reference is stored under in the
+	// payment request merchant data, it is returned by the wallet in the payment.
+	extendedDataReference = "paymailReference"
+)
+
+// Resolver looks up the SRV records of paymail hosts, *net.Resolver implements it.
+type Resolver interface {
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+}
+
+type paymail struct {
+	client    data.HTTPClient
+	resolver  Resolver
+	store     server.PaymailStore
+	cfg       *config.Paymail
+	srvCfg    *config.Server
+	deployCfg *config.Deployment
+	alias     string
+	domain    string
+
+	mu   sync.Mutex
+	caps *models.Capabilities
+}
+
+// NewPaymail will setup a new store that takes payments to a merchant paymail, using the
+// P2P payment destination and P2P transactions capabilities of the paymail host. Invoices
+// are kept in store, the paymail host is found with resolver.
+func NewPaymail(cfg *config.Paymail, srvCfg *config.Server, deployCfg *config.Deployment, client data.HTTPClient,
+	resolver Resolver, store server.PaymailStore) *paymail {
+	alias, domain := cfg.Handle, ""
+	if i := strings.LastIndex(cfg.Handle, "@"); i >= 0 {
+		alias, domain = cfg.Handle[:i], cfg.Handle[i+1:]
+	}
+	return &paymail{
+		client:    client,
+		resolver:  resolver,
+		store:     store,
+		cfg:       cfg,
+		srvCfg:    srvCfg,
+		deployCfg: deployCfg,
+		alias:     alias,
+		domain:    domain,
+	}
+}
+
+// PaymailInvoiceCreate will record an invoice for the amount requested, paid to the merchant paymail.
+func (p *paymail) PaymailInvoiceCreate(ctx context.Context, req server.PaymailInvoiceCreate) (*server.PaymailInvoice, error) {
+	now := time.Now().UTC()
+	expires := now.Add(p.cfg.Expiry)
+	if req.ExpiresAt != nil {
+		expires = req.ExpiresAt.UTC()
+	}
+	inv := server.PaymailInvoice{
+		PaymentID: uuid.NewString(),
+		Satoshis:  req.Satoshis,
+		Memo:      req.Memo,
+		CreatedAt: now,
+		ExpiresAt: expires,
+	}
+	if err := p.store.PaymailInvoiceCreate(ctx, inv); err != nil {
+		return nil, errors.WithMessage(err, "failed to record invoice")
+	}
+	return &inv, nil
+}
+
+// PaymailInvoice will return the invoice with the paymentID.
+func (p *paymail) PaymailInvoice(ctx context.Context, args server.PaymailInvoiceArgs) (*server.PaymailInvoice, error) {
+	return p.store.PaymailInvoice(ctx, args)
+}
+
+// PaymentRequest will request a payment destination for the amount of the invoice from the
+// merchant paymail and map it to a payment request.
+func (p *paymail) PaymentRequest(ctx context.Context, args dpp.PaymentRequestArgs) (*dpp.PaymentRequest, error) {
+	inv, err := p.store.PaymailInvoice(ctx, server.PaymailInvoiceArgs{PaymentID: args.PaymentID})
+	if err != nil {
+		return nil, err
+	}
+	if !inv.ExpiresAt.After(time.Now()) {
+		return nil, errs.NewErrUnprocessable("422", "the invoice has expired")
+	}
+	endpoint, err := p.endpoint(ctx, brfcP2PPaymentDestination)
+	if err != nil {
+		return nil, err
+	}
+	var resp models.DestinationResponse
+	if err := p.client.Do(ctx, http.MethodPost, endpoint, http.StatusOK, models.DestinationRequest{
+		Satoshis: inv.Satoshis,
+	}, &resp); err != nil {
+		return nil, errors.Wrapf(err, "failed to get payment destination for paymail %s", p.cfg.Handle)
+	}
+	outputs := make([]dpp.Output, 0, len(resp.Outputs))
+	for _, o := range resp.Outputs {
+		ls, err := bscript.NewFromHexString(o.Script)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid script returned by paymail %s", p.cfg.Handle)
+		}
+		outputs = append(outputs, dpp.Output{
+			Amount:        o.Satoshis,
+			LockingScript: ls,
+		})
+	}
+	memo := inv.Memo
+	if memo == "" {
+		memo = fmt.Sprintf("payment to %s", p.cfg.Handle)
+	}
+	return &dpp.PaymentRequest{
+		Network:             p.deployCfg.Network,
+		Destinations:        dpp.PaymentDestinations{Outputs: outputs},
+		CreationTimestamp:   time.Now().UTC(),
+		ExpirationTimestamp: inv.ExpiresAt,
+		PaymentURL:          server.PaymentURL(p.srvCfg.FQDN, args.PaymentID),
+		Memo:                memo,
+		MerchantData: &dpp.Merchant{
+			Name: p.cfg.Handle,
+			ExtendedData: map[string]interface{}{
+				"paymentReference":    args.PaymentID,
+				extendedDataReference: resp.Reference,
+			},
+		},
+		FeeRate: bt.NewFeeQuote(),
+	}, nil
+}
+
+// PaymentCreate will submit the payment transaction to the merchant paymail.
+func (p *paymail) PaymentCreate(ctx context.Context, args dpp.PaymentCreateArgs, req dpp.Payment) (*dpp.PaymentACK, error) {
+	ref, _ := req.MerchantData.ExtendedData[extendedDataReference].(string)
+	if err := validator.New().
+		Validate("merchantData.extendedData."+extendedDataReference, validator.NotEmpty(ref)).
+		Validate("rawTx", func() error {
+			if req.RawTx == nil {
+				return errors.New("a rawTx is required for paymail payments")
+			}
+			return nil
+		}).Err(); err != nil {
+		return nil, err
+	}
+	endpoint, err := p.endpoint(ctx, brfcP2PTransactions)
+	if err != nil {
+		return nil, err
+	}
+	var resp models.TransactionResponse
+	if err := p.client.Do(ctx, http.MethodPost, endpoint, http.StatusOK, models.TransactionRequest{
+		Hex:       *req.RawTx,
+		Reference: ref,
+		Metadata: models.TransactionMetadata{
+			Note: req.Memo,
+		},
+	}, &resp); err != nil {
+		return nil, errors.Wrapf(err, "failed to send transaction to paymail %s", p.cfg.Handle)
+	}
+	return &dpp.PaymentACK{
+		ID:   args.PaymentID,
+		TxID: resp.TxID,
+		Memo: resp.Note,
+	}, nil
+}
+
+// endpoint returns the url of a capability for the merchant paymail.
+func (p *paymail) endpoint(ctx context.Context, brfc string) (string, error) {
+	caps, err := p.capabilities(ctx)
+	if err != nil {
+		return "", err
+	}
+	tmpl, ok := caps.Capabilities[brfc].(string)
+	if !ok || tmpl == "" {
+		return "", errors.Errorf("paymail host %s does not support capability %s", p.domain, brfc)
+	}
+	return strings.NewReplacer("{alias}", p.alias, "{domain.tld}", p.domain).Replace(tmpl), nil
+}
+
+// capabilities fetches the capabilities of the merchant paymail host, these are cached
+// once fetched successfully.
+func (p *paymail) capabilities(ctx context.Context) (*models.Capabilities, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.caps != nil {
+		return p.caps, nil
+	}
+	host, err := p.host(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var caps models.Capabilities
+	if err := p.client.Do(ctx, http.MethodGet, fmt.Sprintf(urlWellKnown, host), http.StatusOK, nil, &caps); err != nil {
+		return nil, errors.Wrapf(err, "failed to get capabilities for paymail host %s", p.domain)
+	}
+	p.caps = &caps
+	return p.caps, nil
+}
+
+// host returns the host serving the capabilities of the paymail domain, taken from its
+// _bsvalias._tcp SRV record. The domain itself is used if it has no SRV record.
+func (p *paymail) host(ctx context.Context) (string, error) {
+	_, srvs, err := p.resolver.LookupSRV(ctx, srvService, srvProto, p.domain)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return p.domain, nil
+		}
+		return "", errors.Wrapf(err, "failed to lookup SRV record for paymail host %s", p.domain)
+	}
+	if len(srvs) == 0 {
+		return p.domain, nil
+	}
+	// records are sorted by priority and weight, so the first is used.
+	return net.JoinHostPort(strings.TrimSuffix(srvs[0].Target, "."), fmt.Sprint(srvs[0].Port)), nil
+}
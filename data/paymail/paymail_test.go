@@ -0,0 +1,249 @@
+package paymail_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/libsv/go-dpp"
+	"github.com/stretchr/testify/assert"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/config"
+	"github.com/bitcoin-sv/dpp-proxy/data"
+	"github.com/bitcoin-sv/dpp-proxy/data/memory"
+	"github.com/bitcoin-sv/dpp-proxy/data/paymail"
+	"github.com/bitcoin-sv/dpp-proxy/data/paymail/models"
+)
+
+const script = "76a91455b61be43392125d127f1780fb038437cd67ef9c88ac"
+
+// paymailHost is a fake paymail host supporting the P2P capabilities, requests are recorded.
+func paymailHost(t *testing.T, caps map[string]interface{}) (*httptest.Server, *[]*http.Request, *[][]byte) {
+	var reqs []*http.Request
+	var bodies [][]byte
+	var srv *httptest.Server
+	srv = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body json.RawMessage
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		reqs = append(reqs, r)
+		bodies = append(bodies, body)
+		switch {
+		case r.URL.Path == "/.well-known/bsvalias":
+			c := map[string]interface{}{}
+			for k, v := range caps {
+				c[k] = strings.ReplaceAll(v.(string), "{host}", srv.Listener.Addr().String())
+			}
+			assert.NoError(t, json.NewEncoder(w).Encode(models.Capabilities{BsvAlias: "1.0", Capabilities: c}))
+		case strings.HasSuffix(r.URL.Path, "/payment-destination"):
+			assert.NoError(t, json.NewEncoder(w).Encode(models.DestinationResponse{
+				Outputs:   []models.DestinationOutput{{Script: script, Satoshis: 1000}},
+				Reference: "ref123",
+			}))
+		case strings.HasSuffix(r.URL.Path, "/receive-transaction"):
+			assert.NoError(t, json.NewEncoder(w).Encode(models.TransactionResponse{
+				TxID: "txid123",
+				Note: "thanks",
+			}))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return srv, &reqs, &bodies
+}
+
+func p2pCaps() map[string]interface{} {
+	return map[string]interface{}{
+		"2a40af698840": "https://{host}/api/v1/bsvalias/p2p-payment-destination/{alias}@{domain.tld}/payment-destination",
+		"5f1323cddf31": "https://{host}/api/v1/bsvalias/receive-transaction/{alias}@{domain.tld}/receive-transaction",
+	}
+}
+
+// resolver is a fake DNS resolver, domains without an SRV record in srvs aren't found.
+type resolver struct {
+	srvs map[string]*net.SRV
+}
+
+func (r resolver) LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+	srv, ok := r.srvs[name]
+	if !ok {
+		return "", nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return "_" + service + "._" + proto + "." + name, []*net.SRV{srv}, nil
+}
+
+// invoiceStore returns a paymail invoice store holding an invoice for 2500 satoshis with paymentID abc123.
+func invoiceStore(t *testing.T, expires time.Time) server.PaymailStore {
+	store := memory.NewPaymailInvoices()
+	assert.NoError(t, store.PaymailInvoiceCreate(context.TODO(), server.PaymailInvoice{
+		PaymentID: "abc123",
+		Satoshis:  2500,
+		Memo:      "invoice 123",
+		ExpiresAt: expires,
+	}))
+	return store
+}
+
+func TestPaymail_PaymentRequest(t *testing.T) {
+	expires := time.Now().Add(time.Hour).UTC()
+	tests := map[string]struct {
+		caps      map[string]interface{}
+		paymentID string
+		expires   time.Time
+		srv       bool
+		expErr    error
+	}{
+		"successful payment request": {
+			caps:      p2pCaps(),
+			paymentID: "abc123",
+			expires:   expires,
+		},
+		"capabilities are read from the host delegated to by SRV record": {
+			caps:      p2pCaps(),
+			paymentID: "abc123",
+			expires:   expires,
+			srv:       true,
+		},
+		"missing capability errors": {
+			caps:      map[string]interface{}{"5f1323cddf31": "https://{host}/receive-transaction"},
+			paymentID: "abc123",
+			expires:   expires,
+			expErr:    errors.New("paymail host {domain} does not support capability 2a40af698840"),
+		},
+		"unknown invoice errors": {
+			caps:      p2pCaps(),
+			paymentID: "def456",
+			expires:   expires,
+			expErr:    errors.New("Not found: invoice not found"),
+		},
+		"expired invoice errors": {
+			caps:      p2pCaps(),
+			paymentID: "abc123",
+			expires:   time.Now().Add(-time.Minute),
+			expErr:    errors.New("Unprocessable: the invoice has expired"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv, reqs, bodies := paymailHost(t, test.caps)
+			defer srv.Close()
+			host := srv.Listener.Addr().String()
+			domain, srvs := host, map[string]*net.SRV{}
+			if test.srv {
+				h, port, err := net.SplitHostPort(host)
+				assert.NoError(t, err)
+				p, err := strconv.Atoi(port)
+				assert.NoError(t, err)
+				domain = "example.com"
+				srvs[domain] = &net.SRV{Target: h + ".", Port: uint16(p)}
+			}
+			store := paymail.NewPaymail(
+				&config.Paymail{Handle: "merchant@" + domain, Expiry: time.Hour},
+				&config.Server{FQDN: "dpp.example.com"},
+				&config.Deployment{Network: config.NetworkRegtest},
+				data.NewClient(srv.Client(), time.Second), resolver{srvs: srvs}, invoiceStore(t, test.expires))
+
+			pr, err := store.PaymentRequest(context.TODO(), dpp.PaymentRequestArgs{PaymentID: test.paymentID})
+			if test.expErr != nil {
+				assert.EqualError(t, err, strings.ReplaceAll(test.expErr.Error(), "{domain}", domain))
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "/api/v1/bsvalias/p2p-payment-destination/merchant@"+domain+"/payment-destination", (*reqs)[1].URL.Path)
+			assert.JSONEq(t, `{"satoshis":2500}`, string((*bodies)[1]))
+
+			assert.Equal(t, config.NetworkRegtest, pr.Network)
+			assert.Equal(t, "http://dpp.example.com/api/v1/payment/abc123", pr.PaymentURL)
+			assert.Equal(t, "invoice 123", pr.Memo)
+			assert.Len(t, pr.Destinations.Outputs, 1)
+			assert.Equal(t, uint64(1000), pr.Destinations.Outputs[0].Amount)
+			assert.Equal(t, script, pr.Destinations.Outputs[0].LockingScript.String())
+			assert.Equal(t, map[string]interface{}{
+				"paymentReference": "abc123",
+				"paymailReference": "ref123",
+			}, pr.MerchantData.ExtendedData)
+			assert.NotNil(t, pr.FeeRate)
+			assert.Equal(t, test.expires, pr.ExpirationTimestamp)
+		})
+	}
+}
+
+func TestPaymail_PaymailInvoiceCreate(t *testing.T) {
+	store := paymail.NewPaymail(
+		&config.Paymail{Handle: "merchant@example.com", Expiry: time.Hour},
+		&config.Server{FQDN: "dpp.example.com"},
+		&config.Deployment{Network: config.NetworkRegtest},
+		data.NewClient(http.DefaultClient, time.Second), resolver{}, memory.NewPaymailInvoices())
+
+	inv, err := store.PaymailInvoiceCreate(context.TODO(), server.PaymailInvoiceCreate{Satoshis: 2500, Memo: "invoice 123"})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, inv.PaymentID)
+	assert.Equal(t, uint64(2500), inv.Satoshis)
+	assert.Equal(t, time.Hour, inv.ExpiresAt.Sub(inv.CreatedAt))
+
+	got, err := store.PaymailInvoice(context.TODO(), server.PaymailInvoiceArgs{PaymentID: inv.PaymentID})
+	assert.NoError(t, err)
+	assert.Equal(t, inv, got)
+}
+
+func TestPaymail_PaymentCreate(t *testing.T) {
+	rawTx := "01000000000000000000"
+	tests := map[string]struct {
+		req    dpp.Payment
+		expACK *dpp.PaymentACK
+		expErr error
+	}{
+		"successful payment": {
+			req: dpp.Payment{
+				RawTx: &rawTx,
+				Memo:  "for coffee",
+				MerchantData: dpp.Merchant{
+					ExtendedData: map[string]interface{}{"paymentReference": "abc123", "paymailReference": "ref123"},
+				},
+			},
+			expACK: &dpp.PaymentACK{
+				ID:   "abc123",
+				TxID: "txid123",
+				Memo: "thanks",
+			},
+		},
+		"missing reference is rejected": {
+			req: dpp.Payment{
+				RawTx: &rawTx,
+				MerchantData: dpp.Merchant{
+					ExtendedData: map[string]interface{}{"paymentReference": "abc123"},
+				},
+			},
+			expErr: errors.New("[merchantData.extendedData.paymailReference: value cannot be empty]"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv, reqs, bodies := paymailHost(t, p2pCaps())
+			defer srv.Close()
+			host := srv.Listener.Addr().String()
+			store := paymail.NewPaymail(
+				&config.Paymail{Handle: "merchant@" + host, Expiry: time.Hour},
+				&config.Server{FQDN: "dpp.example.com"},
+				&config.Deployment{Network: config.NetworkRegtest},
+				data.NewClient(srv.Client(), time.Second), resolver{}, memory.NewPaymailInvoices())
+
+			ack, err := store.PaymentCreate(context.TODO(), dpp.PaymentCreateArgs{PaymentID: "abc123"}, test.req)
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expACK, ack)
+			assert.Equal(t, "/api/v1/bsvalias/receive-transaction/merchant@"+host+"/receive-transaction", (*reqs)[1].URL.Path)
+			assert.JSONEq(t, `{"hex":"01000000000000000000","reference":"ref123","metadata":{"note":"for coffee"}}`, string((*bodies)[1]))
+		})
+	}
+}
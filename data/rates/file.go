@@ -0,0 +1,52 @@
+package rates
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/theflyingcodr/lathos/errs"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/config"
+)
+
+type file struct {
+	rates map[string]float64
+}
+
+// NewFile will setup and return a rate source serving fixed rates read from a json file
+// of currency codes to the price of one BSV, for example {"USD": 50.25, "EUR": 46.1}.
+// Useful for testing as rates never change.
+func NewFile(path string) (*file, error) {
+	bb, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read rates file '%s'", path)
+	}
+	var rates map[string]float64
+	if err := json.Unmarshal(bb, &rates); err != nil {
+		return nil, errors.Wrapf(err, "failed to read rates file '%s'", path)
+	}
+	for currency, rate := range rates {
+		if rate <= 0 {
+			return nil, errors.Errorf("invalid %s rate in rates file '%s'", currency, path)
+		}
+	}
+	return &file{rates: rates}, nil
+}
+
+// ExchangeRate will return the rate of a currency from the file.
+func (f *file) ExchangeRate(ctx context.Context, args server.ExchangeRateArgs) (*server.ExchangeRate, error) {
+	rate, ok := f.rates[args.Currency]
+	if !ok {
+		return nil, errs.NewErrNotFoundf("404", "no exchange rate for currency %s", args.Currency)
+	}
+	return &server.ExchangeRate{
+		Currency: args.Currency,
+		Rate:     rate,
+		Source:   config.RatesSourceFile,
+		ReadAt:   time.Now().UTC(),
+	}, nil
+}
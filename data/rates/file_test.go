@@ -0,0 +1,28 @@
+package rates_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/data/rates"
+)
+
+func TestFile_ExchangeRate(t *testing.T) {
+	f, err := rates.NewFile("rates.json")
+	assert.NoError(t, err)
+	rate, err := f.ExchangeRate(context.Background(), server.ExchangeRateArgs{Currency: "USD"})
+	assert.NoError(t, err)
+	assert.Equal(t, 50.0, rate.Rate)
+	_, err = f.ExchangeRate(context.Background(), server.ExchangeRateArgs{Currency: "JPY"})
+	assert.EqualError(t, err, "Not found: no exchange rate for currency JPY")
+
+	path := filepath.Join(t.TempDir(), "rates.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"USD": -1}`), 0600))
+	_, err = rates.NewFile(path)
+	assert.EqualError(t, err, "invalid USD rate in rates file '"+path+"'")
+}
@@ -0,0 +1,52 @@
+package rates
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/config"
+	"github.com/bitcoin-sv/dpp-proxy/data"
+	"github.com/bitcoin-sv/dpp-proxy/data/rates/models"
+)
+
+type ratesClient struct {
+	client data.HTTPClient
+	cfg    *config.Rates
+}
+
+// NewHTTP will setup and return a rate source reading rates from a price feed, {currency} in
+// the url is replaced with the currency code. The feed should return the price of one BSV
+// as json, for example {"currency": "USD", "rate": 50.25}.
+func NewHTTP(cfg *config.Rates, client data.HTTPClient) *ratesClient {
+	return &ratesClient{
+		client: client,
+		cfg:    cfg,
+	}
+}
+
+// ExchangeRate will return the current rate of a currency from the price feed.
+func (r *ratesClient) ExchangeRate(ctx context.Context, args server.ExchangeRateArgs) (*server.ExchangeRate, error) {
+	var resp models.Rate
+	if err := r.client.Do(ctx, http.MethodGet, strings.ReplaceAll(r.cfg.URL, "{currency}", args.Currency),
+		http.StatusOK, nil, &resp); err != nil {
+		return nil, errors.Wrapf(err, "failed to get %s exchange rate", args.Currency)
+	}
+	if resp.Currency != "" && !strings.EqualFold(resp.Currency, args.Currency) {
+		return nil, errors.Errorf("price feed returned a %s rate for %s", resp.Currency, args.Currency)
+	}
+	rate, err := resp.Rate.Float64()
+	if err != nil || rate <= 0 {
+		return nil, errors.Errorf("price feed returned an invalid %s rate '%s'", args.Currency, resp.Rate)
+	}
+	return &server.ExchangeRate{
+		Currency: args.Currency,
+		Rate:     rate,
+		Source:   config.RatesSourceHTTP,
+		ReadAt:   time.Now().UTC(),
+	}, nil
+}
@@ -0,0 +1,69 @@
+package rates_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/config"
+	"github.com/bitcoin-sv/dpp-proxy/data/rates"
+	"github.com/bitcoin-sv/dpp-proxy/data/rates/models"
+	"github.com/bitcoin-sv/dpp-proxy/mocks"
+)
+
+func TestHTTP_ExchangeRate(t *testing.T) {
+	tests := map[string]struct {
+		resp    models.Rate
+		doErr   error
+		expRate float64
+		expErr  error
+	}{
+		"numeric rate is returned": {
+			resp:    models.Rate{Currency: "USD", Rate: "50.25"},
+			expRate: 50.25,
+		},
+		"rate without a currency is returned": {
+			resp:    models.Rate{Rate: "50"},
+			expRate: 50,
+		},
+		"rate for another currency is rejected": {
+			resp:   models.Rate{Currency: "EUR", Rate: "46"},
+			expErr: errors.New("price feed returned a EUR rate for USD"),
+		},
+		"zero rate is rejected": {
+			resp:   models.Rate{Currency: "USD", Rate: "0"},
+			expErr: errors.New("price feed returned an invalid USD rate '0'"),
+		},
+		"feed error is returned": {
+			doErr:  errors.New("connection refused"),
+			expErr: errors.New("failed to get USD exchange rate: connection refused"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := &mocks.HTTPClientMock{
+				DoFunc: func(ctx context.Context, method, endpoint string, expStatus int, req, out interface{}) error {
+					assert.Equal(t, "https://rates.example.com/api/v1/rate/USD", endpoint)
+					if test.doErr != nil {
+						return test.doErr
+					}
+					*out.(*models.Rate) = test.resp
+					return nil
+				},
+			}
+			rate, err := rates.NewHTTP(&config.Rates{URL: "https://rates.example.com/api/v1/rate/{currency}"}, client).
+				ExchangeRate(context.Background(), server.ExchangeRateArgs{Currency: "USD"})
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "USD", rate.Currency)
+			assert.Equal(t, test.expRate, rate.Rate)
+			assert.Equal(t, config.RatesSourceHTTP, rate.Source)
+		})
+	}
+}
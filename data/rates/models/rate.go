@@ -0,0 +1,10 @@
+package models
+
+import "encoding/json"
+
+// Rate is returned by a price feed, the rate is the price of one BSV and can be
+// a json number or a string.
+type Rate struct {
+	Currency string      `json:"currency"`
+	Rate     json.Number `json:"rate"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-dpp"
+	"github.com/pkg/errors"
+)
+
+// Fixtures are the invoices served by the sandbox, read from a json fixture file.
+type Fixtures struct {
+	// Invoices are keyed by paymentID.
+	Invoices map[string]Invoice `json:"invoices"`
+	// Default is served for paymentIDs without an invoice, if not set they are not found.
+	Default *Invoice `json:"default"`
+}
+
+// Invoice is a payment request served by the sandbox.
+type Invoice struct {
+	Memo             string   `json:"memo"`
+	Outputs          []Output `json:"outputs"`
+	AncestryRequired bool     `json:"ancestryRequired"`
+	// Fees are the fee quote returned with the payment request, the default
+	// fees are used if not set.
+	Fees map[bt.FeeType]*bt.Fee `json:"fees"`
+	// FeesExpireIn is how long the fee quote is valid for.
+	FeesExpireIn Duration `json:"feesExpireIn"`
+	// ExpiresIn is how long the payment request is valid for once fetched.
+	ExpiresIn    Duration      `json:"expiresIn"`
+	MerchantData *dpp.Merchant `json:"merchantData"`
+}
+
+// Output is an output the invoice must be paid to.
+type Output struct {
+	Amount uint64 `json:"amount"`
+	// LockingScript is the hex encoded output script.
+	LockingScript string `json:"lockingScript"`
+	Description   string `json:"description"`
+}
+
+// Duration is a time.Duration read from a duration string such as "1h30m".
+type Duration time.Duration
+
+// UnmarshalJSON reads a duration string.
+func (d *Duration) UnmarshalJSON(bb []byte) error {
+	var s string
+	if err := json.Unmarshal(bb, &s); err != nil {
+		return errors.Wrap(err, "duration must be a string such as '1h'")
+	}
+	dur, err := time.ParseDuration(s)
+	if err != nil {
+		return errors.Wrapf(err, "invalid duration '%s'", s)
+	}
+	*d = Duration(dur)
+	return nil
+}
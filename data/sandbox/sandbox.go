@@ -0,0 +1,250 @@
+package sandbox
+
+import (
+	"context"
+	// embed is used to load the default fixtures.
+	_ "embed"
+	"encoding/json"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/libsv/go-bk/envelope"
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/libsv/go-dpp"
+	"github.com/pkg/errors"
+	"github.com/theflyingcodr/lathos/errs"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/config"
+	"github.com/bitcoin-sv/dpp-proxy/data/sandbox/models"
+	"github.com/bitcoin-sv/dpp-proxy/log"
+)
+
+// Magic paymentIDs, paymentIDs starting with these fail in a known way so integrators
+// can test how their wallets handle errors. A suffix can be added to make them unique,
+// for example sandbox-expired-1.
+const (
+	// PaymentIDNotFound payment requests and payments are not found.
+	PaymentIDNotFound = "sandbox-notfound"
+	// PaymentIDExpired payment requests have expired and payments are rejected.
+	PaymentIDExpired = "sandbox-expired"
+	// PaymentIDRejected payments are rejected.
+	PaymentIDRejected = "sandbox-rejected"
+	// PaymentIDSlow payment requests and payments are delayed by the configured slow delay.
+	PaymentIDSlow = "sandbox-slow"
+	// PaymentIDError payment requests and payments fail with an internal error.
+	PaymentIDError = "sandbox-error"
+)
+
+//go:embed fixtures.json
+var defaultFixtures []byte
+
+type sandbox struct {
+	l         log.Logger
+	fixtures  models.Fixtures
+	cfg       *config.Sandbox
+	srvCfg    *config.Server
+	deployCfg *config.Deployment
+}
+
+// NewSandbox will setup and return a sandbox data store serving the invoices in the fixture
+// file in cfg, or a built in set of fixtures if no file is set. Useful for exploring the
+// endpoints and testing wallets without integrating with a merchant wallet.
+func NewSandbox(l log.Logger, cfg *config.Sandbox, srvCfg *config.Server, deployCfg *config.Deployment) (*sandbox, error) {
+	bb := defaultFixtures
+	if cfg.FixturePath != "" {
+		var err error
+		if bb, err = os.ReadFile(cfg.FixturePath); err != nil {
+			return nil, errors.Wrapf(err, "failed to read sandbox fixtures '%s'", cfg.FixturePath)
+		}
+	}
+	var fixtures models.Fixtures
+	if err := json.Unmarshal(bb, &fixtures); err != nil {
+		return nil, errors.Wrap(err, "failed to read sandbox fixtures")
+	}
+	for id, inv := range fixtures.Invoices {
+		if err := validateInvoice(inv); err != nil {
+			return nil, errors.Wrapf(err, "invalid sandbox invoice '%s'", id)
+		}
+	}
+	if fixtures.Default != nil {
+		if err := validateInvoice(*fixtures.Default); err != nil {
+			return nil, errors.Wrap(err, "invalid default sandbox invoice")
+		}
+	}
+	l.Infof("using sandbox data store with %d invoices", len(fixtures.Invoices))
+	return &sandbox{
+		l:         l,
+		fixtures:  fixtures,
+		cfg:       cfg,
+		srvCfg:    srvCfg,
+		deployCfg: deployCfg,
+	}, nil
+}
+
+// PaymentRequest will return a payment request for the invoice with the paymentID.
+func (s *sandbox) PaymentRequest(ctx context.Context, args dpp.PaymentRequestArgs) (*dpp.PaymentRequest, error) {
+	inv, err := s.invoice(ctx, args.PaymentID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	expires := now.Add(time.Duration(inv.ExpiresIn))
+	if strings.HasPrefix(args.PaymentID, PaymentIDExpired) {
+		now, expires = now.Add(-2*time.Hour), now.Add(-time.Hour)
+	}
+	outputs := make([]dpp.Output, 0, len(inv.Outputs))
+	for _, o := range inv.Outputs {
+		ls, err := bscript.NewFromHexString(o.LockingScript)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid sandbox locking script")
+		}
+		outputs = append(outputs, dpp.Output{
+			Amount:        o.Amount,
+			LockingScript: ls,
+			Description:   o.Description,
+		})
+	}
+	fees := bt.NewFeeQuote()
+	for ft, fee := range inv.Fees {
+		f := *fee
+		f.FeeType = ft
+		fees.AddQuote(ft, &f)
+	}
+	feesExpire := inv.FeesExpireIn
+	if feesExpire == 0 {
+		feesExpire = inv.ExpiresIn
+	}
+	fees.UpdateExpiry(now.Add(time.Duration(feesExpire)))
+	return &dpp.PaymentRequest{
+		Network:             s.deployCfg.Network,
+		AncestryRequired:    inv.AncestryRequired,
+		Destinations:        dpp.PaymentDestinations{Outputs: outputs},
+		CreationTimestamp:   now,
+		ExpirationTimestamp: expires,
+		FeeRate:             fees,
+		PaymentURL:          server.PaymentURL(s.srvCfg.FQDN, args.PaymentID),
+		Memo:                inv.Memo,
+		MerchantData:        merchantData(args.PaymentID, inv.MerchantData),
+	}, nil
+}
+
+// PaymentCreate will accept a payment that pays all outputs of the invoice, returning
+// an ack with the txid.
+func (s *sandbox) PaymentCreate(ctx context.Context, args dpp.PaymentCreateArgs, req dpp.Payment) (*dpp.PaymentACK, error) {
+	inv, err := s.invoice(ctx, args.PaymentID)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case strings.HasPrefix(args.PaymentID, PaymentIDExpired):
+		return nil, errs.NewErrUnprocessable("422", "payment request has expired")
+	case strings.HasPrefix(args.PaymentID, PaymentIDRejected):
+		return nil, errs.NewErrUnprocessable("422", "payment rejected by the sandbox")
+	}
+	ack := &dpp.PaymentACK{ID: args.PaymentID, Memo: "payment accepted by the sandbox"}
+	// payments with only ancestry aren't checked.
+	if req.RawTx == nil {
+		return ack, nil
+	}
+	tx, err := bt.NewTxFromString(*req.RawTx)
+	if err != nil {
+		return nil, errs.NewErrUnprocessable("422", "payment transaction cannot be read")
+	}
+	if err := paysInvoice(tx, inv); err != nil {
+		return nil, err
+	}
+	s.l.Infof("sandbox payment %s accepted with txid %s", args.PaymentID, tx.TxID())
+	ack.TxID = tx.TxID()
+	return ack, nil
+}
+
+// ProofCreate will accept and discard a merkle proof.
+func (s *sandbox) ProofCreate(ctx context.Context, args dpp.ProofCreateArgs, req envelope.JSONEnvelope) error {
+	s.l.Infof("sandbox proof received for txid %s", args.TxID)
+	return nil
+}
+
+// DoubleSpendCreate will accept and discard a double spend notification.
+func (s *sandbox) DoubleSpendCreate(ctx context.Context, args dpp.ProofCreateArgs, req server.DoubleSpend) error {
+	s.l.Infof("sandbox double spend received for txid %s", args.TxID)
+	return nil
+}
+
+// invoice returns the invoice for a paymentID, applying the failure of magic paymentIDs.
+func (s *sandbox) invoice(ctx context.Context, paymentID string) (*models.Invoice, error) {
+	switch {
+	case strings.HasPrefix(paymentID, PaymentIDNotFound):
+		return nil, errs.NewErrNotFound("404", "invoice not found")
+	case strings.HasPrefix(paymentID, PaymentIDError):
+		return nil, errors.New("sandbox internal error")
+	case strings.HasPrefix(paymentID, PaymentIDSlow):
+		select {
+		case <-time.After(s.cfg.SlowDelay):
+		case <-ctx.Done():
+			return nil, errors.Wrap(ctx.Err(), "sandbox response cancelled")
+		}
+	}
+	if inv, ok := s.fixtures.Invoices[paymentID]; ok {
+		return &inv, nil
+	}
+	if s.fixtures.Default == nil {
+		return nil, errs.NewErrNotFound("404", "invoice not found")
+	}
+	return s.fixtures.Default, nil
+}
+
+// paysInvoice checks the transaction pays each output of the invoice.
+func paysInvoice(tx *bt.Tx, inv *models.Invoice) error {
+	paid := make([]bool, len(tx.Outputs))
+	for i, o := range inv.Outputs {
+		found := false
+		for j, txo := range tx.Outputs {
+			if !paid[j] && txo.Satoshis == o.Amount && txo.LockingScript.String() == o.LockingScript {
+				paid[j], found = true, true
+				break
+			}
+		}
+		if !found {
+			return errs.NewErrUnprocessablef("422", "transaction does not pay output %d of %d satoshis to %s", i, o.Amount, o.LockingScript)
+		}
+	}
+	return nil
+}
+
+// merchantData returns a copy of the fixture merchant data, with the paymentID as the
+// payment reference if one isn't set.
+func merchantData(paymentID string, m *dpp.Merchant) *dpp.Merchant {
+	resp := &dpp.Merchant{ExtendedData: map[string]interface{}{}}
+	if m != nil {
+		*resp = *m
+		resp.ExtendedData = make(map[string]interface{}, len(m.ExtendedData)+1)
+		for k, v := range m.ExtendedData {
+			resp.ExtendedData[k] = v
+		}
+	}
+	if _, ok := resp.ExtendedData["paymentReference"]; !ok {
+		resp.ExtendedData["paymentReference"] = paymentID
+	}
+	return resp
+}
+
+func validateInvoice(inv models.Invoice) error {
+	if len(inv.Outputs) == 0 {
+		return errors.New("an invoice requires at least one output")
+	}
+	for i, o := range inv.Outputs {
+		if o.Amount == 0 {
+			return errors.Errorf("output %d has no amount", i)
+		}
+		if _, err := bscript.NewFromHexString(o.LockingScript); err != nil || o.LockingScript == "" {
+			return errors.Errorf("output %d has an invalid locking script '%s'", i, o.LockingScript)
+		}
+	}
+	if inv.ExpiresIn <= 0 {
+		return errors.New("expiresIn is required")
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package sandbox_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/libsv/go-dpp"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bitcoin-sv/dpp-proxy/config"
+	"github.com/bitcoin-sv/dpp-proxy/data/sandbox"
+	"github.com/bitcoin-sv/dpp-proxy/log"
+)
+
+const script = "76a91455b61be43392125d127f1780fb038437cd67ef9c88ac"
+
+func newSandbox(t *testing.T, fixtures string) (dpp.PaymentRequestReader, dpp.PaymentWriter) {
+	cfg := &config.Sandbox{SlowDelay: 10 * time.Millisecond}
+	if fixtures != "" {
+		cfg.FixturePath = filepath.Join(t.TempDir(), "fixtures.json")
+		assert.NoError(t, os.WriteFile(cfg.FixturePath, []byte(fixtures), 0600))
+	}
+	s, err := sandbox.NewSandbox(log.Noop{}, cfg, &config.Server{FQDN: "dpp.example.com"}, &config.Deployment{Network: config.NetworkRegtest})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return s, s
+}
+
+// payment returns a payment with a transaction paying satoshis to script.
+func payment(t *testing.T, satoshis uint64) dpp.Payment {
+	tx := bt.NewTx()
+	assert.NoError(t, tx.From("3c8edde27cb9a9132c22038dac4391496be9db16fd21351565cc1006966fdad5", 0, script, satoshis+100))
+	ls, err := bscript.NewFromHexString(script)
+	assert.NoError(t, err)
+	tx.AddOutput(&bt.Output{Satoshis: satoshis, LockingScript: ls})
+	rawTx := tx.String()
+	return dpp.Payment{RawTx: &rawTx}
+}
+
+func TestSandbox_PaymentRequest(t *testing.T) {
+	tests := map[string]struct {
+		paymentID  string
+		expMemo    string
+		expAmounts []uint64
+		expExpired bool
+		expErr     error
+	}{
+		"fixture invoice is returned": {
+			paymentID:  "sandbox-invoice",
+			expMemo:    "sandbox invoice for a coffee",
+			expAmounts: []uint64{1000, 250},
+		},
+		"default invoice is returned for other paymentIDs": {
+			paymentID:  "abc123",
+			expMemo:    "sandbox invoice",
+			expAmounts: []uint64{500},
+		},
+		"slow paymentID is returned after a delay": {
+			paymentID:  sandbox.PaymentIDSlow,
+			expMemo:    "sandbox invoice",
+			expAmounts: []uint64{500},
+		},
+		"expired paymentID has expired": {
+			paymentID:  sandbox.PaymentIDExpired + "-1",
+			expMemo:    "sandbox invoice",
+			expAmounts: []uint64{500},
+			expExpired: true,
+		},
+		"not found paymentID is not found": {
+			paymentID: sandbox.PaymentIDNotFound,
+			expErr:    errors.New("Not found: invoice not found"),
+		},
+		"error paymentID errors": {
+			paymentID: sandbox.PaymentIDError,
+			expErr:    errors.New("sandbox internal error"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			prRdr, _ := newSandbox(t, "")
+			pr, err := prRdr.PaymentRequest(context.Background(), dpp.PaymentRequestArgs{PaymentID: test.paymentID})
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, config.NetworkRegtest, pr.Network)
+			assert.Equal(t, test.expMemo, pr.Memo)
+			assert.Equal(t, "http://dpp.example.com/api/v1/payment/"+test.paymentID, pr.PaymentURL)
+			assert.Equal(t, test.paymentID, pr.MerchantData.ExtendedData["paymentReference"])
+			amounts := make([]uint64, 0, len(pr.Destinations.Outputs))
+			for _, o := range pr.Destinations.Outputs {
+				amounts = append(amounts, o.Amount)
+				assert.NotEmpty(t, o.LockingScript.String())
+			}
+			assert.Equal(t, test.expAmounts, amounts)
+			assert.Equal(t, test.expExpired, pr.ExpirationTimestamp.Before(time.Now()))
+			assert.NotNil(t, pr.FeeRate)
+		})
+	}
+}
+
+func TestSandbox_PaymentCreate(t *testing.T) {
+	tests := map[string]struct {
+		paymentID string
+		req       dpp.Payment
+		expTxID   bool
+		expErr    error
+	}{
+		"payment paying the invoice is accepted": {
+			paymentID: "abc123",
+			req:       payment(t, 500),
+			expTxID:   true,
+		},
+		"payment with only ancestry is accepted": {
+			paymentID: "abc123",
+			req:       dpp.Payment{Ancestry: func() *string { s := "0100"; return &s }()},
+		},
+		"payment not paying the invoice is rejected": {
+			paymentID: "abc123",
+			req:       payment(t, 499),
+			expErr:    errors.New("Unprocessable: transaction does not pay output 0 of 500 satoshis to " + script),
+		},
+		"rejected paymentID is rejected": {
+			paymentID: sandbox.PaymentIDRejected,
+			req:       payment(t, 500),
+			expErr:    errors.New("Unprocessable: payment rejected by the sandbox"),
+		},
+		"expired paymentID is rejected": {
+			paymentID: sandbox.PaymentIDExpired,
+			req:       payment(t, 500),
+			expErr:    errors.New("Unprocessable: payment request has expired"),
+		},
+		"not found paymentID is not found": {
+			paymentID: sandbox.PaymentIDNotFound,
+			req:       payment(t, 500),
+			expErr:    errors.New("Not found: invoice not found"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, paymentWtr := newSandbox(t, "")
+			ack, err := paymentWtr.PaymentCreate(context.Background(), dpp.PaymentCreateArgs{PaymentID: test.paymentID}, test.req)
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, 0, ack.Error)
+			assert.Equal(t, test.expTxID, ack.TxID != "")
+		})
+	}
+}
+
+func TestSandbox_Slow(t *testing.T) {
+	prRdr, _ := newSandbox(t, "")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := prRdr.PaymentRequest(ctx, dpp.PaymentRequestArgs{PaymentID: sandbox.PaymentIDSlow})
+	assert.EqualError(t, err, "sandbox response cancelled: context canceled")
+}
+
+func TestSandbox_Fixtures(t *testing.T) {
+	tests := map[string]struct {
+		fixtures string
+		expErr   error
+	}{
+		"invoice without outputs is rejected": {
+			fixtures: `{"invoices": {"abc": {"expiresIn": "1h"}}}`,
+			expErr:   errors.New("invalid sandbox invoice 'abc': an invoice requires at least one output"),
+		},
+		"invoice with an invalid script is rejected": {
+			fixtures: `{"invoices": {"abc": {"expiresIn": "1h", "outputs": [{"amount": 1, "lockingScript": "zz"}]}}}`,
+			expErr:   errors.New("invalid sandbox invoice 'abc': output 0 has an invalid locking script 'zz'"),
+		},
+		"invalid expiry is rejected": {
+			fixtures: `{"default": {"expiresIn": "soon"}}`,
+			expErr:   errors.New(`failed to read sandbox fixtures: invalid duration 'soon': time: invalid duration "soon"`),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "fixtures.json")
+			assert.NoError(t, os.WriteFile(path, []byte(test.fixtures), 0600))
+			_, err := sandbox.NewSandbox(log.Noop{}, &config.Sandbox{FixturePath: path}, &config.Server{}, &config.Deployment{})
+			assert.EqualError(t, err, test.expErr.Error())
+		})
+	}
+
+	// invoices without a default are not found.
+	prRdr, _ := newSandbox(t, `{"invoices": {"abc": {"expiresIn": "1h", "outputs": [{"amount": 1, "lockingScript": "`+script+`"}]}}}`)
+	_, err := prRdr.PaymentRequest(context.Background(), dpp.PaymentRequestArgs{PaymentID: "def"})
+	assert.EqualError(t, err, "Not found: invoice not found")
+}
@@ -3,6 +3,8 @@ package sockets
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"sync/atomic"
 	"time"
 
 	server "github.com/bitcoin-sv/dpp-proxy"
@@ -11,7 +13,10 @@ import (
 	"github.com/pkg/errors"
 	"github.com/theflyingcodr/lathos/errs"
 	"github.com/theflyingcodr/sockets"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
 
+	"github.com/bitcoin-sv/dpp-proxy/tracing"
 	"github.com/libsv/go-dpp"
 )
 
@@ -21,6 +26,7 @@ const (
 	RoutePaymentACK             = "payment.ack"
 	RoutePaymentError           = "payment.error"
 	RouteProofCreate            = "proof.create"
+	RouteDoubleSpendCreate      = "doublespend.create"
 	RoutePaymentRequestCreate   = "paymentrequest.create"
 	RoutePaymentRequestResponse = "paymentrequest.response"
 	RoutePaymentRequestError    = "paymentrequest.error"
@@ -29,23 +35,52 @@ const (
 )
 
 type payd struct {
-	s sockets.ServerChannelBroadcaster
+	// timeout is accessed atomically so is kept first for 64 bit alignment.
+	timeout int64
+	s       sockets.ServerChannelBroadcaster
 }
 
-// NewPayd will setup and return a new payd socket data store.
-func NewPayd(b sockets.ServerChannelBroadcaster) *payd {
-	return &payd{s: b}
+// NewPayd will setup and return a new payd socket data store, timeout is the
+// max time to wait on a socket client responding to a request.
+func NewPayd(b sockets.ServerChannelBroadcaster, timeout time.Duration) *payd {
+	return &payd{s: b, timeout: int64(timeout)}
+}
+
+// SetTimeout will update the time to wait on socket responses, this is safe to call while requests are in flight.
+func (p *payd) SetTimeout(timeout time.Duration) {
+	atomic.StoreInt64(&p.timeout, int64(timeout))
 }
 
 // ProofCreate will broadcast the proof to all currently listening clients on the socket channel.
 func (p *payd) ProofCreate(ctx context.Context, args dpp.ProofCreateArgs, req envelope.JSONEnvelope) error {
-	msg := sockets.NewMessage("proof.create", "", args.PaymentReference)
+	msg := sockets.NewMessage(RouteProofCreate, "", args.PaymentReference)
 	msg.AppID = appID
 	msg.CorrelationID = args.TxID
 	if err := msg.WithBody(req); err != nil {
 		return err
 	}
 	msg.Headers.Add("x-tx-id", args.TxID)
+	ctx, span := tracing.StartClientSpan(ctx, "socket broadcast "+RouteProofCreate,
+		attribute.String("socket.channel", args.PaymentReference))
+	defer span.End()
+	tracing.Inject(ctx, propagation.HeaderCarrier(msg.Headers))
+	p.s.Broadcast(args.PaymentReference, msg)
+	return nil
+}
+
+// DoubleSpendCreate will broadcast the double spend to all currently listening clients on the socket channel.
+func (p *payd) DoubleSpendCreate(ctx context.Context, args dpp.ProofCreateArgs, req server.DoubleSpend) error {
+	msg := sockets.NewMessage(RouteDoubleSpendCreate, "", args.PaymentReference)
+	msg.AppID = appID
+	msg.CorrelationID = args.TxID
+	if err := msg.WithBody(req); err != nil {
+		return err
+	}
+	msg.Headers.Add("x-tx-id", args.TxID)
+	ctx, span := tracing.StartClientSpan(ctx, "socket broadcast "+RouteDoubleSpendCreate,
+		attribute.String("socket.channel", args.PaymentReference))
+	defer span.End()
+	tracing.Inject(ctx, propagation.HeaderCarrier(msg.Headers))
 	p.s.Broadcast(args.PaymentReference, msg)
 	return nil
 }
@@ -57,14 +92,14 @@ func (p *payd) PaymentRequest(ctx context.Context, args dpp.PaymentRequestArgs)
 	msg.AppID = appID
 	msg.CorrelationID = uuid.NewString()
 
-	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
-	defer cancel()
-
-	resp, err := p.s.BroadcastAwait(ctx, args.PaymentID, msg)
+	resp, err := p.broadcastAwait(ctx, args.PaymentID, msg)
 	if err != nil {
 		if errors.Is(err, sockets.ErrChannelNotFound) {
 			return nil, errs.NewErrNotFound("N00001", "invoice not found")
 		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, errs.NewErrNotAvailable("503", "the wallet did not respond in time")
+		}
 		return nil, errors.Wrap(err, "failed to broadcast message for payment request")
 	}
 	switch resp.Key() {
@@ -75,11 +110,11 @@ func (p *payd) PaymentRequest(ctx context.Context, args dpp.PaymentRequestArgs)
 		}
 		return pr, nil
 	case RoutePaymentRequestError:
-		var clientErr server.ClientError
-		if err := resp.Bind(&clientErr); err != nil {
+		var errBody errorBody
+		if err := resp.Bind(&errBody); err != nil {
 			return nil, errors.Wrap(err, "failed to bind error response")
 		}
-		return nil, toLathosErr(clientErr)
+		return nil, errBody.Err()
 	}
 
 	return nil, fmt.Errorf("unexpected response key '%s'", resp.Key())
@@ -93,10 +128,19 @@ func (p *payd) PaymentCreate(ctx context.Context, args dpp.PaymentCreateArgs, re
 	if err := msg.WithBody(req); err != nil {
 		return nil, err
 	}
-	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
-	defer cancel()
-	resp, err := p.s.BroadcastAwait(ctx, args.PaymentID, msg)
+	// refundTo is in the body, it is also added as a header so it is visible without decoding the payment.
+	if req.RefundTo != nil {
+		msg.Headers.Add("x-refund-to", *req.RefundTo)
+	}
+	resp, err := p.broadcastAwait(ctx, args.PaymentID, msg)
 	if err != nil {
+		if errors.Is(err, sockets.ErrChannelNotFound) {
+			return nil, server.NewErrWalletOffline("503", "the wallet is not connected")
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			// the wallet may still process the payment, so the customer is asked to retry.
+			return nil, errs.NewErrNotAvailable("503", "the wallet did not respond in time, retry the payment")
+		}
 		return nil, errors.Wrap(err, "failed to send payment message for payment")
 	}
 	switch resp.Key() {
@@ -107,21 +151,53 @@ func (p *payd) PaymentCreate(ctx context.Context, args dpp.PaymentCreateArgs, re
 		}
 		return pr, nil
 	case RoutePaymentError:
-		var clientErr server.ClientError
-		if err := resp.Bind(&clientErr); err != nil {
+		var errBody errorBody
+		if err := resp.Bind(&errBody); err != nil {
 			return nil, errors.Wrap(err, "failed to bind error response")
 		}
-		return nil, toLathosErr(clientErr)
+		return nil, errBody.Err()
 	}
 
 	return nil, fmt.Errorf("unexpected response key '%s'", resp.Key())
 }
 
-func toLathosErr(c server.ClientError) error {
-	switch c.Code {
-	case "404", "N0001":
-		return errs.NewErrNotFound(c.Code, c.Message)
+// broadcastAwait will send msg to the channel and wait for the first response, the current
+// trace context is added to the message headers so socket clients can continue the trace.
+func (p *payd) broadcastAwait(ctx context.Context, channelID string, msg *sockets.Message) (*sockets.Message, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(atomic.LoadInt64(&p.timeout)))
+	defer cancel()
+	ctx, span := tracing.StartClientSpan(ctx, "socket broadcastAwait "+msg.Key(),
+		attribute.String("socket.channel", channelID),
+		attribute.String("socket.correlation_id", msg.CorrelationID))
+	defer span.End()
+	tracing.Inject(ctx, propagation.HeaderCarrier(msg.Headers))
+	resp, err := p.s.BroadcastAwait(ctx, channelID, msg)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
 	}
+	span.SetAttributes(attribute.String("socket.response", resp.Key()))
+	return resp, nil
+}
+
+// errorBody is the body of an error message, wallets send a ClientError with a
+// message or a problem with a detail and status.
+type errorBody struct {
+	server.Problem
+	Message string `json:"message"`
+}
 
-	return c
+// Err returns the error matching the status or error code of the body, errors
+// with the legacy not found codes are not found errors.
+func (e errorBody) Err() error {
+	if e.Detail == "" {
+		e.Detail = e.Message
+	}
+	if e.Status == 0 {
+		switch e.Code {
+		case "404", "N0001":
+			e.Status = http.StatusNotFound
+		}
+	}
+	return e.Problem.Err()
 }
@@ -0,0 +1,126 @@
+package xpub
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/theflyingcodr/lathos/errs"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+)
+
+// state is the content of the file.
+type state struct {
+	NextIndex uint32                        `json:"nextIndex"`
+	Invoices  map[string]server.XPubInvoice `json:"invoices"`
+}
+
+type file struct {
+	mu    sync.Mutex
+	path  string
+	state state
+}
+
+// NewFile will setup and return a new xpub store that keeps invoices and the next derivation
+// index in a json file at path, the file is created if it doesn't exist. The whole file is
+// rewritten on each change, so it is suited to merchants with a modest number of invoices.
+func NewFile(path string) (*file, error) {
+	f := &file{
+		path:  path,
+		state: state{Invoices: map[string]server.XPubInvoice{}},
+	}
+	bb, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			return nil, errors.Wrapf(err, "failed to create xpub directory for %s", path)
+		}
+		if err := f.save(); err != nil {
+			return nil, err
+		}
+		return f, nil
+	case err != nil:
+		return nil, errors.Wrapf(err, "failed to read xpub file %s", path)
+	}
+	if err := json.Unmarshal(bb, &f.state); err != nil {
+		return nil, errors.Wrapf(err, "failed to read xpub file %s", path)
+	}
+	if f.state.Invoices == nil {
+		f.state.Invoices = map[string]server.XPubInvoice{}
+	}
+	return f, nil
+}
+
+// XPubIndexNext reserves the next derivation index, it is saved before being returned
+// so an index is never handed out twice.
+func (f *file) XPubIndexNext(ctx context.Context) (uint32, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	idx := f.state.NextIndex
+	f.state.NextIndex++
+	if err := f.save(); err != nil {
+		f.state.NextIndex--
+		return 0, err
+	}
+	return idx, nil
+}
+
+// XPubInvoiceCreate records the invoice, unless one is already recorded with the paymentID.
+func (f *file) XPubInvoiceCreate(ctx context.Context, req server.XPubInvoice) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.state.Invoices[req.PaymentID]; ok {
+		return errs.NewErrDuplicate("409", "an invoice already exists with the paymentID")
+	}
+	f.state.Invoices[req.PaymentID] = req
+	if err := f.save(); err != nil {
+		delete(f.state.Invoices, req.PaymentID)
+		return err
+	}
+	return nil
+}
+
+// XPubInvoiceUpdate replaces a recorded invoice.
+func (f *file) XPubInvoiceUpdate(ctx context.Context, req server.XPubInvoice) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	old, ok := f.state.Invoices[req.PaymentID]
+	if !ok {
+		return errs.NewErrNotFound("404", "invoice not found")
+	}
+	f.state.Invoices[req.PaymentID] = req
+	if err := f.save(); err != nil {
+		f.state.Invoices[req.PaymentID] = old
+		return err
+	}
+	return nil
+}
+
+// XPubInvoice returns the invoice recorded with the paymentID.
+func (f *file) XPubInvoice(ctx context.Context, args server.XPubInvoiceArgs) (*server.XPubInvoice, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	inv, ok := f.state.Invoices[args.PaymentID]
+	if !ok {
+		return nil, errs.NewErrNotFound("404", "invoice not found")
+	}
+	return &inv, nil
+}
+
+// save writes the state to a temporary file which then replaces the file, so the
+// file is never left partially written.
+func (f *file) save() error {
+	bb, err := json.Marshal(f.state)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode xpub state")
+	}
+	tmp := f.path + ".tmp"
+	if err := os.WriteFile(tmp, bb, 0o600); err != nil {
+		return errors.Wrapf(err, "failed to write xpub file %s", tmp)
+	}
+	return errors.Wrapf(os.Rename(tmp, f.path), "failed to replace xpub file %s", f.path)
+}
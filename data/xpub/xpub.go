@@ -0,0 +1,396 @@
+package xpub
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/libsv/go-bk/bip32"
+	"github.com/libsv/go-bk/envelope"
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/libsv/go-dpp"
+	"github.com/pkg/errors"
+	"github.com/theflyingcodr/lathos/errs"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/config"
+	"github.com/bitcoin-sv/dpp-proxy/log"
+)
+
+// externalChain is the chain of the extended public key outputs are derived from,
+// matching the receive addresses of bip44 wallets.
+const externalChain = 0
+
+// extendedDataQuote is the key the quote of a fiat invoice is stored under in the
+// payment request merchant data.
+const extendedDataQuote = "exchangeRate"
+
+type xpub struct {
+	l           log.Logger
+	key         *bip32.ExtendedKey
+	cfg         *config.XPub
+	ratesCfg    *config.Rates
+	srvCfg      *config.Server
+	deployCfg   *config.Deployment
+	store       server.XPubStore
+	rates       server.ExchangeRateReader
+	broadcaster server.Broadcaster
+
+	// locks serialises updates of each invoice, so a quote is locked, or an invoice paid, once.
+	locks *invoiceLocks
+}
+
+// NewXPub will setup and return a data store that creates invoices paid to P2PKH outputs
+// derived from the extended public key in cfg, and checks payments against them, so
+// merchants can be paid without running a wallet. Invoices are kept in store. Invoices
+// priced in fiat are converted with rates, if rates is nil they can't be created. Payment
+// transactions are broadcast with broadcaster before the invoice is marked as paid.
+func NewXPub(l log.Logger, cfg *config.XPub, ratesCfg *config.Rates, srvCfg *config.Server, deployCfg *config.Deployment,
+	store server.XPubStore, rates server.ExchangeRateReader, broadcaster server.Broadcaster) (*xpub, error) {
+	key, err := bip32.NewKeyFromString(cfg.Key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read extended public key")
+	}
+	if key.IsPrivate() {
+		return nil, errors.New("an extended private key can't be used, configure the extended public key")
+	}
+	l.Info("using xpub data store")
+	return &xpub{
+		l:           l,
+		key:         key,
+		cfg:         cfg,
+		ratesCfg:    ratesCfg,
+		srvCfg:      srvCfg,
+		deployCfg:   deployCfg,
+		store:       store,
+		rates:       rates,
+		broadcaster: broadcaster,
+		locks:       newInvoiceLocks(),
+	}, nil
+}
+
+// XPubInvoiceCreate will derive an output from the next unused index and record an invoice paid to it.
+func (x *xpub) XPubInvoiceCreate(ctx context.Context, req server.XPubInvoiceCreate) (*server.XPubInvoice, error) {
+	if req.Fiat != nil {
+		if x.rates == nil {
+			return nil, errs.NewErrUnprocessable("422", "invoices can't be priced in fiat as exchange rates aren't enabled")
+		}
+		// the rate is read so unsupported currencies are rejected when the invoice is created.
+		if _, err := x.rates.ExchangeRate(ctx, server.ExchangeRateArgs{Currency: req.Fiat.Currency}); err != nil {
+			return nil, err
+		}
+	}
+	idx, err := x.store.XPubIndexNext(ctx)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to reserve derivation index")
+	}
+	chain, err := x.key.Child(externalChain)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to derive external chain")
+	}
+	child, err := chain.Child(idx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to derive key %d", idx)
+	}
+	pub, err := child.ECPubKey()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read key %d", idx)
+	}
+	s, err := bscript.NewP2PKHFromPubKeyEC(pub)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create locking script for key %d", idx)
+	}
+	now := time.Now().UTC()
+	expires := now.Add(x.cfg.Expiry)
+	if req.ExpiresAt != nil {
+		expires = req.ExpiresAt.UTC()
+	}
+	inv := server.XPubInvoice{
+		PaymentID:      uuid.NewString(),
+		Satoshis:       req.Satoshis,
+		Fiat:           req.Fiat,
+		Memo:           req.Memo,
+		DerivationPath: fmt.Sprintf("%d/%d", externalChain, idx),
+		LockingScript:  s,
+		CreatedAt:      now,
+		ExpiresAt:      expires,
+	}
+	if err := x.store.XPubInvoiceCreate(ctx, inv); err != nil {
+		return nil, errors.WithMessage(err, "failed to record invoice")
+	}
+	return &inv, nil
+}
+
+// XPubInvoice will return the invoice with the paymentID.
+func (x *xpub) XPubInvoice(ctx context.Context, args server.XPubInvoiceArgs) (*server.XPubInvoice, error) {
+	return x.store.XPubInvoice(ctx, args)
+}
+
+// PaymentRequest will return a payment request for the invoice with the paymentID. Invoices
+// priced in fiat are converted to satoshis with a quote, locked until the payment request expires.
+func (x *xpub) PaymentRequest(ctx context.Context, args dpp.PaymentRequestArgs) (*dpp.PaymentRequest, error) {
+	inv, err := x.store.XPubInvoice(ctx, server.XPubInvoiceArgs{PaymentID: args.PaymentID})
+	if err != nil {
+		return nil, err
+	}
+	satoshis, expires := inv.Satoshis, inv.ExpiresAt
+	extendedData := map[string]interface{}{"paymentReference": args.PaymentID}
+	if inv.Fiat != nil {
+		q, err := x.lockQuote(ctx, inv)
+		if err != nil {
+			return nil, err
+		}
+		satoshis, expires = q.Satoshis, q.LockedUntil
+		extendedData[extendedDataQuote] = q
+	}
+	fees := bt.NewFeeQuote()
+	fees.UpdateExpiry(expires)
+	return &dpp.PaymentRequest{
+		Network: x.deployCfg.Network,
+		Destinations: dpp.PaymentDestinations{
+			Outputs: []dpp.Output{{
+				Amount:        satoshis,
+				LockingScript: inv.LockingScript,
+				Description:   inv.Memo,
+			}},
+		},
+		CreationTimestamp:   inv.CreatedAt,
+		ExpirationTimestamp: expires,
+		FeeRate:             fees,
+		PaymentURL:          server.PaymentURL(x.srvCfg.FQDN, args.PaymentID),
+		Memo:                inv.Memo,
+		MerchantData: &dpp.Merchant{
+			ExtendedData: extendedData,
+		},
+	}, nil
+}
+
+// PaymentCreate will accept a payment paying the invoice output, broadcasting the transaction
+// and marking the invoice as paid once it is accepted. If the broadcast fails the error is
+// returned and the invoice is left unpaid, so the payment can be retried. No lock is held while
+// the transaction is broadcast, the invoice is reserved for it instead.
+func (x *xpub) PaymentCreate(ctx context.Context, args dpp.PaymentCreateArgs, req dpp.Payment) (*dpp.PaymentACK, error) {
+	tx, paid, err := x.reservePayment(ctx, args.PaymentID, req)
+	if err != nil {
+		return nil, err
+	}
+	ack := &dpp.PaymentACK{ID: args.PaymentID, TxID: tx.TxID(), Memo: "payment accepted"}
+	if paid {
+		// a resent payment is acked again, so wallets can safely retry.
+		return ack, nil
+	}
+	// proofs aren't kept, so no callbacks are requested.
+	if err := x.broadcaster.Broadcast(ctx, server.Broadcast{PaymentID: args.PaymentID, Tx: tx}); err != nil {
+		x.locks.release(args.PaymentID)
+		return nil, errors.WithMessagef(err, "failed to broadcast payment for paymentID '%s'", args.PaymentID)
+	}
+	if err := x.markPaid(ctx, args.PaymentID, tx); err != nil {
+		return nil, err
+	}
+	x.l.Infof("xpub invoice %s paid with txid %s", args.PaymentID, tx.TxID())
+	return ack, nil
+}
+
+// reservePayment checks the payment pays the invoice and reserves the invoice for the payment
+// transaction, so no other payment is broadcast for it. True is returned if the invoice has
+// already been paid with the transaction.
+func (x *xpub) reservePayment(ctx context.Context, paymentID string, req dpp.Payment) (*bt.Tx, bool, error) {
+	unlock := x.locks.lock(paymentID)
+	defer unlock()
+	inv, err := x.store.XPubInvoice(ctx, server.XPubInvoiceArgs{PaymentID: paymentID})
+	if err != nil {
+		return nil, false, err
+	}
+	if req.RawTx == nil {
+		return nil, false, errs.NewErrUnprocessable("422", "a rawTx is required")
+	}
+	tx, err := bt.NewTxFromString(*req.RawTx)
+	if err != nil {
+		return nil, false, errs.NewErrUnprocessable("422", "payment transaction cannot be read")
+	}
+	if inv.TxID != "" {
+		if inv.TxID == tx.TxID() {
+			return tx, true, nil
+		}
+		return nil, false, errs.NewErrDuplicate("409", "the invoice has already been paid")
+	}
+	if x.locks.reserved(paymentID) {
+		return nil, false, errs.NewErrDuplicate("409", "a payment for the invoice is being processed, retry shortly")
+	}
+	if !inv.ExpiresAt.After(time.Now()) {
+		return nil, false, errs.NewErrUnprocessable("422", "payment request has expired")
+	}
+	satoshis := inv.Satoshis
+	if inv.Fiat != nil {
+		// fiat invoices are paid at the locked quote, the customer must have been served it.
+		switch {
+		case inv.Quote == nil:
+			return nil, false, errs.NewErrUnprocessable("422", "the invoice hasn't been quoted, request the payment request first")
+		case !inv.Quote.LockedUntil.After(time.Now()):
+			return nil, false, errs.NewErrUnprocessable("422", "the quote has expired, request the payment request again")
+		}
+		satoshis = inv.Quote.Satoshis
+	}
+	if !paysInvoice(tx, satoshis, inv.LockingScript) {
+		return nil, false, errs.NewErrUnprocessablef("422", "transaction does not pay %d satoshis to the invoice output", satoshis)
+	}
+	x.locks.reserve(paymentID)
+	return tx, false, nil
+}
+
+// markPaid records the broadcast transaction as paying the invoice and releases its reservation.
+func (x *xpub) markPaid(ctx context.Context, paymentID string, tx *bt.Tx) error {
+	unlock := x.locks.lock(paymentID)
+	defer unlock()
+	defer x.locks.release(paymentID)
+	inv, err := x.store.XPubInvoice(ctx, server.XPubInvoiceArgs{PaymentID: paymentID})
+	if err != nil {
+		return errors.WithMessage(err, "failed to mark invoice as paid")
+	}
+	now := time.Now().UTC()
+	inv.TxID, inv.PaidAt = tx.TxID(), &now
+	if err := x.store.XPubInvoiceUpdate(ctx, *inv); err != nil {
+		return errors.WithMessage(err, "failed to mark invoice as paid")
+	}
+	return nil
+}
+
+// ProofCreate will accept and discard a merkle proof, there is no wallet to send it to.
+func (x *xpub) ProofCreate(ctx context.Context, args dpp.ProofCreateArgs, req envelope.JSONEnvelope) error {
+	x.l.Infof("xpub proof received for txid %s", args.TxID)
+	return nil
+}
+
+// DoubleSpendCreate will log a double spend notification, there is no wallet to send it to.
+func (x *xpub) DoubleSpendCreate(ctx context.Context, args dpp.ProofCreateArgs, req server.DoubleSpend) error {
+	x.l.Infof("xpub double spend received for txid %s", args.TxID)
+	return nil
+}
+
+// lockQuote returns the quote of a fiat invoice, a new quote is locked if the invoice hasn't
+// been quoted or its quote has expired. Quotes of paid or expired invoices aren't replaced. The
+// rate is read without the invoice locked, if another quote was locked meanwhile it is returned.
+func (x *xpub) lockQuote(ctx context.Context, inv *server.XPubInvoice) (*server.Quote, error) {
+	if quoted(inv, time.Now()) {
+		return inv.Quote, nil
+	}
+	rate, err := x.rates.ExchangeRate(ctx, server.ExchangeRateArgs{Currency: inv.Fiat.Currency})
+	if err != nil {
+		return nil, err
+	}
+	sats, err := inv.Fiat.Satoshis(rate.Rate)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed to quote invoice using %s rate", rate.Source)
+	}
+	unlock := x.locks.lock(inv.PaymentID)
+	defer unlock()
+	inv, err = x.store.XPubInvoice(ctx, server.XPubInvoiceArgs{PaymentID: inv.PaymentID})
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed to lock quote")
+	}
+	now := time.Now().UTC()
+	if quoted(inv, now) {
+		return inv.Quote, nil
+	}
+	locked := now.Add(x.ratesCfg.QuoteExpiry)
+	if inv.ExpiresAt.Before(locked) {
+		locked = inv.ExpiresAt
+	}
+	inv.Quote = &server.Quote{
+		Fiat:        *inv.Fiat,
+		Rate:        rate.Rate,
+		Source:      rate.Source,
+		Satoshis:    sats,
+		QuotedAt:    now,
+		LockedUntil: locked,
+	}
+	if err := x.store.XPubInvoiceUpdate(ctx, *inv); err != nil {
+		return nil, errors.WithMessage(err, "failed to lock quote")
+	}
+	return inv.Quote, nil
+}
+
+// quoted returns true if the invoice has a quote that is still locked, or that can't be
+// replaced as the invoice is paid or expired.
+func quoted(inv *server.XPubInvoice, now time.Time) bool {
+	return inv.Quote != nil && (inv.Quote.LockedUntil.After(now) || inv.TxID != "" || !inv.ExpiresAt.After(now))
+}
+
+// paysInvoice returns true if an output of the transaction pays satoshis to the locking script.
+func paysInvoice(tx *bt.Tx, satoshis uint64, s *bscript.Script) bool {
+	for _, o := range tx.Outputs {
+		if o.Satoshis == satoshis && o.LockingScript.Equals(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// invoiceLocks serialises updates of each invoice, so requests for one invoice don't block
+// requests for others, and records the invoices reserved for a payment being broadcast.
+type invoiceLocks struct {
+	mu       sync.Mutex
+	locks    map[string]*invoiceLock
+	reserves map[string]struct{}
+}
+
+// invoiceLock is the lock of an invoice, refs counts the requests holding or waiting on it
+// so it is removed once unused.
+type invoiceLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+func newInvoiceLocks() *invoiceLocks {
+	return &invoiceLocks{
+		locks:    map[string]*invoiceLock{},
+		reserves: map[string]struct{}{},
+	}
+}
+
+// lock locks the invoice with the paymentID and returns the func unlocking it.
+func (l *invoiceLocks) lock(paymentID string) func() {
+	l.mu.Lock()
+	il, ok := l.locks[paymentID]
+	if !ok {
+		il = &invoiceLock{}
+		l.locks[paymentID] = il
+	}
+	il.refs++
+	l.mu.Unlock()
+
+	il.mu.Lock()
+	return func() {
+		il.mu.Unlock()
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if il.refs--; il.refs == 0 {
+			delete(l.locks, paymentID)
+		}
+	}
+}
+
+// reserve records the invoice as reserved for a payment.
+func (l *invoiceLocks) reserve(paymentID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.reserves[paymentID] = struct{}{}
+}
+
+// reserved returns true if the invoice is reserved for a payment.
+func (l *invoiceLocks) reserved(paymentID string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, ok := l.reserves[paymentID]
+	return ok
+}
+
+// release removes the reservation of the invoice.
+func (l *invoiceLocks) release(paymentID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.reserves, paymentID)
+}
@@ -0,0 +1,377 @@
+package xpub_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/libsv/go-bk/bip32"
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/libsv/go-dpp"
+	"github.com/stretchr/testify/assert"
+	"github.com/theflyingcodr/lathos/errs"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/config"
+	"github.com/bitcoin-sv/dpp-proxy/data/xpub"
+	"github.com/bitcoin-sv/dpp-proxy/log"
+	"github.com/bitcoin-sv/dpp-proxy/mocks"
+)
+
+const (
+	tprv = "tprv8ZgxMBicQKsPdDdJFAqvG3mt4VqsVV125X4vsor5NxK366upt6qvovLQqaCi5SJiCE1aLkt3HtxsnTpzeGu27kPC5RUCr4h3oPBPYnAvhdE"
+	tpub = "tpubD6NzVbkrYhZ4Wgf68pWWfTRzdXMoepBvepfiAKtNoE7RvbAbWVfWzQxH1jbkfNk3iJ9zR65Yw6u3B2QZzpkMSTN4y8Lfm1t44HbpZX7efhZ"
+)
+
+type store interface {
+	server.XPubInvoiceReaderWriter
+	dpp.PaymentRequestReader
+	dpp.PaymentWriter
+}
+
+func newXPub(t *testing.T, path string) store {
+	return newFiatXPub(t, path, nil, 0)
+}
+
+// broadcaster accepts every transaction.
+var broadcaster = &mocks.BroadcasterMock{
+	BroadcastFunc: func(context.Context, server.Broadcast) error {
+		return nil
+	},
+}
+
+// newFiatXPub returns an xpub store converting fiat invoices with rates, quotes are locked for quoteExpiry.
+func newFiatXPub(t *testing.T, path string, rates server.ExchangeRateReader, quoteExpiry time.Duration) store {
+	return newBroadcastXPub(t, path, rates, quoteExpiry, broadcaster)
+}
+
+// newBroadcastXPub returns an xpub store broadcasting payments with b.
+func newBroadcastXPub(t *testing.T, path string, rates server.ExchangeRateReader, quoteExpiry time.Duration,
+	b server.Broadcaster) store {
+	f, err := xpub.NewFile(path)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	x, err := xpub.NewXPub(log.Noop{}, &config.XPub{Key: tpub, Expiry: time.Hour}, &config.Rates{QuoteExpiry: quoteExpiry},
+		&config.Server{FQDN: "dpp.example.com"}, &config.Deployment{Network: config.NetworkRegtest}, f, rates, b)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return x
+}
+
+// script returns the P2PKH locking script of the key at 0/idx, derived from the private key.
+func script(t *testing.T, idx uint32) *bscript.Script {
+	k, err := bip32.NewKeyFromString(tprv)
+	assert.NoError(t, err)
+	k, err = k.Child(0)
+	assert.NoError(t, err)
+	k, err = k.Child(idx)
+	assert.NoError(t, err)
+	priv, err := k.ECPrivKey()
+	assert.NoError(t, err)
+	s, err := bscript.NewP2PKHFromPubKeyEC(priv.PubKey())
+	assert.NoError(t, err)
+	return s
+}
+
+// payment returns a payment with a transaction paying satoshis to s.
+func payment(satoshis uint64, s *bscript.Script) dpp.Payment {
+	tx := bt.NewTx()
+	tx.AddOutput(&bt.Output{Satoshis: satoshis, LockingScript: s})
+	rawTx := tx.String()
+	return dpp.Payment{RawTx: &rawTx}
+}
+
+func TestXPub_XPubInvoiceCreate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "xpub", "invoices.json")
+	x := newXPub(t, path)
+	for i := uint32(0); i < 3; i++ {
+		inv, err := x.XPubInvoiceCreate(context.Background(), server.XPubInvoiceCreate{Satoshis: 1000, Memo: "coffee"})
+		assert.NoError(t, err)
+		assert.Equal(t, script(t, i).String(), inv.LockingScript.String())
+		assert.NotEmpty(t, inv.PaymentID)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), inv.ExpiresAt, time.Minute)
+
+		pr, err := x.PaymentRequest(context.Background(), dpp.PaymentRequestArgs{PaymentID: inv.PaymentID})
+		assert.NoError(t, err)
+		assert.Equal(t, config.NetworkRegtest, pr.Network)
+		assert.Equal(t, "coffee", pr.Memo)
+		assert.Equal(t, "http://dpp.example.com/api/v1/payment/"+inv.PaymentID, pr.PaymentURL)
+		assert.Equal(t, inv.PaymentID, pr.MerchantData.ExtendedData["paymentReference"])
+		assert.Len(t, pr.Destinations.Outputs, 1)
+		assert.Equal(t, uint64(1000), pr.Destinations.Outputs[0].Amount)
+		assert.Equal(t, inv.LockingScript, pr.Destinations.Outputs[0].LockingScript)
+	}
+
+	// outputs aren't reused once restarted.
+	x = newXPub(t, path)
+	inv, err := x.XPubInvoiceCreate(context.Background(), server.XPubInvoiceCreate{Satoshis: 1000})
+	assert.NoError(t, err)
+	assert.Equal(t, "0/3", inv.DerivationPath)
+	assert.Equal(t, script(t, 3).String(), inv.LockingScript.String())
+
+	_, err = x.PaymentRequest(context.Background(), dpp.PaymentRequestArgs{PaymentID: "abc123"})
+	assert.EqualError(t, err, "Not found: invoice not found")
+}
+
+func TestXPub_PaymentCreate(t *testing.T) {
+	tests := map[string]struct {
+		expires  time.Time
+		satoshis uint64
+		scriptFn func(t *testing.T) *bscript.Script
+		expErr   error
+	}{
+		"payment paying the invoice is accepted": {
+			satoshis: 1000,
+		},
+		"payment paying too little is rejected": {
+			satoshis: 999,
+			expErr:   errors.New("Unprocessable: transaction does not pay 1000 satoshis to the invoice output"),
+		},
+		"payment paying another output is rejected": {
+			satoshis: 1000,
+			scriptFn: func(t *testing.T) *bscript.Script {
+				return script(t, 1)
+			},
+			expErr: errors.New("Unprocessable: transaction does not pay 1000 satoshis to the invoice output"),
+		},
+		"payment for an expired invoice is rejected": {
+			expires:  time.Now().Add(50 * time.Millisecond),
+			satoshis: 1000,
+			expErr:   errors.New("Unprocessable: payment request has expired"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			x := newXPub(t, filepath.Join(t.TempDir(), "invoices.json"))
+			req := server.XPubInvoiceCreate{Satoshis: 1000}
+			if !test.expires.IsZero() {
+				req.ExpiresAt = &test.expires
+			}
+			inv, err := x.XPubInvoiceCreate(context.Background(), req)
+			assert.NoError(t, err)
+			if !test.expires.IsZero() {
+				time.Sleep(time.Until(test.expires))
+			}
+			s := inv.LockingScript
+			if test.scriptFn != nil {
+				s = test.scriptFn(t)
+			}
+			args := dpp.PaymentCreateArgs{PaymentID: inv.PaymentID}
+			ack, err := x.PaymentCreate(context.Background(), args, payment(test.satoshis, s))
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotEmpty(t, ack.TxID)
+
+			paid, err := x.XPubInvoice(context.Background(), server.XPubInvoiceArgs{PaymentID: inv.PaymentID})
+			assert.NoError(t, err)
+			assert.Equal(t, ack.TxID, paid.TxID)
+			assert.NotNil(t, paid.PaidAt)
+
+			// the same payment is acked again, another is rejected.
+			resent, err := x.PaymentCreate(context.Background(), args, payment(test.satoshis, s))
+			assert.NoError(t, err)
+			assert.Equal(t, ack.TxID, resent.TxID)
+			_, err = x.PaymentCreate(context.Background(), args, payment(test.satoshis+1, s))
+			assert.EqualError(t, err, "Item already exists: the invoice has already been paid")
+		})
+	}
+}
+
+func TestXPub_PaymentCreate_BroadcastFails(t *testing.T) {
+	rejected := true
+	b := &mocks.BroadcasterMock{
+		BroadcastFunc: func(ctx context.Context, req server.Broadcast) error {
+			if rejected {
+				return errs.NewErrUnprocessable("422", "transaction rejected: missing inputs")
+			}
+			return nil
+		},
+	}
+	x := newBroadcastXPub(t, filepath.Join(t.TempDir(), "invoices.json"), nil, 0, b)
+	inv, err := x.XPubInvoiceCreate(context.Background(), server.XPubInvoiceCreate{Satoshis: 1000})
+	assert.NoError(t, err)
+	args := dpp.PaymentCreateArgs{PaymentID: inv.PaymentID}
+	_, err = x.PaymentCreate(context.Background(), args, payment(1000, inv.LockingScript))
+	assert.EqualError(t, err, "failed to broadcast payment for paymentID '"+inv.PaymentID+"': Unprocessable: transaction rejected: missing inputs")
+
+	// the invoice is left unpaid so the payment can be retried.
+	unpaid, err := x.XPubInvoice(context.Background(), server.XPubInvoiceArgs{PaymentID: inv.PaymentID})
+	assert.NoError(t, err)
+	assert.Empty(t, unpaid.TxID)
+	assert.Nil(t, unpaid.PaidAt)
+
+	rejected = false
+	ack, err := x.PaymentCreate(context.Background(), args, payment(1000, inv.LockingScript))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ack.TxID)
+	assert.Len(t, b.BroadcastCalls(), 2)
+	assert.Equal(t, ack.TxID, b.BroadcastCalls()[1].Req.Tx.TxID())
+}
+
+func TestXPub_PaymentCreate_SlowBroadcast(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	b := &mocks.BroadcasterMock{}
+	x := newBroadcastXPub(t, filepath.Join(t.TempDir(), "invoices.json"), nil, 0, b)
+	slow, err := x.XPubInvoiceCreate(context.Background(), server.XPubInvoiceCreate{Satoshis: 1000})
+	assert.NoError(t, err)
+	other, err := x.XPubInvoiceCreate(context.Background(), server.XPubInvoiceCreate{Satoshis: 2000})
+	assert.NoError(t, err)
+	b.BroadcastFunc = func(ctx context.Context, req server.Broadcast) error {
+		if req.PaymentID == slow.PaymentID {
+			close(started)
+			<-release
+		}
+		return nil
+	}
+
+	slowArgs := dpp.PaymentCreateArgs{PaymentID: slow.PaymentID}
+	done := make(chan error)
+	go func() {
+		_, err := x.PaymentCreate(context.Background(), slowArgs, payment(1000, slow.LockingScript))
+		done <- err
+	}()
+	<-started
+
+	// other invoices aren't blocked by the broadcast, a second payment for the invoice is rejected.
+	_, err = x.PaymentRequest(context.Background(), dpp.PaymentRequestArgs{PaymentID: other.PaymentID})
+	assert.NoError(t, err)
+	_, err = x.PaymentCreate(context.Background(), dpp.PaymentCreateArgs{PaymentID: other.PaymentID},
+		payment(2000, other.LockingScript))
+	assert.NoError(t, err)
+	_, err = x.PaymentCreate(context.Background(), slowArgs, payment(1001, slow.LockingScript))
+	assert.EqualError(t, err, "Item already exists: a payment for the invoice is being processed, retry shortly")
+
+	close(release)
+	assert.NoError(t, <-done)
+	paid, err := x.XPubInvoice(context.Background(), server.XPubInvoiceArgs{PaymentID: slow.PaymentID})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, paid.TxID)
+}
+
+func TestXPub_PaymentRequest_SlowRates(t *testing.T) {
+	var slow int32
+	release := make(chan struct{})
+	started := make(chan struct{})
+	rates := &mocks.ExchangeRateReaderMock{
+		ExchangeRateFunc: func(ctx context.Context, args server.ExchangeRateArgs) (*server.ExchangeRate, error) {
+			if atomic.LoadInt32(&slow) == 1 {
+				close(started)
+				<-release
+			}
+			return &server.ExchangeRate{Currency: "USD", Rate: 50, Source: "file", ReadAt: time.Now()}, nil
+		},
+	}
+	x := newFiatXPub(t, filepath.Join(t.TempDir(), "invoices.json"), rates, time.Minute)
+	fiat, err := x.XPubInvoiceCreate(context.Background(), server.XPubInvoiceCreate{Fiat: &server.FiatAmount{Currency: "USD", Amount: 12.5}})
+	assert.NoError(t, err)
+	other, err := x.XPubInvoiceCreate(context.Background(), server.XPubInvoiceCreate{Satoshis: 2000})
+	assert.NoError(t, err)
+	atomic.StoreInt32(&slow, 1)
+
+	done := make(chan error)
+	go func() {
+		_, err := x.PaymentRequest(context.Background(), dpp.PaymentRequestArgs{PaymentID: fiat.PaymentID})
+		done <- err
+	}()
+	<-started
+
+	// other invoices aren't blocked while the rate is read.
+	_, err = x.PaymentRequest(context.Background(), dpp.PaymentRequestArgs{PaymentID: other.PaymentID})
+	assert.NoError(t, err)
+	_, err = x.PaymentCreate(context.Background(), dpp.PaymentCreateArgs{PaymentID: other.PaymentID},
+		payment(2000, other.LockingScript))
+	assert.NoError(t, err)
+
+	close(release)
+	assert.NoError(t, <-done)
+}
+
+func TestXPub_NewXPub(t *testing.T) {
+	f, err := xpub.NewFile(filepath.Join(t.TempDir(), "invoices.json"))
+	assert.NoError(t, err)
+	_, err = xpub.NewXPub(log.Noop{}, &config.XPub{Key: tprv}, &config.Rates{}, &config.Server{}, &config.Deployment{}, f, nil, nil)
+	assert.EqualError(t, err, "an extended private key can't be used, configure the extended public key")
+}
+
+func TestXPub_Fiat(t *testing.T) {
+	usd := 50.0
+	rates := &mocks.ExchangeRateReaderMock{
+		ExchangeRateFunc: func(ctx context.Context, args server.ExchangeRateArgs) (*server.ExchangeRate, error) {
+			if args.Currency != "USD" {
+				return nil, errs.NewErrNotFoundf("404", "no exchange rate for currency %s", args.Currency)
+			}
+			return &server.ExchangeRate{Currency: "USD", Rate: usd, Source: "file", ReadAt: time.Now()}, nil
+		},
+	}
+	x := newFiatXPub(t, filepath.Join(t.TempDir(), "invoices.json"), rates, 100*time.Millisecond)
+
+	_, err := x.XPubInvoiceCreate(context.Background(), server.XPubInvoiceCreate{Fiat: &server.FiatAmount{Currency: "JPY", Amount: 1000}})
+	assert.EqualError(t, err, "Not found: no exchange rate for currency JPY")
+	inv, err := x.XPubInvoiceCreate(context.Background(), server.XPubInvoiceCreate{Fiat: &server.FiatAmount{Currency: "USD", Amount: 12.5}})
+	assert.NoError(t, err)
+	args := dpp.PaymentCreateArgs{PaymentID: inv.PaymentID}
+
+	// payments can't be made until the customer has been quoted.
+	_, err = x.PaymentCreate(context.Background(), args, payment(25000000, inv.LockingScript))
+	assert.EqualError(t, err, "Unprocessable: the invoice hasn't been quoted, request the payment request first")
+
+	pr, err := x.PaymentRequest(context.Background(), dpp.PaymentRequestArgs{PaymentID: inv.PaymentID})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(25000000), pr.Destinations.Outputs[0].Amount)
+	quote := pr.MerchantData.ExtendedData["exchangeRate"].(*server.Quote)
+	assert.Equal(t, 50.0, quote.Rate)
+	assert.Equal(t, server.FiatAmount{Currency: "USD", Amount: 12.5}, quote.Fiat)
+	assert.Equal(t, quote.LockedUntil, pr.ExpirationTimestamp)
+
+	// the quote is locked, rate changes don't affect the amount until it expires.
+	usd = 40
+	pr, err = x.PaymentRequest(context.Background(), dpp.PaymentRequestArgs{PaymentID: inv.PaymentID})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(25000000), pr.Destinations.Outputs[0].Amount)
+	time.Sleep(time.Until(pr.ExpirationTimestamp))
+	_, err = x.PaymentCreate(context.Background(), args, payment(25000000, inv.LockingScript))
+	assert.EqualError(t, err, "Unprocessable: the quote has expired, request the payment request again")
+
+	// an expired quote is replaced at the new rate.
+	pr, err = x.PaymentRequest(context.Background(), dpp.PaymentRequestArgs{PaymentID: inv.PaymentID})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(31250000), pr.Destinations.Outputs[0].Amount)
+	_, err = x.PaymentCreate(context.Background(), args, payment(25000000, inv.LockingScript))
+	assert.EqualError(t, err, "Unprocessable: transaction does not pay 31250000 satoshis to the invoice output")
+	ack, err := x.PaymentCreate(context.Background(), args, payment(31250000, inv.LockingScript))
+	assert.NoError(t, err)
+
+	paid, err := x.XPubInvoice(context.Background(), server.XPubInvoiceArgs{PaymentID: inv.PaymentID})
+	assert.NoError(t, err)
+	assert.Equal(t, ack.TxID, paid.TxID)
+	assert.Equal(t, uint64(31250000), paid.Quote.Satoshis)
+
+	// a rate that can't convert the amount isn't quoted.
+	for _, bad := range []float64{0, -50, math.NaN(), 1e-12} {
+		usd = bad
+		inv, err = x.XPubInvoiceCreate(context.Background(), server.XPubInvoiceCreate{Fiat: &server.FiatAmount{Currency: "USD", Amount: 12.5}})
+		assert.NoError(t, err)
+		_, err = x.PaymentRequest(context.Background(), dpp.PaymentRequestArgs{PaymentID: inv.PaymentID})
+		assert.EqualError(t, err, fmt.Sprintf("failed to quote invoice using file rate: 12.5 USD can't be converted to satoshis at rate %v", bad))
+		unquoted, err := x.XPubInvoice(context.Background(), server.XPubInvoiceArgs{PaymentID: inv.PaymentID})
+		assert.NoError(t, err)
+		assert.Nil(t, unquoted.Quote)
+	}
+
+	// without rates invoices can't be priced in fiat.
+	x = newXPub(t, filepath.Join(t.TempDir(), "invoices.json"))
+	_, err = x.XPubInvoiceCreate(context.Background(), server.XPubInvoiceCreate{Fiat: &server.FiatAmount{Currency: "USD", Amount: 12.5}})
+	assert.EqualError(t, err, "Unprocessable: invoices can't be priced in fiat as exchange rates aren't enabled")
+}
@@ -0,0 +1,20 @@
+package server
+
+import (
+	"context"
+
+	"github.com/libsv/go-bt/v2"
+)
+
+// FeeQuoteReader reads the fees miners currently accept, such as from an mAPI or ARC server.
+type FeeQuoteReader interface {
+	// FeeQuote returns the current fees, they should be read again once the quote expires.
+	FeeQuote(ctx context.Context) (*bt.FeeQuote, error)
+}
+
+// FeeService decides the fees payment requests are served with.
+type FeeService interface {
+	// Fees returns the miner fees if fees is nil, otherwise fees are kept within the
+	// configured floor and ceiling.
+	Fees(ctx context.Context, fees *bt.FeeQuote) (*bt.FeeQuote, error)
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/libsv/go-dpp"
+	"github.com/pkg/errors"
+	validator "github.com/theflyingcodr/govalidator"
+)
+
+// Invoice is a payment request registered by the merchant ahead of time, so it can be
+// served, and paid, while the merchant wallet isn't connected to the proxy.
+type Invoice struct {
+	PaymentID      string             `json:"paymentId" example:"abc123"`
+	PaymentRequest dpp.PaymentRequest `json:"paymentRequest"`
+	CreatedAt      time.Time          `json:"createdAt"`
+}
+
+// InvoiceArgs identify an invoice.
+type InvoiceArgs struct {
+	PaymentID string `param:"paymentID"`
+}
+
+// Validate will ensure the InvoiceArgs are supplied and correct.
+func (i InvoiceArgs) Validate() error {
+	return validator.New().
+		Validate("paymentID", validator.NotEmpty(i.PaymentID)).
+		Err()
+}
+
+// InvoiceCreate is sent by a merchant to register an invoice. The paymentUrl and
+// network of the payment request are set by the proxy, the creationTimestamp
+// is set if empty.
+type InvoiceCreate struct {
+	PaymentID      string             `json:"paymentId" example:"abc123"`
+	PaymentRequest dpp.PaymentRequest `json:"paymentRequest"`
+}
+
+// Validate will ensure the invoice has outputs, fees and an expiry in the future.
+func (i InvoiceCreate) Validate() error {
+	pr := i.PaymentRequest
+	v := validator.New().
+		Validate("paymentId", validator.NotEmpty(i.PaymentID)).
+		Validate("paymentRequest.destinations.outputs", func() error {
+			if len(pr.Destinations.Outputs) == 0 {
+				return errors.New("at least one output is required")
+			}
+			return nil
+		}).
+		Validate("paymentRequest.expirationTimestamp", func() error {
+			if pr.ExpirationTimestamp.IsZero() {
+				return errors.New("an expiry is required")
+			}
+			if !pr.ExpirationTimestamp.After(time.Now()) {
+				return errors.New("the expiry must be in the future")
+			}
+			return nil
+		}).
+		Validate("paymentRequest.fees", func() error {
+			if pr.FeeRate == nil {
+				return errors.New("fees are required")
+			}
+			return nil
+		})
+	for n, o := range pr.Destinations.Outputs {
+		o := o
+		v = v.Validate(fmt.Sprintf("paymentRequest.destinations.outputs[%d]", n), func() error {
+			if o.Amount == 0 {
+				return errors.New("amount must be greater than 0")
+			}
+			if o.LockingScript == nil || len(*o.LockingScript) == 0 {
+				return errors.New("a locking script is required")
+			}
+			return nil
+		})
+	}
+	return v.Err()
+}
+
+// InvoiceService lets merchants register invoices with the proxy.
+type InvoiceService interface {
+	InvoiceCreate(ctx context.Context, req InvoiceCreate) (*Invoice, error)
+	Invoice(ctx context.Context, args InvoiceArgs) (*Invoice, error)
+	InvoiceDelete(ctx context.Context, args InvoiceArgs) error
+}
+
+// InvoiceReader reads invoices from a data store.
+type InvoiceReader interface {
+	// Invoice returns a registered invoice, a not found error is returned
+	// if the invoice wasn't registered.
+	Invoice(ctx context.Context, args InvoiceArgs) (*Invoice, error)
+}
+
+// InvoiceWriter writes invoices to a data store.
+type InvoiceWriter interface {
+	// InvoiceCreate records an invoice, a duplicate error is returned if
+	// an invoice with the paymentID is already registered.
+	InvoiceCreate(ctx context.Context, req Invoice) error
+	// InvoiceDelete removes an invoice, a not found error is returned if
+	// the invoice wasn't registered.
+	InvoiceDelete(ctx context.Context, args InvoiceArgs) error
+}
+
+// InvoiceReaderWriter combines the reader and writer.
+type InvoiceReaderWriter interface {
+	InvoiceReader
+	InvoiceWriter
+}
@@ -71,6 +71,16 @@ func NewZero(cfg *config.Logging) *Zero {
 	return &Zero{}
 }
 
+// SetLevel will change the global log level.
+func (z Zero) SetLevel(level string) error {
+	lvl, err := zerolog.ParseLevel(level)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse log level %s", level)
+	}
+	zerolog.SetGlobalLevel(lvl)
+	return nil
+}
+
 // Info writes an info level log.
 func (z Zero) Info(s string) {
 	log.Info().Msg(s)
@@ -1,3 +1,30 @@
 package mocks
 
 //go:generate moq -pkg mocks -out http_client.go ../data HTTPClient
+//go:generate moq -pkg mocks -out audit_logger.go ../ AuditLogger
+//go:generate moq -pkg mocks -out audit_store.go ../ AuditStore
+//go:generate moq -pkg mocks -out payment_uri_service.go ../ PaymentURIService
+//go:generate moq -pkg mocks -out refund_service.go ../ RefundService
+//go:generate moq -pkg mocks -out refund_writer.go ../ RefundWriter
+//go:generate moq -pkg mocks -out double_spend_writer.go ../ DoubleSpendWriter
+//go:generate moq -pkg mocks -out proofs_writer.go ../vendor/github.com/libsv/go-dpp ProofsWriter
+//go:generate moq -pkg mocks -out proof_token_writer.go ../ ProofTokenWriter
+//go:generate moq -pkg mocks -out proof_token_reader.go ../ ProofTokenReader
+//go:generate moq -pkg mocks -out proof_verifier.go ../ ProofVerifier
+//go:generate moq -pkg mocks -out block_header_chain.go ../vendor/github.com/libsv/go-bc BlockHeaderChain
+//go:generate moq -pkg mocks -out proof_writer.go ../ ProofWriter
+//go:generate moq -pkg mocks -out proof_service.go ../ ProofService
+//go:generate moq -pkg mocks -out payment_status_writer.go ../ PaymentStatusWriter
+//go:generate moq -pkg mocks -out payment_status_service.go ../ PaymentStatusService
+//go:generate moq -pkg mocks -out peer_channel_creator.go ../ PeerChannelCreator
+//go:generate moq -pkg mocks -out peer_channel_service.go ../ PeerChannelService
+//go:generate moq -pkg mocks -out invoice_service.go ../ InvoiceService
+//go:generate moq -pkg mocks -out payment_queue_service.go ../ PaymentQueueService
+//go:generate moq -pkg mocks -out payment_request_reader.go ../vendor/github.com/libsv/go-dpp PaymentRequestReader
+//go:generate moq -pkg mocks -out xpub_invoice_service.go ../ XPubInvoiceService
+//go:generate moq -pkg mocks -out exchange_rate_reader.go ../ ExchangeRateReader
+//go:generate moq -pkg mocks -out fee_quote_reader.go ../ FeeQuoteReader
+//go:generate moq -pkg mocks -out fee_service.go ../ FeeService
+//go:generate moq -pkg mocks -out broadcaster.go ../ Broadcaster
+//go:generate moq -pkg mocks -out payment_request_policy.go ../ PaymentRequestPolicy
+//go:generate moq -pkg mocks -out paymail_invoice_service.go ../ PaymailInvoiceService
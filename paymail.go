@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	validator "github.com/theflyingcodr/govalidator"
+)
+
+// PaymailInvoice is an invoice created by a merchant taking payments to their paymail, a
+// payment destination for its amount is requested from the paymail host when the payment
+// request is served.
+type PaymailInvoice struct {
+	PaymentID string    `json:"paymentId" example:"e97970bf-2a88-4bc8-90e6-2f597a80b93d"`
+	Satoshis  uint64    `json:"satoshis" example:"1000"`
+	Memo      string    `json:"memo" example:"invoice 123456"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// PaymailInvoiceCreate is sent by a merchant to create an invoice paid to their paymail.
+type PaymailInvoiceCreate struct {
+	Satoshis uint64 `json:"satoshis" example:"1000"`
+	// Memo is displayed to the customer, it can be at most 50 characters.
+	Memo string `json:"memo" example:"invoice 123456"`
+	// ExpiresAt is when the invoice expires, if empty it expires PAYMAIL_EXPIRY after it is created.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// Validate will ensure the invoice has an amount, a short memo and an expiry in the future.
+func (p PaymailInvoiceCreate) Validate() error {
+	return validator.New().
+		Validate("satoshis", func() error {
+			if p.Satoshis == 0 {
+				return errors.New("value 0 should be greater than 0")
+			}
+			return nil
+		}).
+		Validate("memo", validator.StrLength(p.Memo, 0, 50)).
+		Validate("expiresAt", func() error {
+			if p.ExpiresAt != nil && !p.ExpiresAt.After(time.Now()) {
+				return errors.New("the expiry must be in the future")
+			}
+			return nil
+		}).Err()
+}
+
+// PaymailInvoiceArgs identify a paymail invoice.
+type PaymailInvoiceArgs struct {
+	PaymentID string `param:"paymentID"`
+}
+
+// Validate will ensure the PaymailInvoiceArgs are supplied and correct.
+func (p PaymailInvoiceArgs) Validate() error {
+	return validator.New().
+		Validate("paymentID", validator.NotEmpty(p.PaymentID)).
+		Err()
+}
+
+// PaymailInvoiceService lets merchants create invoices paid to their paymail.
+type PaymailInvoiceService interface {
+	PaymailInvoiceCreate(ctx context.Context, req PaymailInvoiceCreate) (*PaymailInvoice, error)
+	PaymailInvoice(ctx context.Context, args PaymailInvoiceArgs) (*PaymailInvoice, error)
+}
+
+// PaymailInvoiceReaderWriter creates invoices paid to the merchant paymail.
+type PaymailInvoiceReaderWriter interface {
+	// PaymailInvoiceCreate records a new invoice for the amount requested.
+	PaymailInvoiceCreate(ctx context.Context, req PaymailInvoiceCreate) (*PaymailInvoice, error)
+	// PaymailInvoice returns an invoice, a not found error is returned if it doesn't exist.
+	PaymailInvoice(ctx context.Context, args PaymailInvoiceArgs) (*PaymailInvoice, error)
+}
+
+// PaymailStore persists paymail invoices.
+type PaymailStore interface {
+	// PaymailInvoiceCreate records a new invoice.
+	PaymailInvoiceCreate(ctx context.Context, req PaymailInvoice) error
+	// PaymailInvoice returns a recorded invoice, a not found error is returned if it doesn't exist.
+	PaymailInvoice(ctx context.Context, args PaymailInvoiceArgs) (*PaymailInvoice, error)
+}
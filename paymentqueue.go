@@ -0,0 +1,66 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/libsv/go-bk/envelope"
+	"github.com/libsv/go-dpp"
+	validator "github.com/theflyingcodr/govalidator"
+)
+
+// QueuedPayment is a payment for a registered invoice received while the merchant wallet
+// wasn't connected, it is kept until the wallet collects and acknowledges it.
+type QueuedPayment struct {
+	PaymentID string      `json:"paymentId" example:"abc123"`
+	Payment   dpp.Payment `json:"payment"`
+	// TxID is the id of the payment transaction.
+	TxID       string    `json:"txid" example:"d21633ba23f70118185227be58a63527675641ad37967e2aa461559f577aec43"`
+	ReceivedAt time.Time `json:"receivedAt"`
+	// Broadcast is true if the proxy broadcast the transaction, the wallet doesn't need to.
+	Broadcast bool `json:"broadcast"`
+	// Proofs are the merkle proofs received for the transaction while the payment was queued.
+	Proofs []envelope.JSONEnvelope `json:"proofs,omitempty"`
+	// DoubleSpends are the double spends reported for the transaction while the payment was queued.
+	DoubleSpends []DoubleSpend `json:"doubleSpends,omitempty"`
+}
+
+// QueuedPaymentArgs identify a queued payment.
+type QueuedPaymentArgs struct {
+	PaymentID string `param:"paymentID"`
+}
+
+// Validate will ensure the QueuedPaymentArgs are supplied and correct.
+func (q QueuedPaymentArgs) Validate() error {
+	return validator.New().
+		Validate("paymentID", validator.NotEmpty(q.PaymentID)).
+		Err()
+}
+
+// PaymentQueueService lets merchant wallets collect payments queued while they were offline.
+type PaymentQueueService interface {
+	QueuedPayments(ctx context.Context) ([]QueuedPayment, error)
+	QueuedPayment(ctx context.Context, args QueuedPaymentArgs) (*QueuedPayment, error)
+	// QueuedPaymentAck removes a collected payment from the queue, the payment is
+	// marked as rejected if the ack has an error.
+	QueuedPaymentAck(ctx context.Context, args QueuedPaymentArgs, req dpp.PaymentACK) error
+}
+
+// PaymentQueueStore keeps queued payments in a data store.
+type PaymentQueueStore interface {
+	// PaymentEnqueue adds a payment to the queue, a duplicate error is returned
+	// if a payment is already queued for the paymentID.
+	PaymentEnqueue(ctx context.Context, req QueuedPayment) error
+	// QueuedPayments returns the queued payments, oldest first.
+	QueuedPayments(ctx context.Context) ([]QueuedPayment, error)
+	// QueuedPaymentUpdate applies fn to the payment queued for a paymentID, such as when a
+	// proof is received for it, the update is atomic so concurrent updates aren't lost. The
+	// payment is left unchanged if fn returns false, a not found error is returned if none is queued.
+	QueuedPaymentUpdate(ctx context.Context, args QueuedPaymentArgs, fn func(qp *QueuedPayment) bool) error
+	// QueuedPayment returns the payment queued for a paymentID, a not found
+	// error is returned if none is queued.
+	QueuedPayment(ctx context.Context, args QueuedPaymentArgs) (*QueuedPayment, error)
+	// PaymentDequeue removes a payment from the queue, a not found error is
+	// returned if none is queued.
+	PaymentDequeue(ctx context.Context, args QueuedPaymentArgs) error
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	validator "github.com/theflyingcodr/govalidator"
+)
+
+// PaymentState is the state of a payment as seen by the proxy.
+type PaymentState string
+
+// Payment states, a payment moves from requested to paid or rejected, and a paid
+// payment to proven once a merkle proof is received for its transaction.
+// Requested payments are expired once the payment request expires. Payments received while
+// the merchant wallet is offline are queued until the wallet accepts or rejects them.
+const (
+	PaymentStateRequested PaymentState = "requested"
+	PaymentStateQueued    PaymentState = "queued"
+	PaymentStatePaid      PaymentState = "paid"
+	PaymentStateRejected  PaymentState = "rejected"
+	PaymentStateProven    PaymentState = "proven"
+	PaymentStateExpired   PaymentState = "expired"
+)
+
+// PaymentStatus is the current state of a payment.
+type PaymentStatus struct {
+	PaymentID string       `json:"paymentId" example:"abc123"`
+	State     PaymentState `json:"state" example:"paid"`
+	// TxID is the payment transaction, set once paid.
+	TxID string `json:"txid,omitempty" example:"d21633ba23f70118185227be58a63527675641ad37967e2aa461559f577aec43"`
+	// Memo is the reason a payment was rejected.
+	Memo string `json:"memo,omitempty"`
+	// ExpiresAt is when the payment request expires, if it does.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+}
+
+// PaymentStatusArgs identify the payment a status is for.
+type PaymentStatusArgs struct {
+	PaymentID string `param:"paymentID"`
+}
+
+// Validate will ensure the PaymentStatusArgs are supplied and correct.
+func (p PaymentStatusArgs) Validate() error {
+	return validator.New().
+		Validate("paymentID", validator.NotEmpty(p.PaymentID)).
+		Err()
+}
+
+// PaymentStatusService returns the status of payments to customers.
+type PaymentStatusService interface {
+	// PaymentStatus returns the current status of a payment.
+	PaymentStatus(ctx context.Context, args PaymentStatusArgs) (*PaymentStatus, error)
+	// PaymentStatusWatch returns the current status of a payment followed by each change,
+	// the channel is closed when ctx is cancelled.
+	PaymentStatusWatch(ctx context.Context, args PaymentStatusArgs) (<-chan PaymentStatus, error)
+}
+
+// PaymentStatusReader reads payment statuses from a data store.
+type PaymentStatusReader interface {
+	PaymentStatus(ctx context.Context, args PaymentStatusArgs) (*PaymentStatus, error)
+}
+
+// PaymentStatusWriter records a change of payment status, it is called by the payment
+// request, payment and proof flows.
+type PaymentStatusWriter interface {
+	PaymentStatusUpdate(ctx context.Context, req PaymentStatus) error
+}
+
+// PaymentStatusReaderWriter reads and writes payment statuses.
+type PaymentStatusReaderWriter interface {
+	PaymentStatusReader
+	PaymentStatusWriter
+}
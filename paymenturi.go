@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"net/url"
+
+	validator "github.com/theflyingcodr/govalidator"
+)
+
+// Supported payment QR code image formats.
+const (
+	QRFormatPNG = "png"
+	QRFormatSVG = "svg"
+)
+
+// PaymentURL returns the url wallets use to fetch the payment request for a paymentID
+// from this server, found under fqdn.
+func PaymentURL(fqdn, paymentID string) string {
+	u := url.URL{
+		Scheme: "http",
+		Host:   fqdn,
+		Path:   "/api/v1/payment/" + paymentID,
+	}
+	return u.String()
+}
+
+// ProofCallbackURL returns the url broadcasters send proof callbacks for a payment transaction
+// to on this server, found under fqdn.
+func ProofCallbackURL(fqdn, txID, paymentID string) string {
+	u := url.URL{
+		Scheme:   "http",
+		Host:     fqdn,
+		Path:     "/api/v1/proofs/" + txID,
+		RawQuery: url.Values{"i": []string{paymentID}}.Encode(),
+	}
+	return u.String()
+}
+
+// PaymentURIArgs identify the invoice a payment uri is generated for.
+type PaymentURIArgs struct {
+	PaymentID string `param:"paymentID"`
+	// Label and Message are optional BIP-21 parameters, wallets can display them to the customer.
+	Label   string `query:"label"`
+	Message string `query:"message"`
+}
+
+// Validate will ensure the PaymentURIArgs are supplied and correct.
+func (p PaymentURIArgs) Validate() error {
+	return validator.New().
+		Validate("paymentID", validator.NotEmpty(p.PaymentID)).
+		Validate("label", validator.StrLength(p.Label, 0, 100)).
+		Validate("message", validator.StrLength(p.Message, 0, 100)).
+		Err()
+}
+
+// PaymentURI is a link wallets can follow to pay an invoice.
+type PaymentURI struct {
+	// URI is a pay: uri pointing at the payment request, used by wallets that can scan a QR code or follow a link.
+	URI string `json:"uri" example:"pay:?r=http%3A%2F%2Fdpp%3A8445%2Fapi%2Fv1%2Fpayment%2Fabc123"`
+	// PaymentURL is the location of the payment request.
+	PaymentURL string `json:"paymentUrl" example:"http://dpp:8445/api/v1/payment/abc123"`
+}
+
+// PaymentQRArgs identify the invoice a payment QR code is generated for and how to render it.
+type PaymentQRArgs struct {
+	PaymentID string `param:"paymentID"`
+	Label     string `query:"label"`
+	Message   string `query:"message"`
+	// Format is the image format, png or svg, defaults to png.
+	Format string `query:"format"`
+	// Size is the width and height of the image in pixels, defaults to 256.
+	Size int `query:"size"`
+}
+
+// Validate will ensure the PaymentQRArgs are supplied and correct.
+func (p PaymentQRArgs) Validate() error {
+	return validator.New().
+		Validate("paymentID", validator.NotEmpty(p.PaymentID)).
+		Validate("label", validator.StrLength(p.Label, 0, 100)).
+		Validate("message", validator.StrLength(p.Message, 0, 100)).
+		Validate("format", validator.AnyString(p.Format, QRFormatPNG, QRFormatSVG)).
+		Validate("size", validator.BetweenInt(p.Size, 64, 1024)).
+		Err()
+}
+
+// PaymentQR is a rendered QR code containing a PaymentURI.
+type PaymentQR struct {
+	ContentType string
+	Image       []byte
+}
+
+// PaymentURIService generates payment uris and QR codes for invoices.
+type PaymentURIService interface {
+	PaymentURI(ctx context.Context, args PaymentURIArgs) (*PaymentURI, error)
+	PaymentQR(ctx context.Context, args PaymentQRArgs) (*PaymentQR, error)
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/libsv/go-dpp"
+	validator "github.com/theflyingcodr/govalidator"
+)
+
+// PeerChannelPath is the path, relative to the server FQDN, peer channels hosted by the
+// proxy are read from, it is returned in the PaymentACK with the channel id.
+const PeerChannelPath = "api/v1/channel"
+
+// PeerChannel is a channel allocated to a paid invoice, used to relay messages such as
+// receipts and proofs from the merchant to the customer.
+type PeerChannel struct {
+	ID        string
+	PaymentID string
+	// Token is the bearer token the customer must present to read the channel.
+	Token     string
+	CreatedAt time.Time
+}
+
+// PeerChannelMessage is a message sent by the merchant on a peer channel.
+type PeerChannelMessage struct {
+	// Sequence increases with each message on a channel, starting at 1.
+	Sequence    uint64    `json:"sequence" example:"1"`
+	Received    time.Time `json:"received"`
+	ContentType string    `json:"contentType" example:"application/json"`
+	// Payload is the message body, base64 encoded.
+	Payload []byte `json:"payload" swaggertype:"primitive,string" example:"eyJyZWNlaXB0IjoiYWJjMTIzIn0="`
+}
+
+// PeerChannelArgs identify a peer channel and authenticate the reader.
+type PeerChannelArgs struct {
+	ChannelID string `param:"channelID"`
+	// Token is the bearer token presented by the reader.
+	Token string
+	// After only returns messages with a greater sequence, used when polling.
+	After uint64 `query:"after"`
+}
+
+// Validate will ensure the PeerChannelArgs are supplied and correct.
+func (p PeerChannelArgs) Validate() error {
+	return validator.New().
+		Validate("channelID", validator.NotEmpty(p.ChannelID)).
+		Err()
+}
+
+// PeerChannelMessageArgs identify the payment a merchant message is sent for.
+type PeerChannelMessageArgs struct {
+	PaymentID   string `param:"paymentID"`
+	ContentType string
+}
+
+// Validate will ensure the PeerChannelMessageArgs are supplied and correct.
+func (p PeerChannelMessageArgs) Validate() error {
+	return validator.New().
+		Validate("paymentID", validator.NotEmpty(p.PaymentID)).
+		Err()
+}
+
+// PeerChannelCreator allocates peer channels for paid invoices.
+type PeerChannelCreator interface {
+	// PeerChannelCreate returns the channel for a payment, allocating one if the payment
+	// doesn't have one yet, in the form returned in the PaymentACK.
+	PeerChannelCreate(ctx context.Context, args dpp.PaymentCreateArgs) (*dpp.PeerChannelData, error)
+}
+
+// PeerChannelService hosts peer channels, merchants send messages that are stored and
+// delivered to customers.
+type PeerChannelService interface {
+	PeerChannelCreator
+	// PeerChannelMessages returns the messages on a channel after args.After.
+	PeerChannelMessages(ctx context.Context, args PeerChannelArgs) ([]PeerChannelMessage, error)
+	// PeerChannelWatch returns the messages on a channel after args.After followed by each
+	// new message, the channel is closed when ctx is cancelled.
+	PeerChannelWatch(ctx context.Context, args PeerChannelArgs) (<-chan PeerChannelMessage, error)
+	// PeerChannelMessageCreate sends a message on the channel of a payment.
+	PeerChannelMessageCreate(ctx context.Context, args PeerChannelMessageArgs, payload []byte) (*PeerChannelMessage, error)
+}
+
+// PeerChannelStore stores peer channels and their messages.
+type PeerChannelStore interface {
+	// PeerChannelCreate records a new channel.
+	PeerChannelCreate(ctx context.Context, req PeerChannel) error
+	// PeerChannel returns a channel by id, a not found error is returned if it doesn't exist.
+	PeerChannel(ctx context.Context, channelID string) (*PeerChannel, error)
+	// PaymentPeerChannel returns the channel of a payment, a not found error is returned
+	// if the payment doesn't have one.
+	PaymentPeerChannel(ctx context.Context, paymentID string) (*PeerChannel, error)
+	// PeerChannelMessageCreate adds a message to a channel, setting its sequence.
+	PeerChannelMessageCreate(ctx context.Context, channelID string, req PeerChannelMessage) (*PeerChannelMessage, error)
+	// PeerChannelMessages returns the messages on a channel with a sequence greater than after, oldest first.
+	PeerChannelMessages(ctx context.Context, channelID string, after uint64) ([]PeerChannelMessage, error)
+}
@@ -0,0 +1,14 @@
+package server
+
+import (
+	"context"
+
+	"github.com/libsv/go-dpp"
+)
+
+// PaymentRequestPolicy checks payment requests received from merchant wallets before
+// they are served to customers.
+type PaymentRequestPolicy interface {
+	// PolicyCheck returns an unprocessable error describing every rule the payment request breaks.
+	PolicyCheck(ctx context.Context, pr *dpp.PaymentRequest) error
+}
@@ -0,0 +1,322 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	validator "github.com/theflyingcodr/govalidator"
+	"github.com/theflyingcodr/lathos"
+	"github.com/theflyingcodr/lathos/errs"
+)
+
+// ProblemContentType is the media type of error responses, defined in RFC 7807.
+const ProblemContentType = "application/problem+json"
+
+// ProblemTypePrefix is prefixed to the error code to form the type of a problem.
+const ProblemTypePrefix = "urn:dpp-proxy:error:"
+
+// Error codes identify the kind of error in problem responses and socket error messages,
+// they are stable so clients can rely on them when handling errors.
+const (
+	ErrCodeBadRequest       = "bad_request"
+	ErrCodeNotAuthenticated = "not_authenticated"
+	ErrCodePermissionDenied = "permission_denied"
+	ErrCodeNotFound         = "not_found"
+	ErrCodeConflict         = "conflict"
+	ErrCodeExpired          = "expired"
+	ErrCodeTooLarge         = "request_too_large"
+	ErrCodeUnprocessable    = "unprocessable"
+	ErrCodeRateLimited      = "rate_limited"
+	ErrCodeInternal         = "internal"
+	ErrCodeWallet           = "wallet_error"
+	ErrCodeUnavailable      = "unavailable"
+)
+
+// errCodeStatuses is the http status returned for each error code.
+var errCodeStatuses = map[string]int{
+	ErrCodeBadRequest:       http.StatusBadRequest,
+	ErrCodeNotAuthenticated: http.StatusUnauthorized,
+	ErrCodePermissionDenied: http.StatusForbidden,
+	ErrCodeNotFound:         http.StatusNotFound,
+	ErrCodeConflict:         http.StatusConflict,
+	ErrCodeExpired:          http.StatusGone,
+	ErrCodeTooLarge:         http.StatusRequestEntityTooLarge,
+	ErrCodeUnprocessable:    http.StatusUnprocessableEntity,
+	ErrCodeRateLimited:      http.StatusTooManyRequests,
+	ErrCodeInternal:         http.StatusInternalServerError,
+	ErrCodeWallet:           http.StatusBadGateway,
+	ErrCodeUnavailable:      http.StatusServiceUnavailable,
+}
+
+// Problem is returned to clients when a request fails, it is an RFC 7807 problem
+// detail with the error code and validation errors added.
+type Problem struct {
+	Type     string `json:"type" example:"urn:dpp-proxy:error:not_found"`
+	Title    string `json:"title" example:"Not Found"`
+	Status   int    `json:"status" example:"404"`
+	Detail   string `json:"detail,omitempty" example:"invoice not found"`
+	Instance string `json:"instance,omitempty" example:"/api/v1/payment/abc123"`
+	Code     string `json:"code" example:"not_found"`
+	ID       string `json:"id,omitempty" example:"e97970bf-2a88-4bc8-90e6-2f597a80b93d"`
+	// RetryAfter is the number of seconds to wait before retrying a rate limited request.
+	RetryAfter int                     `json:"retryAfter,omitempty" example:"30"`
+	Errors     validator.ErrValidation `json:"errors,omitempty" swaggertype:"object"`
+}
+
+// NewProblem returns the problem describing err. The detail of internal errors is not
+// returned, as it can contain information about the proxy or wallet, they should be
+// logged instead.
+func NewProblem(err error) Problem {
+	var valErr validator.ErrValidation
+	if errors.As(err, &valErr) {
+		p := newProblem(ErrCodeBadRequest, "the request is invalid")
+		p.Errors = valErr
+		return p
+	}
+	var clientErr lathos.ClientError
+	if errors.As(err, &clientErr) {
+		p := newProblem(clientErrCode(err), clientErr.Detail())
+		p.ID = clientErr.ID()
+		var rlErr ErrRateLimited
+		if errors.As(err, &rlErr) && rlErr.RetryAfter > 0 {
+			p.RetryAfter = int(rlErr.RetryAfter.Seconds())
+		}
+		return p
+	}
+	// errors the wallet returned that we couldn't classify.
+	var walletErr ClientError
+	if errors.As(err, &walletErr) {
+		p := newProblem(ErrCodeWallet, walletErr.Message)
+		p.ID = walletErr.ID
+		return p
+	}
+	return newProblem(ErrCodeInternal, "an unexpected error occurred")
+}
+
+// Err returns the error matching the kind of the problem, so a problem returned by a
+// wallet can be returned to the client. Problems of an unknown kind are returned as a ClientError.
+func (p Problem) Err() error {
+	status := p.Status
+	if s, ok := errCodeStatuses[p.Code]; ok && status == 0 {
+		status = s
+	}
+	code := p.Code
+	if code == "" {
+		code = strconv.Itoa(status)
+	}
+	if err := StatusError(status, code, p.Detail, time.Duration(p.RetryAfter)*time.Second); err != nil {
+		return err
+	}
+	return ClientError{ID: p.ID, Code: code, Title: p.Title, Message: p.Detail}
+}
+
+// StatusError returns an error of the kind matching the http status, so errors
+// from wallets are returned to clients with the same status. retryAfter is only
+// used for 429 errors. If the status has no matching kind nil is returned.
+func StatusError(status int, code, detail string, retryAfter time.Duration) error {
+	switch status {
+	case http.StatusBadRequest:
+		return NewErrBadRequest(code, detail)
+	case http.StatusUnauthorized:
+		return errs.NewErrNotAuthenticated(code, detail)
+	case http.StatusForbidden:
+		return errs.NewErrNotAuthorised(code, detail)
+	case http.StatusNotFound:
+		return errs.NewErrNotFound(code, detail)
+	case http.StatusConflict:
+		return errs.NewErrDuplicate(code, detail)
+	case http.StatusGone:
+		return NewErrExpired(code, detail)
+	case http.StatusRequestEntityTooLarge:
+		return NewErrTooLarge(code, detail)
+	case http.StatusUnprocessableEntity:
+		return errs.NewErrUnprocessable(code, detail)
+	case http.StatusTooManyRequests:
+		return NewErrRateLimited(code, detail, retryAfter)
+	case http.StatusServiceUnavailable:
+		return errs.NewErrNotAvailable(code, detail)
+	}
+	return nil
+}
+
+func newProblem(code, detail string) Problem {
+	status := errCodeStatuses[code]
+	return Problem{
+		Type:   ProblemTypePrefix + code,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// clientErrCode returns the error code for a lathos client error, errors
+// of an unknown kind are treated as bad requests.
+func clientErrCode(err error) string {
+	switch {
+	case lathos.IsNotAuthenticated(err):
+		return ErrCodeNotAuthenticated
+	case lathos.IsNotAuthorised(err):
+		return ErrCodePermissionDenied
+	case lathos.IsNotFound(err):
+		return ErrCodeNotFound
+	case lathos.IsDuplicate(err):
+		return ErrCodeConflict
+	case IsExpired(err):
+		return ErrCodeExpired
+	case IsTooLarge(err):
+		return ErrCodeTooLarge
+	case lathos.IsCannotProcess(err):
+		return ErrCodeUnprocessable
+	case IsRateLimited(err):
+		return ErrCodeRateLimited
+	case lathos.IsUnavailable(err):
+		return ErrCodeUnavailable
+	}
+	return ErrCodeBadRequest
+}
+
+// clientErr implements lathos.ClientError for the error kinds lathos doesn't provide.
+type clientErr struct {
+	id     string
+	code   string
+	title  string
+	detail string
+}
+
+// ID returns the unique id of the error.
+func (e clientErr) ID() string {
+	return e.id
+}
+
+// Code returns the code identifying the error.
+func (e clientErr) Code() string {
+	return e.code
+}
+
+// Title returns the title of the kind of error.
+func (e clientErr) Title() string {
+	return e.title
+}
+
+// Detail returns the human readable detail of the error.
+func (e clientErr) Detail() string {
+	return e.detail
+}
+
+func (e clientErr) Error() string {
+	return e.title + ": " + e.detail
+}
+
+// ErrBadRequest is returned when a request is invalid but there are no field errors to return.
+type ErrBadRequest struct {
+	clientErr
+}
+
+// NewErrBadRequest will create and return a new BadRequest error.
+func NewErrBadRequest(code, detail string) ErrBadRequest {
+	return ErrBadRequest{clientErr{id: uuid.NewString(), code: code, title: "Bad request", detail: detail}}
+}
+
+// BadRequest implements the lathos.BadRequest interface.
+func (e ErrBadRequest) BadRequest() bool {
+	return true
+}
+
+// ErrExpired is returned when a resource is no longer kept by the proxy.
+type ErrExpired struct {
+	clientErr
+}
+
+// NewErrExpired will create and return a new Expired error.
+func NewErrExpired(code, detail string) ErrExpired {
+	return ErrExpired{clientErr{id: uuid.NewString(), code: code, title: "Gone", detail: detail}}
+}
+
+// Expired is used in error type checks.
+func (e ErrExpired) Expired() bool {
+	return true
+}
+
+// IsExpired returns true if err is an Expired error.
+func IsExpired(err error) bool {
+	var t interface{ Expired() bool }
+	return errors.As(err, &t)
+}
+
+// ErrTooLarge is returned when a request body is larger than allowed.
+type ErrTooLarge struct {
+	clientErr
+}
+
+// NewErrTooLarge will create and return a new TooLarge error.
+func NewErrTooLarge(code, detail string) ErrTooLarge {
+	return ErrTooLarge{clientErr{id: uuid.NewString(), code: code, title: "Request too large", detail: detail}}
+}
+
+// TooLarge is used in error type checks.
+func (e ErrTooLarge) TooLarge() bool {
+	return true
+}
+
+// IsTooLarge returns true if err is a TooLarge error.
+func IsTooLarge(err error) bool {
+	var t interface{ TooLarge() bool }
+	return errors.As(err, &t)
+}
+
+// ErrRateLimited is returned when too many requests have been sent, RetryAfter
+// is the time to wait before retrying, it is 0 if not known.
+type ErrRateLimited struct {
+	clientErr
+	RetryAfter time.Duration
+}
+
+// NewErrRateLimited will create and return a new RateLimited error.
+func NewErrRateLimited(code, detail string, retryAfter time.Duration) ErrRateLimited {
+	return ErrRateLimited{
+		clientErr:  clientErr{id: uuid.NewString(), code: code, title: "Too many requests", detail: detail},
+		RetryAfter: retryAfter,
+	}
+}
+
+// RateLimited is used in error type checks.
+func (e ErrRateLimited) RateLimited() bool {
+	return true
+}
+
+// IsRateLimited returns true if err is a RateLimited error.
+func IsRateLimited(err error) bool {
+	var t interface{ RateLimited() bool }
+	return errors.As(err, &t)
+}
+
+// ErrWalletOffline is returned when the merchant wallet isn't connected, so unlike other
+// unavailable errors the wallet can't have received the request.
+type ErrWalletOffline struct {
+	clientErr
+}
+
+// NewErrWalletOffline will create and return a new WalletOffline error.
+func NewErrWalletOffline(code, detail string) ErrWalletOffline {
+	return ErrWalletOffline{clientErr{id: uuid.NewString(), code: code, title: "Not available", detail: detail}}
+}
+
+// Unavailable implements the lathos.Unavailable interface.
+func (e ErrWalletOffline) Unavailable() bool {
+	return true
+}
+
+// WalletOffline is used in error type checks.
+func (e ErrWalletOffline) WalletOffline() bool {
+	return true
+}
+
+// IsWalletOffline returns true if err is a WalletOffline error.
+func IsWalletOffline(err error) bool {
+	var t interface{ WalletOffline() bool }
+	return errors.As(err, &t)
+}
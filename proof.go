@@ -0,0 +1,72 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/libsv/go-bk/envelope"
+	validator "github.com/theflyingcodr/govalidator"
+)
+
+// Proof is a merkle proof accepted by the proxy, kept so customer wallets can
+// collect the proof for their payment later.
+type Proof struct {
+	TxID      string `json:"txid" example:"d21633ba23f70118185227be58a63527675641ad37967e2aa461559f577aec43"`
+	PaymentID string `json:"paymentId" example:"abc123"`
+	// Envelope is the proof as relayed to the merchant wallet, signed if the miner signed it.
+	Envelope *envelope.JSONEnvelope `json:"envelope"`
+	// Unverified is set to the reason the proof failed verification against the block
+	// headers, if it was accepted regardless.
+	Unverified string    `json:"unverified,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// ProofArgs identify the transaction a proof is for.
+type ProofArgs struct {
+	TxID string `param:"txid"`
+}
+
+// Validate will ensure the ProofArgs are supplied and correct.
+func (p ProofArgs) Validate() error {
+	return validator.New().
+		Validate("txid", validator.StrLength(p.TxID, 64, 64)).
+		Err()
+}
+
+// PaymentProofsArgs identify the payment proofs are for.
+type PaymentProofsArgs struct {
+	PaymentID string `param:"paymentID"`
+}
+
+// Validate will ensure the PaymentProofsArgs are supplied and correct.
+func (p PaymentProofsArgs) Validate() error {
+	return validator.New().
+		Validate("paymentID", validator.NotEmpty(p.PaymentID)).
+		Err()
+}
+
+// ProofService returns proofs kept by the proxy.
+type ProofService interface {
+	// Proof returns the latest proof for a transaction.
+	Proof(ctx context.Context, args ProofArgs) (*Proof, error)
+	// PaymentProofs returns the latest proof of each transaction paying a payment.
+	PaymentProofs(ctx context.Context, args PaymentProofsArgs) ([]Proof, error)
+}
+
+// ProofReader reads proofs from a data store.
+type ProofReader interface {
+	Proof(ctx context.Context, args ProofArgs) (*Proof, error)
+	PaymentProofs(ctx context.Context, args PaymentProofsArgs) ([]Proof, error)
+}
+
+// ProofWriter writes proofs to a data store, a proof replaces any existing
+// proof for the transaction, for example after a reorg.
+type ProofWriter interface {
+	ProofCreate(ctx context.Context, req Proof) error
+}
+
+// ProofReaderWriter reads and writes proofs.
+type ProofReaderWriter interface {
+	ProofReader
+	ProofWriter
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+
+	"github.com/libsv/go-bk/envelope"
+	"github.com/libsv/go-dpp"
+)
+
+// Reasons given by mAPI for sending a callback.
+const (
+	CallbackMerkleProof        = "merkleProof"
+	CallbackDoubleSpend        = "doubleSpend"
+	CallbackDoubleSpendAttempt = "doubleSpendAttempt"
+)
+
+// MIMEMerkleProof is the content type of a bare TSC format merkle proof posted as a proof callback.
+const MIMEMerkleProof = "application/vnd.tsc.merkleproof+json"
+
+// ProofCallbackArgs identify the transaction a callback is for and how it is encoded.
+type ProofCallbackArgs struct {
+	dpp.ProofCreateArgs
+	// ContentType of the callback body, this selects the parser used.
+	ContentType string
+	// Token is the bearer token presented with the callback, it must be one of the
+	// proof callback tokens supplied with the payment.
+	Token string
+}
+
+// ProofCallback is a parsed and validated callback received from a miner or broadcaster.
+type ProofCallback struct {
+	// Reason is the type of callback, only one of Proof or DoubleSpend is set to match.
+	Reason      string
+	Proof       *dpp.ProofWrapper
+	DoubleSpend *DoubleSpend
+	// Envelope is the signed envelope the callback was received in, it is nil
+	// if the callback was unsigned.
+	Envelope *envelope.JSONEnvelope
+}
+
+// DoubleSpend is sent to merchant wallets when a miner reports a transaction paying
+// them has been, or may be, double spent.
+type DoubleSpend struct {
+	TxID string `json:"txid" example:"d21633ba23f70118185227be58a63527675641ad37967e2aa461559f577aec43"`
+	// Reason is either doubleSpend or doubleSpendAttempt, or conflictingInputs if raised by the proxy.
+	Reason string `json:"callbackReason" example:"doubleSpend"`
+	// DoubleSpendTxID is the id of the competing transaction.
+	DoubleSpendTxID string `json:"doubleSpendTxId" example:"f1f8d3cb5bd0ab6fbc1b4e3a4a8cfa3e9e6f1a9a7d39c2c8d5c4d3b2a1a0f0e0"`
+	// RawTx is the competing transaction, if supplied.
+	RawTx       string `json:"rawTx,omitempty"`
+	BlockHash   string `json:"blockHash,omitempty"`
+	BlockHeight uint64 `json:"blockHeight,omitempty"`
+	MinerID     string `json:"minerId,omitempty"`
+	// Envelope is the signed callback as received, so wallets can verify the miner signature.
+	Envelope *envelope.JSONEnvelope `json:"envelope,omitempty"`
+}
+
+// ProofParser decodes and validates a proof callback body.
+type ProofParser interface {
+	// Parse will decode the body of a callback for the transaction in args, returning
+	// a validation error if it isn't correct.
+	Parse(ctx context.Context, args dpp.ProofCreateArgs, body []byte) (*ProofCallback, error)
+}
+
+// ProofCallbackService accepts proof callbacks in the formats sent by miners and broadcasters.
+type ProofCallbackService interface {
+	// ProofCallback parses the callback using the parser for its content type and forwards
+	// merkle proofs and double spends to the merchant wallet.
+	ProofCallback(ctx context.Context, args ProofCallbackArgs, body []byte) error
+}
+
+// ProofVerifier checks a merkle proof links a transaction to a block on the best chain.
+type ProofVerifier interface {
+	// VerifyProof returns a validation error if the proof is invalid or its block
+	// isn't on the best chain.
+	VerifyProof(ctx context.Context, proof *dpp.ProofWrapper) error
+}
+
+// DoubleSpendWriter is used to send double spend notifications to a merchant wallet.
+type DoubleSpendWriter interface {
+	DoubleSpendCreate(ctx context.Context, args dpp.ProofCreateArgs, req DoubleSpend) error
+}
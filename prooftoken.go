@@ -0,0 +1,43 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/libsv/go-dpp"
+)
+
+// ProofToken holds the proof callback tokens supplied with a payment, proof callbacks
+// for the payment transaction must present one of them as a bearer token.
+type ProofToken struct {
+	TxID      string
+	PaymentID string
+	Tokens    []string
+	CreatedAt time.Time
+}
+
+// ProofTokenReader reads proof tokens from a data store.
+type ProofTokenReader interface {
+	// ProofToken returns the tokens recorded for a transaction, a not found error
+	// is returned if no payment has been seen for the txid.
+	ProofToken(ctx context.Context, txID string) (*ProofToken, error)
+}
+
+// ProofTokenWriter writes proof tokens to a data store.
+type ProofTokenWriter interface {
+	// ProofTokenCreate records the tokens for a transaction, a duplicate error is returned if
+	// tokens of another payment, or other tokens, are already recorded for it. Recording tokens
+	// that are already recorded is ignored.
+	ProofTokenCreate(ctx context.Context, req ProofToken) error
+}
+
+// ProofTokenReaderWriter combines the reader and writer.
+type ProofTokenReaderWriter interface {
+	ProofTokenReader
+	ProofTokenWriter
+}
+
+// ProofTokenService records the proof callback tokens supplied with payments.
+type ProofTokenService interface {
+	ProofTokenCreate(ctx context.Context, args dpp.PaymentCreateArgs, req dpp.Payment) error
+}
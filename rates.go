@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"math"
+	"regexp"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// satoshisPerBSV is used to convert rates, which are the price of one BSV, to satoshis.
+	satoshisPerBSV = 1e8
+	// maxSatoshis is the supply of BSV, no amount can be converted to more.
+	maxSatoshis = 21e6 * satoshisPerBSV
+)
+
+var reCurrency = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// FiatAmount is an amount in a fiat currency.
+type FiatAmount struct {
+	// Currency is an ISO 4217 currency code.
+	Currency string  `json:"currency" example:"USD"`
+	Amount   float64 `json:"amount" example:"12.5"`
+}
+
+// Satoshis returns the amount in satoshis at rate, rounded to the nearest satoshi. An error is
+// returned if the amount doesn't convert to between 1 satoshi and the supply of BSV, such as
+// when the rate is 0, negative or not a number.
+func (f FiatAmount) Satoshis(rate float64) (uint64, error) {
+	sats := math.Round(f.Amount / rate * satoshisPerBSV)
+	if math.IsNaN(sats) || math.IsInf(sats, 0) || sats < 1 || sats > maxSatoshis {
+		return 0, errors.Errorf("%v %s can't be converted to satoshis at rate %v", f.Amount, f.Currency, rate)
+	}
+	return uint64(sats), nil
+}
+
+// ExchangeRate is the price of one BSV in a fiat currency.
+type ExchangeRate struct {
+	Currency string    `json:"currency" example:"USD"`
+	Rate     float64   `json:"rate" example:"50.25"`
+	Source   string    `json:"source" example:"http"`
+	ReadAt   time.Time `json:"readAt"`
+}
+
+// ExchangeRateArgs identify the currency an exchange rate is read for.
+type ExchangeRateArgs struct {
+	Currency string
+}
+
+// ExchangeRateReader reads exchange rates, such as from a price feed.
+type ExchangeRateReader interface {
+	// ExchangeRate returns the rate of a currency, a not found error is returned if the
+	// currency isn't supported.
+	ExchangeRate(ctx context.Context, args ExchangeRateArgs) (*ExchangeRate, error)
+}
+
+// Quote is a fiat amount converted to satoshis, the satoshi amount is locked
+// until the quote expires so customers pay what they were shown.
+type Quote struct {
+	Fiat     FiatAmount `json:"fiat"`
+	Rate     float64    `json:"rate" example:"50.25"`
+	Source   string     `json:"source" example:"http"`
+	Satoshis uint64     `json:"satoshis" example:"24875622"`
+	QuotedAt time.Time  `json:"quotedAt"`
+	// LockedUntil is when the quote expires, the payment request expires with it.
+	LockedUntil time.Time `json:"lockedUntil"`
+}
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/libsv/go-bt/v2/bscript"
+	validator "github.com/theflyingcodr/govalidator"
+)
+
+// Refund destination types.
+const (
+	RefundTypePaymail = "paymail"
+	RefundTypeScript  = "script"
+)
+
+// Refund is the refund destination supplied by a customer with a payment, recorded
+// so the merchant can later return funds for the payment.
+type Refund struct {
+	PaymentID string
+	// TxID is the id of the payment transaction being refunded.
+	TxID string
+	// RefundTo is the paymail, address or hex locking script supplied in the payment.
+	RefundTo  string
+	CreatedAt time.Time
+}
+
+// RefundArgs identify the payment a refund is for.
+type RefundArgs struct {
+	PaymentID string `param:"paymentID"`
+}
+
+// Validate will ensure the RefundArgs are supplied and correct.
+func (r RefundArgs) Validate() error {
+	return validator.New().
+		Validate("paymentID", validator.NotEmpty(r.PaymentID)).
+		Err()
+}
+
+// RefundInstructions tell a merchant wallet where to send the funds when refunding a payment.
+type RefundInstructions struct {
+	PaymentID string `json:"paymentId" example:"abc123"`
+	// TxID is the id of the payment transaction being refunded.
+	TxID string `json:"txid" example:"d21633ba23f70118185227be58a63527675641ad37967e2aa461559f577aec43"`
+	// Type is either paymail or script, paymail refunds should be paid using the
+	// paymail's payment destination capability.
+	Type    string `json:"type" example:"script"`
+	Paymail string `json:"paymail,omitempty" example:"me@paymail.com"`
+	// LockingScript is the output script refunds are paid to when the type is script,
+	// refund addresses are converted to a P2PKH script.
+	LockingScript *bscript.Script `json:"lockingScript,omitempty" swaggertype:"primitive,string" example:"76a91455b61be43392125d127f1780fb038437cd67ef9c88ac"`
+	// Memo should be added to the refund so the customer can identify it.
+	Memo      string    `json:"memo" example:"refund for payment abc123"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// RefundService builds refund instructions for merchants.
+type RefundService interface {
+	RefundInstructions(ctx context.Context, args RefundArgs) (*RefundInstructions, error)
+}
+
+// RefundReader reads refunds from a data store.
+type RefundReader interface {
+	// Refund returns the refund recorded for a payment, an Expired error is returned
+	// if the payment had no refund destination or it is no longer kept.
+	Refund(ctx context.Context, args RefundArgs) (*Refund, error)
+}
+
+// RefundWriter writes refunds to a data store.
+type RefundWriter interface {
+	// RefundCreate records the refund destination for a payment, replacing any
+	// already recorded if the payment is resubmitted.
+	RefundCreate(ctx context.Context, req Refund) error
+}
+
+// RefundReaderWriter combines the reader and writer.
+type RefundReaderWriter interface {
+	RefundReader
+	RefundWriter
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+)
+
+// audit hash chains payment traffic events before writing
+// them to an append only audit sink.
+type audit struct {
+	mu   sync.Mutex
+	str  server.AuditStore
+	last *server.AuditEntry
+}
+
+// NewAudit will setup and return a new audit logger, the last entry is read from
+// the store so the chain continues from where it previously ended.
+func NewAudit(ctx context.Context, str server.AuditStore) (*audit, error) {
+	last, err := str.AuditLast(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read last audit entry")
+	}
+	return &audit{
+		str:  str,
+		last: last,
+	}, nil
+}
+
+// AuditLog will add the event to the end of the chain and write it to the audit sink.
+func (a *audit) AuditLog(ctx context.Context, evt server.AuditEvent) error {
+	payload, err := json.Marshal(evt.Payload)
+	if err != nil {
+		return errors.Wrapf(err, "failed to encode audit %s payload", evt.Type)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	entry := server.AuditEntry{
+		Timestamp: time.Now().UTC(),
+		Type:      evt.Type,
+		PaymentID: evt.PaymentID,
+		TxID:      evt.TxID,
+		Payload:   payload,
+	}
+	if a.last != nil {
+		entry.Sequence = a.last.Sequence + 1
+		entry.PrevHash = a.last.Hash
+	}
+	entry.Hash = entry.ComputeHash()
+	if err := a.str.AuditWrite(ctx, entry); err != nil {
+		return errors.Wrapf(err, "failed to write audit entry %d", entry.Sequence)
+	}
+	a.last = &entry
+	return nil
+}
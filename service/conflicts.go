@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-dpp"
+	"github.com/pkg/errors"
+	"github.com/theflyingcodr/lathos/errs"
+	"go.opentelemetry.io/otel/attribute"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/config"
+	"github.com/bitcoin-sv/dpp-proxy/log"
+	"github.com/bitcoin-sv/dpp-proxy/tracing"
+)
+
+// conflictWarning is added to the ack memo of a flagged payment.
+const conflictWarning = "warning: the transaction spends outputs already used to pay another invoice"
+
+type conflicts struct {
+	l          log.Logger
+	paymentWtr dpp.PaymentWriter
+	store      server.SpendStore
+	dsWtr      server.DoubleSpendWriter
+	cfg        *config.Conflicts
+}
+
+// NewConflicts will setup and return a payment writer that reserves the outputs spent by payments
+// in store before sending them to paymentWtr, releasing them if the payment fails. Payments
+// spending an output already spent by a payment for another invoice, within the retention window,
+// are flagged or rejected as set in cfg. Once a flagged payment is accepted the merchant wallets
+// of both invoices are sent a double spend with dsWtr.
+func NewConflicts(l log.Logger, paymentWtr dpp.PaymentWriter, store server.SpendStore, dsWtr server.DoubleSpendWriter,
+	cfg *config.Conflicts) *conflicts {
+	return &conflicts{
+		l:          l,
+		paymentWtr: paymentWtr,
+		store:      store,
+		dsWtr:      dsWtr,
+		cfg:        cfg,
+	}
+}
+
+// PaymentCreate will check the payment transaction for conflicting inputs before sending it to
+// the merchant wallet, flagged payments are acked with a warning.
+func (c *conflicts) PaymentCreate(ctx context.Context, args dpp.PaymentCreateArgs, req dpp.Payment) (*dpp.PaymentACK, error) {
+	ctx, span := tracing.StartSpan(ctx, "service.conflicts.PaymentCreate", attribute.String("paymentID", args.PaymentID))
+	defer span.End()
+	if req.RawTx == nil {
+		return c.paymentWtr.PaymentCreate(ctx, args, req)
+	}
+	tx, err := bt.NewTxFromString(*req.RawTx)
+	if err != nil {
+		// the wallet reports transactions it can't read.
+		return c.paymentWtr.PaymentCreate(ctx, args, req)
+	}
+	now := time.Now().UTC()
+	since := now.Add(-c.cfg.Retention)
+	if err := c.store.SpendsPrune(ctx, since); err != nil {
+		c.l.Errorf(err, "failed to prune spends")
+	}
+	res, err := c.store.SpendsReserve(ctx, server.SpendsReserveArgs{
+		Spends:    spends(args.PaymentID, tx, now),
+		Since:     since,
+		Exclusive: c.cfg.Action == config.ConflictsActionReject,
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, errors.WithMessagef(err, "failed to check inputs of paymentID '%s'", args.PaymentID)
+	}
+	if len(res.Conflicting) > 0 {
+		c.l.Infof("payment for paymentID %s spends outputs already used to pay another invoice", args.PaymentID)
+		// the other merchants aren't told of rejected payments, their transactions aren't checked
+		// by a wallet so anyone could send one spending a known output.
+		if c.cfg.Action == config.ConflictsActionReject {
+			return nil, errs.NewErrDuplicate("409", "the transaction spends outputs already used to pay another invoice")
+		}
+	}
+	ack, err := c.paymentWtr.PaymentCreate(ctx, args, req)
+	if err != nil || ack.Error != 0 {
+		// the outputs weren't spent, so can be used to pay another invoice.
+		if err := c.store.SpendsRelease(ctx, res.Reserved); err != nil {
+			c.l.Errorf(err, "failed to release spends for paymentID %s", args.PaymentID)
+		}
+		return ack, err
+	}
+	if len(res.Conflicting) > 0 {
+		ack.Memo = withWarning(ack.Memo)
+		c.notify(ctx, tx, res.Conflicting)
+		c.notifyPayment(ctx, args.PaymentID, tx, res.Conflicting)
+	}
+	return ack, nil
+}
+
+// notify sends a double spend to the invoices already paid with the conflicting spends,
+// failures are logged as the payment has been processed.
+func (c *conflicts) notify(ctx context.Context, tx *bt.Tx, conflicting []server.Spend) {
+	seen := map[string]struct{}{}
+	for _, s := range conflicting {
+		if _, ok := seen[s.TxID]; ok {
+			continue
+		}
+		seen[s.TxID] = struct{}{}
+		if err := c.dsWtr.DoubleSpendCreate(ctx, dpp.ProofCreateArgs{TxID: s.TxID, PaymentReference: s.PaymentID}, server.DoubleSpend{
+			TxID:            s.TxID,
+			Reason:          server.CallbackConflictingInputs,
+			DoubleSpendTxID: tx.TxID(),
+			RawTx:           tx.String(),
+		}); err != nil {
+			c.l.Errorf(err, "failed to send conflicting inputs to paymentID %s", s.PaymentID)
+		}
+	}
+}
+
+// notifyPayment sends a double spend to the invoice of an accepted payment, naming the first
+// transaction it conflicts with, failures are logged as the payment has been processed.
+func (c *conflicts) notifyPayment(ctx context.Context, paymentID string, tx *bt.Tx, conflicting []server.Spend) {
+	if err := c.dsWtr.DoubleSpendCreate(ctx, dpp.ProofCreateArgs{TxID: tx.TxID(), PaymentReference: paymentID}, server.DoubleSpend{
+		TxID:            tx.TxID(),
+		Reason:          server.CallbackConflictingInputs,
+		DoubleSpendTxID: conflicting[0].TxID,
+	}); err != nil {
+		c.l.Errorf(err, "failed to send conflicting inputs to paymentID %s", paymentID)
+	}
+}
+
+// spends returns the outputs spent by the payment transaction.
+func spends(paymentID string, tx *bt.Tx, spentAt time.Time) []server.Spend {
+	ss := make([]server.Spend, 0, len(tx.Inputs))
+	for _, in := range tx.Inputs {
+		ss = append(ss, server.Spend{
+			Outpoint:  fmt.Sprintf("%s:%d", in.PreviousTxIDStr(), in.PreviousTxOutIndex),
+			PaymentID: paymentID,
+			TxID:      tx.TxID(),
+			SpentAt:   spentAt,
+		})
+	}
+	return ss
+}
+
+// withWarning adds the conflicting inputs warning to an ack memo.
+func withWarning(memo string) string {
+	if memo == "" {
+		return conflictWarning
+	}
+	return memo + "; " + conflictWarning
+}
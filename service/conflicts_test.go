@@ -0,0 +1,226 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-dpp"
+	dppMocks "github.com/libsv/go-dpp/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/theflyingcodr/lathos/errs"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/config"
+	"github.com/bitcoin-sv/dpp-proxy/data/memory"
+	"github.com/bitcoin-sv/dpp-proxy/log"
+	"github.com/bitcoin-sv/dpp-proxy/mocks"
+	"github.com/bitcoin-sv/dpp-proxy/service"
+)
+
+// spendingTx returns a transaction spending the outputs of prevTxID, paying satoshis to the invoice script.
+func spendingTx(t *testing.T, satoshis uint64, prevTxID string, vouts ...uint32) *bt.Tx {
+	tx := payingTx(t, satoshis)
+	for _, vout := range vouts {
+		in := &bt.Input{PreviousTxOutIndex: vout}
+		assert.NoError(t, in.PreviousTxIDAddStr(prevTxID))
+		tx.Inputs = append(tx.Inputs, in)
+	}
+	return tx
+}
+
+func TestConflicts_PaymentCreate(t *testing.T) {
+	const prevTxID = "d21633ba23f70118185227be58a63527675641ad37967e2aa461559f577aec43"
+	tests := map[string]struct {
+		action     string
+		paymentID  string
+		retention  time.Duration
+		vouts      []uint32
+		walletErr  error
+		expMemo    string
+		expNotify  int
+		expPayment bool
+		expErr     error
+	}{
+		"payment spending other outputs is accepted": {
+			action:     config.ConflictsActionReject,
+			paymentID:  "def456",
+			retention:  time.Hour,
+			vouts:      []uint32{1},
+			expMemo:    "thanks",
+			expPayment: true,
+		},
+		"payment resent for the same invoice is accepted": {
+			action:     config.ConflictsActionReject,
+			paymentID:  "abc123",
+			retention:  time.Hour,
+			vouts:      []uint32{0},
+			expMemo:    "thanks",
+			expPayment: true,
+		},
+		"conflicting payment is flagged": {
+			action:     config.ConflictsActionFlag,
+			paymentID:  "def456",
+			retention:  time.Hour,
+			vouts:      []uint32{0, 1},
+			expMemo:    "thanks; warning: the transaction spends outputs already used to pay another invoice",
+			expNotify:  2,
+			expPayment: true,
+		},
+		"conflicting payment is rejected": {
+			action:    config.ConflictsActionReject,
+			paymentID: "def456",
+			retention: time.Hour,
+			vouts:     []uint32{0},
+			expErr:    errors.New("Item already exists: the transaction spends outputs already used to pay another invoice"),
+		},
+		"conflicting payment the wallet rejects isn't notified": {
+			action:     config.ConflictsActionFlag,
+			paymentID:  "def456",
+			retention:  time.Hour,
+			vouts:      []uint32{0},
+			walletErr:  errs.NewErrUnprocessable("422", "invalid transaction"),
+			expPayment: true,
+			expErr:     errors.New("Unprocessable: invalid transaction"),
+		},
+		"spends outside the retention window are ignored": {
+			action:     config.ConflictsActionReject,
+			paymentID:  "def456",
+			retention:  time.Nanosecond,
+			vouts:      []uint32{0},
+			expMemo:    "thanks",
+			expPayment: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			paymentWtr := &dppMocks.PaymentWriterMock{
+				PaymentCreateFunc: func(ctx context.Context, args dpp.PaymentCreateArgs, req dpp.Payment) (*dpp.PaymentACK, error) {
+					if args.PaymentID == test.paymentID && test.walletErr != nil {
+						return nil, test.walletErr
+					}
+					return &dpp.PaymentACK{Memo: "thanks"}, nil
+				},
+			}
+			var notified []server.DoubleSpend
+			dsWtr := &mocks.DoubleSpendWriterMock{
+				DoubleSpendCreateFunc: func(ctx context.Context, args dpp.ProofCreateArgs, req server.DoubleSpend) error {
+					assert.Equal(t, args.TxID, req.TxID)
+					assert.Equal(t, server.CallbackConflictingInputs, req.Reason)
+					notified = append(notified, req)
+					return nil
+				},
+			}
+			svc := service.NewConflicts(log.Noop{}, paymentWtr, memory.NewSpends(), dsWtr,
+				&config.Conflicts{Enabled: true, Action: test.action, Retention: test.retention})
+
+			first := spendingTx(t, 1000, prevTxID, 0)
+			_, err := svc.PaymentCreate(context.Background(), dpp.PaymentCreateArgs{PaymentID: "abc123"}, queuedPayment(first))
+			assert.NoError(t, err)
+
+			tx := spendingTx(t, 2000, prevTxID, test.vouts...)
+			ack, err := svc.PaymentCreate(context.Background(), dpp.PaymentCreateArgs{PaymentID: test.paymentID}, queuedPayment(tx))
+			assert.Len(t, notified, test.expNotify)
+			if test.expPayment {
+				assert.Len(t, paymentWtr.PaymentCreateCalls(), 2)
+			} else {
+				assert.Len(t, paymentWtr.PaymentCreateCalls(), 1)
+			}
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expMemo, ack.Memo)
+			if test.expNotify > 0 {
+				// the invoice paid first is told of the conflicting transaction.
+				assert.Equal(t, first.TxID(), notified[0].TxID)
+				assert.Equal(t, tx.TxID(), notified[0].DoubleSpendTxID)
+				assert.Equal(t, tx.String(), notified[0].RawTx)
+			}
+		})
+	}
+}
+
+func TestConflicts_PaymentCreate_WalletFails(t *testing.T) {
+	const prevTxID = "d21633ba23f70118185227be58a63527675641ad37967e2aa461559f577aec43"
+	tests := map[string]struct {
+		paymentFunc func(context.Context, dpp.PaymentCreateArgs, dpp.Payment) (*dpp.PaymentACK, error)
+	}{
+		"wallet error releases the spent outputs": {
+			paymentFunc: func(context.Context, dpp.PaymentCreateArgs, dpp.Payment) (*dpp.PaymentACK, error) {
+				return nil, errors.New("wallet unavailable")
+			},
+		},
+		"failed ack releases the spent outputs": {
+			paymentFunc: func(context.Context, dpp.PaymentCreateArgs, dpp.Payment) (*dpp.PaymentACK, error) {
+				return &dpp.PaymentACK{Error: 1}, nil
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dsWtr := &mocks.DoubleSpendWriterMock{
+				DoubleSpendCreateFunc: func(context.Context, dpp.ProofCreateArgs, server.DoubleSpend) error {
+					return nil
+				},
+			}
+			cfg := &config.Conflicts{Enabled: true, Action: config.ConflictsActionReject, Retention: time.Hour}
+			store := memory.NewSpends()
+			failing := service.NewConflicts(log.Noop{}, &dppMocks.PaymentWriterMock{PaymentCreateFunc: test.paymentFunc},
+				store, dsWtr, cfg)
+			_, _ = failing.PaymentCreate(context.Background(), dpp.PaymentCreateArgs{PaymentID: "abc123"},
+				queuedPayment(spendingTx(t, 1000, prevTxID, 0)))
+
+			svc := service.NewConflicts(log.Noop{}, &dppMocks.PaymentWriterMock{
+				PaymentCreateFunc: func(context.Context, dpp.PaymentCreateArgs, dpp.Payment) (*dpp.PaymentACK, error) {
+					return &dpp.PaymentACK{Memo: "thanks"}, nil
+				},
+			}, store, dsWtr, cfg)
+			ack, err := svc.PaymentCreate(context.Background(), dpp.PaymentCreateArgs{PaymentID: "def456"},
+				queuedPayment(spendingTx(t, 2000, prevTxID, 0)))
+			assert.NoError(t, err)
+			assert.Equal(t, "thanks", ack.Memo)
+			assert.Empty(t, dsWtr.DoubleSpendCreateCalls())
+		})
+	}
+}
+
+func TestConflicts_PaymentCreate_Concurrent(t *testing.T) {
+	const prevTxID = "d21633ba23f70118185227be58a63527675641ad37967e2aa461559f577aec43"
+	paymentWtr := &dppMocks.PaymentWriterMock{
+		PaymentCreateFunc: func(context.Context, dpp.PaymentCreateArgs, dpp.Payment) (*dpp.PaymentACK, error) {
+			return &dpp.PaymentACK{}, nil
+		},
+	}
+	svc := service.NewConflicts(log.Noop{}, paymentWtr, memory.NewSpends(), &mocks.DoubleSpendWriterMock{
+		DoubleSpendCreateFunc: func(context.Context, dpp.ProofCreateArgs, server.DoubleSpend) error {
+			return nil
+		},
+	}, &config.Conflicts{Enabled: true, Action: config.ConflictsActionReject, Retention: time.Hour})
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var accepted int
+	for i := 0; i < 50; i++ {
+		tx := spendingTx(t, uint64(1000+i), prevTxID, 0)
+		wg.Add(1)
+		go func(paymentID string) {
+			defer wg.Done()
+			if _, err := svc.PaymentCreate(context.Background(), dpp.PaymentCreateArgs{PaymentID: paymentID}, queuedPayment(tx)); err == nil {
+				mu.Lock()
+				accepted++
+				mu.Unlock()
+			}
+		}(fmt.Sprintf("invoice%d", i))
+	}
+	wg.Wait()
+
+	// only one of the invoices paid with the same output at once is accepted.
+	assert.Equal(t, 1, accepted)
+	assert.Len(t, paymentWtr.PaymentCreateCalls(), 1)
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/tracing"
+)
+
+type exchangeRateCache struct {
+	rdr server.ExchangeRateReader
+	ttl time.Duration
+
+	mu    sync.Mutex
+	rates map[string]server.ExchangeRate
+}
+
+// NewExchangeRateCache will setup and return an exchange rate reader that reuses the rates
+// read from rdr for ttl, so a price feed isn't called for every payment request.
+func NewExchangeRateCache(rdr server.ExchangeRateReader, ttl time.Duration) *exchangeRateCache {
+	return &exchangeRateCache{
+		rdr:   rdr,
+		ttl:   ttl,
+		rates: map[string]server.ExchangeRate{},
+	}
+}
+
+// ExchangeRate will return the cached rate of a currency, reading it from the source
+// if it isn't cached or has been cached longer than the ttl.
+func (e *exchangeRateCache) ExchangeRate(ctx context.Context, args server.ExchangeRateArgs) (*server.ExchangeRate, error) {
+	ctx, span := tracing.StartSpan(ctx, "service.exchangeRateCache.ExchangeRate", attribute.String("currency", args.Currency))
+	defer span.End()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if rate, ok := e.rates[args.Currency]; ok && time.Since(rate.ReadAt) < e.ttl {
+		return &rate, nil
+	}
+	rate, err := e.rdr.ExchangeRate(ctx, args)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, errors.WithMessagef(err, "failed to read exchange rate for %s", args.Currency)
+	}
+	e.rates[args.Currency] = *rate
+	return rate, nil
+}
@@ -0,0 +1,46 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/mocks"
+	"github.com/bitcoin-sv/dpp-proxy/service"
+)
+
+func TestExchangeRateCache_ExchangeRate(t *testing.T) {
+	var feedErr error
+	rdr := &mocks.ExchangeRateReaderMock{
+		ExchangeRateFunc: func(ctx context.Context, args server.ExchangeRateArgs) (*server.ExchangeRate, error) {
+			if feedErr != nil {
+				return nil, feedErr
+			}
+			return &server.ExchangeRate{Currency: args.Currency, Rate: 50, ReadAt: time.Now()}, nil
+		},
+	}
+	svc := service.NewExchangeRateCache(rdr, 50*time.Millisecond)
+
+	for i := 0; i < 3; i++ {
+		rate, err := svc.ExchangeRate(context.Background(), server.ExchangeRateArgs{Currency: "USD"})
+		assert.NoError(t, err)
+		assert.Equal(t, 50.0, rate.Rate)
+	}
+	assert.Len(t, rdr.ExchangeRateCalls(), 1)
+
+	// currencies are cached separately.
+	_, err := svc.ExchangeRate(context.Background(), server.ExchangeRateArgs{Currency: "EUR"})
+	assert.NoError(t, err)
+	assert.Len(t, rdr.ExchangeRateCalls(), 2)
+
+	// expired rates are read again.
+	time.Sleep(50 * time.Millisecond)
+	feedErr = errors.New("connection refused")
+	_, err = svc.ExchangeRate(context.Background(), server.ExchangeRateArgs{Currency: "USD"})
+	assert.EqualError(t, err, "failed to read exchange rate for USD: connection refused")
+	assert.Len(t, rdr.ExchangeRateCalls(), 3)
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"github.com/libsv/go-bt/v2"
+	"github.com/pkg/errors"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/config"
+	"github.com/bitcoin-sv/dpp-proxy/tracing"
+)
+
+// feeRateBytes is the number of bytes the fee floor and ceiling are set for.
+const feeRateBytes = 1000
+
+type feeQuoteCache struct {
+	rdr server.FeeQuoteReader
+
+	mu    sync.Mutex
+	quote *bt.FeeQuote
+}
+
+// NewFeeQuoteCache will setup and return a fee quote reader that reuses the quote read
+// from rdr until it expires, so miners aren't called for every payment request.
+func NewFeeQuoteCache(rdr server.FeeQuoteReader) *feeQuoteCache {
+	return &feeQuoteCache{
+		rdr: rdr,
+	}
+}
+
+// FeeQuote will return the cached fee quote, reading it again if it has expired.
+func (f *feeQuoteCache) FeeQuote(ctx context.Context) (*bt.FeeQuote, error) {
+	ctx, span := tracing.StartSpan(ctx, "service.feeQuoteCache.FeeQuote")
+	defer span.End()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.quote != nil && !f.quote.Expired() {
+		return f.quote, nil
+	}
+	fq, err := f.rdr.FeeQuote(ctx)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, errors.WithMessage(err, "failed to read fee quote")
+	}
+	f.quote = fq
+	return fq, nil
+}
+
+type fees struct {
+	rdr server.FeeQuoteReader
+	cfg *config.Fees
+}
+
+// NewFees will setup and return a new fee service, filling payment requests without
+// fees from the quotes read with rdr and keeping wallet fees within the floor and
+// ceiling in cfg.
+func NewFees(rdr server.FeeQuoteReader, cfg *config.Fees) *fees {
+	return &fees{
+		rdr: rdr,
+		cfg: cfg,
+	}
+}
+
+// Fees will return a copy of the miner fee quote if fees is nil, otherwise a copy of fees
+// with each rate raised to the floor or lowered to the ceiling.
+func (f *fees) Fees(ctx context.Context, fees *bt.FeeQuote) (*bt.FeeQuote, error) {
+	ctx, span := tracing.StartSpan(ctx, "service.fees.Fees")
+	defer span.End()
+	if fees == nil {
+		fq, err := f.rdr.FeeQuote(ctx)
+		if err != nil {
+			tracing.RecordError(span, err)
+			return nil, errors.WithMessage(err, "failed to read miner fees")
+		}
+		// the cached quote is copied so it isn't shared between payment requests.
+		return copyFeeQuote(fq, nil), nil
+	}
+	return copyFeeQuote(fees, func(u bt.FeeUnit) bt.FeeUnit {
+		return limitFeeUnit(u, f.cfg.Floor, f.cfg.Ceiling)
+	}), nil
+}
+
+// copyFeeQuote returns a copy of the standard and data fees of fq, with each fee unit
+// passed through limitFn if it is not nil.
+func copyFeeQuote(fq *bt.FeeQuote, limitFn func(bt.FeeUnit) bt.FeeUnit) *bt.FeeQuote {
+	resp := bt.NewFeeQuote()
+	for _, ft := range []bt.FeeType{bt.FeeTypeStandard, bt.FeeTypeData} {
+		fee, err := fq.Fee(ft)
+		if err != nil {
+			continue
+		}
+		c := *fee
+		c.FeeType = ft
+		if limitFn != nil {
+			c.MiningFee, c.RelayFee = limitFn(c.MiningFee), limitFn(c.RelayFee)
+		}
+		resp.AddQuote(ft, &c)
+	}
+	resp.UpdateExpiry(fq.Expiry())
+	return resp
+}
+
+// limitFeeUnit returns the fee unit raised to floor or lowered to ceiling, in satoshis
+// per 1000 bytes, a limit of 0 isn't enforced. Units without bytes are set to the floor.
+func limitFeeUnit(u bt.FeeUnit, floor, ceiling int) bt.FeeUnit {
+	switch {
+	case floor > 0 && (u.Bytes <= 0 || u.Satoshis*feeRateBytes < floor*u.Bytes):
+		return bt.FeeUnit{Satoshis: floor, Bytes: feeRateBytes}
+	case ceiling > 0 && u.Bytes > 0 && u.Satoshis*feeRateBytes > ceiling*u.Bytes:
+		return bt.FeeUnit{Satoshis: ceiling, Bytes: feeRateBytes}
+	}
+	return u
+}
@@ -0,0 +1,178 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-dpp"
+	"github.com/stretchr/testify/assert"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/config"
+	"github.com/bitcoin-sv/dpp-proxy/mocks"
+	"github.com/bitcoin-sv/dpp-proxy/service"
+)
+
+// feeQuote returns a fee quote with the same mining and relay fee for both fee types.
+func feeQuote(satoshis, bytes int, expiry time.Time) *bt.FeeQuote {
+	fq := bt.NewFeeQuote()
+	for _, ft := range []bt.FeeType{bt.FeeTypeStandard, bt.FeeTypeData} {
+		u := bt.FeeUnit{Satoshis: satoshis, Bytes: bytes}
+		fq.AddQuote(ft, &bt.Fee{FeeType: ft, MiningFee: u, RelayFee: u})
+	}
+	fq.UpdateExpiry(expiry)
+	return fq
+}
+
+func TestFees_Fees(t *testing.T) {
+	expiry := time.Now().Add(time.Hour).UTC()
+	tests := map[string]struct {
+		fees     *bt.FeeQuote
+		floor    int
+		ceiling  int
+		readErr  error
+		expUnit  bt.FeeUnit
+		expReads int
+		expErr   error
+	}{
+		"missing fees are filled from the miner": {
+			expUnit:  bt.FeeUnit{Satoshis: 50, Bytes: 1000},
+			expReads: 1,
+		},
+		"missing fees error if the miner can't be read": {
+			readErr:  errors.New("connection refused"),
+			expReads: 1,
+			expErr:   errors.New("failed to read miner fees: connection refused"),
+		},
+		"wallet fees within limits are kept": {
+			fees:    feeQuote(5, 100, expiry),
+			floor:   10,
+			ceiling: 100,
+			expUnit: bt.FeeUnit{Satoshis: 5, Bytes: 100},
+		},
+		"wallet fees below the floor are raised": {
+			fees:    feeQuote(1, 1000, expiry),
+			floor:   10,
+			expUnit: bt.FeeUnit{Satoshis: 10, Bytes: 1000},
+		},
+		"wallet fees above the ceiling are lowered": {
+			fees:    feeQuote(1, 1, expiry),
+			ceiling: 100,
+			expUnit: bt.FeeUnit{Satoshis: 100, Bytes: 1000},
+		},
+		"wallet fees without bytes are set to the floor": {
+			fees:    feeQuote(1, 0, expiry),
+			floor:   10,
+			expUnit: bt.FeeUnit{Satoshis: 10, Bytes: 1000},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			rdr := &mocks.FeeQuoteReaderMock{
+				FeeQuoteFunc: func(context.Context) (*bt.FeeQuote, error) {
+					if test.readErr != nil {
+						return nil, test.readErr
+					}
+					return feeQuote(50, 1000, expiry), nil
+				},
+			}
+			svc := service.NewFees(rdr, &config.Fees{Floor: test.floor, Ceiling: test.ceiling})
+			fq, err := svc.Fees(context.Background(), test.fees)
+			assert.Len(t, rdr.FeeQuoteCalls(), test.expReads)
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, expiry, fq.Expiry())
+			for _, ft := range []bt.FeeType{bt.FeeTypeStandard, bt.FeeTypeData} {
+				fee, err := fq.Fee(ft)
+				assert.NoError(t, err)
+				assert.Equal(t, ft, fee.FeeType)
+				assert.Equal(t, test.expUnit, fee.MiningFee)
+				assert.Equal(t, test.expUnit, fee.RelayFee)
+			}
+		})
+	}
+}
+
+func TestFeeQuoteCache_FeeQuote(t *testing.T) {
+	expiry := time.Now().Add(50 * time.Millisecond)
+	rdr := &mocks.FeeQuoteReaderMock{
+		FeeQuoteFunc: func(context.Context) (*bt.FeeQuote, error) {
+			return feeQuote(50, 1000, expiry), nil
+		},
+	}
+	svc := service.NewFeeQuoteCache(rdr)
+	for i := 0; i < 3; i++ {
+		_, err := svc.FeeQuote(context.Background())
+		assert.NoError(t, err)
+	}
+	assert.Len(t, rdr.FeeQuoteCalls(), 1)
+
+	// expired quotes are read again.
+	time.Sleep(time.Until(expiry))
+	_, err := svc.FeeQuote(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, rdr.FeeQuoteCalls(), 2)
+}
+
+func TestPaymentRequestProxy_Fees(t *testing.T) {
+	tests := map[string]struct {
+		fees    server.FeeService
+		expFees bool
+		expErr  error
+	}{
+		"wallet omitting fees is rejected without a fee service": {
+			expErr: errors.New("no fees received for paymentID abc123"),
+		},
+		"wallet omitting fees is filled by the fee service": {
+			fees: &mocks.FeeServiceMock{
+				FeesFunc: func(ctx context.Context, fees *bt.FeeQuote) (*bt.FeeQuote, error) {
+					return bt.NewFeeQuote(), nil
+				},
+			},
+			expFees: true,
+		},
+		"fee service errors are returned": {
+			fees: &mocks.FeeServiceMock{
+				FeesFunc: func(ctx context.Context, fees *bt.FeeQuote) (*bt.FeeQuote, error) {
+					return nil, errors.New("connection refused")
+				},
+			},
+			expErr: errors.New("failed to set fees for paymentID abc123: connection refused"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			wallet := &mocks.PaymentRequestReaderMock{
+				PaymentRequestFunc: func(ctx context.Context, args dpp.PaymentRequestArgs) (*dpp.PaymentRequest, error) {
+					req := invoiceCreate(args.PaymentID, time.Now().Add(time.Hour)).PaymentRequest
+					req.Network = config.NetworkRegtest
+					req.FeeRate = nil
+					return &req, nil
+				},
+			}
+			svc := service.NewPaymentRequestProxy(wallet, nil, test.fees, nil, &mocks.PaymentStatusWriterMock{
+				PaymentStatusUpdateFunc: func(context.Context, server.PaymentStatus) error {
+					return nil
+				},
+			}, &config.Transports{Mode: config.TransportModeHybrid}, &config.Server{FQDN: "dpp.example.com"},
+				&config.Deployment{Network: config.NetworkRegtest}, &mocks.AuditLoggerMock{
+					AuditLogFunc: func(context.Context, server.AuditEvent) error {
+						return nil
+					},
+				})
+			pr, err := svc.PaymentRequest(context.Background(), dpp.PaymentRequestArgs{PaymentID: "abc123"})
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expFees, pr.FeeRate != nil)
+		})
+	}
+}
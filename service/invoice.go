@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/libsv/go-dpp"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/config"
+	"github.com/bitcoin-sv/dpp-proxy/tracing"
+)
+
+type invoice struct {
+	store     server.InvoiceReaderWriter
+	srvCfg    *config.Server
+	deployCfg *config.Deployment
+}
+
+// NewInvoice will setup and return a new invoice service, storing the payment requests
+// merchants register so they can be served while the merchant wallet is offline.
+func NewInvoice(store server.InvoiceReaderWriter, srvCfg *config.Server, deployCfg *config.Deployment) *invoice {
+	return &invoice{
+		store:     store,
+		srvCfg:    srvCfg,
+		deployCfg: deployCfg,
+	}
+}
+
+// InvoiceCreate will register an invoice, setting the paymentUrl and network of the
+// payment request to those served by the proxy.
+func (i *invoice) InvoiceCreate(ctx context.Context, req server.InvoiceCreate) (*server.Invoice, error) {
+	ctx, span := tracing.StartSpan(ctx, "service.invoice.InvoiceCreate", attribute.String("paymentID", req.PaymentID))
+	defer span.End()
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	pr := req.PaymentRequest
+	if pr.Network != "" {
+		if err := checkNetwork(i.deployCfg.Network, pr.Network); err != nil {
+			return nil, err
+		}
+	}
+	pr.Network = i.deployCfg.Network
+	pr.PaymentURL = server.PaymentURL(i.srvCfg.FQDN, req.PaymentID)
+	if pr.CreationTimestamp.IsZero() {
+		pr.CreationTimestamp = time.Now().UTC()
+	}
+	pr.MerchantData = invoiceMerchantData(req.PaymentID, pr.MerchantData)
+	inv := server.Invoice{
+		PaymentID:      req.PaymentID,
+		PaymentRequest: pr,
+		CreatedAt:      time.Now().UTC(),
+	}
+	if err := i.store.InvoiceCreate(ctx, inv); err != nil {
+		tracing.RecordError(span, err)
+		return nil, errors.WithMessagef(err, "failed to register invoice for paymentID '%s'", req.PaymentID)
+	}
+	return &inv, nil
+}
+
+// Invoice will return a registered invoice.
+func (i *invoice) Invoice(ctx context.Context, args server.InvoiceArgs) (*server.Invoice, error) {
+	ctx, span := tracing.StartSpan(ctx, "service.invoice.Invoice", attribute.String("paymentID", args.PaymentID))
+	defer span.End()
+	if err := args.Validate(); err != nil {
+		return nil, err
+	}
+	inv, err := i.store.Invoice(ctx, args)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, errors.WithMessagef(err, "failed to get invoice for paymentID '%s'", args.PaymentID)
+	}
+	return inv, nil
+}
+
+// InvoiceDelete will remove a registered invoice, payment requests for it are then
+// read from the merchant wallet.
+func (i *invoice) InvoiceDelete(ctx context.Context, args server.InvoiceArgs) error {
+	ctx, span := tracing.StartSpan(ctx, "service.invoice.InvoiceDelete", attribute.String("paymentID", args.PaymentID))
+	defer span.End()
+	if err := args.Validate(); err != nil {
+		return err
+	}
+	if err := i.store.InvoiceDelete(ctx, args); err != nil {
+		tracing.RecordError(span, err)
+		return errors.WithMessagef(err, "failed to delete invoice for paymentID '%s'", args.PaymentID)
+	}
+	return nil
+}
+
+// invoiceMerchantData returns a copy of the merchant data, with the paymentID as the
+// payment reference if one isn't set, wallets copy it into the payment.
+func invoiceMerchantData(paymentID string, m *dpp.Merchant) *dpp.Merchant {
+	resp := &dpp.Merchant{ExtendedData: map[string]interface{}{}}
+	if m != nil {
+		*resp = *m
+		resp.ExtendedData = make(map[string]interface{}, len(m.ExtendedData)+1)
+		for k, v := range m.ExtendedData {
+			resp.ExtendedData[k] = v
+		}
+	}
+	if _, ok := resp.ExtendedData["paymentReference"]; !ok {
+		resp.ExtendedData["paymentReference"] = paymentID
+	}
+	return resp
+}
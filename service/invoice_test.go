@@ -0,0 +1,178 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/libsv/go-dpp"
+	"github.com/stretchr/testify/assert"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/config"
+	"github.com/bitcoin-sv/dpp-proxy/data/memory"
+	"github.com/bitcoin-sv/dpp-proxy/mocks"
+	"github.com/bitcoin-sv/dpp-proxy/service"
+)
+
+const invoiceScript = "76a91455b61be43392125d127f1780fb038437cd67ef9c88ac"
+
+func invoiceCreate(paymentID string, expires time.Time) server.InvoiceCreate {
+	s, _ := bscript.NewFromHexString(invoiceScript)
+	return server.InvoiceCreate{
+		PaymentID: paymentID,
+		PaymentRequest: dpp.PaymentRequest{
+			ExpirationTimestamp: expires,
+			Destinations: dpp.PaymentDestinations{
+				Outputs: []dpp.Output{{Amount: 1000, LockingScript: s}},
+			},
+			FeeRate: bt.NewFeeQuote(),
+			Memo:    "invoice " + paymentID,
+		},
+	}
+}
+
+func TestInvoice_InvoiceCreate(t *testing.T) {
+	future := time.Now().Add(time.Hour).UTC()
+	tests := map[string]struct {
+		existing []server.InvoiceCreate
+		reqFn    func(req *server.InvoiceCreate)
+		expErr   error
+	}{
+		"invoice is registered": {
+			reqFn: func(req *server.InvoiceCreate) {},
+		},
+		"invoice for the network served is registered": {
+			reqFn: func(req *server.InvoiceCreate) {
+				req.PaymentRequest.Network = "regtest"
+			},
+		},
+		"invoice for another network is rejected": {
+			reqFn: func(req *server.InvoiceCreate) {
+				req.PaymentRequest.Network = "mainnet"
+			},
+			expErr: errors.New("Unprocessable: payment request is for network 'mainnet', this server only accepts 'regtest'"),
+		},
+		"invoice without outputs or fees is rejected": {
+			reqFn: func(req *server.InvoiceCreate) {
+				req.PaymentRequest.Destinations.Outputs = nil
+				req.PaymentRequest.FeeRate = nil
+			},
+			expErr: errors.New("[paymentRequest.destinations.outputs: at least one output is required], [paymentRequest.fees: fees are required]"),
+		},
+		"invoice with an empty output is rejected": {
+			reqFn: func(req *server.InvoiceCreate) {
+				req.PaymentRequest.Destinations.Outputs = append(req.PaymentRequest.Destinations.Outputs, dpp.Output{})
+			},
+			expErr: errors.New("[paymentRequest.destinations.outputs[1]: amount must be greater than 0]"),
+		},
+		"expired invoice is rejected": {
+			reqFn: func(req *server.InvoiceCreate) {
+				req.PaymentRequest.ExpirationTimestamp = time.Now().Add(-time.Minute)
+			},
+			expErr: errors.New("[paymentRequest.expirationTimestamp: the expiry must be in the future]"),
+		},
+		"invoice already registered is rejected": {
+			existing: []server.InvoiceCreate{invoiceCreate("abc123", future)},
+			reqFn:    func(req *server.InvoiceCreate) {},
+			expErr:   errors.New("failed to register invoice for paymentID 'abc123': Item already exists: an invoice is already registered for the payment"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			svc := service.NewInvoice(memory.NewInvoices(), &config.Server{FQDN: "dpp.example.com"},
+				&config.Deployment{Network: config.NetworkRegtest})
+			for _, e := range test.existing {
+				_, err := svc.InvoiceCreate(context.Background(), e)
+				assert.NoError(t, err)
+			}
+			req := invoiceCreate("abc123", future)
+			test.reqFn(&req)
+			inv, err := svc.InvoiceCreate(context.Background(), req)
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "abc123", inv.PaymentID)
+			assert.Equal(t, config.NetworkRegtest, inv.PaymentRequest.Network)
+			assert.Equal(t, "http://dpp.example.com/api/v1/payment/abc123", inv.PaymentRequest.PaymentURL)
+			assert.False(t, inv.PaymentRequest.CreationTimestamp.IsZero())
+			assert.Equal(t, "abc123", inv.PaymentRequest.MerchantData.ExtendedData["paymentReference"])
+
+			stored, err := svc.Invoice(context.Background(), server.InvoiceArgs{PaymentID: "abc123"})
+			assert.NoError(t, err)
+			assert.Equal(t, inv, stored)
+		})
+	}
+}
+
+func TestInvoice_InvoiceDelete(t *testing.T) {
+	svc := service.NewInvoice(memory.NewInvoices(), &config.Server{FQDN: "dpp.example.com"},
+		&config.Deployment{Network: config.NetworkRegtest})
+	_, err := svc.InvoiceCreate(context.Background(), invoiceCreate("abc123", time.Now().Add(time.Hour)))
+	assert.NoError(t, err)
+
+	assert.NoError(t, svc.InvoiceDelete(context.Background(), server.InvoiceArgs{PaymentID: "abc123"}))
+	_, err = svc.Invoice(context.Background(), server.InvoiceArgs{PaymentID: "abc123"})
+	assert.EqualError(t, err, "failed to get invoice for paymentID 'abc123': Not found: invoice not found")
+	assert.EqualError(t, svc.InvoiceDelete(context.Background(), server.InvoiceArgs{PaymentID: "abc123"}),
+		"failed to delete invoice for paymentID 'abc123': Not found: invoice not found")
+}
+
+func TestPaymentRequestProxy_Invoices(t *testing.T) {
+	tests := map[string]struct {
+		paymentID string
+		expWallet bool
+		expMemo   string
+	}{
+		"registered invoice is served without the wallet": {
+			paymentID: "abc123",
+			expMemo:   "invoice abc123",
+		},
+		"unregistered invoice is read from the wallet": {
+			paymentID: "def456",
+			expWallet: true,
+			expMemo:   "from the wallet",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			store := memory.NewInvoices()
+			srvCfg := &config.Server{FQDN: "dpp.example.com"}
+			deployCfg := &config.Deployment{Network: config.NetworkRegtest}
+			_, err := service.NewInvoice(store, srvCfg, deployCfg).
+				InvoiceCreate(context.Background(), invoiceCreate("abc123", time.Now().Add(time.Hour)))
+			assert.NoError(t, err)
+			wallet := &mocks.PaymentRequestReaderMock{
+				PaymentRequestFunc: func(ctx context.Context, args dpp.PaymentRequestArgs) (*dpp.PaymentRequest, error) {
+					req := invoiceCreate(args.PaymentID, time.Now().Add(time.Hour)).PaymentRequest
+					req.Network = config.NetworkRegtest
+					req.Memo = "from the wallet"
+					return &req, nil
+				},
+			}
+			statusWtr := &mocks.PaymentStatusWriterMock{
+				PaymentStatusUpdateFunc: func(context.Context, server.PaymentStatus) error {
+					return nil
+				},
+			}
+			auditLog := &mocks.AuditLoggerMock{
+				AuditLogFunc: func(context.Context, server.AuditEvent) error {
+					return nil
+				},
+			}
+			svc := service.NewPaymentRequestProxy(wallet, store, nil, nil, statusWtr,
+				&config.Transports{Mode: config.TransportModeHybrid}, srvCfg, deployCfg, auditLog)
+			pr, err := svc.PaymentRequest(context.Background(), dpp.PaymentRequestArgs{PaymentID: test.paymentID})
+			assert.NoError(t, err)
+			assert.Equal(t, test.expMemo, pr.Memo)
+			assert.Equal(t, "http://dpp.example.com/api/v1/payment/"+test.paymentID, pr.PaymentURL)
+			assert.Equal(t, test.expWallet, len(wallet.PaymentRequestCalls()) == 1)
+			assert.Len(t, statusWtr.PaymentStatusUpdateCalls(), 1)
+		})
+	}
+}
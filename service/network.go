@@ -0,0 +1,55 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/pkg/errors"
+	"github.com/theflyingcodr/lathos/errs"
+
+	"github.com/bitcoin-sv/dpp-proxy/config"
+)
+
+// networkAliases maps network names used by wallets outside of the bip270 spec
+// to the network they refer to.
+var networkAliases = map[string]string{
+	"bitcoin":    config.NetworkMainnet,
+	"bitcoin-sv": config.NetworkMainnet,
+	"main":       config.NetworkMainnet,
+	"test":       config.NetworkTestnet,
+}
+
+// normaliseNetwork returns the network name with any alias resolved.
+func normaliseNetwork(network string) string {
+	n := strings.ToLower(strings.TrimSpace(network))
+	if alias, ok := networkAliases[n]; ok {
+		return alias
+	}
+	return n
+}
+
+// checkNetwork ensures a payment request received from a wallet is for the network we serve.
+func checkNetwork(expected, network string) error {
+	if normaliseNetwork(network) == expected {
+		return nil
+	}
+	return errs.NewErrUnprocessablef("422", "payment request is for network '%s', this server only accepts '%s'", network, expected)
+}
+
+// checkAddressNetwork ensures a base58 P2PKH address is encoded for the network we serve,
+// testnet, stn and regtest share the same encoding.
+func checkAddressNetwork(expected, addr string) error {
+	// NewAddressFromString checks the format, ValidateAddress the checksum.
+	if _, err := bscript.NewAddressFromString(addr); err != nil {
+		return errors.Errorf("'%s' is not a valid address", addr)
+	}
+	if ok, _ := bscript.ValidateAddress(addr); !ok {
+		return errors.Errorf("'%s' is not a valid address", addr)
+	}
+	// mainnet addresses have a 0x00 version byte so always start with a 1.
+	mainnet := strings.HasPrefix(addr, "1")
+	if mainnet == (expected == config.NetworkMainnet) {
+		return nil
+	}
+	return errors.Errorf("'%s' is not a %s address", addr, expected)
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/tracing"
+)
+
+type paymailInvoice struct {
+	store server.PaymailInvoiceReaderWriter
+}
+
+// NewPaymailInvoice will setup and return a new paymail invoice service, creating invoices paid
+// to the merchant's paymail.
+func NewPaymailInvoice(store server.PaymailInvoiceReaderWriter) *paymailInvoice {
+	return &paymailInvoice{
+		store: store,
+	}
+}
+
+// PaymailInvoiceCreate will validate and create an invoice paid to the merchant paymail.
+func (x *paymailInvoice) PaymailInvoiceCreate(ctx context.Context, req server.PaymailInvoiceCreate) (*server.PaymailInvoice, error) {
+	ctx, span := tracing.StartSpan(ctx, "service.paymailInvoice.PaymailInvoiceCreate")
+	defer span.End()
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	inv, err := x.store.PaymailInvoiceCreate(ctx, req)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, errors.WithMessage(err, "failed to create paymail invoice")
+	}
+	return inv, nil
+}
+
+// PaymailInvoice will return an invoice.
+func (x *paymailInvoice) PaymailInvoice(ctx context.Context, args server.PaymailInvoiceArgs) (*server.PaymailInvoice, error) {
+	ctx, span := tracing.StartSpan(ctx, "service.paymailInvoice.PaymailInvoice", attribute.String("paymentID", args.PaymentID))
+	defer span.End()
+	if err := args.Validate(); err != nil {
+		return nil, err
+	}
+	inv, err := x.store.PaymailInvoice(ctx, args)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, errors.WithMessagef(err, "failed to get paymail invoice for paymentID '%s'", args.PaymentID)
+	}
+	return inv, nil
+}
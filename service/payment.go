@@ -2,9 +2,17 @@ package service
 
 import (
 	"context"
+	"time"
 
-	"github.com/bitcoin-sv/dpp-proxy/log"
+	"github.com/libsv/go-bt/v2"
 	"github.com/libsv/go-dpp"
+	validator "github.com/theflyingcodr/govalidator"
+	"go.opentelemetry.io/otel/attribute"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/config"
+	"github.com/bitcoin-sv/dpp-proxy/log"
+	"github.com/bitcoin-sv/dpp-proxy/tracing"
 )
 
 // payment is a layer on top of the payment services of which we currently support:
@@ -13,32 +21,167 @@ import (
 type payment struct {
 	l          log.Logger
 	paymentWtr dpp.PaymentWriter
+	refundWtr  server.RefundWriter
+	tokenWtr   server.ProofTokenWriter
+	statusWtr  server.PaymentStatusWriter
+	channels   server.PeerChannelCreator
+	auditLog   server.AuditLogger
+	deployCfg  *config.Deployment
 }
 
-// NewPayment will create and return a new payment service.
-func NewPayment(l log.Logger, paymentWtr dpp.PaymentWriter) *payment {
+// NewPayment will create and return a new payment service, refund destinations
+// supplied with accepted payments are recorded with refundWtr, proof callback
+// tokens with tokenWtr and whether the payment was paid or rejected with statusWtr.
+// If channels is not nil, paid invoices are allocated a peer channel returned in the ack.
+func NewPayment(l log.Logger, paymentWtr dpp.PaymentWriter, refundWtr server.RefundWriter, tokenWtr server.ProofTokenWriter,
+	statusWtr server.PaymentStatusWriter, channels server.PeerChannelCreator, auditLog server.AuditLogger,
+	deployCfg *config.Deployment) *payment {
 	return &payment{
 		l:          l,
 		paymentWtr: paymentWtr,
+		refundWtr:  refundWtr,
+		tokenWtr:   tokenWtr,
+		statusWtr:  statusWtr,
+		channels:   channels,
+		auditLog:   auditLog,
+		deployCfg:  deployCfg,
 	}
 }
 
 // PaymentCreate will setup a new payment and return the result.
 func (p *payment) PaymentCreate(ctx context.Context, args dpp.PaymentCreateArgs, req dpp.Payment) (*dpp.PaymentACK, error) {
+	ctx, span := tracing.StartSpan(ctx, "service.payment.PaymentCreate", attribute.String("paymentID", args.PaymentID))
+	defer span.End()
 	if err := args.Validate(); err != nil {
 		return nil, err
 	}
 	if err := req.Validate(); err != nil {
 		return nil, err
 	}
+	if err := p.validateRefund(req); err != nil {
+		return nil, err
+	}
 	// broadcast it to a wallet for processing.
 	ack, err := p.paymentWtr.PaymentCreate(ctx, args, req)
 	if err != nil {
 		p.l.Error(err, "failed to create payment")
-		return &dpp.PaymentACK{
-			Memo:  err.Error(),
+		tracing.RecordError(span, err)
+		ack = &dpp.PaymentACK{
+			Memo:  server.NewProblem(err).Detail,
 			Error: 1,
-		}, err
+		}
+	} else {
+		p.recordProofToken(ctx, args, req, ack)
+		p.recordRefund(ctx, args, req)
+		p.peerChannel(ctx, args, ack)
+	}
+	p.recordStatus(ctx, args, req, ack)
+	p.audit(ctx, args, req, ack)
+	return ack, err
+}
+
+// recordStatus marks the payment as paid, queued if the merchant wallet was offline, or rejected
+// if the ack contains an error, failures are logged as the payment has been processed.
+func (p *payment) recordStatus(ctx context.Context, args dpp.PaymentCreateArgs, req dpp.Payment, ack *dpp.PaymentACK) {
+	status := server.PaymentStatus{
+		PaymentID: args.PaymentID,
+		State:     server.PaymentStatePaid,
+		TxID:      ack.TxID,
+	}
+	if ack.Error != 0 {
+		status.State = server.PaymentStateRejected
+		status.TxID = ""
+		status.Memo = ack.Memo
+	} else if isQueued(ack) {
+		status.State = server.PaymentStateQueued
+	} else if status.TxID == "" && req.RawTx != nil {
+		if tx, err := bt.NewTxFromString(*req.RawTx); err == nil {
+			status.TxID = tx.TxID()
+		}
+	}
+	if err := p.statusWtr.PaymentStatusUpdate(ctx, status); err != nil {
+		p.l.Errorf(err, "failed to record status for paymentID %s", args.PaymentID)
+	}
+}
+
+// peerChannel adds the peer channel of the invoice to the ack of a paid payment, unless the
+// wallet hosts its own. Failures are logged as the payment has been processed.
+func (p *payment) peerChannel(ctx context.Context, args dpp.PaymentCreateArgs, ack *dpp.PaymentACK) {
+	if p.channels == nil || ack.Error != 0 || ack.PeerChannel != nil {
+		return
+	}
+	channel, err := p.channels.PeerChannelCreate(ctx, args)
+	if err != nil {
+		p.l.Errorf(err, "failed to allocate peer channel for paymentID %s", args.PaymentID)
+		return
+	}
+	ack.PeerChannel = channel
+}
+
+// validateRefund checks the refund destination supplied with the payment is a paymail, an
+// address for the network we serve or a hex locking script.
+//
+// Transaction outputs are locking scripts which only contain a public key hash, they do not
+// encode a network so cannot be checked, the only address supplied is the refund address.
+func (p *payment) validateRefund(req dpp.Payment) error {
+	if req.RefundTo == nil {
+		return nil
+	}
+	return validator.New().
+		Validate("refundTo", func() error {
+			_, _, err := refundDestination(p.deployCfg.Network, *req.RefundTo)
+			return err
+		}).Err()
+}
+
+// recordProofToken stores the proof callback tokens of an accepted payment. Tokens are only
+// recorded once the wallet accepts the payment, so a known transaction can't be sent for another
+// invoice with new tokens to take over its callbacks, callbacks received before the ack are
+// rejected and retried by the miner. Failures are logged as the payment has been processed.
+func (p *payment) recordProofToken(ctx context.Context, args dpp.PaymentCreateArgs, req dpp.Payment, ack *dpp.PaymentACK) {
+	token, ok := newProofToken(args.PaymentID, req)
+	if !ok || ack.Error != 0 {
+		return
+	}
+	if err := p.tokenWtr.ProofTokenCreate(ctx, *token); err != nil {
+		p.l.Errorf(err, "failed to record proof tokens for paymentID %s", args.PaymentID)
+	}
+}
+
+// recordRefund stores the refund destination for an accepted payment so the merchant can
+// request refund instructions later, failures are logged as the payment has been processed.
+func (p *payment) recordRefund(ctx context.Context, args dpp.PaymentCreateArgs, req dpp.Payment) {
+	if req.RefundTo == nil {
+		return
+	}
+	refund := server.Refund{
+		PaymentID: args.PaymentID,
+		RefundTo:  *req.RefundTo,
+		CreatedAt: time.Now().UTC(),
+	}
+	if tx, err := bt.NewTxFromString(*req.RawTx); err == nil {
+		refund.TxID = tx.TxID()
+	}
+	if err := p.refundWtr.RefundCreate(ctx, refund); err != nil {
+		p.l.Errorf(err, "failed to record refund destination for paymentID %s", args.PaymentID)
+	}
+}
+
+// audit records the payment received and the ack returned, the wallet has already
+// processed the payment at this point so failures are logged rather than returned.
+func (p *payment) audit(ctx context.Context, args dpp.PaymentCreateArgs, req dpp.Payment, ack *dpp.PaymentACK) {
+	evt := server.AuditEvent{
+		Type:      server.AuditPayment,
+		PaymentID: args.PaymentID,
+		Payload: server.AuditPaymentPayload{
+			Payment:    req,
+			PaymentACK: ack,
+		},
+	}
+	if tx, err := bt.NewTxFromString(*req.RawTx); err == nil {
+		evt.TxID = tx.TxID()
+	}
+	if err := p.auditLog.AuditLog(ctx, evt); err != nil {
+		p.l.Errorf(err, "failed to audit payment for paymentID %s", args.PaymentID)
 	}
-	return ack, nil
 }
@@ -6,19 +6,32 @@ import (
 	"errors"
 	"testing"
 
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/config"
 	"github.com/bitcoin-sv/dpp-proxy/log"
+	"github.com/bitcoin-sv/dpp-proxy/mocks"
 	"github.com/bitcoin-sv/dpp-proxy/service"
 	"github.com/libsv/go-bc/spv"
 	"github.com/libsv/go-dpp"
 	dppMocks "github.com/libsv/go-dpp/mocks"
 	"github.com/stretchr/testify/assert"
+	"github.com/theflyingcodr/lathos/errs"
 )
 
 func TestPayment_Create(t *testing.T) {
 	tests := map[string]struct {
 		paymentCreateFn func(context.Context, dpp.PaymentCreateArgs, dpp.Payment) (*dpp.PaymentACK, error)
+		auditLogFn      func(context.Context, server.AuditEvent) error
+		refundCreateFn  func(context.Context, server.Refund) error
+		tokenCreateFn   func(context.Context, server.ProofToken) error
+		peerChannelFn   func(context.Context, dpp.PaymentCreateArgs) (*dpp.PeerChannelData, error)
 		args            dpp.PaymentCreateArgs
 		req             dpp.Payment
+		expAudits       int
+		expRefund       *server.Refund
+		expToken        *server.ProofToken
+		expStatus       *server.PaymentStatus
+		expPeerChannel  *dpp.PeerChannelData
 		expErr          error
 	}{
 		"successful payment create": {
@@ -40,6 +53,30 @@ func TestPayment_Create(t *testing.T) {
 			args: dpp.PaymentCreateArgs{
 				PaymentID: "abc123",
 			},
+			expAudits: 1,
+			expStatus: &server.PaymentStatus{
+				PaymentID: "abc123",
+				State:     server.PaymentStatePaid,
+				TxID:      "d21633ba23f70118185227be58a63527675641ad37967e2aa461559f577aec43",
+			},
+		},
+		"audit error does not fail payment": {
+			paymentCreateFn: func(context.Context, dpp.PaymentCreateArgs, dpp.Payment) (*dpp.PaymentACK, error) {
+				return &dpp.PaymentACK{}, nil
+			},
+			auditLogFn: func(context.Context, server.AuditEvent) error {
+				return errors.New("disk full")
+			},
+			req: dpp.Payment{
+				RawTx: func() *string { s := "01000000000000000000"; return &s }(),
+				MerchantData: dpp.Merchant{
+					ExtendedData: map[string]interface{}{"paymentReference": "omgwow"},
+				},
+			},
+			args: dpp.PaymentCreateArgs{
+				PaymentID: "abc123",
+			},
+			expAudits: 1,
 		},
 		"invalid args errors": {
 			paymentCreateFn: func(context.Context, dpp.PaymentCreateArgs, dpp.Payment) (*dpp.PaymentACK, error) {
@@ -68,6 +105,249 @@ func TestPayment_Create(t *testing.T) {
 			},
 			expErr: errors.New("[ancestry/rawTx: either ancestry or a rawTX are required]"),
 		},
+		"testnet refund address is accepted": {
+			paymentCreateFn: func(context.Context, dpp.PaymentCreateArgs, dpp.Payment) (*dpp.PaymentACK, error) {
+				return &dpp.PaymentACK{}, nil
+			},
+			req: dpp.Payment{
+				RawTx:    func() *string { s := "01000000000000000000"; return &s }(),
+				RefundTo: func() *string { s := "mfWyW5fc9NUj75YAnFgoRLrjxgLDn2MMth"; return &s }(),
+				MerchantData: dpp.Merchant{
+					ExtendedData: map[string]interface{}{"paymentReference": "omgwow"},
+				},
+			},
+			args: dpp.PaymentCreateArgs{
+				PaymentID: "abc123",
+			},
+			expAudits: 1,
+			expRefund: &server.Refund{
+				PaymentID: "abc123",
+				TxID:      "d21633ba23f70118185227be58a63527675641ad37967e2aa461559f577aec43",
+				RefundTo:  "mfWyW5fc9NUj75YAnFgoRLrjxgLDn2MMth",
+			},
+		},
+		"refund script is accepted": {
+			paymentCreateFn: func(context.Context, dpp.PaymentCreateArgs, dpp.Payment) (*dpp.PaymentACK, error) {
+				return &dpp.PaymentACK{}, nil
+			},
+			req: dpp.Payment{
+				RawTx:    func() *string { s := "01000000000000000000"; return &s }(),
+				RefundTo: func() *string { s := "76a91400a3c4fd2bc8db0e5fa9b3d3a5a0b58c4ab4b9a288ac"; return &s }(),
+				MerchantData: dpp.Merchant{
+					ExtendedData: map[string]interface{}{"paymentReference": "omgwow"},
+				},
+			},
+			args: dpp.PaymentCreateArgs{
+				PaymentID: "abc123",
+			},
+			expAudits: 1,
+			expRefund: &server.Refund{
+				PaymentID: "abc123",
+				TxID:      "d21633ba23f70118185227be58a63527675641ad37967e2aa461559f577aec43",
+				RefundTo:  "76a91400a3c4fd2bc8db0e5fa9b3d3a5a0b58c4ab4b9a288ac",
+			},
+		},
+		"refund store error does not fail payment": {
+			paymentCreateFn: func(context.Context, dpp.PaymentCreateArgs, dpp.Payment) (*dpp.PaymentACK, error) {
+				return &dpp.PaymentACK{}, nil
+			},
+			refundCreateFn: func(context.Context, server.Refund) error {
+				return errors.New("store full")
+			},
+			req: dpp.Payment{
+				RawTx:    func() *string { s := "01000000000000000000"; return &s }(),
+				RefundTo: func() *string { s := "me@paymail.com"; return &s }(),
+				MerchantData: dpp.Merchant{
+					ExtendedData: map[string]interface{}{"paymentReference": "omgwow"},
+				},
+			},
+			args: dpp.PaymentCreateArgs{
+				PaymentID: "abc123",
+			},
+			expAudits: 1,
+			expRefund: &server.Refund{
+				PaymentID: "abc123",
+				TxID:      "d21633ba23f70118185227be58a63527675641ad37967e2aa461559f577aec43",
+				RefundTo:  "me@paymail.com",
+			},
+		},
+		"paymail refund is accepted": {
+			paymentCreateFn: func(context.Context, dpp.PaymentCreateArgs, dpp.Payment) (*dpp.PaymentACK, error) {
+				return &dpp.PaymentACK{}, nil
+			},
+			req: dpp.Payment{
+				RawTx:    func() *string { s := "01000000000000000000"; return &s }(),
+				RefundTo: func() *string { s := "me@paymail.com"; return &s }(),
+				MerchantData: dpp.Merchant{
+					ExtendedData: map[string]interface{}{"paymentReference": "omgwow"},
+				},
+			},
+			args: dpp.PaymentCreateArgs{
+				PaymentID: "abc123",
+			},
+			expAudits: 1,
+			expRefund: &server.Refund{
+				PaymentID: "abc123",
+				TxID:      "d21633ba23f70118185227be58a63527675641ad37967e2aa461559f577aec43",
+				RefundTo:  "me@paymail.com",
+			},
+		},
+		"mainnet refund address is rejected": {
+			paymentCreateFn: func(context.Context, dpp.PaymentCreateArgs, dpp.Payment) (*dpp.PaymentACK, error) {
+				return &dpp.PaymentACK{}, nil
+			},
+			req: dpp.Payment{
+				RawTx:    func() *string { s := "01000000000000000000"; return &s }(),
+				RefundTo: func() *string { s := "112D2adLM3UKy4Z4giRbReR6gjWuvHUqB"; return &s }(),
+				MerchantData: dpp.Merchant{
+					ExtendedData: map[string]interface{}{"paymentReference": "omgwow"},
+				},
+			},
+			args: dpp.PaymentCreateArgs{
+				PaymentID: "abc123",
+			},
+			expErr: errors.New("[refundTo: '112D2adLM3UKy4Z4giRbReR6gjWuvHUqB' is not a testnet address]"),
+		},
+		"invalid refund address is rejected": {
+			paymentCreateFn: func(context.Context, dpp.PaymentCreateArgs, dpp.Payment) (*dpp.PaymentACK, error) {
+				return &dpp.PaymentACK{}, nil
+			},
+			req: dpp.Payment{
+				RawTx:    func() *string { s := "01000000000000000000"; return &s }(),
+				RefundTo: func() *string { s := "1notanaddress"; return &s }(),
+				MerchantData: dpp.Merchant{
+					ExtendedData: map[string]interface{}{"paymentReference": "omgwow"},
+				},
+			},
+			args: dpp.PaymentCreateArgs{
+				PaymentID: "abc123",
+			},
+			expErr: errors.New("[refundTo: '1notanaddress' is not a paymail, address or hex locking script]"),
+		},
+		"proof callback tokens are recorded": {
+			paymentCreateFn: func(context.Context, dpp.PaymentCreateArgs, dpp.Payment) (*dpp.PaymentACK, error) {
+				return &dpp.PaymentACK{}, nil
+			},
+			req: dpp.Payment{
+				RawTx: func() *string { s := "01000000000000000000"; return &s }(),
+				ProofCallbacks: map[string]dpp.ProofCallback{
+					"https://proxy.com/api/v1/proofs/d21633ba23f70118185227be58a63527675641ad37967e2aa461559f577aec43": {Token: "abc"},
+				},
+				MerchantData: dpp.Merchant{
+					ExtendedData: map[string]interface{}{"paymentReference": "omgwow"},
+				},
+			},
+			args: dpp.PaymentCreateArgs{
+				PaymentID: "abc123",
+			},
+			expAudits: 1,
+			expToken: &server.ProofToken{
+				TxID:      "d21633ba23f70118185227be58a63527675641ad37967e2aa461559f577aec43",
+				PaymentID: "abc123",
+				Tokens:    []string{"abc"},
+			},
+		},
+		"error recording proof callback tokens does not fail payment": {
+			paymentCreateFn: func(context.Context, dpp.PaymentCreateArgs, dpp.Payment) (*dpp.PaymentACK, error) {
+				return &dpp.PaymentACK{}, nil
+			},
+			tokenCreateFn: func(context.Context, server.ProofToken) error {
+				return errors.New("store full")
+			},
+			req: dpp.Payment{
+				RawTx: func() *string { s := "01000000000000000000"; return &s }(),
+				ProofCallbacks: map[string]dpp.ProofCallback{
+					"https://proxy.com/api/v1/proofs/d21633ba23f70118185227be58a63527675641ad37967e2aa461559f577aec43": {Token: "abc"},
+				},
+				MerchantData: dpp.Merchant{
+					ExtendedData: map[string]interface{}{"paymentReference": "omgwow"},
+				},
+			},
+			args: dpp.PaymentCreateArgs{
+				PaymentID: "abc123",
+			},
+			expToken: &server.ProofToken{
+				TxID:      "d21633ba23f70118185227be58a63527675641ad37967e2aa461559f577aec43",
+				PaymentID: "abc123",
+				Tokens:    []string{"abc"},
+			},
+			expAudits: 1,
+		},
+		"proof callback tokens of a rejected payment aren't recorded": {
+			paymentCreateFn: func(context.Context, dpp.PaymentCreateArgs, dpp.Payment) (*dpp.PaymentACK, error) {
+				return nil, errs.NewErrUnprocessable("422", "transaction does not pay the invoice")
+			},
+			req: dpp.Payment{
+				RawTx: func() *string { s := "01000000000000000000"; return &s }(),
+				ProofCallbacks: map[string]dpp.ProofCallback{
+					"https://proxy.com/api/v1/proofs/d21633ba23f70118185227be58a63527675641ad37967e2aa461559f577aec43": {Token: "abc"},
+				},
+				MerchantData: dpp.Merchant{
+					ExtendedData: map[string]interface{}{"paymentReference": "omgwow"},
+				},
+			},
+			args: dpp.PaymentCreateArgs{
+				PaymentID: "abc123",
+			},
+			expAudits: 1,
+			expErr:    errors.New("Unprocessable: transaction does not pay the invoice"),
+		},
+		"peer channel is added to the ack of a paid payment": {
+			paymentCreateFn: func(context.Context, dpp.PaymentCreateArgs, dpp.Payment) (*dpp.PaymentACK, error) {
+				return &dpp.PaymentACK{}, nil
+			},
+			peerChannelFn: func(ctx context.Context, args dpp.PaymentCreateArgs) (*dpp.PeerChannelData, error) {
+				return &dpp.PeerChannelData{Host: "dpp.example.com", Path: server.PeerChannelPath, ChannelID: "ch1", Token: "abc"}, nil
+			},
+			req: dpp.Payment{
+				RawTx: func() *string { s := "01000000000000000000"; return &s }(),
+				MerchantData: dpp.Merchant{
+					ExtendedData: map[string]interface{}{"paymentReference": "omgwow"},
+				},
+			},
+			args: dpp.PaymentCreateArgs{
+				PaymentID: "abc123",
+			},
+			expAudits:      1,
+			expPeerChannel: &dpp.PeerChannelData{Host: "dpp.example.com", Path: server.PeerChannelPath, ChannelID: "ch1", Token: "abc"},
+		},
+		"peer channel of the wallet is kept": {
+			paymentCreateFn: func(context.Context, dpp.PaymentCreateArgs, dpp.Payment) (*dpp.PaymentACK, error) {
+				return &dpp.PaymentACK{PeerChannel: &dpp.PeerChannelData{Host: "wallet.example.com", ChannelID: "wallet"}}, nil
+			},
+			peerChannelFn: func(ctx context.Context, args dpp.PaymentCreateArgs) (*dpp.PeerChannelData, error) {
+				return nil, errors.New("should not be called")
+			},
+			req: dpp.Payment{
+				RawTx: func() *string { s := "01000000000000000000"; return &s }(),
+				MerchantData: dpp.Merchant{
+					ExtendedData: map[string]interface{}{"paymentReference": "omgwow"},
+				},
+			},
+			args: dpp.PaymentCreateArgs{
+				PaymentID: "abc123",
+			},
+			expAudits:      1,
+			expPeerChannel: &dpp.PeerChannelData{Host: "wallet.example.com", ChannelID: "wallet"},
+		},
+		"peer channel error does not fail payment": {
+			paymentCreateFn: func(context.Context, dpp.PaymentCreateArgs, dpp.Payment) (*dpp.PaymentACK, error) {
+				return &dpp.PaymentACK{}, nil
+			},
+			peerChannelFn: func(ctx context.Context, args dpp.PaymentCreateArgs) (*dpp.PeerChannelData, error) {
+				return nil, errors.New("store full")
+			},
+			req: dpp.Payment{
+				RawTx: func() *string { s := "01000000000000000000"; return &s }(),
+				MerchantData: dpp.Merchant{
+					ExtendedData: map[string]interface{}{"paymentReference": "omgwow"},
+				},
+			},
+			args: dpp.PaymentCreateArgs{
+				PaymentID: "abc123",
+			},
+			expAudits: 1,
+		},
 		"error on payment create is handled": {
 			paymentCreateFn: func(context.Context, dpp.PaymentCreateArgs, dpp.Payment) (*dpp.PaymentACK, error) {
 				return nil, errors.New("lol oh boi")
@@ -76,24 +356,120 @@ func TestPayment_Create(t *testing.T) {
 				PaymentID: "abc123",
 			},
 			req: dpp.Payment{
-				RawTx: func() *string { s := "01000000000000000000"; return &s }(),
+				RawTx:    func() *string { s := "01000000000000000000"; return &s }(),
+				RefundTo: func() *string { s := "me@paymail.com"; return &s }(),
 				MerchantData: dpp.Merchant{
 					ExtendedData: map[string]interface{}{"paymentReference": "omgwow"},
 				},
 			},
+			expAudits: 1,
+			expStatus: &server.PaymentStatus{
+				PaymentID: "abc123",
+				State:     server.PaymentStateRejected,
+				Memo:      "an unexpected error occurred",
+			},
 			expErr: errors.New("lol oh boi"),
 		},
+		"payment rejected by the wallet keeps the reason in the memo": {
+			paymentCreateFn: func(context.Context, dpp.PaymentCreateArgs, dpp.Payment) (*dpp.PaymentACK, error) {
+				return nil, errs.NewErrUnprocessable("422", "insufficient fee")
+			},
+			args: dpp.PaymentCreateArgs{
+				PaymentID: "abc123",
+			},
+			req: dpp.Payment{
+				RawTx: func() *string { s := "01000000000000000000"; return &s }(),
+				MerchantData: dpp.Merchant{
+					ExtendedData: map[string]interface{}{"paymentReference": "omgwow"},
+				},
+			},
+			expAudits: 1,
+			expStatus: &server.PaymentStatus{
+				PaymentID: "abc123",
+				State:     server.PaymentStateRejected,
+				Memo:      "insufficient fee",
+			},
+			expErr: errors.New("Unprocessable: insufficient fee"),
+		},
 	}
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
+			auditLog := &mocks.AuditLoggerMock{
+				AuditLogFunc: func(ctx context.Context, evt server.AuditEvent) error {
+					assert.Equal(t, server.AuditPayment, evt.Type)
+					assert.Equal(t, test.args.PaymentID, evt.PaymentID)
+					assert.Equal(t, "d21633ba23f70118185227be58a63527675641ad37967e2aa461559f577aec43", evt.TxID)
+					if test.auditLogFn != nil {
+						return test.auditLogFn(ctx, evt)
+					}
+					return nil
+				},
+			}
+			refundWtr := &mocks.RefundWriterMock{
+				RefundCreateFunc: func(ctx context.Context, req server.Refund) error {
+					if test.refundCreateFn != nil {
+						return test.refundCreateFn(ctx, req)
+					}
+					return nil
+				},
+			}
+			tokenWtr := &mocks.ProofTokenWriterMock{
+				ProofTokenCreateFunc: func(ctx context.Context, req server.ProofToken) error {
+					if test.tokenCreateFn != nil {
+						return test.tokenCreateFn(ctx, req)
+					}
+					return nil
+				},
+			}
+			statusWtr := &mocks.PaymentStatusWriterMock{
+				PaymentStatusUpdateFunc: func(context.Context, server.PaymentStatus) error {
+					return nil
+				},
+			}
+			var channels server.PeerChannelCreator
+			if test.peerChannelFn != nil {
+				channels = &mocks.PeerChannelCreatorMock{PeerChannelCreateFunc: test.peerChannelFn}
+			}
 			svc := service.NewPayment(
 				log.Noop{},
 				&dppMocks.PaymentWriterMock{
 					PaymentCreateFunc: test.paymentCreateFn,
-				})
+				},
+				refundWtr,
+				tokenWtr,
+				statusWtr,
+				channels,
+				auditLog,
+				&config.Deployment{Network: config.NetworkTestnet})
 
-			_, err := svc.PaymentCreate(context.TODO(), test.args, test.req)
+			ack, err := svc.PaymentCreate(context.TODO(), test.args, test.req)
+			if ack != nil {
+				assert.Equal(t, test.expPeerChannel, ack.PeerChannel)
+			}
+			assert.Len(t, auditLog.AuditLogCalls(), test.expAudits)
+			assert.Len(t, statusWtr.PaymentStatusUpdateCalls(), test.expAudits)
+			if test.expStatus != nil {
+				assert.Equal(t, *test.expStatus, statusWtr.PaymentStatusUpdateCalls()[0].Req)
+			}
+			if test.expRefund != nil {
+				assert.Len(t, refundWtr.RefundCreateCalls(), 1)
+				refund := refundWtr.RefundCreateCalls()[0].Req
+				assert.False(t, refund.CreatedAt.IsZero())
+				refund.CreatedAt = test.expRefund.CreatedAt
+				assert.Equal(t, *test.expRefund, refund)
+			} else {
+				assert.Empty(t, refundWtr.RefundCreateCalls())
+			}
+			if test.expToken != nil {
+				assert.Len(t, tokenWtr.ProofTokenCreateCalls(), 1)
+				token := tokenWtr.ProofTokenCreateCalls()[0].Req
+				assert.False(t, token.CreatedAt.IsZero())
+				token.CreatedAt = test.expToken.CreatedAt
+				assert.Equal(t, *test.expToken, token)
+			} else {
+				assert.Empty(t, tokenWtr.ProofTokenCreateCalls())
+			}
 			if test.expErr != nil {
 				assert.Error(t, err)
 				assert.EqualError(t, err, test.expErr.Error())
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-dpp"
+	"github.com/pkg/errors"
+	"github.com/theflyingcodr/lathos"
+	"github.com/theflyingcodr/lathos/errs"
+	"go.opentelemetry.io/otel/attribute"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/config"
+	"github.com/bitcoin-sv/dpp-proxy/tracing"
+)
+
+// Memos returned to customers in the ack of a queued payment.
+const (
+	queuedPaymentMemo    = "payment received, it will be processed when the merchant is online"
+	broadcastPaymentMemo = "payment broadcast, it will be processed when the merchant is online"
+)
+
+type paymentQueue struct {
+	paymentWtr  dpp.PaymentWriter
+	invoices    server.InvoiceReader
+	store       server.PaymentQueueStore
+	statusWtr   server.PaymentStatusWriter
+	broadcaster server.Broadcaster
+	tokens      server.ProofTokenReaderWriter
+	srvCfg      *config.Server
+}
+
+// NewPaymentQueue will setup and return a new payment queue. Payments are sent to the
+// merchant wallet with paymentWtr, if the wallet isn't connected payments for invoices
+// registered in invoices are checked against the invoice and queued in store until the
+// wallet collects them. Collected payments are marked as paid, or rejected, with statusWtr.
+//
+// If broadcaster is not nil queued payments are broadcast with it, proof callbacks
+// are requested to the proxy under the srvCfg fqdn with a token recorded in tokens.
+func NewPaymentQueue(paymentWtr dpp.PaymentWriter, invoices server.InvoiceReader, store server.PaymentQueueStore,
+	statusWtr server.PaymentStatusWriter, broadcaster server.Broadcaster, tokens server.ProofTokenReaderWriter,
+	srvCfg *config.Server) *paymentQueue {
+	return &paymentQueue{
+		paymentWtr:  paymentWtr,
+		invoices:    invoices,
+		store:       store,
+		statusWtr:   statusWtr,
+		broadcaster: broadcaster,
+		tokens:      tokens,
+		srvCfg:      srvCfg,
+	}
+}
+
+// PaymentCreate will send the payment to the merchant wallet, queueing it if the wallet
+// isn't connected and the invoice was registered. Other errors, such as the wallet not
+// responding in time, are returned as the wallet may have received the payment.
+func (p *paymentQueue) PaymentCreate(ctx context.Context, args dpp.PaymentCreateArgs, req dpp.Payment) (*dpp.PaymentACK, error) {
+	ctx, span := tracing.StartSpan(ctx, "service.paymentQueue.PaymentCreate", attribute.String("paymentID", args.PaymentID))
+	defer span.End()
+	ack, err := p.paymentWtr.PaymentCreate(ctx, args, req)
+	if err == nil || !server.IsWalletOffline(err) {
+		return ack, err
+	}
+	inv, invErr := p.invoices.Invoice(ctx, server.InvoiceArgs{PaymentID: args.PaymentID})
+	if invErr != nil {
+		if lathos.IsNotFound(invErr) {
+			// only registered invoices can be checked, so the wallet error stands.
+			return nil, err
+		}
+		tracing.RecordError(span, invErr)
+		return nil, errors.WithMessagef(invErr, "failed to get invoice for paymentID '%s'", args.PaymentID)
+	}
+	if !inv.PaymentRequest.ExpirationTimestamp.After(time.Now()) {
+		return nil, errs.NewErrUnprocessable("422", "payment request has expired")
+	}
+	if req.RawTx == nil {
+		return nil, errs.NewErrUnprocessable("422", "a rawTx is required while the merchant is offline")
+	}
+	tx, txErr := bt.NewTxFromString(*req.RawTx)
+	if txErr != nil {
+		return nil, errs.NewErrUnprocessable("422", "payment transaction cannot be read")
+	}
+	if err := paysOutputs(tx, inv.PaymentRequest.Destinations.Outputs); err != nil {
+		return nil, err
+	}
+	// the payment is queued first, so a second payment for the invoice is never broadcast.
+	if err := p.store.PaymentEnqueue(ctx, server.QueuedPayment{
+		PaymentID:  args.PaymentID,
+		Payment:    req,
+		TxID:       tx.TxID(),
+		ReceivedAt: time.Now().UTC(),
+		Broadcast:  p.broadcaster != nil,
+	}); err != nil {
+		tracing.RecordError(span, err)
+		return nil, errors.WithMessagef(err, "failed to queue payment for paymentID '%s'", args.PaymentID)
+	}
+	ack = &dpp.PaymentACK{
+		ID:   args.PaymentID,
+		TxID: tx.TxID(),
+		Memo: queuedPaymentMemo,
+	}
+	if p.broadcaster == nil {
+		return ack, nil
+	}
+	if err := p.broadcast(ctx, args.PaymentID, tx, req); err != nil {
+		tracing.RecordError(span, err)
+		// the payment wasn't taken, so the customer can pay again.
+		if dqErr := p.store.PaymentDequeue(ctx, server.QueuedPaymentArgs{PaymentID: args.PaymentID}); dqErr != nil {
+			return nil, errors.WithMessagef(dqErr, "failed to dequeue payment for paymentID '%s'", args.PaymentID)
+		}
+		return nil, errors.WithMessagef(err, "failed to broadcast payment for paymentID '%s'", args.PaymentID)
+	}
+	ack.Memo = broadcastPaymentMemo
+	return ack, nil
+}
+
+// broadcast submits the payment transaction, proof callbacks are sent to the proxy with a
+// token generated for the broadcast, which is recorded before the transaction is broadcast
+// so callbacks are accepted as soon as they are sent.
+func (p *paymentQueue) broadcast(ctx context.Context, paymentID string, tx *bt.Tx, req dpp.Payment) error {
+	token, err := p.callbackToken(ctx, paymentID, tx, req)
+	if err != nil {
+		return err
+	}
+	return p.broadcaster.Broadcast(ctx, server.Broadcast{
+		PaymentID:     paymentID,
+		Tx:            tx,
+		CallbackURL:   server.ProofCallbackURL(p.srvCfg.FQDN, tx.TxID(), paymentID),
+		CallbackToken: token,
+	})
+}
+
+// callbackToken returns the token proof callbacks for a broadcast transaction present. A new
+// token is recorded along with the proof callback tokens supplied with the payment, unless the
+// transaction already has tokens recorded for the invoice, such as when a failed broadcast is
+// retried, then the first is reused. Tokens recorded for another invoice aren't replaced.
+func (p *paymentQueue) callbackToken(ctx context.Context, paymentID string, tx *bt.Tx, req dpp.Payment) (string, error) {
+	existing, err := p.tokens.ProofToken(ctx, tx.TxID())
+	if err == nil && existing.PaymentID == paymentID && len(existing.Tokens) > 0 {
+		return existing.Tokens[0], nil
+	}
+	if err != nil && !lathos.IsNotFound(err) {
+		return "", errors.WithMessage(err, "failed to read proof callback tokens")
+	}
+	bb := make([]byte, 32)
+	if _, err := rand.Read(bb); err != nil {
+		return "", errors.Wrap(err, "failed to generate proof callback token")
+	}
+	token := server.ProofToken{
+		TxID:      tx.TxID(),
+		PaymentID: paymentID,
+		Tokens:    []string{hex.EncodeToString(bb)},
+		CreatedAt: time.Now().UTC(),
+	}
+	if supplied, ok := newProofToken(paymentID, req); ok {
+		token.Tokens = append(token.Tokens, supplied.Tokens...)
+	}
+	if err := p.tokens.ProofTokenCreate(ctx, token); err != nil {
+		return "", errors.WithMessage(err, "failed to record proof callback token")
+	}
+	return token.Tokens[0], nil
+}
+
+// QueuedPayments will return the payments waiting to be collected by the merchant wallet.
+func (p *paymentQueue) QueuedPayments(ctx context.Context) ([]server.QueuedPayment, error) {
+	ctx, span := tracing.StartSpan(ctx, "service.paymentQueue.QueuedPayments")
+	defer span.End()
+	pp, err := p.store.QueuedPayments(ctx)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, errors.WithMessage(err, "failed to get queued payments")
+	}
+	return pp, nil
+}
+
+// QueuedPayment will return the payment queued for an invoice.
+func (p *paymentQueue) QueuedPayment(ctx context.Context, args server.QueuedPaymentArgs) (*server.QueuedPayment, error) {
+	ctx, span := tracing.StartSpan(ctx, "service.paymentQueue.QueuedPayment", attribute.String("paymentID", args.PaymentID))
+	defer span.End()
+	if err := args.Validate(); err != nil {
+		return nil, err
+	}
+	qp, err := p.store.QueuedPayment(ctx, args)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, errors.WithMessagef(err, "failed to get queued payment for paymentID '%s'", args.PaymentID)
+	}
+	return qp, nil
+}
+
+// QueuedPaymentAck will remove a payment collected by the merchant wallet from the queue,
+// marking it as paid, or rejected if the wallet returned an error.
+func (p *paymentQueue) QueuedPaymentAck(ctx context.Context, args server.QueuedPaymentArgs, req dpp.PaymentACK) error {
+	ctx, span := tracing.StartSpan(ctx, "service.paymentQueue.QueuedPaymentAck", attribute.String("paymentID", args.PaymentID))
+	defer span.End()
+	if err := args.Validate(); err != nil {
+		return err
+	}
+	qp, err := p.store.QueuedPayment(ctx, args)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return errors.WithMessagef(err, "failed to get queued payment for paymentID '%s'", args.PaymentID)
+	}
+	if err := p.store.PaymentDequeue(ctx, args); err != nil {
+		tracing.RecordError(span, err)
+		return errors.WithMessagef(err, "failed to dequeue payment for paymentID '%s'", args.PaymentID)
+	}
+	status := server.PaymentStatus{
+		PaymentID: args.PaymentID,
+		State:     server.PaymentStatePaid,
+		TxID:      qp.TxID,
+	}
+	if req.Error != 0 {
+		status.State = server.PaymentStateRejected
+		status.TxID = ""
+		status.Memo = req.Memo
+	}
+	if err := p.statusWtr.PaymentStatusUpdate(ctx, status); err != nil {
+		return errors.Wrapf(err, "failed to record status for paymentID '%s'", args.PaymentID)
+	}
+	return nil
+}
+
+// isQueued returns true if the ack is for a payment queued while the merchant wallet was offline,
+// warnings may have been added to the memo.
+func isQueued(ack *dpp.PaymentACK) bool {
+	return ack.Error == 0 && (strings.HasPrefix(ack.Memo, queuedPaymentMemo) || strings.HasPrefix(ack.Memo, broadcastPaymentMemo))
+}
+
+// paysOutputs checks the transaction pays each of the outputs.
+func paysOutputs(tx *bt.Tx, outputs []dpp.Output) error {
+	paid := make([]bool, len(tx.Outputs))
+	for i, o := range outputs {
+		found := false
+		for j, txo := range tx.Outputs {
+			if !paid[j] && txo.Satoshis == o.Amount && o.LockingScript != nil && txo.LockingScript.Equals(o.LockingScript) {
+				paid[j], found = true, true
+				break
+			}
+		}
+		if !found {
+			return errs.NewErrUnprocessablef("422", "transaction does not pay output %d of %d satoshis", i, o.Amount)
+		}
+	}
+	return nil
+}
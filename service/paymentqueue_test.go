@@ -0,0 +1,289 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/libsv/go-dpp"
+	dppMocks "github.com/libsv/go-dpp/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/theflyingcodr/lathos/errs"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/config"
+	"github.com/bitcoin-sv/dpp-proxy/data/memory"
+	"github.com/bitcoin-sv/dpp-proxy/log"
+	"github.com/bitcoin-sv/dpp-proxy/mocks"
+	"github.com/bitcoin-sv/dpp-proxy/service"
+)
+
+// payingTx returns a transaction paying satoshis to the invoice script.
+func payingTx(t *testing.T, satoshis uint64) *bt.Tx {
+	s, err := bscript.NewFromHexString(invoiceScript)
+	assert.NoError(t, err)
+	tx := bt.NewTx()
+	tx.AddOutput(&bt.Output{Satoshis: satoshis, LockingScript: s})
+	return tx
+}
+
+func queuedPayment(tx *bt.Tx) dpp.Payment {
+	rawTx := tx.String()
+	return dpp.Payment{
+		RawTx: &rawTx,
+		MerchantData: dpp.Merchant{
+			ExtendedData: map[string]interface{}{"paymentReference": "abc123"},
+		},
+	}
+}
+
+func TestPaymentQueue_PaymentCreate(t *testing.T) {
+	offline := server.NewErrWalletOffline("503", "the wallet is not connected")
+	tests := map[string]struct {
+		paymentCreateFn func(context.Context, dpp.PaymentCreateArgs, dpp.Payment) (*dpp.PaymentACK, error)
+		paymentID       string
+		expires         time.Time
+		satoshis        uint64
+		expQueued       bool
+		expErr          error
+	}{
+		"payment accepted by the wallet isn't queued": {
+			paymentCreateFn: func(context.Context, dpp.PaymentCreateArgs, dpp.Payment) (*dpp.PaymentACK, error) {
+				return &dpp.PaymentACK{Memo: "thanks"}, nil
+			},
+			paymentID: "abc123",
+			satoshis:  1000,
+		},
+		"payment rejected by the wallet isn't queued": {
+			paymentCreateFn: func(context.Context, dpp.PaymentCreateArgs, dpp.Payment) (*dpp.PaymentACK, error) {
+				return nil, errs.NewErrUnprocessable("422", "insufficient fee")
+			},
+			paymentID: "abc123",
+			satoshis:  1000,
+			expErr:    errors.New("Unprocessable: insufficient fee"),
+		},
+		"payment for a registered invoice is queued while the wallet is offline": {
+			paymentID: "abc123",
+			satoshis:  1000,
+			expQueued: true,
+		},
+		"payment the wallet didn't respond to in time isn't queued": {
+			paymentCreateFn: func(context.Context, dpp.PaymentCreateArgs, dpp.Payment) (*dpp.PaymentACK, error) {
+				return nil, errs.NewErrNotAvailable("503", "the wallet did not respond in time, retry the payment")
+			},
+			paymentID: "abc123",
+			satoshis:  1000,
+			expErr:    errors.New("Not available: the wallet did not respond in time, retry the payment"),
+		},
+		"payment for an unregistered invoice returns the wallet error": {
+			paymentID: "def456",
+			satoshis:  1000,
+			expErr:    errors.New("Not available: the wallet is not connected"),
+		},
+		"payment not paying the invoice is rejected": {
+			paymentID: "abc123",
+			satoshis:  999,
+			expErr:    errors.New("Unprocessable: transaction does not pay output 0 of 1000 satoshis"),
+		},
+		"payment for an expired invoice is rejected": {
+			paymentID: "abc123",
+			expires:   time.Now().Add(50 * time.Millisecond),
+			satoshis:  1000,
+			expErr:    errors.New("Unprocessable: payment request has expired"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if test.paymentCreateFn == nil {
+				test.paymentCreateFn = func(context.Context, dpp.PaymentCreateArgs, dpp.Payment) (*dpp.PaymentACK, error) {
+					return nil, offline
+				}
+			}
+			expires := time.Now().Add(time.Hour)
+			if !test.expires.IsZero() {
+				expires = test.expires
+			}
+			invoices := memory.NewInvoices()
+			_, err := service.NewInvoice(invoices, &config.Server{FQDN: "dpp.example.com"}, &config.Deployment{Network: config.NetworkRegtest}).
+				InvoiceCreate(context.Background(), invoiceCreate("abc123", expires))
+			assert.NoError(t, err)
+			if !test.expires.IsZero() {
+				time.Sleep(time.Until(test.expires))
+			}
+			statusSvc := service.NewPaymentStatus(memory.NewPaymentStatuses(time.Hour))
+			svc := service.NewPaymentQueue(&dppMocks.PaymentWriterMock{PaymentCreateFunc: test.paymentCreateFn},
+				invoices, memory.NewPaymentQueue(), statusSvc, nil, nil, &config.Server{FQDN: "dpp.example.com"})
+
+			tx := payingTx(t, test.satoshis)
+			ack, err := svc.PaymentCreate(context.Background(), dpp.PaymentCreateArgs{PaymentID: test.paymentID}, queuedPayment(tx))
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+			queued, err := svc.QueuedPayments(context.Background())
+			assert.NoError(t, err)
+			if !test.expQueued {
+				assert.Equal(t, "thanks", ack.Memo)
+				assert.Empty(t, queued)
+				return
+			}
+			assert.Equal(t, tx.TxID(), ack.TxID)
+			assert.Zero(t, ack.Error)
+			assert.Len(t, queued, 1)
+			assert.Equal(t, tx.TxID(), queued[0].TxID)
+
+			_, err = svc.PaymentCreate(context.Background(), dpp.PaymentCreateArgs{PaymentID: test.paymentID}, queuedPayment(tx))
+			assert.EqualError(t, err, "failed to queue payment for paymentID 'abc123': Item already exists: a payment has already been received for the invoice")
+		})
+	}
+}
+
+func TestPaymentQueue_PaymentCreate_Broadcast(t *testing.T) {
+	tests := map[string]struct {
+		tokenPaymentID string
+		broadcastErr   error
+		expMemo        string
+		expErr         error
+	}{
+		"broadcast payment is queued": {
+			expMemo: "payment broadcast, it will be processed when the merchant is online",
+		},
+		"rejected payment isn't queued": {
+			broadcastErr: errs.NewErrUnprocessable("422", "transaction was rejected by the miner: missing inputs"),
+			expErr: errors.New("failed to broadcast payment for paymentID 'abc123': " +
+				"Unprocessable: transaction was rejected by the miner: missing inputs"),
+		},
+		"transaction with tokens of another invoice isn't broadcast": {
+			tokenPaymentID: "def456",
+			expErr: errors.New("failed to broadcast payment for paymentID 'abc123': failed to record proof callback token: " +
+				"Item already exists: proof tokens are already recorded for the transaction"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			invoices := memory.NewInvoices()
+			_, err := service.NewInvoice(invoices, &config.Server{FQDN: "dpp.example.com"}, &config.Deployment{Network: config.NetworkRegtest}).
+				InvoiceCreate(context.Background(), invoiceCreate("abc123", time.Now().Add(time.Hour)))
+			assert.NoError(t, err)
+			tx := payingTx(t, 1000)
+			tokens := memory.NewProofTokens(time.Hour)
+			if test.tokenPaymentID != "" {
+				assert.NoError(t, tokens.ProofTokenCreate(context.Background(), server.ProofToken{
+					TxID:      tx.TxID(),
+					PaymentID: test.tokenPaymentID,
+					Tokens:    []string{"def"},
+					CreatedAt: time.Now().UTC(),
+				}))
+			}
+			broadcaster := &mocks.BroadcasterMock{
+				BroadcastFunc: func(ctx context.Context, req server.Broadcast) error {
+					assert.Equal(t, "abc123", req.PaymentID)
+					assert.Equal(t, tx.TxID(), req.Tx.TxID())
+					assert.Equal(t, "http://dpp.example.com/api/v1/proofs/"+tx.TxID()+"?i=abc123", req.CallbackURL)
+					// the callback token is recorded before the broadcast, with the tokens of the payment.
+					token, err := tokens.ProofToken(ctx, tx.TxID())
+					assert.NoError(t, err)
+					assert.Equal(t, "abc123", token.PaymentID)
+					assert.Equal(t, []string{req.CallbackToken, "abc"}, token.Tokens)
+					assert.Len(t, req.CallbackToken, 64)
+					return test.broadcastErr
+				},
+			}
+			svc := service.NewPaymentQueue(&dppMocks.PaymentWriterMock{
+				PaymentCreateFunc: func(context.Context, dpp.PaymentCreateArgs, dpp.Payment) (*dpp.PaymentACK, error) {
+					return nil, server.NewErrWalletOffline("503", "the wallet is not connected")
+				},
+			}, invoices, memory.NewPaymentQueue(), service.NewPaymentStatus(memory.NewPaymentStatuses(time.Hour)), broadcaster,
+				tokens, &config.Server{FQDN: "dpp.example.com"})
+
+			payment := queuedPayment(tx)
+			payment.ProofCallbacks = map[string]dpp.ProofCallback{"https://wallet.example.com/proofs": {Token: "abc"}}
+			ack, err := svc.PaymentCreate(context.Background(), dpp.PaymentCreateArgs{PaymentID: "abc123"}, payment)
+			queued, qErr := svc.QueuedPayments(context.Background())
+			assert.NoError(t, qErr)
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+				assert.Empty(t, queued)
+				if test.broadcastErr == nil {
+					assert.Empty(t, broadcaster.BroadcastCalls())
+					return
+				}
+				// a retried broadcast reuses the recorded callback token.
+				_, err = svc.PaymentCreate(context.Background(), dpp.PaymentCreateArgs{PaymentID: "abc123"}, payment)
+				assert.EqualError(t, err, test.expErr.Error())
+				calls := broadcaster.BroadcastCalls()
+				assert.Len(t, calls, 2)
+				assert.Equal(t, calls[0].Req.CallbackToken, calls[1].Req.CallbackToken)
+				return
+			}
+			assert.Len(t, broadcaster.BroadcastCalls(), 1)
+			assert.NoError(t, err)
+			assert.Equal(t, tx.TxID(), ack.TxID)
+			assert.Equal(t, test.expMemo, ack.Memo)
+			assert.Len(t, queued, 1)
+			assert.True(t, queued[0].Broadcast)
+		})
+	}
+}
+
+func TestPaymentQueue_QueuedPaymentAck(t *testing.T) {
+	tests := map[string]struct {
+		ack       dpp.PaymentACK
+		expStatus server.PaymentState
+		expMemo   string
+	}{
+		"accepted payment is paid": {
+			expStatus: server.PaymentStatePaid,
+		},
+		"rejected payment is rejected": {
+			ack:       dpp.PaymentACK{Error: 1, Memo: "invoice cancelled"},
+			expStatus: server.PaymentStateRejected,
+			expMemo:   "invoice cancelled",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			invoices := memory.NewInvoices()
+			_, err := service.NewInvoice(invoices, &config.Server{FQDN: "dpp.example.com"}, &config.Deployment{Network: config.NetworkRegtest}).
+				InvoiceCreate(context.Background(), invoiceCreate("abc123", time.Now().Add(time.Hour)))
+			assert.NoError(t, err)
+			statusSvc := service.NewPaymentStatus(memory.NewPaymentStatuses(time.Hour))
+			svc := service.NewPaymentQueue(&dppMocks.PaymentWriterMock{
+				PaymentCreateFunc: func(context.Context, dpp.PaymentCreateArgs, dpp.Payment) (*dpp.PaymentACK, error) {
+					return nil, server.NewErrWalletOffline("503", "the wallet is not connected")
+				},
+			}, invoices, memory.NewPaymentQueue(), statusSvc, nil, nil, &config.Server{FQDN: "dpp.example.com"})
+			args := server.QueuedPaymentArgs{PaymentID: "abc123"}
+
+			assert.EqualError(t, svc.QueuedPaymentAck(context.Background(), args, test.ack),
+				"failed to get queued payment for paymentID 'abc123': Not found: no payment queued for invoice")
+
+			// payments are queued through the payment service, which records them as queued.
+			paymentSvc := service.NewPayment(log.Noop{}, svc, memory.NewRefunds(time.Hour), memory.NewProofTokens(time.Hour), statusSvc, nil,
+				&mocks.AuditLoggerMock{
+					AuditLogFunc: func(context.Context, server.AuditEvent) error {
+						return nil
+					},
+				}, &config.Deployment{Network: config.NetworkRegtest})
+			tx := payingTx(t, 1000)
+			_, err = paymentSvc.PaymentCreate(context.Background(), dpp.PaymentCreateArgs{PaymentID: "abc123"}, queuedPayment(tx))
+			assert.NoError(t, err)
+			status, err := statusSvc.PaymentStatus(context.Background(), server.PaymentStatusArgs{PaymentID: "abc123"})
+			assert.NoError(t, err)
+			assert.Equal(t, server.PaymentStateQueued, status.State)
+			assert.Equal(t, tx.TxID(), status.TxID)
+
+			assert.NoError(t, svc.QueuedPaymentAck(context.Background(), args, test.ack))
+			_, err = svc.QueuedPayment(context.Background(), args)
+			assert.EqualError(t, err, "failed to get queued payment for paymentID 'abc123': Not found: no payment queued for invoice")
+			status, err = statusSvc.PaymentStatus(context.Background(), server.PaymentStatusArgs{PaymentID: "abc123"})
+			assert.NoError(t, err)
+			assert.Equal(t, test.expStatus, status.State)
+			assert.Equal(t, test.expMemo, status.Memo)
+		})
+	}
+}
@@ -6,22 +6,41 @@ import (
 	"github.com/libsv/go-dpp"
 	"github.com/pkg/errors"
 	validator "github.com/theflyingcodr/govalidator"
+	"go.opentelemetry.io/otel/attribute"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/config"
+	"github.com/bitcoin-sv/dpp-proxy/tracing"
 )
 
 type paymentRequest struct {
-	prRdr dpp.PaymentRequestReader
+	prRdr     dpp.PaymentRequestReader
+	fees      server.FeeService
+	policy    server.PaymentRequestPolicy
+	statusWtr server.PaymentStatusWriter
+	auditLog  server.AuditLogger
+	deployCfg *config.Deployment
 }
 
 // NewPaymentRequest will setup and return a new PaymentRequest service that will generate outputs
-// using the provided outputter which is defined in server config.
-func NewPaymentRequest(prRdr dpp.PaymentRequestReader) *paymentRequest {
+// using the provided outputter which is defined in server config. Payments are marked as
+// requested with statusWtr. If fees is not nil, missing fees are filled and fees kept within
+// limits by it. If policy is not nil, payment requests breaking it aren't served.
+func NewPaymentRequest(prRdr dpp.PaymentRequestReader, fees server.FeeService, policy server.PaymentRequestPolicy, statusWtr server.PaymentStatusWriter, auditLog server.AuditLogger, deployCfg *config.Deployment) *paymentRequest {
 	return &paymentRequest{
-		prRdr: prRdr,
+		prRdr:     prRdr,
+		fees:      fees,
+		policy:    policy,
+		statusWtr: statusWtr,
+		auditLog:  auditLog,
+		deployCfg: deployCfg,
 	}
 }
 
 // PaymentRequest handles setting up a new PaymentRequest response and will validate that we have a paymentID.
 func (p *paymentRequest) PaymentRequest(ctx context.Context, args dpp.PaymentRequestArgs) (*dpp.PaymentRequest, error) {
+	ctx, span := tracing.StartSpan(ctx, "service.paymentRequest.PaymentRequest", attribute.String("paymentID", args.PaymentID))
+	defer span.End()
 	if err := validator.New().
 		Validate("paymentID", validator.NotEmpty(args.PaymentID)); err.Err() != nil {
 		return nil, err
@@ -29,13 +48,53 @@ func (p *paymentRequest) PaymentRequest(ctx context.Context, args dpp.PaymentReq
 
 	pReq, err := p.prRdr.PaymentRequest(ctx, args)
 	if err != nil {
+		tracing.RecordError(span, err)
 		return nil, errors.Wrapf(err, "failed to get payment request for paymentID %s", args.PaymentID)
 	}
+	if err := checkNetwork(p.deployCfg.Network, pReq.Network); err != nil {
+		return nil, err
+	}
+	if p.fees != nil {
+		if pReq.FeeRate, err = p.fees.Fees(ctx, pReq.FeeRate); err != nil {
+			tracing.RecordError(span, err)
+			return nil, errors.Wrapf(err, "failed to set fees for paymentID %s", args.PaymentID)
+		}
+	}
 	if pReq.MerchantData != nil && pReq.MerchantData.ExtendedData == nil {
 		pReq.MerchantData.ExtendedData = map[string]interface{}{
 			"paymentReference": args.PaymentID,
 		}
 	}
+	if p.policy != nil {
+		if err := p.policy.PolicyCheck(ctx, pReq); err != nil {
+			tracing.RecordError(span, err)
+			return nil, errors.Wrapf(err, "invalid payment request for paymentID %s", args.PaymentID)
+		}
+	}
+	if err := p.auditLog.AuditLog(ctx, server.AuditEvent{
+		Type:      server.AuditPaymentRequest,
+		PaymentID: args.PaymentID,
+		Payload:   pReq,
+	}); err != nil {
+		return nil, errors.Wrapf(err, "failed to audit payment request for paymentID %s", args.PaymentID)
+	}
+	if err := p.statusWtr.PaymentStatusUpdate(ctx, requestedStatus(args.PaymentID, pReq)); err != nil {
+		return nil, errors.Wrapf(err, "failed to record status for paymentID %s", args.PaymentID)
+	}
 
 	return pReq, nil
 }
+
+// requestedStatus returns the status of a payment once its payment request has been
+// returned, expiring with the payment request.
+func requestedStatus(paymentID string, pr *dpp.PaymentRequest) server.PaymentStatus {
+	status := server.PaymentStatus{
+		PaymentID: paymentID,
+		State:     server.PaymentStateRequested,
+	}
+	if !pr.ExpirationTimestamp.IsZero() {
+		expires := pr.ExpirationTimestamp.UTC()
+		status.ExpiresAt = &expires
+	}
+	return status
+}
@@ -3,12 +3,16 @@ package service
 import (
 	"context"
 	"fmt"
-	"net/url"
 
-	"github.com/bitcoin-sv/dpp-proxy/config"
 	"github.com/libsv/go-dpp"
 	"github.com/pkg/errors"
 	validator "github.com/theflyingcodr/govalidator"
+	"github.com/theflyingcodr/lathos"
+	"go.opentelemetry.io/otel/attribute"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/config"
+	"github.com/bitcoin-sv/dpp-proxy/tracing"
 )
 
 // paymentRequestProxy simply acts as a pass-through to the data layer
@@ -16,45 +20,100 @@ import (
 // TODO - remove the other payment request service.
 type paymentRequestProxy struct {
 	preqRdr   dpp.PaymentRequestReader
+	invoices  server.InvoiceReader
+	fees      server.FeeService
+	policy    server.PaymentRequestPolicy
+	statusWtr server.PaymentStatusWriter
 	transCfg  *config.Transports
 	walletCfg *config.Server
+	deployCfg *config.Deployment
+	auditLog  server.AuditLogger
 }
 
 // NewPaymentRequestProxy will setup and return a new PaymentRequest service that will generate outputs
-// using the provided outputter which is defined in server config.
-func NewPaymentRequestProxy(preqRdr dpp.PaymentRequestReader, transCfg *config.Transports, walletCfg *config.Server) *paymentRequestProxy {
+// using the provided outputter which is defined in server config. Payments are marked as
+// requested with statusWtr. If invoices is not nil, payment requests for registered invoices
+// are served from it rather than the wallet. If fees is not nil, missing fees are filled and
+// wallet fees kept within limits by it, otherwise payment requests without fees are rejected.
+// If policy is not nil, payment requests breaking it aren't served.
+func NewPaymentRequestProxy(preqRdr dpp.PaymentRequestReader, invoices server.InvoiceReader, fees server.FeeService, policy server.PaymentRequestPolicy, statusWtr server.PaymentStatusWriter, transCfg *config.Transports, walletCfg *config.Server, deployCfg *config.Deployment, auditLog server.AuditLogger) *paymentRequestProxy {
 	return &paymentRequestProxy{
 		preqRdr:   preqRdr,
+		invoices:  invoices,
+		fees:      fees,
+		policy:    policy,
+		statusWtr: statusWtr,
 		transCfg:  transCfg,
 		walletCfg: walletCfg,
+		deployCfg: deployCfg,
+		auditLog:  auditLog,
 	}
 }
 
 // PaymentRequest will call to the data layer to return a full payment request.
 func (p *paymentRequestProxy) PaymentRequest(ctx context.Context, args dpp.PaymentRequestArgs) (*dpp.PaymentRequest, error) {
+	ctx, span := tracing.StartSpan(ctx, "service.paymentRequestProxy.PaymentRequest", attribute.String("paymentID", args.PaymentID))
+	defer span.End()
 	if err := validator.New().
 		Validate("paymentID", validator.NotEmpty(args.PaymentID)); err.Err() != nil {
 		return nil, err
 	}
-	resp, err := p.preqRdr.PaymentRequest(ctx, args)
+	resp, err := p.paymentRequest(ctx, args)
 	if err != nil {
+		tracing.RecordError(span, err)
 		return nil, errors.Wrapf(err, "failed to read payment request for paymentID %s", args.PaymentID)
 	}
+	if err := checkNetwork(p.deployCfg.Network, resp.Network); err != nil {
+		return nil, err
+	}
 	if len(resp.Destinations.Outputs) == 0 {
 		return nil, fmt.Errorf("no outputs received for paymentID %s", args.PaymentID)
 	}
+	if p.fees != nil {
+		if resp.FeeRate, err = p.fees.Fees(ctx, resp.FeeRate); err != nil {
+			tracing.RecordError(span, err)
+			return nil, errors.Wrapf(err, "failed to set fees for paymentID %s", args.PaymentID)
+		}
+	}
 	if resp.FeeRate == nil {
 		return nil, fmt.Errorf("no fees received for paymentID %s", args.PaymentID)
 	}
+	if p.policy != nil {
+		if err := p.policy.PolicyCheck(ctx, resp); err != nil {
+			tracing.RecordError(span, err)
+			return nil, errors.Wrapf(err, "invalid payment request for paymentID %s", args.PaymentID)
+		}
+	}
 
 	if p.transCfg.Mode == config.TransportModeHybrid {
-		u := url.URL{
-			Scheme: "http",
-			Host:   p.walletCfg.FQDN,
-			Path:   "/api/v1/payment/" + args.PaymentID,
-		}
-		resp.PaymentURL = u.String()
+		resp.PaymentURL = server.PaymentURL(p.walletCfg.FQDN, args.PaymentID)
+	}
+	if err := p.auditLog.AuditLog(ctx, server.AuditEvent{
+		Type:      server.AuditPaymentRequest,
+		PaymentID: args.PaymentID,
+		Payload:   resp,
+	}); err != nil {
+		return nil, errors.Wrapf(err, "failed to audit payment request for paymentID %s", args.PaymentID)
+	}
+	if err := p.statusWtr.PaymentStatusUpdate(ctx, requestedStatus(args.PaymentID, resp)); err != nil {
+		return nil, errors.Wrapf(err, "failed to record status for paymentID %s", args.PaymentID)
 	}
 
 	return resp, nil
 }
+
+// paymentRequest returns the payment request of a registered invoice, or reads it from
+// the wallet if the invoice wasn't registered.
+func (p *paymentRequestProxy) paymentRequest(ctx context.Context, args dpp.PaymentRequestArgs) (*dpp.PaymentRequest, error) {
+	if p.invoices == nil {
+		return p.preqRdr.PaymentRequest(ctx, args)
+	}
+	inv, err := p.invoices.Invoice(ctx, server.InvoiceArgs{PaymentID: args.PaymentID})
+	if err != nil {
+		if lathos.IsNotFound(err) {
+			return p.preqRdr.PaymentRequest(ctx, args)
+		}
+		return nil, err
+	}
+	return &inv.PaymentRequest, nil
+}
@@ -6,7 +6,11 @@ import (
 	"testing"
 	"time"
 
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/config"
+	"github.com/bitcoin-sv/dpp-proxy/mocks"
 	"github.com/bitcoin-sv/dpp-proxy/service"
+	"github.com/libsv/go-bt/v2"
 	"github.com/libsv/go-bt/v2/bscript"
 	"github.com/libsv/go-dpp"
 	dppMocks "github.com/libsv/go-dpp/mocks"
@@ -28,6 +32,7 @@ func TestPaymentRequest_PaymentRequest(t *testing.T) {
 			},
 			paymentRequestFunc: func(context.Context, dpp.PaymentRequestArgs) (*dpp.PaymentRequest, error) {
 				return &dpp.PaymentRequest{
+					Network:             "regtest",
 					AncestryRequired:    false,
 					CreationTimestamp:   created,
 					ExpirationTimestamp: expired,
@@ -49,6 +54,7 @@ func TestPaymentRequest_PaymentRequest(t *testing.T) {
 				}, nil
 			},
 			expResp: &dpp.PaymentRequest{
+				Network:             "regtest",
 				AncestryRequired:    false,
 				CreationTimestamp:   created,
 				ExpirationTimestamp: expired,
@@ -75,6 +81,7 @@ func TestPaymentRequest_PaymentRequest(t *testing.T) {
 			},
 			paymentRequestFunc: func(context.Context, dpp.PaymentRequestArgs) (*dpp.PaymentRequest, error) {
 				return &dpp.PaymentRequest{
+					Network:             "regtest",
 					AncestryRequired:    false,
 					CreationTimestamp:   created,
 					ExpirationTimestamp: expired,
@@ -94,6 +101,7 @@ func TestPaymentRequest_PaymentRequest(t *testing.T) {
 				}, nil
 			},
 			expResp: &dpp.PaymentRequest{
+				Network:             "regtest",
 				AncestryRequired:    false,
 				CreationTimestamp:   created,
 				ExpirationTimestamp: expired,
@@ -114,6 +122,17 @@ func TestPaymentRequest_PaymentRequest(t *testing.T) {
 				},
 			},
 		},
+		"payment request for another network is rejected": {
+			args: dpp.PaymentRequestArgs{
+				PaymentID: "abc123",
+			},
+			paymentRequestFunc: func(context.Context, dpp.PaymentRequestArgs) (*dpp.PaymentRequest, error) {
+				return &dpp.PaymentRequest{
+					Network: "mainnet",
+				}, nil
+			},
+			expErr: errors.New("Unprocessable: payment request is for network 'mainnet', this server only accepts 'regtest'"),
+		},
 		"invalid args rejected": {
 			expErr: errors.New("[paymentID: value cannot be empty]"),
 		},
@@ -130,9 +149,20 @@ func TestPaymentRequest_PaymentRequest(t *testing.T) {
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
+			statusWtr := &mocks.PaymentStatusWriterMock{
+				PaymentStatusUpdateFunc: func(context.Context, server.PaymentStatus) error {
+					return nil
+				},
+			}
 			svc := service.NewPaymentRequest(&dppMocks.PaymentRequestServiceMock{
 				PaymentRequestFunc: test.paymentRequestFunc,
-			})
+			}, nil, nil, statusWtr, &mocks.AuditLoggerMock{
+				AuditLogFunc: func(ctx context.Context, evt server.AuditEvent) error {
+					assert.Equal(t, server.AuditPaymentRequest, evt.Type)
+					assert.Equal(t, test.args.PaymentID, evt.PaymentID)
+					return nil
+				},
+			}, &config.Deployment{Network: config.NetworkRegtest})
 
 			resp, err := svc.PaymentRequest(context.TODO(), test.args)
 			if test.expErr != nil {
@@ -144,6 +174,84 @@ func TestPaymentRequest_PaymentRequest(t *testing.T) {
 			assert.NoError(t, err)
 			assert.NotNil(t, resp)
 			assert.Equal(t, *test.expResp, *resp)
+			assert.Len(t, statusWtr.PaymentStatusUpdateCalls(), 1)
+			status := statusWtr.PaymentStatusUpdateCalls()[0].Req
+			assert.Equal(t, test.args.PaymentID, status.PaymentID)
+			assert.Equal(t, server.PaymentStateRequested, status.State)
+			if assert.NotNil(t, status.ExpiresAt) {
+				assert.True(t, resp.ExpirationTimestamp.Equal(*status.ExpiresAt))
+			}
 		})
 	}
 }
+
+func TestPaymentRequest_PaymentRequest_Fees(t *testing.T) {
+	walletFees := bt.NewFeeQuote()
+	limited := bt.NewFeeQuote()
+	tests := map[string]struct {
+		feesFunc func(context.Context, *bt.FeeQuote) (*bt.FeeQuote, error)
+		expFees  *bt.FeeQuote
+		expErr   error
+	}{
+		"wallet fees are kept within limits": {
+			feesFunc: func(ctx context.Context, fees *bt.FeeQuote) (*bt.FeeQuote, error) {
+				assert.Equal(t, walletFees, fees)
+				return limited, nil
+			},
+			expFees: limited,
+		},
+		"fee service error is returned": {
+			feesFunc: func(context.Context, *bt.FeeQuote) (*bt.FeeQuote, error) {
+				return nil, errors.New("miner unavailable")
+			},
+			expErr: errors.New("failed to set fees for paymentID abc123: miner unavailable"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			svc := service.NewPaymentRequest(&dppMocks.PaymentRequestServiceMock{
+				PaymentRequestFunc: func(context.Context, dpp.PaymentRequestArgs) (*dpp.PaymentRequest, error) {
+					return &dpp.PaymentRequest{Network: "regtest", FeeRate: walletFees}, nil
+				},
+			}, &mocks.FeeServiceMock{FeesFunc: test.feesFunc}, nil, &mocks.PaymentStatusWriterMock{
+				PaymentStatusUpdateFunc: func(context.Context, server.PaymentStatus) error {
+					return nil
+				},
+			}, &mocks.AuditLoggerMock{
+				AuditLogFunc: func(context.Context, server.AuditEvent) error {
+					return nil
+				},
+			}, &config.Deployment{Network: config.NetworkRegtest})
+
+			resp, err := svc.PaymentRequest(context.Background(), dpp.PaymentRequestArgs{PaymentID: "abc123"})
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Same(t, test.expFees, resp.FeeRate)
+		})
+	}
+}
+
+func TestPaymentRequest_PaymentRequest_Policy(t *testing.T) {
+	svc := service.NewPaymentRequest(&dppMocks.PaymentRequestServiceMock{
+		PaymentRequestFunc: func(context.Context, dpp.PaymentRequestArgs) (*dpp.PaymentRequest, error) {
+			return &dpp.PaymentRequest{Network: "regtest", MerchantData: &dpp.Merchant{}}, nil
+		},
+	}, nil, service.NewPolicy(&config.Policy{MerchantData: []string{"extendedData.paymentReference"}}),
+		&mocks.PaymentStatusWriterMock{
+			PaymentStatusUpdateFunc: func(context.Context, server.PaymentStatus) error {
+				return nil
+			},
+		}, &mocks.AuditLoggerMock{
+			AuditLogFunc: func(context.Context, server.AuditEvent) error {
+				return nil
+			},
+		}, &config.Deployment{Network: config.NetworkRegtest})
+
+	// the payment reference is set by the proxy before the policy is checked.
+	resp, err := svc.PaymentRequest(context.Background(), dpp.PaymentRequestArgs{PaymentID: "abc123"})
+	assert.NoError(t, err)
+	assert.Equal(t, "abc123", resp.MerchantData.ExtendedData["paymentReference"])
+}
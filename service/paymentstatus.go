@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/theflyingcodr/lathos"
+	"go.opentelemetry.io/otel/attribute"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/tracing"
+)
+
+// paymentTransitions lists the states a payment can move to from each state, updates to
+// any other state are ignored, for example a late ack for a payment that has been proven.
+var paymentTransitions = map[server.PaymentState][]server.PaymentState{
+	server.PaymentStateRequested: {server.PaymentStateRequested, server.PaymentStateQueued, server.PaymentStatePaid, server.PaymentStateRejected, server.PaymentStateExpired},
+	server.PaymentStateQueued:    {server.PaymentStatePaid, server.PaymentStateRejected},
+	server.PaymentStateRejected:  {server.PaymentStateRequested, server.PaymentStateQueued, server.PaymentStatePaid, server.PaymentStateRejected},
+	server.PaymentStateExpired:   {server.PaymentStateRequested, server.PaymentStateQueued, server.PaymentStatePaid},
+	server.PaymentStatePaid:      {server.PaymentStateProven},
+	server.PaymentStateProven:    {server.PaymentStateProven},
+}
+
+// watchBuffer is the number of status changes buffered for each watcher, changes are
+// dropped for watchers that fall further behind.
+const watchBuffer = 8
+
+type paymentStatus struct {
+	store server.PaymentStatusReaderWriter
+
+	mu       sync.Mutex
+	watchers map[string][]chan server.PaymentStatus
+}
+
+// NewPaymentStatus will setup and return a new payment status service, tracking the
+// state of payments through the payment request, payment and proof flows.
+func NewPaymentStatus(store server.PaymentStatusReaderWriter) *paymentStatus {
+	return &paymentStatus{
+		store:    store,
+		watchers: map[string][]chan server.PaymentStatus{},
+	}
+}
+
+// PaymentStatus will return the current status of a payment.
+func (p *paymentStatus) PaymentStatus(ctx context.Context, args server.PaymentStatusArgs) (*server.PaymentStatus, error) {
+	ctx, span := tracing.StartSpan(ctx, "service.paymentStatus.PaymentStatus", attribute.String("paymentID", args.PaymentID))
+	defer span.End()
+	if err := args.Validate(); err != nil {
+		return nil, err
+	}
+	status, err := p.store.PaymentStatus(ctx, args)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, errors.WithMessagef(err, "failed to get status for paymentID '%s'", args.PaymentID)
+	}
+	expire(status)
+	return status, nil
+}
+
+// PaymentStatusUpdate will move a payment to a new state, notifying anyone watching
+// the payment. Updates that aren't a valid transition from the current state are ignored.
+func (p *paymentStatus) PaymentStatusUpdate(ctx context.Context, req server.PaymentStatus) error {
+	ctx, span := tracing.StartSpan(ctx, "service.paymentStatus.PaymentStatusUpdate",
+		attribute.String("paymentID", req.PaymentID),
+		attribute.String("state", string(req.State)))
+	defer span.End()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	current, err := p.store.PaymentStatus(ctx, server.PaymentStatusArgs{PaymentID: req.PaymentID})
+	if err != nil && !lathos.IsNotFound(err) {
+		tracing.RecordError(span, err)
+		return errors.WithMessagef(err, "failed to get status for paymentID '%s'", req.PaymentID)
+	}
+	if current != nil {
+		expire(current)
+		if !canTransition(current.State, req.State) {
+			return nil
+		}
+		if req.TxID == "" {
+			req.TxID = current.TxID
+		}
+		if req.ExpiresAt == nil {
+			req.ExpiresAt = current.ExpiresAt
+		}
+	}
+	req.UpdatedAt = time.Now().UTC()
+	if err := p.store.PaymentStatusUpdate(ctx, req); err != nil {
+		tracing.RecordError(span, err)
+		return errors.Wrapf(err, "failed to update status for paymentID '%s'", req.PaymentID)
+	}
+	for _, w := range p.watchers[req.PaymentID] {
+		select {
+		case w <- req:
+		default:
+		}
+	}
+	return nil
+}
+
+// PaymentStatusWatch will return the current status of the payment, followed by each change
+// until ctx is cancelled. Requested payments are sent as expired when the payment request expires.
+func (p *paymentStatus) PaymentStatusWatch(ctx context.Context, args server.PaymentStatusArgs) (<-chan server.PaymentStatus, error) {
+	// the watcher is added first so changes made while the status is read aren't missed.
+	changes := make(chan server.PaymentStatus, watchBuffer)
+	p.mu.Lock()
+	p.watchers[args.PaymentID] = append(p.watchers[args.PaymentID], changes)
+	p.mu.Unlock()
+	status, err := p.PaymentStatus(ctx, args)
+	if err != nil {
+		p.unwatch(args.PaymentID, changes)
+		return nil, err
+	}
+
+	out := make(chan server.PaymentStatus)
+	go func() {
+		defer close(out)
+		defer p.unwatch(args.PaymentID, changes)
+		last := *status
+		for {
+			select {
+			case out <- last:
+			case <-ctx.Done():
+				return
+			}
+			// a nil channel never receives, so there is no expiry unless one is set.
+			var expiry <-chan time.Time
+			var timer *time.Timer
+			if last.State == server.PaymentStateRequested && last.ExpiresAt != nil {
+				timer = time.NewTimer(time.Until(*last.ExpiresAt))
+				expiry = timer.C
+			}
+			select {
+			case last = <-changes:
+			case <-expiry:
+				expire(&last)
+			case <-ctx.Done():
+			}
+			if timer != nil {
+				timer.Stop()
+			}
+			if ctx.Err() != nil {
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// unwatch stops sending changes to a watcher.
+func (p *paymentStatus) unwatch(paymentID string, changes chan server.PaymentStatus) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ww := p.watchers[paymentID]
+	for i, w := range ww {
+		if w == changes {
+			ww = append(ww[:i], ww[i+1:]...)
+			break
+		}
+	}
+	if len(ww) == 0 {
+		delete(p.watchers, paymentID)
+		return
+	}
+	p.watchers[paymentID] = ww
+}
+
+// expire moves a requested payment to expired once its payment request has expired,
+// payments are expired when read rather than stored as expired.
+func expire(status *server.PaymentStatus) {
+	if status.State != server.PaymentStateRequested || status.ExpiresAt == nil || time.Now().Before(*status.ExpiresAt) {
+		return
+	}
+	status.State = server.PaymentStateExpired
+	status.UpdatedAt = *status.ExpiresAt
+}
+
+func canTransition(from, to server.PaymentState) bool {
+	for _, s := range paymentTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
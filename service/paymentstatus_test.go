@@ -0,0 +1,168 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/data/memory"
+	"github.com/bitcoin-sv/dpp-proxy/service"
+)
+
+func TestPaymentStatus_PaymentStatusUpdate(t *testing.T) {
+	future := time.Now().Add(time.Hour).UTC()
+	past := time.Now().Add(-time.Hour).UTC()
+	tests := map[string]struct {
+		updates   []server.PaymentStatus
+		expStatus server.PaymentStatus
+	}{
+		"payment is requested": {
+			updates: []server.PaymentStatus{
+				{PaymentID: "abc123", State: server.PaymentStateRequested, ExpiresAt: &future},
+			},
+			expStatus: server.PaymentStatus{PaymentID: "abc123", State: server.PaymentStateRequested, ExpiresAt: &future},
+		},
+		"payment is paid then proven": {
+			updates: []server.PaymentStatus{
+				{PaymentID: "abc123", State: server.PaymentStateRequested, ExpiresAt: &future},
+				{PaymentID: "abc123", State: server.PaymentStatePaid, TxID: proofTxID},
+				{PaymentID: "abc123", State: server.PaymentStateProven},
+			},
+			expStatus: server.PaymentStatus{PaymentID: "abc123", State: server.PaymentStateProven, TxID: proofTxID, ExpiresAt: &future},
+		},
+		"rejected payment can be paid": {
+			updates: []server.PaymentStatus{
+				{PaymentID: "abc123", State: server.PaymentStateRequested},
+				{PaymentID: "abc123", State: server.PaymentStateRejected, Memo: "insufficient fee"},
+				{PaymentID: "abc123", State: server.PaymentStatePaid, TxID: proofTxID},
+			},
+			expStatus: server.PaymentStatus{PaymentID: "abc123", State: server.PaymentStatePaid, TxID: proofTxID},
+		},
+		"late rejection of a paid payment is ignored": {
+			updates: []server.PaymentStatus{
+				{PaymentID: "abc123", State: server.PaymentStatePaid, TxID: proofTxID},
+				{PaymentID: "abc123", State: server.PaymentStateRejected, Memo: "duplicate"},
+			},
+			expStatus: server.PaymentStatus{PaymentID: "abc123", State: server.PaymentStatePaid, TxID: proofTxID},
+		},
+		"payment request returned again after payment is ignored": {
+			updates: []server.PaymentStatus{
+				{PaymentID: "abc123", State: server.PaymentStatePaid, TxID: proofTxID},
+				{PaymentID: "abc123", State: server.PaymentStateRequested},
+			},
+			expStatus: server.PaymentStatus{PaymentID: "abc123", State: server.PaymentStatePaid, TxID: proofTxID},
+		},
+		"queued payment is paid once collected": {
+			updates: []server.PaymentStatus{
+				{PaymentID: "abc123", State: server.PaymentStateRequested},
+				{PaymentID: "abc123", State: server.PaymentStateQueued, TxID: proofTxID},
+				{PaymentID: "abc123", State: server.PaymentStatePaid},
+			},
+			expStatus: server.PaymentStatus{PaymentID: "abc123", State: server.PaymentStatePaid, TxID: proofTxID},
+		},
+		"payment request returned again for a queued payment is ignored": {
+			updates: []server.PaymentStatus{
+				{PaymentID: "abc123", State: server.PaymentStateQueued, TxID: proofTxID},
+				{PaymentID: "abc123", State: server.PaymentStateRequested},
+			},
+			expStatus: server.PaymentStatus{PaymentID: "abc123", State: server.PaymentStateQueued, TxID: proofTxID},
+		},
+		"requested payment past its expiry is expired": {
+			updates: []server.PaymentStatus{
+				{PaymentID: "abc123", State: server.PaymentStateRequested, ExpiresAt: &past},
+			},
+			expStatus: server.PaymentStatus{PaymentID: "abc123", State: server.PaymentStateExpired, ExpiresAt: &past, UpdatedAt: past},
+		},
+		"expired payment can still be paid": {
+			updates: []server.PaymentStatus{
+				{PaymentID: "abc123", State: server.PaymentStateRequested, ExpiresAt: &past},
+				{PaymentID: "abc123", State: server.PaymentStatePaid, TxID: proofTxID},
+			},
+			expStatus: server.PaymentStatus{PaymentID: "abc123", State: server.PaymentStatePaid, TxID: proofTxID, ExpiresAt: &past},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			svc := service.NewPaymentStatus(memory.NewPaymentStatuses(time.Hour))
+			for _, update := range test.updates {
+				assert.NoError(t, svc.PaymentStatusUpdate(context.Background(), update))
+			}
+			status, err := svc.PaymentStatus(context.Background(), server.PaymentStatusArgs{PaymentID: "abc123"})
+			assert.NoError(t, err)
+			assert.False(t, status.UpdatedAt.IsZero())
+			if test.expStatus.UpdatedAt.IsZero() {
+				test.expStatus.UpdatedAt = status.UpdatedAt
+			}
+			assert.Equal(t, test.expStatus, *status)
+		})
+	}
+}
+
+func TestPaymentStatus_PaymentStatus(t *testing.T) {
+	tests := map[string]struct {
+		args   server.PaymentStatusArgs
+		expErr error
+	}{
+		"invalid args are rejected": {
+			expErr: errors.New("[paymentID: value cannot be empty]"),
+		},
+		"unknown payment is not found": {
+			args:   server.PaymentStatusArgs{PaymentID: "abc123"},
+			expErr: errors.New("failed to get status for paymentID 'abc123': Not found: no status found for payment"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			svc := service.NewPaymentStatus(memory.NewPaymentStatuses(time.Hour))
+			_, err := svc.PaymentStatus(context.Background(), test.args)
+			assert.EqualError(t, err, test.expErr.Error())
+		})
+	}
+}
+
+func TestPaymentStatus_PaymentStatusWatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	svc := service.NewPaymentStatus(memory.NewPaymentStatuses(time.Hour))
+	args := server.PaymentStatusArgs{PaymentID: "abc123"}
+	expires := time.Now().Add(200 * time.Millisecond).UTC()
+	assert.NoError(t, svc.PaymentStatusUpdate(ctx, server.PaymentStatus{
+		PaymentID: "abc123",
+		State:     server.PaymentStateRequested,
+		ExpiresAt: &expires,
+	}))
+
+	changes, err := svc.PaymentStatusWatch(ctx, args)
+	assert.NoError(t, err)
+	next := func() server.PaymentStatus {
+		select {
+		case status := <-changes:
+			return status
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for status")
+		}
+		return server.PaymentStatus{}
+	}
+	assert.Equal(t, server.PaymentStateRequested, next().State)
+	// the payment isn't paid before the payment request expires.
+	assert.Equal(t, server.PaymentStateExpired, next().State)
+
+	assert.NoError(t, svc.PaymentStatusUpdate(ctx, server.PaymentStatus{
+		PaymentID: "abc123",
+		State:     server.PaymentStatePaid,
+		TxID:      proofTxID,
+	}))
+	status := next()
+	assert.Equal(t, server.PaymentStatePaid, status.State)
+	assert.Equal(t, proofTxID, status.TxID)
+
+	cancel()
+	for range changes {
+	}
+	_, err = svc.PaymentStatusWatch(context.Background(), server.PaymentStatusArgs{PaymentID: "unknown"})
+	assert.Error(t, err)
+}
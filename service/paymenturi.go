@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/skip2/go-qrcode"
+	"go.opentelemetry.io/otel/attribute"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/config"
+	"github.com/bitcoin-sv/dpp-proxy/tracing"
+)
+
+const (
+	qrDefaultSize = 256
+	contentPNG    = "image/png"
+	contentSVG    = "image/svg+xml"
+)
+
+type paymentURI struct {
+	cfg *config.Server
+}
+
+// NewPaymentURI will setup and return a new PaymentURI service, uris point at the payment
+// request endpoint on the FQDN this server is found under.
+func NewPaymentURI(cfg *config.Server) *paymentURI {
+	return &paymentURI{cfg: cfg}
+}
+
+// PaymentURI will build a pay: uri for an invoice.
+func (p *paymentURI) PaymentURI(ctx context.Context, args server.PaymentURIArgs) (*server.PaymentURI, error) {
+	_, span := tracing.StartSpan(ctx, "service.paymentURI.PaymentURI", attribute.String("paymentID", args.PaymentID))
+	defer span.End()
+	if err := args.Validate(); err != nil {
+		return nil, err
+	}
+	return p.uri(args), nil
+}
+
+// PaymentQR will build a pay: uri for an invoice and render it as a QR code.
+func (p *paymentURI) PaymentQR(ctx context.Context, args server.PaymentQRArgs) (*server.PaymentQR, error) {
+	_, span := tracing.StartSpan(ctx, "service.paymentURI.PaymentQR", attribute.String("paymentID", args.PaymentID))
+	defer span.End()
+	if args.Format == "" {
+		args.Format = server.QRFormatPNG
+	}
+	if args.Size == 0 {
+		args.Size = qrDefaultSize
+	}
+	if err := args.Validate(); err != nil {
+		return nil, err
+	}
+	u := p.uri(server.PaymentURIArgs{
+		PaymentID: args.PaymentID,
+		Label:     args.Label,
+		Message:   args.Message,
+	})
+	qr, err := qrcode.New(u.URI, qrcode.Medium)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, errors.Wrapf(err, "failed to create qr code for paymentID %s", args.PaymentID)
+	}
+	if args.Format == server.QRFormatSVG {
+		return &server.PaymentQR{
+			ContentType: contentSVG,
+			Image:       qrSVG(qr.Bitmap(), args.Size),
+		}, nil
+	}
+	png, err := qr.PNG(args.Size)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, errors.Wrapf(err, "failed to render qr code for paymentID %s", args.PaymentID)
+	}
+	return &server.PaymentQR{
+		ContentType: contentPNG,
+		Image:       png,
+	}, nil
+}
+
+// uri builds a BIP-272 style pay: uri, the payment request url is supplied in the r parameter
+// followed by any optional BIP-21 parameters.
+func (p *paymentURI) uri(args server.PaymentURIArgs) *server.PaymentURI {
+	payURL := server.PaymentURL(p.cfg.FQDN, args.PaymentID)
+	sb := strings.Builder{}
+	sb.WriteString("pay:?r=" + url.QueryEscape(payURL))
+	if args.Label != "" {
+		sb.WriteString("&label=" + url.QueryEscape(args.Label))
+	}
+	if args.Message != "" {
+		sb.WriteString("&message=" + url.QueryEscape(args.Message))
+	}
+	return &server.PaymentURI{
+		URI:        sb.String(),
+		PaymentURL: payURL,
+	}
+}
+
+// qrSVG renders a QR code bitmap as an svg, each dark module is drawn as a 1x1 square
+// and the image scaled to size.
+func qrSVG(bitmap [][]bool, size int) []byte {
+	n := len(bitmap)
+	sb := strings.Builder{}
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, size, size, n, n)
+	fmt.Fprintf(&sb, `<rect width="%d" height="%d" fill="#ffffff"/><path fill="#000000" d="`, n, n)
+	for y, row := range bitmap {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(&sb, "M%d %dh1v1h-1z", x, y)
+			}
+		}
+	}
+	sb.WriteString(`"/></svg>`)
+	return []byte(sb.String())
+}
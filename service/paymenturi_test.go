@@ -0,0 +1,99 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/config"
+	"github.com/bitcoin-sv/dpp-proxy/service"
+)
+
+func TestPaymentURI_PaymentURI(t *testing.T) {
+	tests := map[string]struct {
+		args   server.PaymentURIArgs
+		expURI *server.PaymentURI
+		expErr error
+	}{
+		"successful uri": {
+			args: server.PaymentURIArgs{PaymentID: "abc123"},
+			expURI: &server.PaymentURI{
+				URI:        "pay:?r=http%3A%2F%2Fdpp.example.com%3A8445%2Fapi%2Fv1%2Fpayment%2Fabc123",
+				PaymentURL: "http://dpp.example.com:8445/api/v1/payment/abc123",
+			},
+		},
+		"bip21 params are appended": {
+			args: server.PaymentURIArgs{PaymentID: "abc123", Label: "Coffee Shop", Message: "order #12"},
+			expURI: &server.PaymentURI{
+				URI:        "pay:?r=http%3A%2F%2Fdpp.example.com%3A8445%2Fapi%2Fv1%2Fpayment%2Fabc123&label=Coffee+Shop&message=order+%2312",
+				PaymentURL: "http://dpp.example.com:8445/api/v1/payment/abc123",
+			},
+		},
+		"missing paymentID is rejected": {
+			args:   server.PaymentURIArgs{},
+			expErr: errors.New("[paymentID: value cannot be empty]"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			svc := service.NewPaymentURI(&config.Server{FQDN: "dpp.example.com:8445"})
+			uri, err := svc.PaymentURI(context.TODO(), test.args)
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expURI, uri)
+		})
+	}
+}
+
+func TestPaymentURI_PaymentQR(t *testing.T) {
+	tests := map[string]struct {
+		args           server.PaymentQRArgs
+		expContentType string
+		expErr         error
+	}{
+		"png is the default": {
+			args:           server.PaymentQRArgs{PaymentID: "abc123"},
+			expContentType: "image/png",
+		},
+		"svg is rendered": {
+			args:           server.PaymentQRArgs{PaymentID: "abc123", Format: server.QRFormatSVG},
+			expContentType: "image/svg+xml",
+		},
+		"unknown format is rejected": {
+			args:   server.PaymentQRArgs{PaymentID: "abc123", Format: "gif"},
+			expErr: errors.New("[format: value not found in allowed values]"),
+		},
+		"size out of range is rejected": {
+			args:   server.PaymentQRArgs{PaymentID: "abc123", Size: 5000},
+			expErr: errors.New("[size: value 5000 must be between 64 and 1024]"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			svc := service.NewPaymentURI(&config.Server{FQDN: "dpp.example.com:8445"})
+			qr, err := svc.PaymentQR(context.TODO(), test.args)
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expContentType, qr.ContentType)
+			switch qr.ContentType {
+			case "image/png":
+				img, err := png.Decode(bytes.NewReader(qr.Image))
+				assert.NoError(t, err)
+				assert.Equal(t, 256, img.Bounds().Dx())
+			case "image/svg+xml":
+				assert.True(t, bytes.HasPrefix(qr.Image, []byte(`<svg xmlns="http://www.w3.org/2000/svg" width="256" height="256"`)))
+			}
+		})
+	}
+}
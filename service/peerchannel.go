@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/libsv/go-dpp"
+	"github.com/pkg/errors"
+	validator "github.com/theflyingcodr/govalidator"
+	"github.com/theflyingcodr/lathos"
+	"github.com/theflyingcodr/lathos/errs"
+	"go.opentelemetry.io/otel/attribute"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/config"
+	"github.com/bitcoin-sv/dpp-proxy/tracing"
+)
+
+type peerChannel struct {
+	store  server.PeerChannelStore
+	cfg    *config.PeerChannels
+	srvCfg *config.Server
+
+	mu       sync.Mutex
+	watchers map[string][]chan server.PeerChannelMessage
+}
+
+// NewPeerChannel will setup and return a new peer channel service, hosting a channel for
+// each paid invoice on the FQDN in srvCfg.
+func NewPeerChannel(store server.PeerChannelStore, cfg *config.PeerChannels, srvCfg *config.Server) *peerChannel {
+	return &peerChannel{
+		store:    store,
+		cfg:      cfg,
+		srvCfg:   srvCfg,
+		watchers: map[string][]chan server.PeerChannelMessage{},
+	}
+}
+
+// PeerChannelCreate will return the channel of a payment, allocating one with a new read token
+// if the payment doesn't have one, so a payment sent again returns the same channel.
+func (p *peerChannel) PeerChannelCreate(ctx context.Context, args dpp.PaymentCreateArgs) (*dpp.PeerChannelData, error) {
+	ctx, span := tracing.StartSpan(ctx, "service.peerChannel.PeerChannelCreate", attribute.String("paymentID", args.PaymentID))
+	defer span.End()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	channel, err := p.store.PaymentPeerChannel(ctx, args.PaymentID)
+	if err != nil && !lathos.IsNotFound(err) {
+		tracing.RecordError(span, err)
+		return nil, errors.Wrapf(err, "failed to get peer channel for paymentID '%s'", args.PaymentID)
+	}
+	if channel == nil {
+		token, err := newChannelToken()
+		if err != nil {
+			return nil, err
+		}
+		channel = &server.PeerChannel{
+			ID:        uuid.NewString(),
+			PaymentID: args.PaymentID,
+			Token:     token,
+			CreatedAt: time.Now().UTC(),
+		}
+		if err := p.store.PeerChannelCreate(ctx, *channel); err != nil {
+			tracing.RecordError(span, err)
+			return nil, errors.Wrapf(err, "failed to create peer channel for paymentID '%s'", args.PaymentID)
+		}
+	}
+	return &dpp.PeerChannelData{
+		Host:      p.srvCfg.FQDN,
+		Path:      server.PeerChannelPath,
+		ChannelID: channel.ID,
+		Token:     channel.Token,
+	}, nil
+}
+
+// PeerChannelMessages will return the messages on a channel after args.After, the reader must
+// present the channel token.
+func (p *peerChannel) PeerChannelMessages(ctx context.Context, args server.PeerChannelArgs) ([]server.PeerChannelMessage, error) {
+	ctx, span := tracing.StartSpan(ctx, "service.peerChannel.PeerChannelMessages", attribute.String("channelID", args.ChannelID))
+	defer span.End()
+	if err := p.authenticate(ctx, args); err != nil {
+		return nil, err
+	}
+	mm, err := p.store.PeerChannelMessages(ctx, args.ChannelID, args.After)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, errors.WithMessagef(err, "failed to get messages for peer channel '%s'", args.ChannelID)
+	}
+	return mm, nil
+}
+
+// PeerChannelWatch will return the messages on a channel after args.After followed by each new
+// message until ctx is cancelled, the reader must present the channel token.
+func (p *peerChannel) PeerChannelWatch(ctx context.Context, args server.PeerChannelArgs) (<-chan server.PeerChannelMessage, error) {
+	// the watcher is added first so messages sent while the channel is read aren't missed.
+	msgs := make(chan server.PeerChannelMessage, watchBuffer)
+	p.mu.Lock()
+	p.watchers[args.ChannelID] = append(p.watchers[args.ChannelID], msgs)
+	p.mu.Unlock()
+	mm, err := p.PeerChannelMessages(ctx, args)
+	if err != nil {
+		p.unwatch(args.ChannelID, msgs)
+		return nil, err
+	}
+
+	out := make(chan server.PeerChannelMessage)
+	go func() {
+		defer close(out)
+		defer p.unwatch(args.ChannelID, msgs)
+		last := args.After
+		send := func(m server.PeerChannelMessage) bool {
+			// messages already read from the store are also sent to the watcher.
+			if m.Sequence <= last {
+				return true
+			}
+			select {
+			case out <- m:
+				last = m.Sequence
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		for _, m := range mm {
+			if !send(m) {
+				return
+			}
+		}
+		for {
+			select {
+			case m := <-msgs:
+				if !send(m) {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// PeerChannelMessageCreate will send a message on the channel of a payment, notifying anyone
+// watching the channel.
+func (p *peerChannel) PeerChannelMessageCreate(ctx context.Context, args server.PeerChannelMessageArgs, payload []byte) (*server.PeerChannelMessage, error) {
+	ctx, span := tracing.StartSpan(ctx, "service.peerChannel.PeerChannelMessageCreate", attribute.String("paymentID", args.PaymentID))
+	defer span.End()
+	if err := validator.New().
+		Validate("paymentID", validator.NotEmpty(args.PaymentID)).
+		Validate("payload", func() error {
+			if len(payload) == 0 {
+				return errors.New("a message body is required")
+			}
+			if len(payload) > p.cfg.MaxMessageBytes {
+				return fmt.Errorf("message is %d bytes, the maximum is %d", len(payload), p.cfg.MaxMessageBytes)
+			}
+			return nil
+		}).Err(); err != nil {
+		return nil, err
+	}
+	channel, err := p.store.PaymentPeerChannel(ctx, args.PaymentID)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, errors.WithMessagef(err, "failed to get peer channel for paymentID '%s'", args.PaymentID)
+	}
+	contentType := args.ContentType
+	if contentType == "" {
+		contentType = http.DetectContentType(payload)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	msg, err := p.store.PeerChannelMessageCreate(ctx, channel.ID, server.PeerChannelMessage{
+		Received:    time.Now().UTC(),
+		ContentType: contentType,
+		Payload:     payload,
+	})
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, errors.Wrapf(err, "failed to send message on peer channel for paymentID '%s'", args.PaymentID)
+	}
+	for _, w := range p.watchers[channel.ID] {
+		select {
+		case w <- *msg:
+		default:
+		}
+	}
+	return msg, nil
+}
+
+// authenticate checks the channel exists and the reader presented its token.
+func (p *peerChannel) authenticate(ctx context.Context, args server.PeerChannelArgs) error {
+	if err := args.Validate(); err != nil {
+		return err
+	}
+	if args.Token == "" {
+		return errs.NewErrNotAuthenticated("401", "bearer token required")
+	}
+	channel, err := p.store.PeerChannel(ctx, args.ChannelID)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to get peer channel '%s'", args.ChannelID)
+	}
+	if subtle.ConstantTimeCompare([]byte(channel.Token), []byte(args.Token)) != 1 {
+		return errs.NewErrNotAuthenticated("401", "invalid bearer token")
+	}
+	return nil
+}
+
+// unwatch stops sending messages to a watcher.
+func (p *peerChannel) unwatch(channelID string, msgs chan server.PeerChannelMessage) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ww := p.watchers[channelID]
+	for i, w := range ww {
+		if w == msgs {
+			ww = append(ww[:i], ww[i+1:]...)
+			break
+		}
+	}
+	if len(ww) == 0 {
+		delete(p.watchers, channelID)
+		return
+	}
+	p.watchers[channelID] = ww
+}
+
+// newChannelToken returns a random token customers read a channel with.
+func newChannelToken() (string, error) {
+	bb := make([]byte, 32)
+	if _, err := rand.Read(bb); err != nil {
+		return "", errors.Wrap(err, "failed to generate peer channel token")
+	}
+	return hex.EncodeToString(bb), nil
+}
@@ -0,0 +1,198 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/libsv/go-dpp"
+	"github.com/stretchr/testify/assert"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/config"
+	"github.com/bitcoin-sv/dpp-proxy/data/memory"
+	"github.com/bitcoin-sv/dpp-proxy/service"
+)
+
+func newPeerChannelSvc() server.PeerChannelService {
+	return service.NewPeerChannel(memory.NewPeerChannels(2),
+		&config.PeerChannels{Enabled: true, MaxMessages: 2, MaxMessageBytes: 16},
+		&config.Server{FQDN: "dpp.example.com"})
+}
+
+func TestPeerChannel_PeerChannelCreate(t *testing.T) {
+	svc := newPeerChannelSvc()
+	channel, err := svc.PeerChannelCreate(context.Background(), dpp.PaymentCreateArgs{PaymentID: "abc123"})
+	assert.NoError(t, err)
+	assert.Equal(t, "dpp.example.com", channel.Host)
+	assert.Equal(t, server.PeerChannelPath, channel.Path)
+	assert.NotEmpty(t, channel.ChannelID)
+	assert.Len(t, channel.Token, 64)
+
+	// paying an invoice again returns the same channel.
+	again, err := svc.PeerChannelCreate(context.Background(), dpp.PaymentCreateArgs{PaymentID: "abc123"})
+	assert.NoError(t, err)
+	assert.Equal(t, channel, again)
+
+	other, err := svc.PeerChannelCreate(context.Background(), dpp.PaymentCreateArgs{PaymentID: "def456"})
+	assert.NoError(t, err)
+	assert.NotEqual(t, channel.ChannelID, other.ChannelID)
+	assert.NotEqual(t, channel.Token, other.Token)
+}
+
+func TestPeerChannel_PeerChannelMessages(t *testing.T) {
+	tests := map[string]struct {
+		argsFn      func(channel *dpp.PeerChannelData) server.PeerChannelArgs
+		expSequence []uint64
+		expErr      error
+	}{
+		"messages are returned with the channel token": {
+			argsFn: func(channel *dpp.PeerChannelData) server.PeerChannelArgs {
+				return server.PeerChannelArgs{ChannelID: channel.ChannelID, Token: channel.Token}
+			},
+			expSequence: []uint64{2, 3},
+		},
+		"messages after a sequence are returned": {
+			argsFn: func(channel *dpp.PeerChannelData) server.PeerChannelArgs {
+				return server.PeerChannelArgs{ChannelID: channel.ChannelID, Token: channel.Token, After: 2}
+			},
+			expSequence: []uint64{3},
+		},
+		"missing token is rejected": {
+			argsFn: func(channel *dpp.PeerChannelData) server.PeerChannelArgs {
+				return server.PeerChannelArgs{ChannelID: channel.ChannelID}
+			},
+			expErr: errors.New("Not authenticated: bearer token required"),
+		},
+		"invalid token is rejected": {
+			argsFn: func(channel *dpp.PeerChannelData) server.PeerChannelArgs {
+				return server.PeerChannelArgs{ChannelID: channel.ChannelID, Token: "abc"}
+			},
+			expErr: errors.New("Not authenticated: invalid bearer token"),
+		},
+		"unknown channel is not found": {
+			argsFn: func(channel *dpp.PeerChannelData) server.PeerChannelArgs {
+				return server.PeerChannelArgs{ChannelID: "abc", Token: channel.Token}
+			},
+			expErr: errors.New("failed to get peer channel 'abc': Not found: peer channel not found"),
+		},
+		"invalid args are rejected": {
+			argsFn: func(channel *dpp.PeerChannelData) server.PeerChannelArgs {
+				return server.PeerChannelArgs{}
+			},
+			expErr: errors.New("[channelID: value cannot be empty]"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			svc := newPeerChannelSvc()
+			ctx := context.Background()
+			channel, err := svc.PeerChannelCreate(ctx, dpp.PaymentCreateArgs{PaymentID: "abc123"})
+			assert.NoError(t, err)
+			// only the latest 2 messages are kept.
+			for _, body := range []string{"one", "two", "three"} {
+				_, err := svc.PeerChannelMessageCreate(ctx, server.PeerChannelMessageArgs{PaymentID: "abc123", ContentType: "text/plain"}, []byte(body))
+				assert.NoError(t, err)
+			}
+			mm, err := svc.PeerChannelMessages(ctx, test.argsFn(channel))
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+			seqs := make([]uint64, 0, len(mm))
+			for _, m := range mm {
+				seqs = append(seqs, m.Sequence)
+				assert.Equal(t, "text/plain", m.ContentType)
+			}
+			assert.Equal(t, test.expSequence, seqs)
+		})
+	}
+}
+
+func TestPeerChannel_PeerChannelMessageCreate(t *testing.T) {
+	tests := map[string]struct {
+		args    server.PeerChannelMessageArgs
+		payload []byte
+		expMsg  *server.PeerChannelMessage
+		expErr  error
+	}{
+		"message is sent": {
+			args:    server.PeerChannelMessageArgs{PaymentID: "abc123", ContentType: "application/json"},
+			payload: []byte(`{"receipt":1}`),
+			expMsg:  &server.PeerChannelMessage{Sequence: 1, ContentType: "application/json", Payload: []byte(`{"receipt":1}`)},
+		},
+		"content type is detected if not supplied": {
+			args:    server.PeerChannelMessageArgs{PaymentID: "abc123"},
+			payload: []byte("hello"),
+			expMsg:  &server.PeerChannelMessage{Sequence: 1, ContentType: "text/plain; charset=utf-8", Payload: []byte("hello")},
+		},
+		"empty message is rejected": {
+			args:   server.PeerChannelMessageArgs{PaymentID: "abc123"},
+			expErr: errors.New("[payload: a message body is required]"),
+		},
+		"large message is rejected": {
+			args:    server.PeerChannelMessageArgs{PaymentID: "abc123"},
+			payload: []byte("this message is too long"),
+			expErr:  errors.New("[payload: message is 24 bytes, the maximum is 16]"),
+		},
+		"message for an unpaid invoice is not found": {
+			args:    server.PeerChannelMessageArgs{PaymentID: "def456"},
+			payload: []byte("hello"),
+			expErr:  errors.New("failed to get peer channel for paymentID 'def456': Not found: no peer channel found for payment"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			svc := newPeerChannelSvc()
+			_, err := svc.PeerChannelCreate(context.Background(), dpp.PaymentCreateArgs{PaymentID: "abc123"})
+			assert.NoError(t, err)
+			msg, err := svc.PeerChannelMessageCreate(context.Background(), test.args, test.payload)
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.False(t, msg.Received.IsZero())
+			msg.Received = time.Time{}
+			assert.Equal(t, test.expMsg, msg)
+		})
+	}
+}
+
+func TestPeerChannel_PeerChannelWatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	svc := newPeerChannelSvc()
+	channel, err := svc.PeerChannelCreate(ctx, dpp.PaymentCreateArgs{PaymentID: "abc123"})
+	assert.NoError(t, err)
+	args := server.PeerChannelMessageArgs{PaymentID: "abc123"}
+	_, err = svc.PeerChannelMessageCreate(ctx, args, []byte("one"))
+	assert.NoError(t, err)
+
+	_, err = svc.PeerChannelWatch(ctx, server.PeerChannelArgs{ChannelID: channel.ChannelID, Token: "abc"})
+	assert.EqualError(t, err, "Not authenticated: invalid bearer token")
+
+	msgs, err := svc.PeerChannelWatch(ctx, server.PeerChannelArgs{ChannelID: channel.ChannelID, Token: channel.Token})
+	assert.NoError(t, err)
+	next := func() server.PeerChannelMessage {
+		select {
+		case m := <-msgs:
+			return m
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for message")
+		}
+		return server.PeerChannelMessage{}
+	}
+	assert.Equal(t, "one", string(next().Payload))
+	_, err = svc.PeerChannelMessageCreate(ctx, args, []byte("two"))
+	assert.NoError(t, err)
+	m := next()
+	assert.Equal(t, uint64(2), m.Sequence)
+	assert.Equal(t, "two", string(m.Payload))
+
+	cancel()
+	for range msgs {
+	}
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/libsv/go-dpp"
+	"github.com/pkg/errors"
+	validator "github.com/theflyingcodr/govalidator"
+	"github.com/theflyingcodr/lathos/errs"
+
+	"github.com/bitcoin-sv/dpp-proxy/config"
+)
+
+// scriptTemplates match a locking script to the templates it can be configured by.
+var scriptTemplates = map[string]func(s *bscript.Script) bool{
+	config.ScriptP2PKH:    (*bscript.Script).IsP2PKH,
+	config.ScriptP2PK:     (*bscript.Script).IsP2PK,
+	config.ScriptP2SH:     (*bscript.Script).IsP2SH,
+	config.ScriptData:     (*bscript.Script).IsData,
+	config.ScriptMultiSig: (*bscript.Script).IsMultiSigOut,
+}
+
+type policy struct {
+	cfg *config.Policy
+}
+
+// NewPolicy will setup and return a payment request policy enforcing the rules in cfg.
+func NewPolicy(cfg *config.Policy) *policy {
+	return &policy{cfg: cfg}
+}
+
+// PolicyCheck will check the outputs, expiry and merchant data of the payment request, every
+// rule broken is listed in the error.
+func (p *policy) PolicyCheck(ctx context.Context, pr *dpp.PaymentRequest) error {
+	outputs := pr.Destinations.Outputs
+	v := validator.New().
+		Validate("destinations.outputs", func() error {
+			if p.cfg.MaxOutputs > 0 && len(outputs) > p.cfg.MaxOutputs {
+				return fmt.Errorf("%d outputs are requested, the most allowed is %d", len(outputs), p.cfg.MaxOutputs)
+			}
+			var total uint64
+			for _, o := range outputs {
+				if o.Amount > math.MaxUint64-total {
+					return errors.New("the total amount requested is too large")
+				}
+				total += o.Amount
+			}
+			if p.cfg.MaxTotal > 0 && total > p.cfg.MaxTotal {
+				return fmt.Errorf("%d satoshis are requested, the most allowed is %d", total, p.cfg.MaxTotal)
+			}
+			return nil
+		}).
+		Validate("expirationTimestamp", func() error {
+			if p.cfg.MaxExpiry == 0 {
+				return nil
+			}
+			if pr.ExpirationTimestamp.IsZero() {
+				return errors.New("an expiry is required")
+			}
+			if horizon := time.Now().Add(p.cfg.MaxExpiry); pr.ExpirationTimestamp.After(horizon) {
+				return fmt.Errorf("the expiry must be within %s", p.cfg.MaxExpiry)
+			}
+			return nil
+		})
+	for n, o := range outputs {
+		o := o
+		v = v.Validate(fmt.Sprintf("destinations.outputs[%d]", n), func() error {
+			if o.LockingScript == nil {
+				return errors.New("a locking script is required")
+			}
+			if !p.scriptAllowed(o.LockingScript) {
+				return fmt.Errorf("the locking script isn't allowed, it must be one of: %s", strings.Join(p.cfg.Scripts, ", "))
+			}
+			if o.Amount < p.cfg.DustLimit && !o.LockingScript.IsData() {
+				return fmt.Errorf("amount %d is below the dust limit of %d", o.Amount, p.cfg.DustLimit)
+			}
+			return nil
+		})
+	}
+	for _, field := range p.cfg.MerchantData {
+		field := field
+		v = v.Validate("merchantData."+field, func() error {
+			if !hasMerchantData(pr.MerchantData, field) {
+				return errors.New("value is required")
+			}
+			return nil
+		})
+	}
+	if err := v.Err(); err != nil {
+		return errs.NewErrUnprocessablef("422", "payment request breaks the policy: %s", err)
+	}
+	return nil
+}
+
+// scriptAllowed returns true if the script matches one of the allowed templates, or any
+// script is allowed.
+func (p *policy) scriptAllowed(s *bscript.Script) bool {
+	if len(p.cfg.Scripts) == 0 {
+		return true
+	}
+	for _, t := range p.cfg.Scripts {
+		if match, ok := scriptTemplates[t]; ok && match(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasMerchantData returns true if the merchant data field, or extendedData.{key}, is set.
+func hasMerchantData(m *dpp.Merchant, field string) bool {
+	if m == nil {
+		return false
+	}
+	switch field {
+	case "avatar":
+		return m.AvatarURL != ""
+	case "name":
+		return m.Name != ""
+	case "email":
+		return m.Email != ""
+	case "address":
+		return m.Address != ""
+	}
+	val, ok := m.ExtendedData[strings.TrimPrefix(field, "extendedData.")]
+	return ok && val != nil && val != ""
+}
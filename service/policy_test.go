@@ -0,0 +1,176 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/libsv/go-dpp"
+	"github.com/stretchr/testify/assert"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/config"
+	"github.com/bitcoin-sv/dpp-proxy/mocks"
+	"github.com/bitcoin-sv/dpp-proxy/service"
+)
+
+func TestPolicy_PolicyCheck(t *testing.T) {
+	data, err := bscript.NewFromHexString("006a0568656c6c6f")
+	assert.NoError(t, err)
+	p2sh, err := bscript.NewFromHexString("a9149a5b1aa3a8c1bf2f3bf0c1b4c3b9c1b2f0a1c2d387")
+	assert.NoError(t, err)
+	tests := map[string]struct {
+		cfg    config.Policy
+		reqFn  func(pr *dpp.PaymentRequest)
+		expErr error
+	}{
+		"payment request within the policy passes": {
+			cfg: config.Policy{
+				DustLimit:    1,
+				Scripts:      []string{config.ScriptP2PKH, config.ScriptData},
+				MaxOutputs:   2,
+				MaxTotal:     1000,
+				MaxExpiry:    2 * time.Hour,
+				MerchantData: []string{"name", "extendedData.orderID"},
+			},
+			reqFn: func(pr *dpp.PaymentRequest) {
+				pr.Destinations.Outputs = append(pr.Destinations.Outputs, dpp.Output{LockingScript: data})
+				pr.MerchantData = &dpp.Merchant{
+					Name:         "merchant",
+					ExtendedData: map[string]interface{}{"orderID": "123"},
+				}
+			},
+		},
+		"limits of 0 aren't enforced": {
+			cfg: config.Policy{},
+			reqFn: func(pr *dpp.PaymentRequest) {
+				pr.ExpirationTimestamp = time.Time{}
+				pr.Destinations.Outputs = append(pr.Destinations.Outputs, dpp.Output{Amount: 0, LockingScript: p2sh})
+			},
+		},
+		"too many outputs fail": {
+			cfg: config.Policy{MaxOutputs: 1},
+			reqFn: func(pr *dpp.PaymentRequest) {
+				pr.Destinations.Outputs = append(pr.Destinations.Outputs, pr.Destinations.Outputs[0])
+			},
+			expErr: errors.New("Unprocessable: payment request breaks the policy: [destinations.outputs: 2 outputs are requested, the most allowed is 1]"),
+		},
+		"total above the max fails": {
+			cfg:    config.Policy{MaxTotal: 999},
+			reqFn:  func(pr *dpp.PaymentRequest) {},
+			expErr: errors.New("Unprocessable: payment request breaks the policy: [destinations.outputs: 1000 satoshis are requested, the most allowed is 999]"),
+		},
+		"total overflowing fails": {
+			cfg: config.Policy{},
+			reqFn: func(pr *dpp.PaymentRequest) {
+				pr.Destinations.Outputs = append(pr.Destinations.Outputs, dpp.Output{
+					Amount:        math.MaxUint64,
+					LockingScript: pr.Destinations.Outputs[0].LockingScript,
+				})
+			},
+			expErr: errors.New("Unprocessable: payment request breaks the policy: [destinations.outputs: the total amount requested is too large]"),
+		},
+		"output below the dust limit fails": {
+			cfg: config.Policy{DustLimit: 1001},
+			reqFn: func(pr *dpp.PaymentRequest) {
+				pr.Destinations.Outputs = append(pr.Destinations.Outputs, dpp.Output{LockingScript: data})
+			},
+			expErr: errors.New("Unprocessable: payment request breaks the policy: [destinations.outputs[0]: amount 1000 is below the dust limit of 1001]"),
+		},
+		"script template not allowed fails": {
+			cfg: config.Policy{Scripts: []string{config.ScriptP2PKH}},
+			reqFn: func(pr *dpp.PaymentRequest) {
+				pr.Destinations.Outputs = append(pr.Destinations.Outputs, dpp.Output{LockingScript: p2sh})
+			},
+			expErr: errors.New("Unprocessable: payment request breaks the policy: [destinations.outputs[1]: the locking script isn't allowed, it must be one of: p2pkh]"),
+		},
+		"expiry beyond the horizon fails": {
+			cfg: config.Policy{MaxExpiry: time.Minute},
+			reqFn: func(pr *dpp.PaymentRequest) {
+				pr.ExpirationTimestamp = time.Now().Add(time.Hour)
+			},
+			expErr: errors.New("Unprocessable: payment request breaks the policy: [expirationTimestamp: the expiry must be within 1m0s]"),
+		},
+		"missing expiry fails with a max expiry": {
+			cfg: config.Policy{MaxExpiry: time.Minute},
+			reqFn: func(pr *dpp.PaymentRequest) {
+				pr.ExpirationTimestamp = time.Time{}
+			},
+			expErr: errors.New("Unprocessable: payment request breaks the policy: [expirationTimestamp: an expiry is required]"),
+		},
+		"missing merchant data fails": {
+			cfg: config.Policy{MerchantData: []string{"email", "extendedData.orderID"}},
+			reqFn: func(pr *dpp.PaymentRequest) {
+				pr.MerchantData = &dpp.Merchant{Email: "merchant@example.com"}
+			},
+			expErr: errors.New("Unprocessable: payment request breaks the policy: [merchantData.extendedData.orderID: value is required]"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			pr := invoiceCreate("abc123", time.Now().Add(time.Hour)).PaymentRequest
+			test.reqFn(&pr)
+			err := service.NewPolicy(&test.cfg).PolicyCheck(context.Background(), &pr)
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestPaymentRequestProxy_Policy(t *testing.T) {
+	tests := map[string]struct {
+		policyErr error
+		expStatus bool
+		expErr    error
+	}{
+		"payment request passing the policy is served": {
+			expStatus: true,
+		},
+		"payment request breaking the policy isn't served": {
+			policyErr: errors.New("payment request breaks the policy"),
+			expErr:    errors.New("invalid payment request for paymentID abc123: payment request breaks the policy"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			wallet := &mocks.PaymentRequestReaderMock{
+				PaymentRequestFunc: func(ctx context.Context, args dpp.PaymentRequestArgs) (*dpp.PaymentRequest, error) {
+					req := invoiceCreate(args.PaymentID, time.Now().Add(time.Hour)).PaymentRequest
+					req.Network = config.NetworkRegtest
+					return &req, nil
+				},
+			}
+			policy := &mocks.PaymentRequestPolicyMock{
+				PolicyCheckFunc: func(ctx context.Context, pr *dpp.PaymentRequest) error {
+					return test.policyErr
+				},
+			}
+			statusWtr := &mocks.PaymentStatusWriterMock{
+				PaymentStatusUpdateFunc: func(context.Context, server.PaymentStatus) error {
+					return nil
+				},
+			}
+			svc := service.NewPaymentRequestProxy(wallet, nil, nil, policy, statusWtr,
+				&config.Transports{Mode: config.TransportModeHybrid}, &config.Server{FQDN: "dpp.example.com"},
+				&config.Deployment{Network: config.NetworkRegtest}, &mocks.AuditLoggerMock{
+					AuditLogFunc: func(context.Context, server.AuditEvent) error {
+						return nil
+					},
+				})
+			_, err := svc.PaymentRequest(context.Background(), dpp.PaymentRequestArgs{PaymentID: "abc123"})
+			assert.Len(t, policy.PolicyCheckCalls(), 1)
+			assert.Equal(t, test.expStatus, len(statusWtr.PaymentStatusUpdateCalls()) == 1)
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/tracing"
+)
+
+type proofLookup struct {
+	store server.ProofReader
+}
+
+// NewProofLookup will setup and return a new service returning the proofs kept by the proxy.
+func NewProofLookup(store server.ProofReader) *proofLookup {
+	return &proofLookup{store: store}
+}
+
+// Proof will return the proof for a transaction.
+func (p *proofLookup) Proof(ctx context.Context, args server.ProofArgs) (*server.Proof, error) {
+	ctx, span := tracing.StartSpan(ctx, "service.proofLookup.Proof", attribute.String("txid", args.TxID))
+	defer span.End()
+	if err := args.Validate(); err != nil {
+		return nil, err
+	}
+	proof, err := p.store.Proof(ctx, args)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, errors.WithMessagef(err, "failed to get proof for txid '%s'", args.TxID)
+	}
+	return proof, nil
+}
+
+// PaymentProofs will return the proofs for the transactions of a payment.
+func (p *proofLookup) PaymentProofs(ctx context.Context, args server.PaymentProofsArgs) ([]server.Proof, error) {
+	ctx, span := tracing.StartSpan(ctx, "service.proofLookup.PaymentProofs", attribute.String("paymentID", args.PaymentID))
+	defer span.End()
+	if err := args.Validate(); err != nil {
+		return nil, err
+	}
+	proofs, err := p.store.PaymentProofs(ctx, args)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, errors.WithMessagef(err, "failed to get proofs for paymentID '%s'", args.PaymentID)
+	}
+	return proofs, nil
+}
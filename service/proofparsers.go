@@ -0,0 +1,282 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/libsv/go-bc"
+	"github.com/libsv/go-bk/crypto"
+	"github.com/libsv/go-bk/envelope"
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-dpp"
+	"github.com/pkg/errors"
+	validator "github.com/theflyingcodr/govalidator"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+)
+
+const mimeJSON = "application/json"
+
+// DefaultProofParsers returns the proof parsers supported by the proxy, keyed by content type:
+// * application/json - mAPI callbacks, in a JSON envelope or unwrapped, parsed by callback reason
+// * application/vnd.tsc.merkleproof+json - a TSC format merkle proof.
+func DefaultProofParsers() map[string]server.ProofParser {
+	doubleSpend := NewDoubleSpendParser()
+	return map[string]server.ProofParser{
+		mimeJSON: NewMAPICallbackParser(map[string]server.ProofParser{
+			server.CallbackMerkleProof:        NewMerkleProofCallbackParser(),
+			server.CallbackDoubleSpend:        doubleSpend,
+			server.CallbackDoubleSpendAttempt: doubleSpend,
+		}),
+		server.MIMEMerkleProof: NewTSCMerkleProofParser(),
+	}
+}
+
+// mapiCallback is the common part of every mAPI callback, used to select the parser.
+type mapiCallback struct {
+	CallbackReason string `json:"callbackReason"`
+}
+
+type mapiCallbackParser struct {
+	reasons map[string]server.ProofParser
+}
+
+// NewMAPICallbackParser will setup and return a parser for mAPI callbacks. Callbacks can be sent
+// in a JSON envelope, which is verified, or unwrapped. The callback payload is parsed by
+// the parser for its callbackReason.
+func NewMAPICallbackParser(reasons map[string]server.ProofParser) *mapiCallbackParser {
+	// reasons are matched case insensitively.
+	m := &mapiCallbackParser{reasons: make(map[string]server.ProofParser, len(reasons))}
+	for reason, p := range reasons {
+		m.reasons[strings.ToLower(reason)] = p
+	}
+	return m
+}
+
+// Parse will open the envelope, if used, and parse the callback payload.
+func (m *mapiCallbackParser) Parse(ctx context.Context, args dpp.ProofCreateArgs, body []byte) (*server.ProofCallback, error) {
+	var env envelope.JSONEnvelope
+	if err := json.Unmarshal(body, &env); err != nil {
+		return nil, validator.NewFromError("body", errors.Wrap(err, "callback is not valid json"))
+	}
+	payload := body
+	var signed *envelope.JSONEnvelope
+	if env.Payload != "" {
+		if err := validator.New().Validate("jsonEnvelope", func() error {
+			if ok, err := env.IsValid(); !ok || err != nil {
+				return errors.Wrap(err, "invalid merkleProof envelope")
+			}
+			return nil
+		}).Err(); err != nil {
+			return nil, err
+		}
+		payload = []byte(env.Payload)
+		if env.Signature != nil {
+			signed = &env
+		}
+	}
+	var cb mapiCallback
+	if err := json.Unmarshal(payload, &cb); err != nil {
+		return nil, validator.NewFromError("payload", errors.Wrap(err, "callback payload is not valid json"))
+	}
+	p, ok := m.reasons[strings.ToLower(cb.CallbackReason)]
+	if !ok {
+		return nil, validator.NewFromError("callbackReason", fmt.Errorf("callback reason '%s' is not supported", cb.CallbackReason))
+	}
+	resp, err := p.Parse(ctx, args, payload)
+	if err != nil {
+		return nil, err
+	}
+	resp.Envelope = signed
+	return resp, nil
+}
+
+// merkleProofCallback is a mAPI merkleProof callback, mAPI sends the callbackPayload
+// as a json encoded string though it is also accepted as an object.
+type merkleProofCallback struct {
+	CallbackPayload json.RawMessage `json:"callbackPayload"`
+	BlockHash       string          `json:"blockHash"`
+	BlockHeight     uint32          `json:"blockHeight"`
+	CallbackTxID    string          `json:"callbackTxID"`
+	CallbackReason  string          `json:"callbackReason"`
+}
+
+type merkleProofCallbackParser struct{}
+
+// NewMerkleProofCallbackParser will setup and return a parser for mAPI merkleProof callbacks.
+func NewMerkleProofCallbackParser() *merkleProofCallbackParser {
+	return &merkleProofCallbackParser{}
+}
+
+// Parse will decode and validate the merkle proof.
+func (m *merkleProofCallbackParser) Parse(ctx context.Context, args dpp.ProofCreateArgs, body []byte) (*server.ProofCallback, error) {
+	var cb merkleProofCallback
+	if err := json.Unmarshal(body, &cb); err != nil {
+		return nil, validator.NewFromError("payload", errors.Wrap(err, "invalid merkleProof callback"))
+	}
+	proof := &dpp.ProofWrapper{
+		BlockHash:      cb.BlockHash,
+		BlockHeight:    cb.BlockHeight,
+		CallbackTxID:   cb.CallbackTxID,
+		CallbackReason: cb.CallbackReason,
+	}
+	if err := unmarshalEmbedded(cb.CallbackPayload, &proof.CallbackPayload); err != nil {
+		return nil, validator.NewFromError("callbackPayload", errors.Wrap(err, "invalid merkle proof"))
+	}
+	// the TSC spec defaults the target type to a block hash.
+	if proof.CallbackPayload != nil && proof.CallbackPayload.TargetType == "" {
+		proof.CallbackPayload.TargetType = "hash"
+	}
+	if err := proof.Validate(args); err != nil {
+		return nil, err
+	}
+	return &server.ProofCallback{
+		Reason: server.CallbackMerkleProof,
+		Proof:  proof,
+	}, nil
+}
+
+// doubleSpendCallback is a mAPI doubleSpend or doubleSpendAttempt callback.
+type doubleSpendCallback struct {
+	CallbackPayload json.RawMessage `json:"callbackPayload"`
+	BlockHash       string          `json:"blockHash"`
+	BlockHeight     uint64          `json:"blockHeight"`
+	MinerID         string          `json:"minerId"`
+	CallbackTxID    string          `json:"callbackTxID"`
+	CallbackReason  string          `json:"callbackReason"`
+}
+
+// doubleSpendPayload is the callbackPayload of a double spend callback, payload is the competing tx.
+type doubleSpendPayload struct {
+	DoubleSpendTxID string `json:"doubleSpendTxId"`
+	Payload         string `json:"payload"`
+}
+
+type doubleSpendParser struct{}
+
+// NewDoubleSpendParser will setup and return a parser for mAPI doubleSpend and doubleSpendAttempt callbacks.
+func NewDoubleSpendParser() *doubleSpendParser {
+	return &doubleSpendParser{}
+}
+
+// Parse will decode and validate the double spend.
+func (d *doubleSpendParser) Parse(ctx context.Context, args dpp.ProofCreateArgs, body []byte) (*server.ProofCallback, error) {
+	var cb doubleSpendCallback
+	if err := json.Unmarshal(body, &cb); err != nil {
+		return nil, validator.NewFromError("payload", errors.Wrap(err, "invalid double spend callback"))
+	}
+	var p doubleSpendPayload
+	v := validator.New().
+		Validate("callbackTxID", func() error {
+			if cb.CallbackTxID != args.TxID {
+				return fmt.Errorf("double spend txid does not match expected txid %s", args.TxID)
+			}
+			return nil
+		}).
+		Validate("callbackPayload", func() error {
+			return errors.Wrap(unmarshalEmbedded(cb.CallbackPayload, &p), "invalid double spend payload")
+		})
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+	v = v.Validate("callbackPayload.doubleSpendTxId", validator.StrLength(p.DoubleSpendTxID, 64, 64))
+	if p.Payload != "" {
+		v = v.Validate("callbackPayload.payload", func() error {
+			tx, err := bt.NewTxFromString(p.Payload)
+			if err != nil {
+				return errors.Wrap(err, "failed to parse competing tx")
+			}
+			if tx.TxID() != p.DoubleSpendTxID {
+				return fmt.Errorf("competing tx doesn't match doubleSpendTxId %s", p.DoubleSpendTxID)
+			}
+			return nil
+		})
+	}
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+	return &server.ProofCallback{
+		Reason: cb.CallbackReason,
+		DoubleSpend: &server.DoubleSpend{
+			TxID:            args.TxID,
+			Reason:          cb.CallbackReason,
+			DoubleSpendTxID: p.DoubleSpendTxID,
+			RawTx:           p.Payload,
+			BlockHash:       cb.BlockHash,
+			BlockHeight:     cb.BlockHeight,
+			MinerID:         cb.MinerID,
+		},
+	}, nil
+}
+
+type tscMerkleProofParser struct{}
+
+// NewTSCMerkleProofParser will setup and return a parser for bare TSC format merkle proofs,
+// these are unsigned and must target a block hash or header.
+func NewTSCMerkleProofParser() *tscMerkleProofParser {
+	return &tscMerkleProofParser{}
+}
+
+// Parse will decode the merkle proof and validate it as a merkleProof callback.
+func (t *tscMerkleProofParser) Parse(ctx context.Context, args dpp.ProofCreateArgs, body []byte) (*server.ProofCallback, error) {
+	var mp bc.MerkleProof
+	if err := json.Unmarshal(body, &mp); err != nil {
+		return nil, validator.NewFromError("body", errors.Wrap(err, "invalid merkle proof"))
+	}
+	// the TSC spec defaults the target type to a block hash.
+	if mp.TargetType == "" {
+		mp.TargetType = "hash"
+	}
+	blockHash, err := targetBlockHash(mp)
+	if err != nil {
+		return nil, validator.NewFromError("target", err)
+	}
+	proof := &dpp.ProofWrapper{
+		CallbackPayload: &mp,
+		BlockHash:       blockHash,
+		CallbackTxID:    args.TxID,
+		CallbackReason:  server.CallbackMerkleProof,
+	}
+	if err := proof.Validate(args); err != nil {
+		return nil, err
+	}
+	return &server.ProofCallback{
+		Reason: server.CallbackMerkleProof,
+		Proof:  proof,
+	}, nil
+}
+
+// targetBlockHash returns the hash of the block a merkle proof targets.
+func targetBlockHash(mp bc.MerkleProof) (string, error) {
+	switch mp.TargetType {
+	case "hash":
+		return mp.Target, nil
+	case "header":
+		hb, err := hex.DecodeString(mp.Target)
+		if err != nil || len(hb) != 80 {
+			return "", errors.New("target is not a valid block header")
+		}
+		return hex.EncodeToString(bt.ReverseBytes(crypto.Sha256d(hb))), nil
+	}
+	return "", fmt.Errorf("targetType '%s' is not supported, merkle proofs must target a block hash or header", mp.TargetType)
+}
+
+// unmarshalEmbedded decodes a json value that may have been encoded as a json string.
+func unmarshalEmbedded(raw json.RawMessage, v interface{}) error {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) > 0 && raw[0] == '"' {
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return err
+		}
+		raw = []byte(s)
+	}
+	if len(raw) == 0 {
+		return errors.New("value cannot be empty")
+	}
+	return json.Unmarshal(raw, v)
+}
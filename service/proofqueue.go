@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+
+	"github.com/libsv/go-bk/envelope"
+	"github.com/libsv/go-dpp"
+	"github.com/pkg/errors"
+	"github.com/theflyingcodr/lathos"
+	"go.opentelemetry.io/otel/attribute"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/tracing"
+)
+
+type proofQueue struct {
+	proofWtr dpp.ProofsWriter
+	dsWtr    server.DoubleSpendWriter
+	store    server.PaymentQueueStore
+}
+
+// NewProofQueue will setup and return a proof writer that keeps the proofs and double spends
+// of queued payments with the payment in store, so the merchant wallet receives them when it
+// collects the payment. Callbacks are also sent on to the wallet with proofWtr and dsWtr.
+func NewProofQueue(proofWtr dpp.ProofsWriter, dsWtr server.DoubleSpendWriter, store server.PaymentQueueStore) *proofQueue {
+	return &proofQueue{
+		proofWtr: proofWtr,
+		dsWtr:    dsWtr,
+		store:    store,
+	}
+}
+
+// ProofCreate will add the proof to the queued payment of the invoice, if there is one, and
+// send it to the merchant wallet.
+func (p *proofQueue) ProofCreate(ctx context.Context, args dpp.ProofCreateArgs, req envelope.JSONEnvelope) error {
+	ctx, span := tracing.StartSpan(ctx, "service.proofQueue.ProofCreate", attribute.String("txid", args.TxID))
+	defer span.End()
+	if err := p.enqueue(ctx, args, func(qp *server.QueuedPayment) {
+		qp.Proofs = append(qp.Proofs, req)
+	}); err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+	return p.proofWtr.ProofCreate(ctx, args, req)
+}
+
+// DoubleSpendCreate will add the double spend to the queued payment of the invoice, if there is
+// one, and send it to the merchant wallet.
+func (p *proofQueue) DoubleSpendCreate(ctx context.Context, args dpp.ProofCreateArgs, req server.DoubleSpend) error {
+	ctx, span := tracing.StartSpan(ctx, "service.proofQueue.DoubleSpendCreate", attribute.String("txid", args.TxID))
+	defer span.End()
+	if err := p.enqueue(ctx, args, func(qp *server.QueuedPayment) {
+		qp.DoubleSpends = append(qp.DoubleSpends, req)
+	}); err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+	return p.dsWtr.DoubleSpendCreate(ctx, args, req)
+}
+
+// enqueue applies fn to the payment queued for the invoice, callbacks for other transactions,
+// or invoices without a queued payment, are left to the wallet. The payment is updated
+// atomically so callbacks received together aren't lost.
+func (p *proofQueue) enqueue(ctx context.Context, args dpp.ProofCreateArgs, fn func(qp *server.QueuedPayment)) error {
+	if args.PaymentReference == "" {
+		return nil
+	}
+	err := p.store.QueuedPaymentUpdate(ctx, server.QueuedPaymentArgs{PaymentID: args.PaymentReference},
+		func(qp *server.QueuedPayment) bool {
+			if qp.TxID != args.TxID {
+				return false
+			}
+			fn(qp)
+			return true
+		})
+	if lathos.IsNotFound(err) {
+		return nil
+	}
+	return errors.WithMessagef(err, "failed to queue callback for paymentID '%s'", args.PaymentReference)
+}
@@ -0,0 +1,113 @@
+package service_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/libsv/go-bk/envelope"
+	"github.com/libsv/go-dpp"
+	"github.com/stretchr/testify/assert"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/data/memory"
+	"github.com/bitcoin-sv/dpp-proxy/mocks"
+	"github.com/bitcoin-sv/dpp-proxy/service"
+)
+
+func TestProofQueue(t *testing.T) {
+	const txID = "d21633ba23f70118185227be58a63527675641ad37967e2aa461559f577aec43"
+	tests := map[string]struct {
+		args      dpp.ProofCreateArgs
+		expQueued bool
+	}{
+		"callback for a queued payment is queued": {
+			args:      dpp.ProofCreateArgs{TxID: txID, PaymentReference: "abc123"},
+			expQueued: true,
+		},
+		"callback for another transaction isn't queued": {
+			args: dpp.ProofCreateArgs{TxID: "f1f8d3cb5bd0ab6fbc1b4e3a4a8cfa3e9e6f1a9a7d39c2c8d5c4d3b2a1a0f0e0", PaymentReference: "abc123"},
+		},
+		"callback for an invoice without a queued payment isn't queued": {
+			args: dpp.ProofCreateArgs{TxID: txID, PaymentReference: "def456"},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			store := memory.NewPaymentQueue()
+			assert.NoError(t, store.PaymentEnqueue(context.Background(), server.QueuedPayment{
+				PaymentID:  "abc123",
+				TxID:       txID,
+				ReceivedAt: time.Now().UTC(),
+			}))
+			proofWtr := &mocks.ProofsWriterMock{
+				ProofCreateFunc: func(context.Context, dpp.ProofCreateArgs, envelope.JSONEnvelope) error {
+					return nil
+				},
+			}
+			dsWtr := &mocks.DoubleSpendWriterMock{
+				DoubleSpendCreateFunc: func(context.Context, dpp.ProofCreateArgs, server.DoubleSpend) error {
+					return nil
+				},
+			}
+			svc := service.NewProofQueue(proofWtr, dsWtr, store)
+			env := envelope.JSONEnvelope{Payload: `{"txOrId":"` + txID + `"}`}
+			ds := server.DoubleSpend{TxID: txID, Reason: server.CallbackDoubleSpend}
+			assert.NoError(t, svc.ProofCreate(context.Background(), test.args, env))
+			assert.NoError(t, svc.DoubleSpendCreate(context.Background(), test.args, ds))
+
+			// callbacks are always sent on to the wallet.
+			assert.Len(t, proofWtr.ProofCreateCalls(), 1)
+			assert.Len(t, dsWtr.DoubleSpendCreateCalls(), 1)
+			qp, err := store.QueuedPayment(context.Background(), server.QueuedPaymentArgs{PaymentID: "abc123"})
+			assert.NoError(t, err)
+			if !test.expQueued {
+				assert.Empty(t, qp.Proofs)
+				assert.Empty(t, qp.DoubleSpends)
+				return
+			}
+			assert.Equal(t, []envelope.JSONEnvelope{env}, qp.Proofs)
+			assert.Equal(t, []server.DoubleSpend{ds}, qp.DoubleSpends)
+		})
+	}
+}
+
+func TestProofQueue_Concurrent(t *testing.T) {
+	const txID = "d21633ba23f70118185227be58a63527675641ad37967e2aa461559f577aec43"
+	store := memory.NewPaymentQueue()
+	assert.NoError(t, store.PaymentEnqueue(context.Background(), server.QueuedPayment{
+		PaymentID:  "abc123",
+		TxID:       txID,
+		ReceivedAt: time.Now().UTC(),
+	}))
+	svc := service.NewProofQueue(&mocks.ProofsWriterMock{
+		ProofCreateFunc: func(context.Context, dpp.ProofCreateArgs, envelope.JSONEnvelope) error {
+			return nil
+		},
+	}, &mocks.DoubleSpendWriterMock{
+		DoubleSpendCreateFunc: func(context.Context, dpp.ProofCreateArgs, server.DoubleSpend) error {
+			return nil
+		},
+	}, store)
+	args := dpp.ProofCreateArgs{TxID: txID, PaymentReference: "abc123"}
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, svc.ProofCreate(context.Background(), args, envelope.JSONEnvelope{}))
+		}()
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, svc.DoubleSpendCreate(context.Background(), args, server.DoubleSpend{TxID: txID}))
+		}()
+	}
+	wg.Wait()
+
+	// no callback is lost to a concurrent update.
+	qp, err := store.QueuedPayment(context.Background(), server.QueuedPaymentArgs{PaymentID: "abc123"})
+	assert.NoError(t, err)
+	assert.Len(t, qp.Proofs, 50)
+	assert.Len(t, qp.DoubleSpends, 50)
+}
@@ -2,46 +2,244 @@ package service
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"mime"
+	"strings"
+	"time"
 
 	"github.com/libsv/go-bk/envelope"
 	"github.com/libsv/go-dpp"
 	"github.com/pkg/errors"
 	validator "github.com/theflyingcodr/govalidator"
+	"github.com/theflyingcodr/lathos/errs"
+	"go.opentelemetry.io/otel/attribute"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/config"
+	"github.com/bitcoin-sv/dpp-proxy/log"
+	"github.com/bitcoin-sv/dpp-proxy/tracing"
 )
 
 // proof enforces business rules.
 type proof struct {
-	store dpp.ProofsWriter
+	l         log.Logger
+	store     dpp.ProofsWriter
+	dsWtr     server.DoubleSpendWriter
+	tokens    server.ProofTokenReader
+	verifier  server.ProofVerifier
+	proofWtr  server.ProofWriter
+	statusWtr server.PaymentStatusWriter
+	auditLog  server.AuditLogger
+	cfg       *config.Proofs
+	parsers   map[string]server.ProofParser
 }
 
-// NewProof will setup a new proof service.
-func NewProof(store dpp.ProofsWriter) *proof {
+// NewProof will setup a new proof service. Callbacks are parsed using the parser
+// for their content type, see DefaultProofParsers, and if required must present
+// a proof callback token read from tokens.
+//
+// Merkle proofs are checked with verifier before being relayed and kept locally with
+// proofWtr, both are optional and the step is skipped if nil. Payments are marked as
+// proven with statusWtr once a proof is relayed.
+func NewProof(l log.Logger, store dpp.ProofsWriter, dsWtr server.DoubleSpendWriter, tokens server.ProofTokenReader,
+	verifier server.ProofVerifier, proofWtr server.ProofWriter, statusWtr server.PaymentStatusWriter, auditLog server.AuditLogger,
+	cfg *config.Proofs, parsers map[string]server.ProofParser) *proof {
 	return &proof{
-		store: store,
+		l:         l,
+		store:     store,
+		dsWtr:     dsWtr,
+		tokens:    tokens,
+		verifier:  verifier,
+		proofWtr:  proofWtr,
+		statusWtr: statusWtr,
+		auditLog:  auditLog,
+		cfg:       cfg,
+		parsers:   parsers,
 	}
 }
 
 // Create will add an object to the data store, rejecting the request
 // if it fails to match required validation params.
 func (s *proof) Create(ctx context.Context, args dpp.ProofCreateArgs, req envelope.JSONEnvelope) error {
-	var proof *dpp.ProofWrapper
-	if err := json.Unmarshal([]byte(req.Payload), &proof); err != nil {
-		return errors.Wrap(err, "failed to unmarshall JSONEnvelope")
+	bb, err := json.Marshal(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode JSONEnvelope")
 	}
-	if err := validator.New().Validate("jsonEnvelope", func() error {
-		if ok, err := req.IsValid(); !ok || err != nil {
-			return errors.Wrap(err, "invalid merkleProof envelope")
+	return s.ProofCallback(ctx, server.ProofCallbackArgs{ProofCreateArgs: args, ContentType: mimeJSON}, bb)
+}
+
+// ProofCallback will parse a callback from a miner or broadcaster, forwarding merkle proofs
+// and double spends to the merchant wallet.
+func (s *proof) ProofCallback(ctx context.Context, args server.ProofCallbackArgs, body []byte) error {
+	ctx, span := tracing.StartSpan(ctx, "service.proof.ProofCallback",
+		attribute.String("txid", args.TxID),
+		attribute.String("paymentReference", args.PaymentReference),
+		attribute.String("contentType", args.ContentType))
+	defer span.End()
+	if s.cfg.RequireToken {
+		if err := s.authenticate(ctx, &args); err != nil {
+			tracing.RecordError(span, err)
+			return err
 		}
-		return nil
-	}).Err(); err != nil {
+	}
+	p, err := s.parser(args.ContentType)
+	if err != nil {
+		return err
+	}
+	cb, err := p.Parse(ctx, args.ProofCreateArgs, body)
+	if err != nil {
 		return err
 	}
-	if err := proof.Validate(args); err != nil {
+	if cb.Envelope == nil && !s.cfg.AllowUnsigned {
+		return validator.NewFromError("signature", errors.New("unsigned callbacks are not accepted"))
+	}
+	switch {
+	case cb.Proof != nil:
+		err = s.proofCreate(ctx, args.ProofCreateArgs, cb)
+	case cb.DoubleSpend != nil:
+		err = s.doubleSpendCreate(ctx, args.ProofCreateArgs, cb)
+	default:
+		err = errors.Errorf("callback with reason '%s' contained no proof or double spend", cb.Reason)
+	}
+	if err != nil {
+		tracing.RecordError(span, err)
+	}
+	return err
+}
+
+// authenticate ensures the callback presents a token supplied with the payment of the txid,
+// the payment reference is set from the payment if not supplied.
+func (s *proof) authenticate(ctx context.Context, args *server.ProofCallbackArgs) error {
+	if args.Token == "" {
+		return errs.NewErrNotAuthenticated("401", "bearer token required")
+	}
+	token, err := s.tokens.ProofToken(ctx, args.TxID)
+	if err != nil {
+		return errors.WithMessagef(err, "failed to get proof tokens for txid '%s'", args.TxID)
+	}
+	if args.PaymentReference == "" {
+		args.PaymentReference = token.PaymentID
+	}
+	if args.PaymentReference != token.PaymentID {
+		return errs.NewErrNotAuthorised("403", "transaction is not a payment for the invoice")
+	}
+	for _, t := range token.Tokens {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(args.Token)) == 1 {
+			return nil
+		}
+	}
+	return errs.NewErrNotAuthenticated("401", "invalid bearer token")
+}
+
+// parser returns the parser for a content type, json is assumed if no content type is supplied.
+func (s *proof) parser(contentType string) (server.ProofParser, error) {
+	mt := mimeJSON
+	if contentType != "" {
+		var err error
+		if mt, _, err = mime.ParseMediaType(contentType); err != nil {
+			return nil, validator.NewFromError("contentType", errors.Wrapf(err, "invalid content type '%s'", contentType))
+		}
+	}
+	p, ok := s.parsers[strings.ToLower(mt)]
+	if !ok {
+		return nil, validator.NewFromError("contentType", errors.Errorf("content type '%s' is not supported", mt))
+	}
+	return p, nil
+}
+
+// proofCreate forwards a merkle proof to the merchant wallet, proofs are always sent in an
+// envelope so unsigned proofs are wrapped in one without a signature.
+//
+// Proofs are kept before being forwarded, so customers can collect them if the merchant
+// wallet is offline.
+func (s *proof) proofCreate(ctx context.Context, args dpp.ProofCreateArgs, cb *server.ProofCallback) error {
+	unverified, err := s.verify(ctx, args, cb.Proof)
+	if err != nil {
 		return err
 	}
-	if err := s.store.ProofCreate(ctx, args, req); err != nil {
+	env := cb.Envelope
+	if env == nil {
+		bb, err := json.Marshal(cb.Proof)
+		if err != nil {
+			return errors.Wrap(err, "failed to encode proof")
+		}
+		env = &envelope.JSONEnvelope{
+			Payload:  string(bb),
+			Encoding: "UTF-8",
+			MimeType: mimeJSON,
+		}
+	}
+	if s.proofWtr != nil {
+		if err := s.proofWtr.ProofCreate(ctx, server.Proof{
+			TxID:       args.TxID,
+			PaymentID:  args.PaymentReference,
+			Envelope:   env,
+			Unverified: unverified,
+			CreatedAt:  time.Now().UTC(),
+		}); err != nil {
+			return errors.Wrapf(err, "failed to store proof with txid '%s'", args.TxID)
+		}
+	}
+	if err := s.store.ProofCreate(ctx, args, *env); err != nil {
 		return errors.Wrapf(err, "failed to add proof with txid '%s' and invoiceID '%s'", args.TxID, args.PaymentReference)
 	}
+	if args.PaymentReference != "" {
+		// the proof has been relayed, so a failure is logged rather than failing the callback.
+		if err := s.statusWtr.PaymentStatusUpdate(ctx, server.PaymentStatus{
+			PaymentID: args.PaymentReference,
+			State:     server.PaymentStateProven,
+			TxID:      args.TxID,
+		}); err != nil {
+			s.l.Errorf(err, "failed to record status for paymentID %s", args.PaymentReference)
+		}
+	}
+	if err := s.auditLog.AuditLog(ctx, server.AuditEvent{
+		Type:      server.AuditProof,
+		PaymentID: args.PaymentReference,
+		TxID:      args.TxID,
+		Payload: server.AuditProofPayload{
+			Envelope:   env,
+			Unverified: unverified,
+		},
+	}); err != nil {
+		return errors.Wrapf(err, "failed to audit proof with txid '%s'", args.TxID)
+	}
+	return nil
+}
+
+// verify checks the proof against the block headers, if a verifier is setup. Proofs that
+// fail are rejected if configured, otherwise the reason is returned so the proof can be
+// relayed and flagged.
+func (s *proof) verify(ctx context.Context, args dpp.ProofCreateArgs, proof *dpp.ProofWrapper) (string, error) {
+	if s.verifier == nil {
+		return "", nil
+	}
+	err := s.verifier.VerifyProof(ctx, proof)
+	if err == nil {
+		return "", nil
+	}
+	if s.cfg.RejectUnverified {
+		return "", errors.WithMessagef(err, "failed to verify proof for txid '%s'", args.TxID)
+	}
+	s.l.Warnf("relaying unverified proof for txid %s and invoiceID %s: %s", args.TxID, args.PaymentReference, err)
+	return err.Error(), nil
+}
+
+// doubleSpendCreate notifies the merchant wallet that a payment has been double spent.
+func (s *proof) doubleSpendCreate(ctx context.Context, args dpp.ProofCreateArgs, cb *server.ProofCallback) error {
+	ds := *cb.DoubleSpend
+	ds.Envelope = cb.Envelope
+	if err := s.dsWtr.DoubleSpendCreate(ctx, args, ds); err != nil {
+		return errors.Wrapf(err, "failed to send double spend of txid '%s' for invoiceID '%s'", args.TxID, args.PaymentReference)
+	}
+	if err := s.auditLog.AuditLog(ctx, server.AuditEvent{
+		Type:      server.AuditDoubleSpend,
+		PaymentID: args.PaymentReference,
+		TxID:      args.TxID,
+		Payload:   ds,
+	}); err != nil {
+		return errors.Wrapf(err, "failed to audit double spend of txid '%s'", args.TxID)
+	}
 	return nil
 }
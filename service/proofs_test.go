@@ -0,0 +1,380 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/libsv/go-bc"
+	"github.com/libsv/go-bk/envelope"
+	"github.com/libsv/go-dpp"
+	"github.com/stretchr/testify/assert"
+	"github.com/theflyingcodr/lathos/errs"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/config"
+	"github.com/bitcoin-sv/dpp-proxy/log"
+	"github.com/bitcoin-sv/dpp-proxy/mocks"
+	"github.com/bitcoin-sv/dpp-proxy/service"
+)
+
+const (
+	proofTxID       = "d21633ba23f70118185227be58a63527675641ad37967e2aa461559f577aec43"
+	genesisHeader   = "0100000000000000000000000000000000000000000000000000000000000000000000003ba3edfd7a7b12b27ac72c3e67768f617fc81bc3888a51323a9fb8aa4b1e5e4a29ab5f49ffff001d1dac2b7c"
+	genesisHash     = "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"
+	doubleSpendTxID = "f1f8d3cb5bd0ab6fbc1b4e3a4a8cfa3e9e6f1a9a7d39c2c8d5c4d3b2a1a0f0e0"
+)
+
+func TestProof_ProofCallback(t *testing.T) {
+	merkleProof := &bc.MerkleProof{
+		TxOrID:     proofTxID,
+		Target:     genesisHash,
+		TargetType: "hash",
+		Nodes:      []string{proofTxID},
+	}
+	signed, err := envelope.NewJSONEnvelope(dpp.ProofWrapper{
+		CallbackPayload: merkleProof,
+		BlockHash:       genesisHash,
+		CallbackTxID:    proofTxID,
+		CallbackReason:  "merkleProof",
+	})
+	assert.NoError(t, err)
+	signedBody, err := json.Marshal(signed)
+	assert.NoError(t, err)
+	mpBody, err := json.Marshal(merkleProof)
+	assert.NoError(t, err)
+
+	tests := map[string]struct {
+		contentType    string
+		body           string
+		allowUnsigned  bool
+		expProof       bool
+		expSigned      bool
+		expBlockHash   string
+		expDoubleSpend *server.DoubleSpend
+		expErr         error
+	}{
+		"signed mapi merkle proof is forwarded in its envelope": {
+			contentType:  "application/json; charset=UTF-8",
+			body:         string(signedBody),
+			expProof:     true,
+			expSigned:    true,
+			expBlockHash: genesisHash,
+		},
+		"unwrapped mapi merkle proof with string payload is forwarded": {
+			body: `{"callbackPayload":"{\"index\":0,\"txOrId\":\"` + proofTxID + `\",\"target\":\"` + genesisHash +
+				`\",\"nodes\":[\"` + proofTxID + `\"]}","blockHash":"` + genesisHash +
+				`","blockHeight":1,"callbackTxId":"` + proofTxID + `","callbackReason":"merkleProof"}`,
+			allowUnsigned: true,
+			expProof:      true,
+			expBlockHash:  genesisHash,
+		},
+		"unsigned callback is rejected when not allowed": {
+			body: `{"callbackPayload":"{\"index\":0,\"txOrId\":\"` + proofTxID + `\",\"target\":\"` + genesisHash +
+				`\",\"nodes\":[\"` + proofTxID + `\"]}","blockHash":"` + genesisHash +
+				`","blockHeight":1,"callbackTxId":"` + proofTxID + `","callbackReason":"merkleProof"}`,
+			expErr: errors.New("[signature: unsigned callbacks are not accepted]"),
+		},
+		"tsc merkle proof targeting a header is forwarded": {
+			contentType: server.MIMEMerkleProof,
+			body: `{"index":0,"txOrId":"` + proofTxID + `","target":"` + genesisHeader +
+				`","targetType":"header","nodes":["` + proofTxID + `"]}`,
+			allowUnsigned: true,
+			expProof:      true,
+			expBlockHash:  genesisHash,
+		},
+		"tsc merkle proof defaults to a hash target": {
+			contentType:   server.MIMEMerkleProof,
+			body:          string(mpBody),
+			allowUnsigned: true,
+			expProof:      true,
+			expBlockHash:  genesisHash,
+		},
+		"tsc merkle proof targeting a merkle root is rejected": {
+			contentType: server.MIMEMerkleProof,
+			body: `{"index":0,"txOrId":"` + proofTxID + `","target":"` + genesisHash +
+				`","targetType":"merkleRoot","nodes":["` + proofTxID + `"]}`,
+			allowUnsigned: true,
+			expErr:        errors.New("[target: targetType 'merkleRoot' is not supported, merkle proofs must target a block hash or header]"),
+		},
+		"double spend is forwarded": {
+			body: `{"callbackPayload":"{\"doubleSpendTxId\":\"` + doubleSpendTxID + `\"}","blockHash":"","blockHeight":0,` +
+				`"minerId":"030d1fe5c1b560efe196ba40540ce9017c20daa9504c4c4cec6184fc702d9f274e","callbackTxId":"` + proofTxID +
+				`","callbackReason":"doubleSpendAttempt"}`,
+			allowUnsigned: true,
+			expDoubleSpend: &server.DoubleSpend{
+				TxID:            proofTxID,
+				Reason:          server.CallbackDoubleSpendAttempt,
+				DoubleSpendTxID: doubleSpendTxID,
+				MinerID:         "030d1fe5c1b560efe196ba40540ce9017c20daa9504c4c4cec6184fc702d9f274e",
+			},
+		},
+		"double spend for another tx is rejected": {
+			body: `{"callbackPayload":{"doubleSpendTxId":"` + doubleSpendTxID + `"},"callbackTxId":"` + doubleSpendTxID +
+				`","callbackReason":"doubleSpend"}`,
+			allowUnsigned: true,
+			expErr:        errors.New("[callbackTxID: double spend txid does not match expected txid " + proofTxID + "]"),
+		},
+		"unknown callback reason is rejected": {
+			body:          `{"callbackReason":"somethingElse"}`,
+			allowUnsigned: true,
+			expErr:        errors.New("[callbackReason: callback reason 'somethingElse' is not supported]"),
+		},
+		"unknown content type is rejected": {
+			contentType: "text/plain",
+			body:        "hello",
+			expErr:      errors.New("[contentType: content type 'text/plain' is not supported]"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			store := &mocks.ProofsWriterMock{
+				ProofCreateFunc: func(context.Context, dpp.ProofCreateArgs, envelope.JSONEnvelope) error {
+					return nil
+				},
+			}
+			dsWtr := &mocks.DoubleSpendWriterMock{
+				DoubleSpendCreateFunc: func(context.Context, dpp.ProofCreateArgs, server.DoubleSpend) error {
+					return nil
+				},
+			}
+			auditLog := &mocks.AuditLoggerMock{
+				AuditLogFunc: func(context.Context, server.AuditEvent) error {
+					return nil
+				},
+			}
+			statusWtr := &mocks.PaymentStatusWriterMock{
+				PaymentStatusUpdateFunc: func(context.Context, server.PaymentStatus) error {
+					return nil
+				},
+			}
+			svc := service.NewProof(log.Noop{}, store, dsWtr, &mocks.ProofTokenReaderMock{}, nil, nil, statusWtr, auditLog,
+				&config.Proofs{AllowUnsigned: test.allowUnsigned}, service.DefaultProofParsers())
+			args := server.ProofCallbackArgs{
+				ProofCreateArgs: dpp.ProofCreateArgs{TxID: proofTxID, PaymentReference: "abc123"},
+				ContentType:     test.contentType,
+			}
+			err := svc.ProofCallback(context.Background(), args, []byte(test.body))
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+				assert.Empty(t, store.ProofCreateCalls())
+				assert.Empty(t, dsWtr.DoubleSpendCreateCalls())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, auditLog.AuditLogCalls(), 1)
+			if test.expProof {
+				if !assert.Len(t, store.ProofCreateCalls(), 1) {
+					return
+				}
+				env := store.ProofCreateCalls()[0].Req
+				assert.Equal(t, test.expSigned, env.Signature != nil)
+				var proof dpp.ProofWrapper
+				assert.NoError(t, json.Unmarshal([]byte(env.Payload), &proof))
+				assert.Equal(t, test.expBlockHash, proof.BlockHash)
+				assert.Equal(t, proofTxID, proof.CallbackPayload.TxOrID)
+				if assert.Len(t, statusWtr.PaymentStatusUpdateCalls(), 1) {
+					assert.Equal(t, server.PaymentStatus{
+						PaymentID: "abc123",
+						State:     server.PaymentStateProven,
+						TxID:      proofTxID,
+					}, statusWtr.PaymentStatusUpdateCalls()[0].Req)
+				}
+			}
+			if test.expDoubleSpend != nil {
+				if !assert.Len(t, dsWtr.DoubleSpendCreateCalls(), 1) {
+					return
+				}
+				assert.Equal(t, *test.expDoubleSpend, dsWtr.DoubleSpendCreateCalls()[0].Req)
+				assert.Equal(t, server.AuditDoubleSpend, auditLog.AuditLogCalls()[0].Evt.Type)
+			}
+		})
+	}
+}
+
+func TestProof_ProofCallbackToken(t *testing.T) {
+	body := `{"callbackPayload":"{\"doubleSpendTxId\":\"` + doubleSpendTxID + `\"}","callbackTxId":"` + proofTxID +
+		`","callbackReason":"doubleSpend"}`
+	tests := map[string]struct {
+		token               string
+		paymentReference    string
+		proofTokenFn        func(context.Context, string) (*server.ProofToken, error)
+		expPaymentReference string
+		expErr              error
+	}{
+		"callback with a payment token is accepted": {
+			token:            "def",
+			paymentReference: "abc123",
+			proofTokenFn: func(context.Context, string) (*server.ProofToken, error) {
+				return &server.ProofToken{TxID: proofTxID, PaymentID: "abc123", Tokens: []string{"abc", "def"}}, nil
+			},
+			expPaymentReference: "abc123",
+		},
+		"payment reference is set from the payment if missing": {
+			token: "abc",
+			proofTokenFn: func(context.Context, string) (*server.ProofToken, error) {
+				return &server.ProofToken{TxID: proofTxID, PaymentID: "abc123", Tokens: []string{"abc"}}, nil
+			},
+			expPaymentReference: "abc123",
+		},
+		"missing token is rejected": {
+			paymentReference: "abc123",
+			expErr:           errors.New("bearer token required"),
+		},
+		"invalid token is rejected": {
+			token:            "xyz",
+			paymentReference: "abc123",
+			proofTokenFn: func(context.Context, string) (*server.ProofToken, error) {
+				return &server.ProofToken{TxID: proofTxID, PaymentID: "abc123", Tokens: []string{"abc"}}, nil
+			},
+			expErr: errors.New("invalid bearer token"),
+		},
+		"callback for another invoice is rejected": {
+			token:            "abc",
+			paymentReference: "other",
+			proofTokenFn: func(context.Context, string) (*server.ProofToken, error) {
+				return &server.ProofToken{TxID: proofTxID, PaymentID: "abc123", Tokens: []string{"abc"}}, nil
+			},
+			expErr: errors.New("transaction is not a payment for the invoice"),
+		},
+		"callback for an unknown txid is rejected": {
+			token:            "abc",
+			paymentReference: "abc123",
+			proofTokenFn: func(context.Context, string) (*server.ProofToken, error) {
+				return nil, errs.NewErrNotFound("404", "no payment found for txid")
+			},
+			expErr: errors.New("failed to get proof tokens for txid '" + proofTxID + "': Not found: no payment found for txid"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dsWtr := &mocks.DoubleSpendWriterMock{
+				DoubleSpendCreateFunc: func(context.Context, dpp.ProofCreateArgs, server.DoubleSpend) error {
+					return nil
+				},
+			}
+			tokens := &mocks.ProofTokenReaderMock{
+				ProofTokenFunc: func(ctx context.Context, txID string) (*server.ProofToken, error) {
+					assert.Equal(t, proofTxID, txID)
+					return test.proofTokenFn(ctx, txID)
+				},
+			}
+			auditLog := &mocks.AuditLoggerMock{
+				AuditLogFunc: func(context.Context, server.AuditEvent) error {
+					return nil
+				},
+			}
+			svc := service.NewProof(log.Noop{}, &mocks.ProofsWriterMock{}, dsWtr, tokens, nil, nil, &mocks.PaymentStatusWriterMock{}, auditLog,
+				&config.Proofs{AllowUnsigned: true, RequireToken: true}, service.DefaultProofParsers())
+			err := svc.ProofCallback(context.Background(), server.ProofCallbackArgs{
+				ProofCreateArgs: dpp.ProofCreateArgs{TxID: proofTxID, PaymentReference: test.paymentReference},
+				Token:           test.token,
+			}, []byte(body))
+			if test.expErr != nil {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), test.expErr.Error())
+				assert.Empty(t, dsWtr.DoubleSpendCreateCalls())
+				return
+			}
+			assert.NoError(t, err)
+			if assert.Len(t, dsWtr.DoubleSpendCreateCalls(), 1) {
+				assert.Equal(t, test.expPaymentReference, dsWtr.DoubleSpendCreateCalls()[0].Args.PaymentReference)
+			}
+		})
+	}
+}
+
+func TestProof_ProofCallbackVerify(t *testing.T) {
+	body, err := json.Marshal(&bc.MerkleProof{
+		TxOrID:     proofTxID,
+		Target:     genesisHash,
+		TargetType: "hash",
+		Nodes:      []string{proofTxID},
+	})
+	assert.NoError(t, err)
+	tests := map[string]struct {
+		verifyProofFn    func(context.Context, *dpp.ProofWrapper) error
+		rejectUnverified bool
+		expUnverified    string
+		expErr           error
+	}{
+		"verified proof is relayed": {
+			verifyProofFn: func(context.Context, *dpp.ProofWrapper) error {
+				return nil
+			},
+		},
+		"unverified proof is relayed and flagged": {
+			verifyProofFn: func(context.Context, *dpp.ProofWrapper) error {
+				return errors.New("[blockHash: block " + genesisHash + " is not on the best chain]")
+			},
+			expUnverified: "[blockHash: block " + genesisHash + " is not on the best chain]",
+		},
+		"unverified proof is rejected when configured": {
+			verifyProofFn: func(context.Context, *dpp.ProofWrapper) error {
+				return errors.New("[blockHash: block " + genesisHash + " is not on the best chain]")
+			},
+			rejectUnverified: true,
+			expErr: errors.New("failed to verify proof for txid '" + proofTxID + "': [blockHash: block " + genesisHash +
+				" is not on the best chain]"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			store := &mocks.ProofsWriterMock{
+				ProofCreateFunc: func(context.Context, dpp.ProofCreateArgs, envelope.JSONEnvelope) error {
+					return nil
+				},
+			}
+			verifier := &mocks.ProofVerifierMock{
+				VerifyProofFunc: func(ctx context.Context, proof *dpp.ProofWrapper) error {
+					assert.Equal(t, genesisHash, proof.BlockHash)
+					return test.verifyProofFn(ctx, proof)
+				},
+			}
+			proofWtr := &mocks.ProofWriterMock{
+				ProofCreateFunc: func(context.Context, server.Proof) error {
+					return nil
+				},
+			}
+			auditLog := &mocks.AuditLoggerMock{
+				AuditLogFunc: func(context.Context, server.AuditEvent) error {
+					return nil
+				},
+			}
+			statusWtr := &mocks.PaymentStatusWriterMock{
+				PaymentStatusUpdateFunc: func(context.Context, server.PaymentStatus) error {
+					return nil
+				},
+			}
+			svc := service.NewProof(log.Noop{}, store, &mocks.DoubleSpendWriterMock{}, &mocks.ProofTokenReaderMock{}, verifier, proofWtr, statusWtr, auditLog,
+				&config.Proofs{AllowUnsigned: true, RejectUnverified: test.rejectUnverified}, service.DefaultProofParsers())
+			err := svc.ProofCallback(context.Background(), server.ProofCallbackArgs{
+				ProofCreateArgs: dpp.ProofCreateArgs{TxID: proofTxID, PaymentReference: "abc123"},
+				ContentType:     server.MIMEMerkleProof,
+			}, body)
+			assert.Len(t, verifier.VerifyProofCalls(), 1)
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+				assert.Empty(t, store.ProofCreateCalls())
+				assert.Empty(t, proofWtr.ProofCreateCalls())
+				assert.Empty(t, auditLog.AuditLogCalls())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, store.ProofCreateCalls(), 1)
+			if assert.Len(t, proofWtr.ProofCreateCalls(), 1) {
+				proof := proofWtr.ProofCreateCalls()[0].Req
+				assert.Equal(t, proofTxID, proof.TxID)
+				assert.Equal(t, "abc123", proof.PaymentID)
+				assert.Equal(t, test.expUnverified, proof.Unverified)
+				assert.Equal(t, store.ProofCreateCalls()[0].Req, *proof.Envelope)
+			}
+			if assert.Len(t, auditLog.AuditLogCalls(), 1) {
+				payload, ok := auditLog.AuditLogCalls()[0].Evt.Payload.(server.AuditProofPayload)
+				assert.True(t, ok)
+				assert.Equal(t, test.expUnverified, payload.Unverified)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/libsv/go-bt/v2"
+	"github.com/libsv/go-dpp"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/tracing"
+)
+
+type proofToken struct {
+	store server.ProofTokenWriter
+}
+
+// NewProofToken will setup and return a new service recording the proof callback tokens
+// of payments that aren't sent through the payment service, such as socket payments.
+func NewProofToken(store server.ProofTokenWriter) *proofToken {
+	return &proofToken{store: store}
+}
+
+// ProofTokenCreate will record the proof callback tokens supplied with a payment.
+func (p *proofToken) ProofTokenCreate(ctx context.Context, args dpp.PaymentCreateArgs, req dpp.Payment) error {
+	ctx, span := tracing.StartSpan(ctx, "service.proofToken.ProofTokenCreate", attribute.String("paymentID", args.PaymentID))
+	defer span.End()
+	token, ok := newProofToken(args.PaymentID, req)
+	if !ok {
+		return nil
+	}
+	if err := p.store.ProofTokenCreate(ctx, *token); err != nil {
+		tracing.RecordError(span, err)
+		return errors.Wrapf(err, "failed to record proof tokens for paymentID '%s'", args.PaymentID)
+	}
+	return nil
+}
+
+// newProofToken returns the proof callback tokens supplied with a payment, false
+// is returned if there are none or the payment transaction can't be read.
+func newProofToken(paymentID string, req dpp.Payment) (*server.ProofToken, bool) {
+	if req.RawTx == nil || len(req.ProofCallbacks) == 0 {
+		return nil, false
+	}
+	tx, err := bt.NewTxFromString(*req.RawTx)
+	if err != nil {
+		return nil, false
+	}
+	token := &server.ProofToken{
+		TxID:      tx.TxID(),
+		PaymentID: paymentID,
+		CreatedAt: time.Now().UTC(),
+	}
+	for _, cb := range req.ProofCallbacks {
+		if cb.Token != "" {
+			token.Tokens = append(token.Tokens, cb.Token)
+		}
+	}
+	return token, len(token.Tokens) > 0
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/libsv/go-bc"
+	"github.com/libsv/go-bc/spv"
+	"github.com/libsv/go-dpp"
+	"github.com/pkg/errors"
+	validator "github.com/theflyingcodr/govalidator"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/bitcoin-sv/dpp-proxy/tracing"
+)
+
+type proofVerifier struct {
+	headers bc.BlockHeaderChain
+	mpv     spv.MerkleProofVerifier
+}
+
+// NewProofVerifier will setup and return a verifier that computes the merkle root of
+// proofs and checks it against the block header, read from headers, of the proof target.
+func NewProofVerifier(headers bc.BlockHeaderChain) (*proofVerifier, error) {
+	mpv, err := spv.NewMerkleProofVerifier(headers)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to setup merkle proof verifier")
+	}
+	return &proofVerifier{
+		headers: headers,
+		mpv:     mpv,
+	}, nil
+}
+
+// VerifyProof will check the proof links the transaction to a block on the best chain.
+//
+// Validation errors are returned if the proof is invalid, or its block is unknown or
+// stale, other errors are returned if the headers couldn't be read.
+func (p *proofVerifier) VerifyProof(ctx context.Context, proof *dpp.ProofWrapper) error {
+	ctx, span := tracing.StartSpan(ctx, "service.proofVerifier.VerifyProof",
+		attribute.String("txid", proof.CallbackTxID),
+		attribute.String("blockHash", proof.BlockHash))
+	defer span.End()
+	if proof.CallbackPayload == nil {
+		return validator.NewFromError("callbackPayload", errors.New("merkle proof is missing"))
+	}
+	mp := *proof.CallbackPayload
+	blockHash := proof.BlockHash
+	if mp.TargetType != "merkleRoot" {
+		hash, err := targetBlockHash(mp)
+		if err != nil {
+			return validator.NewFromError("callbackPayload.target", err)
+		}
+		if proof.BlockHash != "" && hash != proof.BlockHash {
+			return validator.NewFromError("blockHash", fmt.Errorf("proof targets block %s not %s", hash, proof.BlockHash))
+		}
+		blockHash = hash
+	}
+	header, err := p.headers.BlockHeader(ctx, blockHash)
+	switch {
+	case errors.Is(err, bc.ErrHeaderNotFound):
+		return validator.NewFromError("blockHash", fmt.Errorf("block %s was not found", blockHash))
+	case errors.Is(err, bc.ErrNotOnLongestChain):
+		return validator.NewFromError("blockHash", fmt.Errorf("block %s is not on the best chain", blockHash))
+	case err != nil:
+		tracing.RecordError(span, err)
+		return errors.Wrapf(err, "failed to get header for block %s", blockHash)
+	}
+	merkleRoot := header.HashMerkleRootStr()
+	if mp.TargetType == "merkleRoot" && mp.Target != merkleRoot {
+		return validator.NewFromError("callbackPayload.target", fmt.Errorf("merkle root does not match block %s", blockHash))
+	}
+	// the header has been read, so verify against its merkle root rather than reading it again.
+	mp.TargetType = "merkleRoot"
+	mp.Target = merkleRoot
+	valid, _, err := p.mpv.VerifyMerkleProofJSON(ctx, &mp)
+	if err != nil {
+		return validator.NewFromError("callbackPayload", errors.Wrap(err, "invalid merkle proof"))
+	}
+	if !valid {
+		return validator.NewFromError("callbackPayload", fmt.Errorf("merkle proof does not link the transaction to block %s", blockHash))
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/libsv/go-bc"
+	"github.com/libsv/go-dpp"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bitcoin-sv/dpp-proxy/mocks"
+	"github.com/bitcoin-sv/dpp-proxy/service"
+)
+
+const (
+	// block 1 contains only its coinbase, so its merkle root is the coinbase txid.
+	block1Header   = "010000006fe28c0ab6f1b372c1a6a246ae63f74f931e8365e15a089c68d6190000000000982051fd1e4ba744bbbe680e1fee14677ba1a3c3540bf7b1cdb606e857233e0e61bc6649ffff001d01e36299"
+	block1Hash     = "00000000839a8e6886ab5951d76f411475428afc90947ee320161bbf18eb6048"
+	block1Coinbase = "0e3e2357e806b6cdb1f70b54c3a3a17b6714ee1f0e68bebb44a74b1efd512098"
+)
+
+func TestProofVerifier_VerifyProof(t *testing.T) {
+	header, err := bc.NewBlockHeaderFromStr(block1Header)
+	assert.NoError(t, err)
+	tests := map[string]struct {
+		proof         *dpp.ProofWrapper
+		blockHeaderFn func(context.Context, string) (*bc.BlockHeader, error)
+		expErr        error
+	}{
+		"proof targeting a block hash on the best chain is verified": {
+			proof: &dpp.ProofWrapper{
+				BlockHash:       block1Hash,
+				CallbackPayload: &bc.MerkleProof{TxOrID: block1Coinbase, Target: block1Hash, TargetType: "hash"},
+			},
+			blockHeaderFn: func(context.Context, string) (*bc.BlockHeader, error) {
+				return header, nil
+			},
+		},
+		"proof targeting a block header on the best chain is verified": {
+			proof: &dpp.ProofWrapper{
+				BlockHash:       block1Hash,
+				CallbackPayload: &bc.MerkleProof{TxOrID: block1Coinbase, Target: block1Header, TargetType: "header"},
+			},
+			blockHeaderFn: func(context.Context, string) (*bc.BlockHeader, error) {
+				return header, nil
+			},
+		},
+		"proof targeting a merkle root is verified against the block hash": {
+			proof: &dpp.ProofWrapper{
+				BlockHash:       block1Hash,
+				CallbackPayload: &bc.MerkleProof{TxOrID: block1Coinbase, Target: block1Coinbase, TargetType: "merkleRoot"},
+			},
+			blockHeaderFn: func(context.Context, string) (*bc.BlockHeader, error) {
+				return header, nil
+			},
+		},
+		"proof not linking the tx to the block is rejected": {
+			proof: &dpp.ProofWrapper{
+				BlockHash: block1Hash,
+				CallbackPayload: &bc.MerkleProof{TxOrID: block1Coinbase, Target: block1Hash, TargetType: "hash",
+					Nodes: []string{block1Coinbase}},
+			},
+			blockHeaderFn: func(context.Context, string) (*bc.BlockHeader, error) {
+				return header, nil
+			},
+			expErr: errors.New("[callbackPayload: merkle proof does not link the transaction to block " + block1Hash + "]"),
+		},
+		"proof with a merkle root not matching the block is rejected": {
+			proof: &dpp.ProofWrapper{
+				BlockHash:       block1Hash,
+				CallbackPayload: &bc.MerkleProof{TxOrID: proofTxID, Target: proofTxID, TargetType: "merkleRoot"},
+			},
+			blockHeaderFn: func(context.Context, string) (*bc.BlockHeader, error) {
+				return header, nil
+			},
+			expErr: errors.New("[callbackPayload.target: merkle root does not match block " + block1Hash + "]"),
+		},
+		"proof targeting a different block to the block hash is rejected": {
+			proof: &dpp.ProofWrapper{
+				BlockHash:       genesisHash,
+				CallbackPayload: &bc.MerkleProof{TxOrID: block1Coinbase, Target: block1Hash, TargetType: "hash"},
+			},
+			expErr: errors.New("[blockHash: proof targets block " + block1Hash + " not " + genesisHash + "]"),
+		},
+		"proof for an unknown block is rejected": {
+			proof: &dpp.ProofWrapper{
+				BlockHash:       block1Hash,
+				CallbackPayload: &bc.MerkleProof{TxOrID: block1Coinbase, Target: block1Hash, TargetType: "hash"},
+			},
+			blockHeaderFn: func(context.Context, string) (*bc.BlockHeader, error) {
+				return nil, bc.ErrHeaderNotFound
+			},
+			expErr: errors.New("[blockHash: block " + block1Hash + " was not found]"),
+		},
+		"proof for a stale block is rejected": {
+			proof: &dpp.ProofWrapper{
+				BlockHash:       block1Hash,
+				CallbackPayload: &bc.MerkleProof{TxOrID: block1Coinbase, Target: block1Hash, TargetType: "hash"},
+			},
+			blockHeaderFn: func(context.Context, string) (*bc.BlockHeader, error) {
+				return nil, bc.ErrNotOnLongestChain
+			},
+			expErr: errors.New("[blockHash: block " + block1Hash + " is not on the best chain]"),
+		},
+		"header source error is returned": {
+			proof: &dpp.ProofWrapper{
+				BlockHash:       block1Hash,
+				CallbackPayload: &bc.MerkleProof{TxOrID: block1Coinbase, Target: block1Hash, TargetType: "hash"},
+			},
+			blockHeaderFn: func(context.Context, string) (*bc.BlockHeader, error) {
+				return nil, errors.New("connection refused")
+			},
+			expErr: errors.New("failed to get header for block " + block1Hash + ": connection refused"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			headers := &mocks.BlockHeaderChainMock{
+				BlockHeaderFunc: func(ctx context.Context, blockHash string) (*bc.BlockHeader, error) {
+					assert.Equal(t, block1Hash, blockHash)
+					return test.blockHeaderFn(ctx, blockHash)
+				},
+			}
+			v, err := service.NewProofVerifier(headers)
+			assert.NoError(t, err)
+			err = v.VerifyProof(context.Background(), test.proof)
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/config"
+	"github.com/bitcoin-sv/dpp-proxy/tracing"
+)
+
+type refund struct {
+	store     server.RefundReader
+	deployCfg *config.Deployment
+}
+
+// NewRefund will setup and return a new refund service, building refund instructions
+// from the refund destinations recorded with payments.
+func NewRefund(store server.RefundReader, deployCfg *config.Deployment) *refund {
+	return &refund{
+		store:     store,
+		deployCfg: deployCfg,
+	}
+}
+
+// RefundInstructions will return the destination a payment should be refunded to.
+func (r *refund) RefundInstructions(ctx context.Context, args server.RefundArgs) (*server.RefundInstructions, error) {
+	ctx, span := tracing.StartSpan(ctx, "service.refund.RefundInstructions", attribute.String("paymentID", args.PaymentID))
+	defer span.End()
+	if err := args.Validate(); err != nil {
+		return nil, err
+	}
+	rf, err := r.store.Refund(ctx, args)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, errors.WithMessagef(err, "failed to get refund for paymentID '%s'", args.PaymentID)
+	}
+	typ, script, err := refundDestination(r.deployCfg.Network, rf.RefundTo)
+	if err != nil {
+		// destinations are validated when the payment is received, so this shouldn't happen.
+		return nil, errors.Wrapf(err, "invalid refund destination stored for paymentID '%s'", args.PaymentID)
+	}
+	instructions := &server.RefundInstructions{
+		PaymentID:     rf.PaymentID,
+		TxID:          rf.TxID,
+		Type:          typ,
+		LockingScript: script,
+		Memo:          fmt.Sprintf("refund for payment %s", rf.PaymentID),
+		CreatedAt:     rf.CreatedAt,
+	}
+	if typ == server.RefundTypePaymail {
+		instructions.Paymail = rf.RefundTo
+	}
+	return instructions, nil
+}
+
+// refundDestination returns the type of a refundTo value supplied with a payment, either
+// a paymail or a locking script. Addresses must be for the network we serve and are
+// converted to a P2PKH locking script.
+func refundDestination(network, refundTo string) (string, *bscript.Script, error) {
+	if strings.Contains(refundTo, "@") {
+		return server.RefundTypePaymail, nil, nil
+	}
+	if ok, _ := bscript.ValidateAddress(refundTo); ok {
+		if err := checkAddressNetwork(network, refundTo); err != nil {
+			return "", nil, err
+		}
+		s, err := bscript.NewP2PKHFromAddress(refundTo)
+		if err != nil {
+			return "", nil, errors.Errorf("'%s' is not a valid address", refundTo)
+		}
+		return server.RefundTypeScript, s, nil
+	}
+	s, err := bscript.NewFromHexString(refundTo)
+	if err != nil || len(*s) == 0 {
+		return "", nil, errors.Errorf("'%s' is not a paymail, address or hex locking script", refundTo)
+	}
+	return server.RefundTypeScript, s, nil
+}
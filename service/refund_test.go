@@ -0,0 +1,106 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/stretchr/testify/assert"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/config"
+	"github.com/bitcoin-sv/dpp-proxy/data/memory"
+	"github.com/bitcoin-sv/dpp-proxy/service"
+)
+
+func TestRefund_RefundInstructions(t *testing.T) {
+	created := time.Now().UTC().Truncate(time.Second)
+	p2pkh, _ := bscript.NewP2PKHFromAddress("mfWyW5fc9NUj75YAnFgoRLrjxgLDn2MMth")
+	opReturn, _ := bscript.NewFromHexString("006a")
+	tests := map[string]struct {
+		refundTo  string
+		createdAt time.Time
+		args      server.RefundArgs
+		exp       *server.RefundInstructions
+		expErr    error
+	}{
+		"paymail refund": {
+			refundTo: "me@paymail.com",
+			args:     server.RefundArgs{PaymentID: "abc123"},
+			exp: &server.RefundInstructions{
+				PaymentID: "abc123",
+				TxID:      "txid123",
+				Type:      server.RefundTypePaymail,
+				Paymail:   "me@paymail.com",
+				Memo:      "refund for payment abc123",
+				CreatedAt: created,
+			},
+		},
+		"address refund is converted to a script": {
+			refundTo: "mfWyW5fc9NUj75YAnFgoRLrjxgLDn2MMth",
+			args:     server.RefundArgs{PaymentID: "abc123"},
+			exp: &server.RefundInstructions{
+				PaymentID:     "abc123",
+				TxID:          "txid123",
+				Type:          server.RefundTypeScript,
+				LockingScript: p2pkh,
+				Memo:          "refund for payment abc123",
+				CreatedAt:     created,
+			},
+		},
+		"script refund": {
+			refundTo: "006a",
+			args:     server.RefundArgs{PaymentID: "abc123"},
+			exp: &server.RefundInstructions{
+				PaymentID:     "abc123",
+				TxID:          "txid123",
+				Type:          server.RefundTypeScript,
+				LockingScript: opReturn,
+				Memo:          "refund for payment abc123",
+				CreatedAt:     created,
+			},
+		},
+		"unknown payment errors": {
+			refundTo: "me@paymail.com",
+			args:     server.RefundArgs{PaymentID: "def456"},
+			expErr: errors.New("failed to get refund for paymentID 'def456': Gone: refund destination expired or wasn't supplied, " +
+				"destinations are kept for 1h0m0s and not across restarts"),
+		},
+		"expired refund errors": {
+			refundTo:  "me@paymail.com",
+			createdAt: created.Add(-2 * time.Hour),
+			args:      server.RefundArgs{PaymentID: "abc123"},
+			expErr: errors.New("failed to get refund for paymentID 'abc123': Gone: refund destination expired or wasn't supplied, " +
+				"destinations are kept for 1h0m0s and not across restarts"),
+		},
+		"missing paymentID errors": {
+			refundTo: "me@paymail.com",
+			expErr:   errors.New("[paymentID: value cannot be empty]"),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			createdAt := test.createdAt
+			if createdAt.IsZero() {
+				createdAt = created
+			}
+			store := memory.NewRefunds(time.Hour)
+			assert.NoError(t, store.RefundCreate(context.Background(), server.Refund{
+				PaymentID: "abc123",
+				TxID:      "txid123",
+				RefundTo:  test.refundTo,
+				CreatedAt: createdAt,
+			}))
+			svc := service.NewRefund(store, &config.Deployment{Network: config.NetworkTestnet})
+			resp, err := svc.RefundInstructions(context.Background(), test.args)
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.exp, resp)
+		})
+	}
+}
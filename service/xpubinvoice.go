@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/tracing"
+)
+
+type xpubInvoice struct {
+	store server.XPubInvoiceReaderWriter
+}
+
+// NewXPubInvoice will setup and return a new xpub invoice service, creating invoices paid
+// to outputs derived from the merchant's extended public key.
+func NewXPubInvoice(store server.XPubInvoiceReaderWriter) *xpubInvoice {
+	return &xpubInvoice{
+		store: store,
+	}
+}
+
+// XPubInvoiceCreate will validate and create an invoice paid to a newly derived output.
+func (x *xpubInvoice) XPubInvoiceCreate(ctx context.Context, req server.XPubInvoiceCreate) (*server.XPubInvoice, error) {
+	ctx, span := tracing.StartSpan(ctx, "service.xpubInvoice.XPubInvoiceCreate")
+	defer span.End()
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	inv, err := x.store.XPubInvoiceCreate(ctx, req)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, errors.WithMessage(err, "failed to create xpub invoice")
+	}
+	return inv, nil
+}
+
+// XPubInvoice will return an invoice, including the txid it was paid with once paid.
+func (x *xpubInvoice) XPubInvoice(ctx context.Context, args server.XPubInvoiceArgs) (*server.XPubInvoice, error) {
+	ctx, span := tracing.StartSpan(ctx, "service.xpubInvoice.XPubInvoice", attribute.String("paymentID", args.PaymentID))
+	defer span.End()
+	if err := args.Validate(); err != nil {
+		return nil, err
+	}
+	inv, err := x.store.XPubInvoice(ctx, args)
+	if err != nil {
+		tracing.RecordError(span, err)
+		return nil, errors.WithMessagef(err, "failed to get xpub invoice for paymentID '%s'", args.PaymentID)
+	}
+	return inv, nil
+}
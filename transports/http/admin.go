@@ -0,0 +1,38 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/bitcoin-sv/dpp-proxy/config"
+)
+
+// admin exposes endpoints used to operate the proxy.
+type admin struct {
+	cfg func() *config.Config
+}
+
+// NewAdmin will setup and return a new admin http handler, cfg should
+// return the config currently in effect.
+func NewAdmin(cfg func() *config.Config) *admin {
+	return &admin{cfg: cfg}
+}
+
+// RegisterRoutes will setup all admin routes with the supplied echo group.
+func (a *admin) RegisterRoutes(g *echo.Group) {
+	g.GET(RouteV1AdminConfig, a.config)
+}
+
+// config godoc
+// @Summary Effective config
+// @Description Returns the config currently in effect, including any hot reloaded settings, with secrets redacted.
+// @Tags Admin
+// @Produce json
+// @Security BearerToken
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} server.Problem "returned if the admin bearer token is missing or invalid"
+// @Router /api/v1/admin/config [GET].
+func (a *admin) config(c echo.Context) error {
+	return c.JSON(http.StatusOK, a.cfg().Redacted())
+}
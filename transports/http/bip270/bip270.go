@@ -0,0 +1,363 @@
+// Package bip270 encodes and decodes the protobuf messages sent by BIP-270 wallets that
+// predate the json dpp messages, converting them to and from their go-dpp equivalents.
+//
+// The messages follow the BIP-70 definitions used by these wallets:
+//
+//	message Output {
+//	    optional uint64 amount = 1 [default = 0];
+//	    required bytes script = 2;
+//	}
+//	message PaymentDetails {
+//	    optional string network = 1 [default = "main"];
+//	    repeated Output outputs = 2;
+//	    required uint64 time = 3;
+//	    optional uint64 expires = 4;
+//	    optional string memo = 5;
+//	    optional string payment_url = 6;
+//	    optional bytes merchant_data = 7;
+//	}
+//	message PaymentRequest {
+//	    optional uint32 payment_details_version = 1 [default = 1];
+//	    optional string pki_type = 2 [default = "none"];
+//	    optional bytes pki_data = 3;
+//	    required bytes serialized_payment_details = 4;
+//	    optional bytes signature = 5;
+//	}
+//	message Payment {
+//	    optional bytes merchant_data = 1;
+//	    repeated bytes transactions = 2;
+//	    repeated Output refund_to = 3;
+//	    optional string memo = 4;
+//	}
+//	message PaymentACK {
+//	    required Payment payment = 1;
+//	    optional string memo = 2;
+//	}
+package bip270
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/libsv/go-dpp"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/bitcoin-sv/dpp-proxy/config"
+)
+
+// Content types used by protobuf BIP-270 wallets.
+const (
+	MIMEPaymentRequest = "application/bitcoinsv-paymentrequest"
+	MIMEPayment        = "application/bitcoinsv-payment"
+	MIMEPaymentACK     = "application/bitcoinsv-paymentack"
+)
+
+const (
+	networkMain = "main"
+	networkTest = "test"
+)
+
+// EncodePaymentRequest converts a dpp PaymentRequest to a protobuf PaymentRequest.
+//
+// MerchantData is json encoded so it can be returned unchanged by the wallet in the Payment.
+func EncodePaymentRequest(pr *dpp.PaymentRequest) ([]byte, error) {
+	var details []byte
+	details = appendString(details, 1, encodeNetwork(pr.Network))
+	for _, o := range pr.Destinations.Outputs {
+		details = appendMessage(details, 2, encodeOutput(o))
+	}
+	details = appendVarint(details, 3, unix(pr.CreationTimestamp))
+	if !pr.ExpirationTimestamp.IsZero() {
+		details = appendVarint(details, 4, unix(pr.ExpirationTimestamp))
+	}
+	details = appendString(details, 5, pr.Memo)
+	details = appendString(details, 6, pr.PaymentURL)
+	if pr.MerchantData != nil {
+		md, err := json.Marshal(pr.MerchantData)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to encode merchant data")
+		}
+		details = appendBytes(details, 7, md)
+	}
+
+	var b []byte
+	b = appendVarint(b, 1, 1)
+	b = appendString(b, 2, "none")
+	return appendMessage(b, 4, details), nil
+}
+
+// DecodePaymentRequest converts a protobuf PaymentRequest to a dpp PaymentRequest.
+func DecodePaymentRequest(b []byte) (*dpp.PaymentRequest, error) {
+	ff, err := fields(b)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode payment request")
+	}
+	var details []byte
+	for _, f := range ff {
+		if f.num == 4 {
+			details = f.b
+		}
+	}
+	if details == nil {
+		return nil, errors.New("payment request is missing serialized_payment_details")
+	}
+	if ff, err = fields(details); err != nil {
+		return nil, errors.Wrap(err, "failed to decode payment details")
+	}
+	pr := &dpp.PaymentRequest{Network: decodeNetwork(networkMain)}
+	for _, f := range ff {
+		switch f.num {
+		case 1:
+			pr.Network = decodeNetwork(string(f.b))
+		case 2:
+			o, err := decodeOutput(f.b)
+			if err != nil {
+				return nil, err
+			}
+			pr.Destinations.Outputs = append(pr.Destinations.Outputs, *o)
+		case 3:
+			pr.CreationTimestamp = time.Unix(int64(f.u), 0).UTC()
+		case 4:
+			pr.ExpirationTimestamp = time.Unix(int64(f.u), 0).UTC()
+		case 5:
+			pr.Memo = string(f.b)
+		case 6:
+			pr.PaymentURL = string(f.b)
+		case 7:
+			var m dpp.Merchant
+			if err := json.Unmarshal(f.b, &m); err != nil {
+				return nil, errors.Wrap(err, "failed to decode merchant data")
+			}
+			pr.MerchantData = &m
+		}
+	}
+	return pr, nil
+}
+
+// EncodePayment converts a dpp Payment to a protobuf Payment.
+func EncodePayment(p *dpp.Payment) ([]byte, error) {
+	md, err := json.Marshal(p.MerchantData)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode merchant data")
+	}
+	b := appendBytes(nil, 1, md)
+	if p.RawTx != nil {
+		tx, err := hex.DecodeString(*p.RawTx)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode rawTx")
+		}
+		b = appendMessage(b, 2, tx)
+	}
+	// only script refunds can be encoded, paymails and addresses are dropped.
+	if p.RefundTo != nil {
+		if script, err := bscript.NewFromHexString(*p.RefundTo); err == nil && len(*script) > 0 {
+			b = appendMessage(b, 3, encodeOutput(dpp.Output{LockingScript: script}))
+		}
+	}
+	return appendString(b, 4, p.Memo), nil
+}
+
+// DecodePayment converts a protobuf Payment to a dpp Payment.
+//
+// Only a single transaction and a single refund_to output are supported, the refund_to
+// script is set as the hex encoded refundTo of the payment and any amount is ignored.
+func DecodePayment(b []byte) (*dpp.Payment, error) {
+	ff, err := fields(b)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode payment")
+	}
+	p := &dpp.Payment{}
+	var txs, refunds int
+	for _, f := range ff {
+		switch f.num {
+		case 1:
+			if len(f.b) == 0 {
+				continue
+			}
+			if err := json.Unmarshal(f.b, &p.MerchantData); err != nil {
+				return nil, errors.Wrap(err, "merchant_data must be the value supplied in the payment request")
+			}
+		case 2:
+			txs++
+			tx := hex.EncodeToString(f.b)
+			p.RawTx = &tx
+		case 3:
+			refunds++
+			o, err := decodeOutput(f.b)
+			if err != nil {
+				return nil, err
+			}
+			if o.LockingScript != nil {
+				refundTo := o.LockingScript.String()
+				p.RefundTo = &refundTo
+			}
+		case 4:
+			p.Memo = string(f.b)
+		}
+	}
+	if txs > 1 {
+		return nil, errors.Errorf("payment contains %d transactions, only 1 is supported", txs)
+	}
+	if refunds > 1 {
+		return nil, errors.Errorf("payment contains %d refund_to outputs, only 1 is supported", refunds)
+	}
+	return p, nil
+}
+
+// EncodePaymentACK converts a dpp PaymentACK, and the Payment it is acknowledging, to a protobuf PaymentACK.
+//
+// The protobuf PaymentACK has no error field, a failed payment is indicated by the http status code
+// with the reason in the memo.
+func EncodePaymentACK(p *dpp.Payment, ack *dpp.PaymentACK) ([]byte, error) {
+	pb, err := EncodePayment(p)
+	if err != nil {
+		return nil, err
+	}
+	b := appendMessage(nil, 1, pb)
+	return appendString(b, 2, ack.Memo), nil
+}
+
+// DecodePaymentACK converts a protobuf PaymentACK to a dpp Payment and PaymentACK.
+func DecodePaymentACK(b []byte) (*dpp.Payment, *dpp.PaymentACK, error) {
+	ff, err := fields(b)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to decode payment ack")
+	}
+	p := &dpp.Payment{}
+	ack := &dpp.PaymentACK{}
+	for _, f := range ff {
+		switch f.num {
+		case 1:
+			if p, err = DecodePayment(f.b); err != nil {
+				return nil, nil, err
+			}
+		case 2:
+			ack.Memo = string(f.b)
+		}
+	}
+	return p, ack, nil
+}
+
+func encodeOutput(o dpp.Output) []byte {
+	b := appendVarint(nil, 1, o.Amount)
+	var script []byte
+	if o.LockingScript != nil {
+		script = *o.LockingScript
+	}
+	return appendMessage(b, 2, script)
+}
+
+func decodeOutput(b []byte) (*dpp.Output, error) {
+	ff, err := fields(b)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode output")
+	}
+	o := &dpp.Output{}
+	for _, f := range ff {
+		switch f.num {
+		case 1:
+			o.Amount = f.u
+		case 2:
+			o.LockingScript = bscript.NewFromBytes(f.b)
+		}
+	}
+	return o, nil
+}
+
+// encodeNetwork converts a dpp network to the bip70 names wallets expect.
+func encodeNetwork(network string) string {
+	switch network {
+	case config.NetworkMainnet:
+		return networkMain
+	case config.NetworkTestnet:
+		return networkTest
+	}
+	return network
+}
+
+func decodeNetwork(network string) string {
+	switch network {
+	case networkMain:
+		return config.NetworkMainnet
+	case networkTest:
+		return config.NetworkTestnet
+	}
+	return network
+}
+
+func unix(t time.Time) uint64 {
+	if t.IsZero() {
+		return 0
+	}
+	return uint64(t.Unix())
+}
+
+// field is a decoded protobuf field, varints are stored in u and length delimited values in b.
+type field struct {
+	num protowire.Number
+	u   uint64
+	b   []byte
+}
+
+// fields decodes the top level fields of a protobuf message, fields of other types are skipped.
+func fields(b []byte) ([]field, error) {
+	var ff []field
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		b = b[n:]
+		f := field{num: num}
+		switch typ {
+		case protowire.VarintType:
+			f.u, n = protowire.ConsumeVarint(b)
+		case protowire.BytesType:
+			f.b, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			b = b[n:]
+			continue
+		}
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		b = b[n:]
+		ff = append(ff, f)
+	}
+	return ff, nil
+}
+
+func appendVarint(b []byte, num protowire.Number, v uint64) []byte {
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+// appendString appends an optional string field, empty values are omitted.
+func appendString(b []byte, num protowire.Number, s string) []byte {
+	if s == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+// appendBytes appends an optional bytes field, empty values are omitted.
+func appendBytes(b []byte, num protowire.Number, v []byte) []byte {
+	if len(v) == 0 {
+		return b
+	}
+	return appendMessage(b, num, v)
+}
+
+// appendMessage appends a length delimited field, it is always written.
+func appendMessage(b []byte, num protowire.Number, v []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
@@ -0,0 +1,120 @@
+package bip270_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/libsv/go-dpp"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bitcoin-sv/dpp-proxy/transports/http/bip270"
+)
+
+func TestPaymentRequest_RoundTrip(t *testing.T) {
+	script, err := bscript.NewFromHexString("76a91455b61be43392125d127f1780fb038437cd67ef9c88ac")
+	assert.NoError(t, err)
+	created := time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		req *dpp.PaymentRequest
+		exp *dpp.PaymentRequest
+	}{
+		"full payment request": {
+			req: &dpp.PaymentRequest{
+				Network: "mainnet",
+				Destinations: dpp.PaymentDestinations{
+					Outputs: []dpp.Output{{Amount: 1000, LockingScript: script}, {Amount: 5, LockingScript: script}},
+				},
+				CreationTimestamp:   created,
+				ExpirationTimestamp: created.Add(time.Hour),
+				PaymentURL:          "http://dpp:8445/api/v1/payment/abc123",
+				Memo:                "invoice abc123",
+				MerchantData: &dpp.Merchant{
+					Name:         "merchant",
+					ExtendedData: map[string]interface{}{"paymentReference": "abc123"},
+				},
+			},
+			exp: &dpp.PaymentRequest{
+				Network: "mainnet",
+				Destinations: dpp.PaymentDestinations{
+					Outputs: []dpp.Output{{Amount: 1000, LockingScript: script}, {Amount: 5, LockingScript: script}},
+				},
+				CreationTimestamp:   created,
+				ExpirationTimestamp: created.Add(time.Hour),
+				PaymentURL:          "http://dpp:8445/api/v1/payment/abc123",
+				Memo:                "invoice abc123",
+				MerchantData: &dpp.Merchant{
+					Name:         "merchant",
+					ExtendedData: map[string]interface{}{"paymentReference": "abc123"},
+				},
+			},
+		},
+		"regtest network is kept": {
+			req: &dpp.PaymentRequest{
+				Network:           "regtest",
+				CreationTimestamp: created,
+			},
+			exp: &dpp.PaymentRequest{
+				Network:           "regtest",
+				CreationTimestamp: created,
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			bb, err := bip270.EncodePaymentRequest(test.req)
+			assert.NoError(t, err)
+			pr, err := bip270.DecodePaymentRequest(bb)
+			assert.NoError(t, err)
+			assert.Equal(t, test.exp, pr)
+		})
+	}
+}
+
+func TestDecodePayment(t *testing.T) {
+	tests := map[string]struct {
+		payment []byte
+		exp     *dpp.Payment
+		expErr  string
+	}{
+		"payment is decoded": {
+			// merchant_data {}, transactions 0x0100, memo "hi".
+			payment: []byte{0x0a, 0x02, '{', '}', 0x12, 0x02, 0x01, 0x00, 0x22, 0x02, 'h', 'i'},
+			exp: &dpp.Payment{
+				RawTx: func() *string { s := "0100"; return &s }(),
+				Memo:  "hi",
+			},
+		},
+		"refund_to script is decoded": {
+			// transactions 0x01, refund_to {amount 0, script OP_RETURN}.
+			payment: []byte{0x12, 0x01, 0x01, 0x1a, 0x05, 0x08, 0x00, 0x12, 0x01, 0x6a},
+			exp: &dpp.Payment{
+				RawTx:    func() *string { s := "01"; return &s }(),
+				RefundTo: func() *string { s := "6a"; return &s }(),
+			},
+		},
+		"multiple refund_to outputs are rejected": {
+			payment: []byte{0x1a, 0x03, 0x12, 0x01, 0x6a, 0x1a, 0x03, 0x12, 0x01, 0x6a},
+			expErr:  "payment contains 2 refund_to outputs, only 1 is supported",
+		},
+		"multiple transactions are rejected": {
+			payment: []byte{0x12, 0x01, 0x01, 0x12, 0x01, 0x02},
+			expErr:  "payment contains 2 transactions, only 1 is supported",
+		},
+		"merchant data that isn't json is rejected": {
+			payment: []byte{0x0a, 0x02, 'h', 'i'},
+			expErr:  "merchant_data must be the value supplied in the payment request: invalid character 'h' looking for beginning of value",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, err := bip270.DecodePayment(test.payment)
+			if test.expErr != "" {
+				assert.EqualError(t, err, test.expErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.exp, p)
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+)
+
+// invoiceHandler lets merchants register invoices.
+type invoiceHandler struct {
+	svc server.InvoiceService
+}
+
+// NewInvoiceHandler will create and return a new InvoiceHandler, routes should be
+// registered with a group authenticating the merchant.
+func NewInvoiceHandler(svc server.InvoiceService) *invoiceHandler {
+	return &invoiceHandler{
+		svc: svc,
+	}
+}
+
+// RegisterRoutes will setup all routes with an echo group.
+func (h *invoiceHandler) RegisterRoutes(g *echo.Group) {
+	g.POST(RouteV1Invoices, h.createInvoice)
+	g.GET(RouteV1Invoice, h.invoice)
+	g.DELETE(RouteV1Invoice, h.deleteInvoice)
+}
+
+// createInvoice godoc
+// @Summary Register an invoice
+// @Description Registers the payment request of an invoice, it is served to customers and payments for it are queued while the merchant wallet is offline.
+// @Tags Merchant
+// @Accept json
+// @Produce json
+// @Security BearerToken
+// @Param body body server.InvoiceCreate true "the invoice to register"
+// @Success 201 {object} server.Invoice
+// @Failure 400 {object} server.Problem "returned if the invoice is invalid"
+// @Failure 401 {object} server.Problem "returned if the merchant bearer token is missing or invalid"
+// @Failure 409 {object} server.Problem "returned if an invoice is already registered with the paymentId"
+// @Failure 422 {object} server.Problem "returned if the payment request is for another network"
+// @Router /api/v1/invoice [POST].
+func (h *invoiceHandler) createInvoice(e echo.Context) error {
+	var req server.InvoiceCreate
+	if err := e.Bind(&req); err != nil {
+		return errors.Wrap(err, "failed to bind request")
+	}
+	resp, err := h.svc.InvoiceCreate(e.Request().Context(), req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return e.JSON(http.StatusCreated, resp)
+}
+
+// invoice godoc
+// @Summary A registered invoice
+// @Description Returns an invoice registered by the merchant.
+// @Tags Merchant
+// @Produce json
+// @Security BearerToken
+// @Param paymentID path string true "Payment ID"
+// @Success 200 {object} server.Invoice
+// @Failure 400 {object} server.Problem "returned if the user input is invalid"
+// @Failure 401 {object} server.Problem "returned if the merchant bearer token is missing or invalid"
+// @Failure 404 {object} server.Problem "returned if the invoice isn't registered"
+// @Router /api/v1/invoice/{paymentID} [GET].
+func (h *invoiceHandler) invoice(e echo.Context) error {
+	var args server.InvoiceArgs
+	if err := e.Bind(&args); err != nil {
+		return errors.Wrap(err, "failed to bind request")
+	}
+	resp, err := h.svc.Invoice(e.Request().Context(), args)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return e.JSON(http.StatusOK, resp)
+}
+
+// deleteInvoice godoc
+// @Summary Delete a registered invoice
+// @Description Removes a registered invoice, payment requests for it are then read from the merchant wallet.
+// @Tags Merchant
+// @Security BearerToken
+// @Param paymentID path string true "Payment ID"
+// @Success 204
+// @Failure 400 {object} server.Problem "returned if the user input is invalid"
+// @Failure 401 {object} server.Problem "returned if the merchant bearer token is missing or invalid"
+// @Failure 404 {object} server.Problem "returned if the invoice isn't registered"
+// @Router /api/v1/invoice/{paymentID} [DELETE].
+func (h *invoiceHandler) deleteInvoice(e echo.Context) error {
+	var args server.InvoiceArgs
+	if err := e.Bind(&args); err != nil {
+		return errors.Wrap(err, "failed to bind request")
+	}
+	if err := h.svc.InvoiceDelete(e.Request().Context(), args); err != nil {
+		return errors.WithStack(err)
+	}
+	return e.NoContent(http.StatusNoContent)
+}
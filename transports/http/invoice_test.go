@@ -0,0 +1,60 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/mocks"
+)
+
+func TestInvoiceHandler_CreateInvoice(t *testing.T) {
+	e := echo.New()
+	svc := &mocks.InvoiceServiceMock{
+		InvoiceCreateFunc: func(ctx context.Context, req server.InvoiceCreate) (*server.Invoice, error) {
+			return &server.Invoice{PaymentID: req.PaymentID, PaymentRequest: req.PaymentRequest}, nil
+		},
+	}
+	h := NewInvoiceHandler(svc)
+
+	body := `{"paymentId":"abc123","paymentRequest":{"memo":"invoice abc123","destinations":{"outputs":[{"amount":1000,"script":"76a91455b61be43392125d127f1780fb038437cd67ef9c88ac"}]}}}`
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+
+	assert.NoError(t, h.createInvoice(ctx))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	call := svc.InvoiceCreateCalls()[0].Req
+	assert.Equal(t, "abc123", call.PaymentID)
+	assert.Equal(t, "invoice abc123", call.PaymentRequest.Memo)
+	assert.Equal(t, uint64(1000), call.PaymentRequest.Destinations.Outputs[0].Amount)
+	assert.Contains(t, rec.Body.String(), `"paymentId":"abc123"`)
+}
+
+func TestInvoiceHandler_DeleteInvoice(t *testing.T) {
+	e := echo.New()
+	svc := &mocks.InvoiceServiceMock{
+		InvoiceDeleteFunc: func(ctx context.Context, args server.InvoiceArgs) error {
+			return nil
+		},
+	}
+	h := NewInvoiceHandler(svc)
+
+	req := httptest.NewRequest(http.MethodDelete, "/", nil)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/api/v1/invoice/:paymentID")
+	ctx.SetParamNames("paymentID")
+	ctx.SetParamValues("abc123")
+
+	assert.NoError(t, h.deleteInvoice(ctx))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, server.InvoiceArgs{PaymentID: "abc123"}, svc.InvoiceDeleteCalls()[0].Args)
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/theflyingcodr/lathos/errs"
+)
+
+// BearerToken will reject any request that doesn't supply the token
+// in the Authorization header as a bearer token.
+func BearerToken(token string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			auth := c.Request().Header.Get(echo.HeaderAuthorization)
+			if !strings.HasPrefix(auth, "Bearer ") {
+				return errs.NewErrNotAuthenticated("401", "bearer token required")
+			}
+			if subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(auth, "Bearer ")), []byte(token)) != 1 {
+				return errs.NewErrNotAuthenticated("401", "invalid bearer token")
+			}
+			return next(c)
+		}
+	}
+}
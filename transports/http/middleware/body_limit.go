@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"fmt"
+	"io"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/labstack/echo/v4"
+)
+
+// BodyLimit rejects requests with a body larger than the limit for their route, limits
+// are keyed by route path, for example '/api/v1/payment/:paymentID', other routes are
+// limited to def.
+func BodyLimit(def int64, limits map[string]int64) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			limit, ok := limits[c.Path()]
+			if !ok {
+				limit = def
+			}
+			req := c.Request()
+			if req.ContentLength > limit {
+				return tooLarge(limit)
+			}
+			req.Body = &limitedReader{ReadCloser: req.Body, remaining: limit, limit: limit}
+			return next(c)
+		}
+	}
+}
+
+// limitedReader returns a TooLarge error once more than limit bytes are read, the
+// content length can't be relied on as it isn't set for chunked requests.
+type limitedReader struct {
+	io.ReadCloser
+	remaining int64
+	limit     int64
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	if r.remaining < 0 {
+		return 0, tooLarge(r.limit)
+	}
+	// read one byte more than remains so bodies of exactly the limit are allowed.
+	if int64(len(p)) > r.remaining+1 {
+		p = p[:r.remaining+1]
+	}
+	n, err := r.ReadCloser.Read(p)
+	r.remaining -= int64(n)
+	if r.remaining < 0 {
+		return n + int(r.remaining), tooLarge(r.limit)
+	}
+	return n, err
+}
+
+func tooLarge(limit int64) error {
+	return server.NewErrTooLarge("413", fmt.Sprintf("the request body is larger than the limit of %d bytes", limit))
+}
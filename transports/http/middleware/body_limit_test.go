@@ -0,0 +1,84 @@
+package middleware_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bitcoin-sv/dpp-proxy/log"
+	"github.com/bitcoin-sv/dpp-proxy/transports/http/middleware"
+)
+
+func TestBodyLimit(t *testing.T) {
+	tests := map[string]struct {
+		path          string
+		body          string
+		chunked       bool
+		expStatusCode int
+		expBody       string
+	}{
+		"body within the default limit is read": {
+			path:          "/small",
+			body:          "0123456789",
+			expStatusCode: http.StatusOK,
+			expBody:       "0123456789",
+		},
+		"body over the default limit is rejected": {
+			path:          "/small",
+			body:          "0123456789a",
+			expStatusCode: http.StatusRequestEntityTooLarge,
+			expBody:       "the request body is larger than the limit of 10 bytes",
+		},
+		"chunked body over the default limit is rejected": {
+			path:          "/small",
+			body:          "0123456789a",
+			chunked:       true,
+			expStatusCode: http.StatusRequestEntityTooLarge,
+			expBody:       "the request body is larger than the limit of 10 bytes",
+		},
+		"route limit is used for the route": {
+			path:          "/large",
+			body:          strings.Repeat("a", 20),
+			chunked:       true,
+			expStatusCode: http.StatusOK,
+			expBody:       strings.Repeat("a", 20),
+		},
+		"body over the route limit is rejected": {
+			path:          "/large",
+			body:          strings.Repeat("a", 21),
+			expStatusCode: http.StatusRequestEntityTooLarge,
+			expBody:       "the request body is larger than the limit of 20 bytes",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			e := echo.New()
+			e.HTTPErrorHandler = middleware.ErrorHandler(log.Noop{})
+			e.Use(middleware.BodyLimit(10, map[string]int64{"/large": 20}))
+			handler := func(c echo.Context) error {
+				bb, err := io.ReadAll(c.Request().Body)
+				if err != nil {
+					return err
+				}
+				return c.String(http.StatusOK, string(bb))
+			}
+			e.POST("/small", handler)
+			e.POST("/large", handler)
+
+			req := httptest.NewRequest(http.MethodPost, test.path, strings.NewReader(test.body))
+			if test.chunked {
+				req.ContentLength = -1
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, test.expStatusCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), test.expBody)
+		})
+	}
+}
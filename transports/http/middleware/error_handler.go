@@ -1,82 +1,57 @@
 package middleware
 
 import (
+	"encoding/json"
 	"errors"
 	"net/http"
+	"strconv"
 
 	server "github.com/bitcoin-sv/dpp-proxy"
 	"github.com/bitcoin-sv/dpp-proxy/log"
 	"github.com/labstack/echo/v4"
-	validator "github.com/theflyingcodr/govalidator"
 	"github.com/theflyingcodr/lathos"
 	"github.com/theflyingcodr/lathos/errs"
 )
 
-// ErrorHandler we can flesh this out.
+// ErrorHandler returns errors to clients as RFC 7807 problems, internal errors
+// are logged and returned without their detail.
 func ErrorHandler(l log.Logger) echo.HTTPErrorHandler {
 	return func(err error, c echo.Context) {
-		if err == nil {
-			return
-		}
-		var valErr validator.ErrValidation
-		if errors.As(err, &valErr) {
-			resp := map[string]interface{}{
-				"errors": valErr,
+		if err == nil || c.Response().Committed {
+			return
+		}
+		// echo errors, such as unknown routes, are mapped by status unless they wrap a client
+		// error, client errors without a kind keep their status and are returned as bad requests.
+		var httpErr *echo.HTTPError
+		status := 0
+		if errors.As(err, &httpErr) && !lathos.IsClientError(err) {
+			if sErr := server.StatusError(httpErr.Code, strconv.Itoa(httpErr.Code), http.StatusText(httpErr.Code), 0); sErr != nil {
+				err = sErr
+			} else if httpErr.Code < http.StatusInternalServerError {
+				err = server.NewErrBadRequest(strconv.Itoa(httpErr.Code), http.StatusText(httpErr.Code))
+				status = httpErr.Code
 			}
-			_ = c.JSON(http.StatusBadRequest, resp)
-			return
 		}
 
-		if errors.Is(err, echo.ErrNotFound) {
-			err = errs.NewErrNotFound("404", "Not found")
+		p := server.NewProblem(err)
+		if status != 0 {
+			p.Status = status
+			p.Title = http.StatusText(status)
 		}
-
-		var cErr server.ClientError
-		if errors.As(err, &cErr) {
-			_ = c.JSON(http.StatusInternalServerError, cErr)
-			return
-		}
-
-		// Internal error, log it to a system and return small detail
-		if !lathos.IsClientError(err) {
+		p.Instance = c.Request().URL.Path
+		if p.Code == server.ErrCodeInternal {
 			internalErr := errs.NewErrInternal(err, "500")
 			l.Error(internalErr, "internal error")
-
-			_ = c.JSON(http.StatusInternalServerError, server.ClientError{
-				ID:      internalErr.ID(),
-				Code:    "500",
-				Title:   "Internal Server Error",
-				Message: internalErr.Error(),
-			})
-			return
+			p.ID = internalErr.ID()
 		}
-		var clientErr lathos.ClientError
-		errors.As(err, &clientErr)
-		resp := server.ClientError{
-			ID:      clientErr.ID(),
-			Code:    clientErr.Code(),
-			Title:   clientErr.Title(),
-			Message: clientErr.Detail(),
-		}
-		if lathos.IsNotFound(err) {
-			_ = c.JSON(http.StatusNotFound, resp)
-			return
-		}
-		if lathos.IsDuplicate(err) {
-			_ = c.JSON(http.StatusConflict, resp)
-			return
-		}
-		if lathos.IsNotAuthenticated(err) {
-			_ = c.JSON(http.StatusUnauthorized, resp)
-			return
-		}
-		if lathos.IsNotAuthorised(err) {
-			_ = c.JSON(http.StatusForbidden, resp)
-			return
+		if p.RetryAfter > 0 {
+			c.Response().Header().Set("Retry-After", strconv.Itoa(p.RetryAfter))
 		}
-		if lathos.IsCannotProcess(err) {
-			_ = c.JSON(http.StatusUnprocessableEntity, resp)
+		bb, err := json.Marshal(p)
+		if err != nil {
+			l.Error(err, "failed to encode problem")
 			return
 		}
+		_ = c.Blob(p.Status, server.ProblemContentType, bb)
 	}
 }
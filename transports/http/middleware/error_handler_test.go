@@ -6,7 +6,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	server "github.com/bitcoin-sv/dpp-proxy"
 	"github.com/bitcoin-sv/dpp-proxy/log"
 	"github.com/bitcoin-sv/dpp-proxy/transports/http/middleware"
 	"github.com/labstack/echo/v4"
@@ -20,77 +22,188 @@ func TestErrorHandler(t *testing.T) {
 		err           error
 		expResp       interface{}
 		expStatusCode int
+		expRetryAfter string
 	}{
-		"client error 400": {
+		"validation error 400": {
 			err: validator.ErrValidation{
 				"paymentID": []string{"no style", "no class"},
 			},
 			expResp: map[string]interface{}{
+				"type":     "urn:dpp-proxy:error:bad_request",
+				"title":    "Bad Request",
+				"status":   float64(400),
+				"detail":   "the request is invalid",
+				"instance": "/api/v1/payment/abc",
+				"code":     "bad_request",
 				"errors": map[string]interface{}{
 					"paymentID": []interface{}{"no style", "no class"},
 				},
 			},
 			expStatusCode: http.StatusBadRequest,
 		},
-		"internal error 500": {
+		"bad request 400": {
+			err: server.NewErrBadRequest("my 400", "no body"),
+			expResp: map[string]interface{}{
+				"type":     "urn:dpp-proxy:error:bad_request",
+				"title":    "Bad Request",
+				"status":   float64(400),
+				"detail":   "no body",
+				"instance": "/api/v1/payment/abc",
+				"code":     "bad_request",
+			},
+			expStatusCode: http.StatusBadRequest,
+		},
+		"internal error 500 doesn't leak the error": {
 			err: errors.New("ahnah"),
 			expResp: map[string]interface{}{
-				"code":    "500",
-				"title":   "Internal Server Error",
-				"message": "ahnah",
+				"type":     "urn:dpp-proxy:error:internal",
+				"title":    "Internal Server Error",
+				"status":   float64(500),
+				"detail":   "an unexpected error occurred",
+				"instance": "/api/v1/payment/abc",
+				"code":     "internal",
 			},
 			expStatusCode: http.StatusInternalServerError,
 		},
 		"not found 404": {
 			err: errs.NewErrNotFound("my 404", "not found"),
 			expResp: map[string]interface{}{
-				"code":    "my 404",
-				"title":   "Not found",
-				"message": "not found",
+				"type":     "urn:dpp-proxy:error:not_found",
+				"title":    "Not Found",
+				"status":   float64(404),
+				"detail":   "not found",
+				"instance": "/api/v1/payment/abc",
+				"code":     "not_found",
+			},
+			expStatusCode: http.StatusNotFound,
+		},
+		"echo not found 404": {
+			err: echo.ErrNotFound,
+			expResp: map[string]interface{}{
+				"type":     "urn:dpp-proxy:error:not_found",
+				"title":    "Not Found",
+				"status":   float64(404),
+				"detail":   "Not Found",
+				"instance": "/api/v1/payment/abc",
+				"code":     "not_found",
 			},
 			expStatusCode: http.StatusNotFound,
 		},
+		"echo method not allowed 405": {
+			err: echo.ErrMethodNotAllowed,
+			expResp: map[string]interface{}{
+				"type":     "urn:dpp-proxy:error:bad_request",
+				"title":    "Method Not Allowed",
+				"status":   float64(405),
+				"detail":   "Method Not Allowed",
+				"instance": "/api/v1/payment/abc",
+				"code":     "bad_request",
+			},
+			expStatusCode: http.StatusMethodNotAllowed,
+		},
 		"conflict 409": {
 			err: errs.NewErrDuplicate("my 409", "collision"),
 			expResp: map[string]interface{}{
-				"code":    "my 409",
-				"title":   "Item already exists",
-				"message": "collision",
+				"type":     "urn:dpp-proxy:error:conflict",
+				"title":    "Conflict",
+				"status":   float64(409),
+				"detail":   "collision",
+				"instance": "/api/v1/payment/abc",
+				"code":     "conflict",
 			},
 			expStatusCode: http.StatusConflict,
 		},
+		"expired 410": {
+			err: server.NewErrExpired("my 410", "long gone"),
+			expResp: map[string]interface{}{
+				"type":     "urn:dpp-proxy:error:expired",
+				"title":    "Gone",
+				"status":   float64(410),
+				"detail":   "long gone",
+				"instance": "/api/v1/payment/abc",
+				"code":     "expired",
+			},
+			expStatusCode: http.StatusGone,
+		},
 		"not auth'd 401": {
 			err: errs.NewErrNotAuthenticated("my 401", "will ya login"),
 			expResp: map[string]interface{}{
-				"code":    "my 401",
-				"title":   "Not authenticated",
-				"message": "will ya login",
+				"type":     "urn:dpp-proxy:error:not_authenticated",
+				"title":    "Unauthorized",
+				"status":   float64(401),
+				"detail":   "will ya login",
+				"instance": "/api/v1/payment/abc",
+				"code":     "not_authenticated",
 			},
 			expStatusCode: http.StatusUnauthorized,
 		},
 		"forbidden 403": {
 			err: errs.NewErrNotAuthorised("my 403", "lol nice try buddy"),
 			expResp: map[string]interface{}{
-				"code":    "my 403",
-				"title":   "Permission denied",
-				"message": "lol nice try buddy",
+				"type":     "urn:dpp-proxy:error:permission_denied",
+				"title":    "Forbidden",
+				"status":   float64(403),
+				"detail":   "lol nice try buddy",
+				"instance": "/api/v1/payment/abc",
+				"code":     "permission_denied",
 			},
 			expStatusCode: http.StatusForbidden,
 		},
 		"cannot process 422": {
 			err: errs.NewErrUnprocessable("my 422", "what did you even send?"),
 			expResp: map[string]interface{}{
-				"code":    "my 422",
-				"title":   "Unprocessable",
-				"message": "what did you even send?",
+				"type":     "urn:dpp-proxy:error:unprocessable",
+				"title":    "Unprocessable Entity",
+				"status":   float64(422),
+				"detail":   "what did you even send?",
+				"instance": "/api/v1/payment/abc",
+				"code":     "unprocessable",
 			},
 			expStatusCode: http.StatusUnprocessableEntity,
 		},
+		"rate limited 429": {
+			err: server.NewErrRateLimited("my 429", "slow down", 30*time.Second),
+			expResp: map[string]interface{}{
+				"type":       "urn:dpp-proxy:error:rate_limited",
+				"title":      "Too Many Requests",
+				"status":     float64(429),
+				"detail":     "slow down",
+				"instance":   "/api/v1/payment/abc",
+				"code":       "rate_limited",
+				"retryAfter": float64(30),
+			},
+			expStatusCode: http.StatusTooManyRequests,
+			expRetryAfter: "30",
+		},
+		"unavailable 503": {
+			err: errs.NewErrNotAvailable("my 503", "wallet is not connected"),
+			expResp: map[string]interface{}{
+				"type":     "urn:dpp-proxy:error:unavailable",
+				"title":    "Service Unavailable",
+				"status":   float64(503),
+				"detail":   "wallet is not connected",
+				"instance": "/api/v1/payment/abc",
+				"code":     "unavailable",
+			},
+			expStatusCode: http.StatusServiceUnavailable,
+		},
+		"unclassified wallet error 502": {
+			err: server.ClientError{Code: "W01", Title: "Wallet", Message: "wallet is sad"},
+			expResp: map[string]interface{}{
+				"type":     "urn:dpp-proxy:error:wallet_error",
+				"title":    "Bad Gateway",
+				"status":   float64(502),
+				"detail":   "wallet is sad",
+				"instance": "/api/v1/payment/abc",
+				"code":     "wallet_error",
+			},
+			expStatusCode: http.StatusBadGateway,
+		},
 	}
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/payment/abc", nil)
 			rec := httptest.NewRecorder()
 
 			e := echo.New()
@@ -108,6 +221,8 @@ func TestErrorHandler(t *testing.T) {
 
 			assert.Equal(t, test.expResp, mm)
 			assert.Equal(t, test.expStatusCode, response.StatusCode)
+			assert.Equal(t, server.ProblemContentType, response.Header.Get(echo.HeaderContentType))
+			assert.Equal(t, test.expRetryAfter, response.Header.Get("Retry-After"))
 		})
 	}
 }
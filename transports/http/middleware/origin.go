@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CheckOrigin returns a websocket origin check allowing requests from the origins,
+// or any origin if '*' is one of them. Requests without an origin, which aren't sent
+// by browsers, are allowed.
+func CheckOrigin(origins []string) func(r *http.Request) bool {
+	allowed := make(map[string]struct{}, len(origins))
+	for _, o := range origins {
+		allowed[o] = struct{}{}
+	}
+	_, allowAny := allowed["*"]
+	return func(r *http.Request) bool {
+		origin := r.Header.Get(echo.HeaderOrigin)
+		if allowAny || origin == "" {
+			return true
+		}
+		_, ok := allowed[origin]
+		return ok
+	}
+}
@@ -0,0 +1,48 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bitcoin-sv/dpp-proxy/transports/http/middleware"
+)
+
+func TestCheckOrigin(t *testing.T) {
+	tests := map[string]struct {
+		origins []string
+		origin  string
+		exp     bool
+	}{
+		"any origin is allowed with a wildcard": {
+			origins: []string{"*"},
+			origin:  "https://evil.example.com",
+			exp:     true,
+		},
+		"allowed origin is allowed": {
+			origins: []string{"https://wallet.example.com", "https://shop.example.com"},
+			origin:  "https://shop.example.com",
+			exp:     true,
+		},
+		"other origin is rejected": {
+			origins: []string{"https://wallet.example.com"},
+			origin:  "https://evil.example.com",
+		},
+		"request without an origin is allowed": {
+			origins: []string{"https://wallet.example.com"},
+			exp:     true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/ws/abc", nil)
+			if test.origin != "" {
+				req.Header.Set(echo.HeaderOrigin, test.origin)
+			}
+			assert.Equal(t, test.exp, middleware.CheckOrigin(test.origins)(req))
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"golang.org/x/time/rate"
+)
+
+// RateLimiter limits the requests each client ip can make, the limit can be changed
+// while running.
+type RateLimiter struct {
+	mu    sync.RWMutex
+	limit float64
+	store *middleware.RateLimiterMemoryStore
+}
+
+// NewRateLimiter will setup and return a rate limiter allowing limit requests per second,
+// with bursts of up to burst requests. Requests aren't limited if limit is 0.
+func NewRateLimiter(limit float64, burst int) *RateLimiter {
+	r := &RateLimiter{}
+	r.SetLimit(limit, burst)
+	return r
+}
+
+// SetLimit changes the requests allowed per second and the burst, the requests
+// clients have already made are forgotten.
+func (r *RateLimiter) SetLimit(limit float64, burst int) {
+	var store *middleware.RateLimiterMemoryStore
+	if limit > 0 {
+		store = middleware.NewRateLimiterMemoryStoreWithConfig(middleware.RateLimiterMemoryStoreConfig{
+			Rate:  rate.Limit(limit),
+			Burst: burst,
+		})
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.limit, r.store = limit, store
+}
+
+// Allow implements the echo RateLimiterStore interface, it returns true if the
+// client can make a request.
+func (r *RateLimiter) Allow(identifier string) (bool, error) {
+	r.mu.RLock()
+	store := r.store
+	r.mu.RUnlock()
+	if store == nil {
+		return true, nil
+	}
+	return store.Allow(identifier)
+}
+
+// retryAfter returns the time until a client can make another request.
+func (r *RateLimiter) retryAfter() time.Duration {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.limit <= 0 {
+		return 0
+	}
+	return time.Duration(math.Ceil(1/r.limit)) * time.Second
+}
+
+// RateLimit rejects requests from clients making more requests than allowed by r with a
+// RateLimited error.
+func RateLimit(r *RateLimiter) echo.MiddlewareFunc {
+	return middleware.RateLimiterWithConfig(middleware.RateLimiterConfig{
+		Store: r,
+		DenyHandler: func(c echo.Context, identifier string, err error) error {
+			return server.NewErrRateLimited("429", "too many requests", r.retryAfter())
+		},
+	})
+}
@@ -0,0 +1,75 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bitcoin-sv/dpp-proxy/log"
+	"github.com/bitcoin-sv/dpp-proxy/transports/http/middleware"
+)
+
+func TestRateLimit(t *testing.T) {
+	tests := map[string]struct {
+		limit          float64
+		burst          int
+		reloadLimit    float64
+		reloadBurst    int
+		expStatusCodes []int
+		expRetryAfter  string
+	}{
+		"requests within the burst are allowed": {
+			limit:          0.5,
+			burst:          2,
+			reloadLimit:    0.5,
+			reloadBurst:    2,
+			expStatusCodes: []int{http.StatusOK, http.StatusOK},
+		},
+		"requests over the burst are rejected": {
+			limit:          0.5,
+			burst:          2,
+			reloadLimit:    0.5,
+			reloadBurst:    2,
+			expStatusCodes: []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+			expRetryAfter:  "2",
+		},
+		"requests aren't limited when the limit is 0": {
+			expStatusCodes: []int{http.StatusOK, http.StatusOK, http.StatusOK},
+		},
+		"reloaded limit is used": {
+			reloadLimit:    1,
+			reloadBurst:    1,
+			expStatusCodes: []int{http.StatusOK, http.StatusTooManyRequests},
+			expRetryAfter:  "1",
+		},
+		"limit removed on reload allows requests": {
+			limit:          1,
+			burst:          1,
+			expStatusCodes: []int{http.StatusOK, http.StatusOK, http.StatusOK},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			limiter := middleware.NewRateLimiter(test.limit, test.burst)
+			limiter.SetLimit(test.reloadLimit, test.reloadBurst)
+
+			e := echo.New()
+			e.HTTPErrorHandler = middleware.ErrorHandler(log.Noop{})
+			e.Use(middleware.RateLimit(limiter))
+			e.GET("/", func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			})
+
+			var rec *httptest.ResponseRecorder
+			for _, code := range test.expStatusCodes {
+				rec = httptest.NewRecorder()
+				e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+				assert.Equal(t, code, rec.Code)
+			}
+			assert.Equal(t, test.expRetryAfter, rec.Header().Get("Retry-After"))
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"fmt"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.10.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/bitcoin-sv/dpp-proxy/tracing"
+)
+
+// Tracing will start a server span for each request, continuing any trace
+// propagated by the caller in the request headers.
+func Tracing(serverName string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			ctx := tracing.Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+			ctx, span := tracing.Tracer().Start(ctx, fmt.Sprintf("%s %s", req.Method, c.Path()),
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(semconv.HTTPServerAttributesFromHTTPRequest(serverName, c.Path(), req)...))
+			defer span.End()
+			c.SetRequest(req.WithContext(ctx))
+
+			if err := next(c); err != nil {
+				tracing.RecordError(span, err)
+				// invoke the error handler here so the response status is known.
+				c.Error(err)
+			}
+			status := c.Response().Status
+			span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(status)...)
+			if code, msg := semconv.SpanStatusFromHTTPStatusCodeAndSpanKind(status, trace.SpanKindServer); code != codes.Unset {
+				span.SetStatus(code, msg)
+			}
+			return nil
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package middleware_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/bitcoin-sv/dpp-proxy/log"
+	"github.com/bitcoin-sv/dpp-proxy/transports/http/middleware"
+)
+
+func TestTracing(t *testing.T) {
+	tests := map[string]struct {
+		handler     echo.HandlerFunc
+		traceParent string
+		expName     string
+		expStatus   codes.Code
+		expParent   string
+	}{
+		"successful request is traced": {
+			handler: func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			},
+			expName:   "GET /api/v1/payment/:paymentID",
+			expStatus: codes.Unset,
+		},
+		"incoming trace is continued": {
+			handler: func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			},
+			traceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			expName:     "GET /api/v1/payment/:paymentID",
+			expStatus:   codes.Unset,
+			expParent:   "00f067aa0ba902b7",
+		},
+		"internal error marks span as failed": {
+			handler: func(c echo.Context) error {
+				return errors.New("oh no")
+			},
+			expName:   "GET /api/v1/payment/:paymentID",
+			expStatus: codes.Error,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			sr := tracetest.NewSpanRecorder()
+			otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)))
+			otel.SetTextMapPropagator(propagation.TraceContext{})
+
+			e := echo.New()
+			e.HTTPErrorHandler = middleware.ErrorHandler(log.Noop{})
+			e.Use(middleware.Tracing("test"))
+			e.GET("/api/v1/payment/:paymentID", test.handler)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/payment/abc123", nil)
+			if test.traceParent != "" {
+				req.Header.Set("traceparent", test.traceParent)
+			}
+			e.ServeHTTP(httptest.NewRecorder(), req)
+
+			spans := sr.Ended()
+			assert.Len(t, spans, 1)
+			assert.Equal(t, test.expName, spans[0].Name())
+			assert.Equal(t, trace.SpanKindServer, spans[0].SpanKind())
+			assert.Equal(t, test.expStatus, spans[0].Status().Code)
+			if test.expParent != "" {
+				assert.Equal(t, test.expParent, spans[0].Parent().SpanID().String())
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package http
+
+import (
+	"mime"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// accepts returns true if the Accept header of the request includes the content type.
+func accepts(e echo.Context, contentType string) bool {
+	for _, a := range strings.Split(e.Request().Header.Get(echo.HeaderAccept), ",") {
+		if mt, _, err := mime.ParseMediaType(strings.TrimSpace(a)); err == nil && mt == contentType {
+			return true
+		}
+	}
+	return false
+}
+
+// hasContentType returns true if the request body is of the content type.
+func hasContentType(e echo.Context, contentType string) bool {
+	mt, _, err := mime.ParseMediaType(e.Request().Header.Get(echo.HeaderContentType))
+	return err == nil && mt == contentType
+}
@@ -0,0 +1,77 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+)
+
+// paymailInvoiceHandler lets merchants create invoices paid to their paymail.
+type paymailInvoiceHandler struct {
+	svc server.PaymailInvoiceService
+}
+
+// NewPaymailInvoiceHandler will create and return a new PaymailInvoiceHandler, routes should be
+// registered with a group authenticating the merchant.
+func NewPaymailInvoiceHandler(svc server.PaymailInvoiceService) *paymailInvoiceHandler {
+	return &paymailInvoiceHandler{
+		svc: svc,
+	}
+}
+
+// RegisterRoutes will setup all routes with an echo group.
+func (h *paymailInvoiceHandler) RegisterRoutes(g *echo.Group) {
+	g.POST(RouteV1PaymailInvoices, h.createPaymailInvoice)
+	g.GET(RouteV1PaymailInvoice, h.paymailInvoice)
+}
+
+// createPaymailInvoice godoc
+// @Summary Create a paymail invoice
+// @Description Creates an invoice paid to the configured merchant paymail, customers are sent the paymentId to pay it.
+// @Tags Merchant
+// @Accept json
+// @Produce json
+// @Security BearerToken
+// @Param body body server.PaymailInvoiceCreate true "the invoice to create"
+// @Success 201 {object} server.PaymailInvoice
+// @Failure 400 {object} server.Problem "returned if the invoice is invalid"
+// @Failure 401 {object} server.Problem "returned if the merchant bearer token is missing or invalid"
+// @Router /api/v1/paymail/invoice [POST].
+func (h *paymailInvoiceHandler) createPaymailInvoice(e echo.Context) error {
+	var req server.PaymailInvoiceCreate
+	if err := e.Bind(&req); err != nil {
+		return errors.Wrap(err, "failed to bind request")
+	}
+	resp, err := h.svc.PaymailInvoiceCreate(e.Request().Context(), req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return e.JSON(http.StatusCreated, resp)
+}
+
+// paymailInvoice godoc
+// @Summary A paymail invoice
+// @Description Returns a paymail invoice.
+// @Tags Merchant
+// @Produce json
+// @Security BearerToken
+// @Param paymentID path string true "Payment ID"
+// @Success 200 {object} server.PaymailInvoice
+// @Failure 400 {object} server.Problem "returned if the user input is invalid"
+// @Failure 401 {object} server.Problem "returned if the merchant bearer token is missing or invalid"
+// @Failure 404 {object} server.Problem "returned if the invoice doesn't exist"
+// @Router /api/v1/paymail/invoice/{paymentID} [GET].
+func (h *paymailInvoiceHandler) paymailInvoice(e echo.Context) error {
+	var args server.PaymailInvoiceArgs
+	if err := e.Bind(&args); err != nil {
+		return errors.Wrap(err, "failed to bind request")
+	}
+	resp, err := h.svc.PaymailInvoice(e.Request().Context(), args)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return e.JSON(http.StatusOK, resp)
+}
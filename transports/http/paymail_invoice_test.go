@@ -0,0 +1,36 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/mocks"
+)
+
+func TestPaymailInvoiceHandler_CreatePaymailInvoice(t *testing.T) {
+	e := echo.New()
+	svc := &mocks.PaymailInvoiceServiceMock{
+		PaymailInvoiceCreateFunc: func(ctx context.Context, req server.PaymailInvoiceCreate) (*server.PaymailInvoice, error) {
+			return &server.PaymailInvoice{PaymentID: "abc123", Satoshis: req.Satoshis, Memo: req.Memo}, nil
+		},
+	}
+	h := NewPaymailInvoiceHandler(svc)
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"satoshis":2500,"memo":"invoice 123"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+
+	assert.NoError(t, h.createPaymailInvoice(ctx))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, server.PaymailInvoiceCreate{Satoshis: 2500, Memo: "invoice 123"}, svc.PaymailInvoiceCreateCalls()[0].Req)
+	assert.Contains(t, rec.Body.String(), `"paymentId":"abc123"`)
+	assert.Contains(t, rec.Body.String(), `"satoshis":2500`)
+}
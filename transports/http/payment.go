@@ -1,11 +1,15 @@
 package http
 
 import (
+	"io"
 	"net/http"
 
 	"github.com/labstack/echo/v4"
 	"github.com/libsv/go-dpp"
 	"github.com/pkg/errors"
+	validator "github.com/theflyingcodr/govalidator"
+
+	"github.com/bitcoin-sv/dpp-proxy/transports/http/bip270"
 )
 
 // paymentHandler is an http handler that supports BIP-270 requests.
@@ -28,29 +32,53 @@ func (h *paymentHandler) RegisterRoutes(g *echo.Group) {
 // @Summary A user will submit an SpvEnvelope along with other information that is validated before being broadcast to the network.
 // @Description Creates a payment based on a payment id (the identifier for an invoice).
 // @Tags Payment
-// @Accept json
-// @Produce json
+// @Accept json,application/bitcoinsv-payment
+// @Produce json,application/bitcoinsv-paymentack
 // @Param paymentID path string true "Payment ID"
 // @Param body body dpp.PaymentCreateArgs true "payment message used in BIP270"
 // @Success 201 {object} dpp.PaymentACK "if success, error code will be empty, otherwise it will be filled in with reason"
-// @Failure 404 {object} server.ClientError "returned if the paymentID has not been found"
-// @Failure 400 {object} server.ClientError "returned if the user input is invalid, usually an issue with the paymentID"
-// @Failure 500 {string} string "returned if there is an unexpected internal error"
+// @Failure 404 {object} server.Problem "returned if the paymentID has not been found"
+// @Failure 400 {object} server.Problem "returned if the user input is invalid, usually an issue with the paymentID"
+// @Failure 413 {object} server.Problem "returned if the payment is larger than SERVER_PAYMENT_MAXBODY_BYTES"
+// @Failure 429 {object} server.Problem "returned if the wallet is rate limiting requests"
+// @Failure 500 {object} server.Problem "returned if there is an unexpected internal error"
+// @Failure 502 {object} server.Problem "returned if the wallet returned an unexpected error"
+// @Failure 503 {object} server.Problem "returned if the wallet is unavailable or did not respond in time"
 // @Router /api/v1/payment/{paymentID} [POST].
 func (h *paymentHandler) createPayment(e echo.Context) error {
 	args := dpp.PaymentCreateArgs{
 		PaymentID: e.Param("paymentID"),
 	}
 	var req dpp.Payment
-	if err := e.Bind(&req); err != nil {
+	isProto := hasContentType(e, bip270.MIMEPayment)
+	if isProto {
+		bb, err := io.ReadAll(e.Request().Body)
+		if err != nil {
+			return errors.Wrap(err, "failed to read payment")
+		}
+		p, err := bip270.DecodePayment(bb)
+		if err != nil {
+			return validator.NewFromError("payment", err)
+		}
+		req = *p
+	} else if err := e.Bind(&req); err != nil {
 		return errors.WithStack(err)
 	}
 	resp, err := h.svc.PaymentCreate(e.Request().Context(), args, req)
 	if err != nil {
 		return errors.WithStack(err)
 	}
+	status := http.StatusCreated
 	if resp.Error > 0 {
-		return e.JSON(http.StatusUnprocessableEntity, resp)
+		status = http.StatusUnprocessableEntity
+	}
+	// wallets sending protobuf payments expect a protobuf ack.
+	if isProto {
+		bb, err := bip270.EncodePaymentACK(&req, resp)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		return e.Blob(status, bip270.MIMEPaymentACK, bb)
 	}
-	return e.JSON(http.StatusCreated, resp)
+	return e.JSON(status, resp)
 }
@@ -0,0 +1,99 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/libsv/go-dpp"
+	"github.com/pkg/errors"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+)
+
+// paymentQueueHandler lets merchant wallets collect payments queued while they were offline.
+type paymentQueueHandler struct {
+	svc server.PaymentQueueService
+}
+
+// NewPaymentQueueHandler will create and return a new PaymentQueueHandler, routes should be
+// registered with a group authenticating the merchant.
+func NewPaymentQueueHandler(svc server.PaymentQueueService) *paymentQueueHandler {
+	return &paymentQueueHandler{
+		svc: svc,
+	}
+}
+
+// RegisterRoutes will setup all routes with an echo group.
+func (h *paymentQueueHandler) RegisterRoutes(g *echo.Group) {
+	g.GET(RouteV1PaymentQueue, h.queuedPayments)
+	g.GET(RouteV1QueuedPayment, h.queuedPayment)
+	g.POST(RouteV1QueuedAck, h.queuedPaymentAck)
+}
+
+// queuedPayments godoc
+// @Summary Queued payments
+// @Description Returns the payments received for registered invoices while the merchant wallet was offline, oldest first.
+// @Tags Merchant
+// @Produce json
+// @Security BearerToken
+// @Success 200 {array} server.QueuedPayment
+// @Failure 401 {object} server.Problem "returned if the merchant bearer token is missing or invalid"
+// @Router /api/v1/queue [GET].
+func (h *paymentQueueHandler) queuedPayments(e echo.Context) error {
+	resp, err := h.svc.QueuedPayments(e.Request().Context())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return e.JSON(http.StatusOK, resp)
+}
+
+// queuedPayment godoc
+// @Summary A queued payment
+// @Description Returns the payment queued for an invoice.
+// @Tags Merchant
+// @Produce json
+// @Security BearerToken
+// @Param paymentID path string true "Payment ID"
+// @Success 200 {object} server.QueuedPayment
+// @Failure 400 {object} server.Problem "returned if the user input is invalid"
+// @Failure 401 {object} server.Problem "returned if the merchant bearer token is missing or invalid"
+// @Failure 404 {object} server.Problem "returned if no payment is queued for the invoice"
+// @Router /api/v1/queue/{paymentID} [GET].
+func (h *paymentQueueHandler) queuedPayment(e echo.Context) error {
+	var args server.QueuedPaymentArgs
+	if err := e.Bind(&args); err != nil {
+		return errors.Wrap(err, "failed to bind request")
+	}
+	resp, err := h.svc.QueuedPayment(e.Request().Context(), args)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return e.JSON(http.StatusOK, resp)
+}
+
+// queuedPaymentAck godoc
+// @Summary Acknowledge a queued payment
+// @Description Removes a collected payment from the queue, the payment is marked as rejected if the ack has an error.
+// @Tags Merchant
+// @Accept json
+// @Security BearerToken
+// @Param paymentID path string true "Payment ID"
+// @Param body body dpp.PaymentACK true "the result of processing the payment"
+// @Success 204
+// @Failure 400 {object} server.Problem "returned if the user input is invalid"
+// @Failure 401 {object} server.Problem "returned if the merchant bearer token is missing or invalid"
+// @Failure 404 {object} server.Problem "returned if no payment is queued for the invoice"
+// @Router /api/v1/queue/{paymentID}/ack [POST].
+func (h *paymentQueueHandler) queuedPaymentAck(e echo.Context) error {
+	args := server.QueuedPaymentArgs{
+		PaymentID: e.Param("paymentID"),
+	}
+	var req dpp.PaymentACK
+	if err := e.Bind(&req); err != nil {
+		return errors.Wrap(err, "failed to bind request")
+	}
+	if err := h.svc.QueuedPaymentAck(e.Request().Context(), args, req); err != nil {
+		return errors.WithStack(err)
+	}
+	return e.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,63 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/libsv/go-dpp"
+	"github.com/stretchr/testify/assert"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/mocks"
+)
+
+func TestPaymentQueueHandler_QueuedPayments(t *testing.T) {
+	e := echo.New()
+	svc := &mocks.PaymentQueueServiceMock{
+		QueuedPaymentsFunc: func(ctx context.Context) ([]server.QueuedPayment, error) {
+			return []server.QueuedPayment{{PaymentID: "abc123", TxID: "txid123"}}, nil
+		},
+	}
+	h := NewPaymentQueueHandler(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+
+	assert.NoError(t, h.queuedPayments(ctx))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var resp []map[string]interface{}
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	assert.Len(t, resp, 1)
+	assert.Equal(t, "abc123", resp[0]["paymentId"])
+	assert.Equal(t, "txid123", resp[0]["txid"])
+}
+
+func TestPaymentQueueHandler_QueuedPaymentAck(t *testing.T) {
+	e := echo.New()
+	svc := &mocks.PaymentQueueServiceMock{
+		QueuedPaymentAckFunc: func(ctx context.Context, args server.QueuedPaymentArgs, req dpp.PaymentACK) error {
+			return nil
+		},
+	}
+	h := NewPaymentQueueHandler(svc)
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"error":1,"memo":"invoice cancelled"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/api/v1/queue/:paymentID/ack")
+	ctx.SetParamNames("paymentID")
+	ctx.SetParamValues("abc123")
+
+	assert.NoError(t, h.queuedPaymentAck(ctx))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	call := svc.QueuedPaymentAckCalls()[0]
+	assert.Equal(t, server.QueuedPaymentArgs{PaymentID: "abc123"}, call.Args)
+	assert.Equal(t, dpp.PaymentACK{Error: 1, Memo: "invoice cancelled"}, call.Req)
+}
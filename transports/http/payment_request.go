@@ -6,6 +6,8 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/libsv/go-dpp"
 	"github.com/pkg/errors"
+
+	"github.com/bitcoin-sv/dpp-proxy/transports/http/bip270"
 )
 
 type (
@@ -32,12 +34,15 @@ func (h *paymentRequestHandler) RegisterRoutes(g *echo.Group) {
 // @Description Creates a payment request based on a payment id (the identifier for an invoice).
 // @Tags Payment
 // @Accept json
-// @Produce json
+// @Produce json,application/bitcoinsv-paymentrequest
 // @Param paymentID path string true "Payment ID"
 // @Success 201 {object} dpp.PaymentRequest "contains outputs, merchant data and expiry information, used by the payee to construct a transaction"
-// @Failure 404 {object} server.ClientError "returned if the paymentID has not been found"
-// @Failure 400 {object} server.ClientError "returned if the user input is invalid, usually an issue with the paymentID"
-// @Failure 500 {string} string "returned if there is an unexpected internal error"
+// @Failure 404 {object} server.Problem "returned if the paymentID has not been found"
+// @Failure 400 {object} server.Problem "returned if the user input is invalid, usually an issue with the paymentID"
+// @Failure 429 {object} server.Problem "returned if the wallet is rate limiting requests"
+// @Failure 500 {object} server.Problem "returned if there is an unexpected internal error"
+// @Failure 502 {object} server.Problem "returned if the wallet returned an unexpected error"
+// @Failure 503 {object} server.Problem "returned if the wallet is unavailable or did not respond in time"
 // @Router /api/v1/payment/{paymentID} [GET].
 func (h *paymentRequestHandler) buildPaymentRequest(e echo.Context) error {
 	var args dpp.PaymentRequestArgs
@@ -48,5 +53,12 @@ func (h *paymentRequestHandler) buildPaymentRequest(e echo.Context) error {
 	if err != nil {
 		return errors.WithStack(err)
 	}
+	if accepts(e, bip270.MIMEPaymentRequest) {
+		bb, err := bip270.EncodePaymentRequest(resp)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		return e.Blob(http.StatusOK, bip270.MIMEPaymentRequest, bb)
+	}
 	return e.JSON(http.StatusOK, resp)
 }
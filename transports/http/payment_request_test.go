@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -13,6 +14,8 @@ import (
 	"github.com/libsv/go-dpp"
 	dppMocks "github.com/libsv/go-dpp/mocks"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/bitcoin-sv/dpp-proxy/transports/http/bip270"
 )
 
 func TestPaymentRequestHandler_BuildPaymentRequest(t *testing.T) {
@@ -81,3 +84,38 @@ func TestPaymentRequestHandler_BuildPaymentRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestPaymentRequestHandler_BuildPaymentRequestProtobuf(t *testing.T) {
+	e := echo.New()
+	h := NewPaymentRequestHandler(&dppMocks.PaymentRequestServiceMock{
+		PaymentRequestFunc: func(ctx context.Context, args dpp.PaymentRequestArgs) (*dpp.PaymentRequest, error) {
+			return &dpp.PaymentRequest{
+				Network: "mainnet",
+				Memo:    fmt.Sprintf("payment %s", args.PaymentID),
+			}, nil
+		},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Add(echo.HeaderAccept, "application/json;q=0.5, "+bip270.MIMEPaymentRequest)
+	rec := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/api/v1/payment/:paymentID")
+	ctx.SetParamNames("paymentID")
+	ctx.SetParamValues("abc123")
+
+	assert.NoError(t, h.buildPaymentRequest(ctx))
+
+	response := rec.Result()
+	defer response.Body.Close()
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, bip270.MIMEPaymentRequest, response.Header.Get(echo.HeaderContentType))
+
+	bb, err := io.ReadAll(response.Body)
+	assert.NoError(t, err)
+	pr, err := bip270.DecodePaymentRequest(bb)
+	assert.NoError(t, err)
+	assert.Equal(t, "mainnet", pr.Network)
+	assert.Equal(t, "payment abc123", pr.Memo)
+}
@@ -0,0 +1,118 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+)
+
+// streamKeepAlive is how often a comment is sent on idle status streams so proxies
+// don't close the connection.
+const streamKeepAlive = 15 * time.Second
+
+// paymentStatus returns the status of payments to customers.
+type paymentStatus struct {
+	svc server.PaymentStatusService
+}
+
+// NewPaymentStatusHandler will setup and return a new payment status http handler.
+func NewPaymentStatusHandler(svc server.PaymentStatusService) *paymentStatus {
+	return &paymentStatus{svc: svc}
+}
+
+// RegisterRoutes will setup all payment status routes with the supplied echo group.
+func (p *paymentStatus) RegisterRoutes(g *echo.Group) {
+	g.GET(RouteV1PaymentStatus, p.status)
+	g.GET(RouteV1PaymentStream, p.stream)
+}
+
+// status godoc
+// @Summary Payment status
+// @Description Returns the status of a payment, one of requested, paid, rejected, proven or expired.
+// @Tags Payment
+// @Produce json
+// @Param paymentID path string true "Payment ID"
+// @Success 200 {object} server.PaymentStatus
+// @Failure 400 {object} server.Problem "returned if the user input is invalid"
+// @Failure 404 {object} server.Problem "returned if no payment request has been returned for the payment"
+// @Router /api/v1/payment/{paymentID}/status [GET].
+func (p *paymentStatus) status(c echo.Context) error {
+	var args server.PaymentStatusArgs
+	if err := c.Bind(&args); err != nil {
+		return errors.Wrap(err, "failed to bind request")
+	}
+	resp, err := p.svc.PaymentStatus(c.Request().Context(), args)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// stream godoc
+// @Summary Payment status stream
+// @Description Streams the status of a payment as server-sent events, a status event is sent with the current status
+// @Description and then each change. The stream ends once the payment is proven or expired.
+// @Tags Payment
+// @Produce text/event-stream
+// @Param paymentID path string true "Payment ID"
+// @Success 200 {object} server.PaymentStatus
+// @Failure 400 {object} server.Problem "returned if the user input is invalid"
+// @Failure 404 {object} server.Problem "returned if no payment request has been returned for the payment"
+// @Router /api/v1/payment/{paymentID}/status/stream [GET].
+func (p *paymentStatus) stream(c echo.Context) error {
+	var args server.PaymentStatusArgs
+	if err := c.Bind(&args); err != nil {
+		return errors.Wrap(err, "failed to bind request")
+	}
+	ctx := c.Request().Context()
+	changes, err := p.svc.PaymentStatusWatch(ctx, args)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	res := c.Response()
+	// the server write timeout would end the stream, it is cleared for this response.
+	if err := http.NewResponseController(res.Writer).SetWriteDeadline(time.Time{}); err != nil &&
+		!errors.Is(err, http.ErrNotSupported) {
+		return errors.Wrap(err, "failed to clear the write deadline")
+	}
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.WriteHeader(http.StatusOK)
+	res.Flush()
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case status, ok := <-changes:
+			if !ok {
+				return nil
+			}
+			bb, err := json.Marshal(status)
+			if err != nil {
+				return errors.Wrap(err, "failed to encode payment status")
+			}
+			if _, err := fmt.Fprintf(res, "event: status\ndata: %s\n\n", bb); err != nil {
+				return errors.Wrap(err, "failed to write payment status")
+			}
+			res.Flush()
+			if status.State == server.PaymentStateProven || status.State == server.PaymentStateExpired {
+				return nil
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(res, ": keepalive\n\n"); err != nil {
+				return errors.Wrap(err, "failed to write keepalive")
+			}
+			res.Flush()
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
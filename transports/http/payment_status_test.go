@@ -0,0 +1,108 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/mocks"
+)
+
+func TestPaymentStatus_Status(t *testing.T) {
+	e := echo.New()
+	svc := &mocks.PaymentStatusServiceMock{
+		PaymentStatusFunc: func(ctx context.Context, args server.PaymentStatusArgs) (*server.PaymentStatus, error) {
+			return &server.PaymentStatus{
+				PaymentID: args.PaymentID,
+				State:     server.PaymentStatePaid,
+				TxID:      "d21633ba23f70118185227be58a63527675641ad37967e2aa461559f577aec43",
+			}, nil
+		},
+	}
+	h := NewPaymentStatusHandler(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/api/v1/payment/:paymentID/status")
+	ctx.SetParamNames("paymentID")
+	ctx.SetParamValues("abc123")
+
+	assert.NoError(t, h.status(ctx))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var resp server.PaymentStatus
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	assert.Equal(t, "abc123", resp.PaymentID)
+	assert.Equal(t, server.PaymentStatePaid, resp.State)
+	assert.Equal(t, "d21633ba23f70118185227be58a63527675641ad37967e2aa461559f577aec43", resp.TxID)
+}
+
+func TestPaymentStatus_Stream(t *testing.T) {
+	e := echo.New()
+	svc := &mocks.PaymentStatusServiceMock{
+		PaymentStatusWatchFunc: func(ctx context.Context, args server.PaymentStatusArgs) (<-chan server.PaymentStatus, error) {
+			changes := make(chan server.PaymentStatus, 3)
+			changes <- server.PaymentStatus{PaymentID: args.PaymentID, State: server.PaymentStateRequested}
+			changes <- server.PaymentStatus{PaymentID: args.PaymentID, State: server.PaymentStatePaid}
+			changes <- server.PaymentStatus{PaymentID: args.PaymentID, State: server.PaymentStateProven}
+			return changes, nil
+		},
+	}
+	h := NewPaymentStatusHandler(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/api/v1/payment/:paymentID/status/stream")
+	ctx.SetParamNames("paymentID")
+	ctx.SetParamValues("abc123")
+
+	// the stream ends once the payment is proven, without the channel being closed.
+	assert.NoError(t, h.stream(ctx))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/event-stream", rec.Header().Get(echo.HeaderContentType))
+	assert.Equal(t, "event: status\n"+
+		`data: {"paymentId":"abc123","state":"requested","updatedAt":"0001-01-01T00:00:00Z"}`+"\n\n"+
+		"event: status\n"+
+		`data: {"paymentId":"abc123","state":"paid","updatedAt":"0001-01-01T00:00:00Z"}`+"\n\n"+
+		"event: status\n"+
+		`data: {"paymentId":"abc123","state":"proven","updatedAt":"0001-01-01T00:00:00Z"}`+"\n\n", rec.Body.String())
+}
+
+func TestPaymentStatus_Stream_OutlivesWriteTimeout(t *testing.T) {
+	e := echo.New()
+	svc := &mocks.PaymentStatusServiceMock{
+		PaymentStatusWatchFunc: func(ctx context.Context, args server.PaymentStatusArgs) (<-chan server.PaymentStatus, error) {
+			changes := make(chan server.PaymentStatus, 1)
+			changes <- server.PaymentStatus{PaymentID: args.PaymentID, State: server.PaymentStateRequested}
+			go func() {
+				time.Sleep(300 * time.Millisecond)
+				changes <- server.PaymentStatus{PaymentID: args.PaymentID, State: server.PaymentStateProven}
+			}()
+			return changes, nil
+		},
+	}
+	NewPaymentStatusHandler(svc).RegisterRoutes(e.Group(""))
+	svr := httptest.NewUnstartedServer(e)
+	svr.Config.WriteTimeout = 100 * time.Millisecond
+	svr.Start()
+	defer svr.Close()
+
+	resp, err := http.Get(svr.URL + "/" + strings.ReplaceAll(RouteV1PaymentStream, ":paymentID", "abc123"))
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+
+	// the proven status is sent after the server write timeout.
+	assert.Contains(t, string(body), `"state":"proven"`)
+}
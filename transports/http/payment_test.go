@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -14,6 +15,8 @@ import (
 	"github.com/libsv/go-dpp"
 	dppMocks "github.com/libsv/go-dpp/mocks"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/bitcoin-sv/dpp-proxy/transports/http/bip270"
 )
 
 func TestPaymentHandler_CreatedPayment(t *testing.T) {
@@ -104,3 +107,99 @@ func TestPaymentHandler_CreatedPayment(t *testing.T) {
 		})
 	}
 }
+
+func TestPaymentHandler_CreatePaymentProtobuf(t *testing.T) {
+	rawTx := "01000000000000000000"
+	tests := map[string]struct {
+		paymentCreateFunc func(context.Context, dpp.PaymentCreateArgs, dpp.Payment) (*dpp.PaymentACK, error)
+		reqBody           []byte
+		expPayment        *dpp.Payment
+		expMemo           string
+		expStatusCode     int
+		expErr            error
+	}{
+		"successful protobuf payment returns protobuf ack": {
+			paymentCreateFunc: func(ctx context.Context, args dpp.PaymentCreateArgs, req dpp.Payment) (*dpp.PaymentACK, error) {
+				return &dpp.PaymentACK{Memo: "thanks"}, nil
+			},
+			reqBody: func() []byte {
+				bb, err := bip270.EncodePayment(&dpp.Payment{
+					RawTx: &rawTx,
+					Memo:  "for invoice abc123",
+					MerchantData: dpp.Merchant{
+						ExtendedData: map[string]interface{}{"paymentReference": "abc123"},
+					},
+				})
+				assert.NoError(t, err)
+				return bb
+			}(),
+			expPayment: &dpp.Payment{
+				RawTx: &rawTx,
+				Memo:  "for invoice abc123",
+				MerchantData: dpp.Merchant{
+					ExtendedData: map[string]interface{}{"paymentReference": "abc123"},
+				},
+			},
+			expMemo:       "thanks",
+			expStatusCode: http.StatusCreated,
+		},
+		"failed protobuf payment returns 422": {
+			paymentCreateFunc: func(ctx context.Context, args dpp.PaymentCreateArgs, req dpp.Payment) (*dpp.PaymentACK, error) {
+				return &dpp.PaymentACK{Memo: "failed", Error: 1}, nil
+			},
+			reqBody: func() []byte {
+				bb, err := bip270.EncodePayment(&dpp.Payment{RawTx: &rawTx})
+				assert.NoError(t, err)
+				return bb
+			}(),
+			expPayment: &dpp.Payment{
+				RawTx: &rawTx,
+			},
+			expMemo:       "failed",
+			expStatusCode: http.StatusUnprocessableEntity,
+		},
+		"invalid protobuf returns validation error": {
+			reqBody: []byte{0xff, 0xff},
+			expErr:  errors.New("[payment: failed to decode payment: unexpected EOF]"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			e := echo.New()
+			svc := &dppMocks.PaymentServiceMock{
+				PaymentCreateFunc: test.paymentCreateFunc,
+			}
+			h := NewPaymentHandler(svc)
+
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(test.reqBody))
+			req.Header.Add(echo.HeaderContentType, bip270.MIMEPayment)
+			rec := httptest.NewRecorder()
+
+			ctx := e.NewContext(req, rec)
+			ctx.SetPath("/api/v1/payment/:paymentID")
+			ctx.SetParamNames("paymentID")
+			ctx.SetParamValues("abc123")
+
+			err := h.createPayment(ctx)
+			if test.expErr != nil {
+				assert.EqualError(t, err, test.expErr.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, *test.expPayment, svc.PaymentCreateCalls()[0].Req)
+
+			response := rec.Result()
+			defer response.Body.Close()
+			assert.Equal(t, test.expStatusCode, response.StatusCode)
+			assert.Equal(t, bip270.MIMEPaymentACK, response.Header.Get(echo.HeaderContentType))
+
+			bb, err := io.ReadAll(response.Body)
+			assert.NoError(t, err)
+			payment, ack, err := bip270.DecodePaymentACK(bb)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expPayment, payment)
+			assert.Equal(t, test.expMemo, ack.Memo)
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+)
+
+// paymentURIHandler returns links and QR codes wallets use to find a payment request.
+type paymentURIHandler struct {
+	svc server.PaymentURIService
+}
+
+// NewPaymentURIHandler will create and return a new PaymentURIHandler.
+func NewPaymentURIHandler(svc server.PaymentURIService) *paymentURIHandler {
+	return &paymentURIHandler{
+		svc: svc,
+	}
+}
+
+// RegisterRoutes will setup all routes with an echo group.
+func (h *paymentURIHandler) RegisterRoutes(g *echo.Group) {
+	g.GET(RouteV1PaymentURI, h.paymentURI)
+	g.GET(RouteV1PaymentQR, h.paymentQR)
+}
+
+// paymentURI godoc
+// @Summary Payment uri for an invoice
+// @Description Returns a pay: uri that points a wallet at the payment request for an invoice.
+// @Tags Payment
+// @Produce json
+// @Param paymentID path string true "Payment ID"
+// @Param label query string false "BIP-21 label shown by the wallet"
+// @Param message query string false "BIP-21 message shown by the wallet"
+// @Success 200 {object} server.PaymentURI
+// @Failure 400 {object} server.Problem "returned if the user input is invalid"
+// @Router /api/v1/payment/{paymentID}/uri [GET].
+func (h *paymentURIHandler) paymentURI(e echo.Context) error {
+	var args server.PaymentURIArgs
+	if err := e.Bind(&args); err != nil {
+		return errors.Wrap(err, "failed to bind request")
+	}
+	resp, err := h.svc.PaymentURI(e.Request().Context(), args)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return e.JSON(http.StatusOK, resp)
+}
+
+// paymentQR godoc
+// @Summary Payment QR code for an invoice
+// @Description Returns a QR code containing the pay: uri for an invoice, as a png or svg image.
+// @Tags Payment
+// @Produce png,image/svg+xml
+// @Param paymentID path string true "Payment ID"
+// @Param label query string false "BIP-21 label shown by the wallet"
+// @Param message query string false "BIP-21 message shown by the wallet"
+// @Param format query string false "image format, png or svg" default(png)
+// @Param size query int false "width and height in pixels, between 64 and 1024" default(256)
+// @Success 200 {file} binary
+// @Failure 400 {object} server.Problem "returned if the user input is invalid"
+// @Router /api/v1/payment/{paymentID}/qr [GET].
+func (h *paymentURIHandler) paymentQR(e echo.Context) error {
+	var args server.PaymentQRArgs
+	if err := e.Bind(&args); err != nil {
+		return errors.Wrap(err, "failed to bind request")
+	}
+	resp, err := h.svc.PaymentQR(e.Request().Context(), args)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return e.Blob(http.StatusOK, resp.ContentType, resp.Image)
+}
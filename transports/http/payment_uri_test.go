@@ -0,0 +1,61 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/mocks"
+)
+
+func TestPaymentURIHandler_PaymentURI(t *testing.T) {
+	e := echo.New()
+	svc := &mocks.PaymentURIServiceMock{
+		PaymentURIFunc: func(ctx context.Context, args server.PaymentURIArgs) (*server.PaymentURI, error) {
+			return &server.PaymentURI{URI: "pay:?r=" + args.PaymentID + "&label=" + args.Label}, nil
+		},
+	}
+	h := NewPaymentURIHandler(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/?label=shop", nil)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/api/v1/payment/:paymentID/uri")
+	ctx.SetParamNames("paymentID")
+	ctx.SetParamValues("abc123")
+
+	assert.NoError(t, h.paymentURI(ctx))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var resp server.PaymentURI
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	assert.Equal(t, "pay:?r=abc123&label=shop", resp.URI)
+}
+
+func TestPaymentURIHandler_PaymentQR(t *testing.T) {
+	e := echo.New()
+	svc := &mocks.PaymentURIServiceMock{
+		PaymentQRFunc: func(ctx context.Context, args server.PaymentQRArgs) (*server.PaymentQR, error) {
+			return &server.PaymentQR{ContentType: "image/svg+xml", Image: []byte("<svg/>")}, nil
+		},
+	}
+	h := NewPaymentURIHandler(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/?format=svg&size=128", nil)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/api/v1/payment/:paymentID/qr")
+	ctx.SetParamNames("paymentID")
+	ctx.SetParamValues("abc123")
+
+	assert.NoError(t, h.paymentQR(ctx))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "image/svg+xml", rec.Header().Get(echo.HeaderContentType))
+	assert.Equal(t, "<svg/>", rec.Body.String())
+	assert.Equal(t, server.PaymentQRArgs{PaymentID: "abc123", Format: "svg", Size: 128}, svc.PaymentQRCalls()[0].Args)
+}
@@ -0,0 +1,173 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/theflyingcodr/lathos/errs"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+)
+
+// peerChannel returns the messages on peer channels to customers.
+type peerChannel struct {
+	svc      server.PeerChannelService
+	upgrader websocket.Upgrader
+}
+
+// NewPeerChannelHandler will setup and return a new peer channel http handler, customers
+// read channels with the token returned in the PaymentACK. Websockets are only opened
+// from origins allowed by checkOrigin.
+func NewPeerChannelHandler(svc server.PeerChannelService, checkOrigin func(r *http.Request) bool) *peerChannel {
+	return &peerChannel{
+		svc:      svc,
+		upgrader: websocket.Upgrader{CheckOrigin: checkOrigin},
+	}
+}
+
+// RegisterRoutes will setup all peer channel routes with the supplied echo group.
+func (p *peerChannel) RegisterRoutes(g *echo.Group) {
+	g.GET(RouteV1PeerChannel, p.messages)
+	g.GET(RouteV1PeerChannelWS, p.notify)
+}
+
+// messages godoc
+// @Summary Peer channel messages
+// @Description Returns the messages sent by the merchant on a peer channel, oldest first. Poll with after set to
+// @Description the sequence of the last message received to only return new messages.
+// @Tags PeerChannels
+// @Produce json
+// @Param channelID path string true "Channel ID"
+// @Param after query int false "Only return messages with a greater sequence"
+// @Security BearerToken
+// @Success 200 {array} server.PeerChannelMessage
+// @Failure 400 {object} server.Problem "returned if the user input is invalid"
+// @Failure 401 {object} server.Problem "returned if the channel token is missing or invalid"
+// @Failure 404 {object} server.Problem "returned if the channel doesn't exist"
+// @Router /api/v1/channel/{channelID} [GET].
+func (p *peerChannel) messages(c echo.Context) error {
+	var args server.PeerChannelArgs
+	if err := c.Bind(&args); err != nil {
+		return errors.Wrap(err, "failed to bind request")
+	}
+	args.Token = bearerToken(c)
+	resp, err := p.svc.PeerChannelMessages(c.Request().Context(), args)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// notify godoc
+// @Summary Peer channel websocket
+// @Description Upgrades to a websocket, messages on the channel after the after sequence are sent followed by each new message
+// @Description as json. Browsers can't set headers on websockets so the channel token can also be supplied in the token query parameter.
+// @Tags PeerChannels
+// @Param channelID path string true "Channel ID"
+// @Param after query int false "Only send messages with a greater sequence"
+// @Param token query string false "Channel token, if not sent as a bearer token"
+// @Security BearerToken
+// @Success 101 {object} server.PeerChannelMessage
+// @Failure 400 {object} server.Problem "returned if the user input is invalid"
+// @Failure 401 {object} server.Problem "returned if the channel token is missing or invalid"
+// @Failure 403 {object} server.Problem "returned if the origin isn't allowed to open websockets"
+// @Failure 404 {object} server.Problem "returned if the channel doesn't exist"
+// @Router /api/v1/channel/{channelID}/notify [GET].
+func (p *peerChannel) notify(c echo.Context) error {
+	var args server.PeerChannelArgs
+	if err := c.Bind(&args); err != nil {
+		return errors.Wrap(err, "failed to bind request")
+	}
+	args.Token = bearerToken(c)
+	if args.Token == "" {
+		args.Token = c.QueryParam("token")
+	}
+	ctx, cancel := context.WithCancel(c.Request().Context())
+	defer cancel()
+	// the origin and channel are checked before upgrading so errors are returned as http errors.
+	if !p.upgrader.CheckOrigin(c.Request()) {
+		return errs.NewErrNotAuthorised("403", "websockets can't be opened from this origin")
+	}
+	msgs, err := p.svc.PeerChannelWatch(ctx, args)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	ws, err := p.upgrader.Upgrade(c.Response(), c.Request(), nil)
+	if err != nil {
+		return nil
+	}
+	defer func() {
+		_ = ws.Close()
+	}()
+	// messages from the customer are discarded, reading detects when they disconnect.
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := ws.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+	for msg := range msgs {
+		if err := ws.WriteJSON(msg); err != nil {
+			return nil
+		}
+	}
+	return nil
+}
+
+// peerChannelMessage lets merchants send messages to customers on peer channels.
+type peerChannelMessage struct {
+	svc server.PeerChannelService
+}
+
+// NewPeerChannelMessageHandler will setup and return a new peer channel message handler, routes
+// should be registered with a group authenticating the merchant.
+func NewPeerChannelMessageHandler(svc server.PeerChannelService) *peerChannelMessage {
+	return &peerChannelMessage{svc: svc}
+}
+
+// RegisterRoutes will setup all peer channel message routes with the supplied echo group.
+func (p *peerChannelMessage) RegisterRoutes(g *echo.Group) {
+	g.POST(RouteV1PaymentMessage, p.create)
+}
+
+// create godoc
+// @Summary Send a message to the customer
+// @Description Sends the request body as a message on the peer channel of a paid invoice, such as a receipt or merkle proof.
+// @Description The content type of the request is kept with the message.
+// @Tags Merchant
+// @Accept */*
+// @Produce json
+// @Param paymentID path string true "Payment ID"
+// @Security BearerToken
+// @Success 201 {object} server.PeerChannelMessage
+// @Failure 400 {object} server.Problem "returned if the message is empty"
+// @Failure 401 {object} server.Problem "returned if the merchant bearer token is missing or invalid"
+// @Failure 404 {object} server.Problem "returned if the invoice hasn't been paid"
+// @Failure 413 {object} server.Problem "returned if the message is larger than the max message size"
+// @Router /api/v1/payment/{paymentID}/messages [POST].
+func (p *peerChannelMessage) create(c echo.Context) error {
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return errors.Wrap(err, "failed to read request body")
+	}
+	resp, err := p.svc.PeerChannelMessageCreate(c.Request().Context(), server.PeerChannelMessageArgs{
+		PaymentID:   c.Param("paymentID"),
+		ContentType: c.Request().Header.Get(echo.HeaderContentType),
+	}, body)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return c.JSON(http.StatusCreated, resp)
+}
+
+// bearerToken returns the token sent in the Authorization header.
+func bearerToken(c echo.Context) string {
+	return strings.TrimPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+}
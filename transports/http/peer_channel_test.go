@@ -0,0 +1,104 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/log"
+	"github.com/bitcoin-sv/dpp-proxy/mocks"
+	"github.com/bitcoin-sv/dpp-proxy/transports/http/middleware"
+)
+
+func TestPeerChannel_Messages(t *testing.T) {
+	e := echo.New()
+	svc := &mocks.PeerChannelServiceMock{
+		PeerChannelMessagesFunc: func(ctx context.Context, args server.PeerChannelArgs) ([]server.PeerChannelMessage, error) {
+			return []server.PeerChannelMessage{{Sequence: 2, ContentType: "text/plain", Payload: []byte("hello")}}, nil
+		},
+	}
+	h := NewPeerChannelHandler(svc, middleware.CheckOrigin([]string{"*"}))
+
+	req := httptest.NewRequest(http.MethodGet, "/?after=1", nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer abc")
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/api/v1/channel/:channelID")
+	ctx.SetParamNames("channelID")
+	ctx.SetParamValues("ch1")
+
+	assert.NoError(t, h.messages(ctx))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `[{"sequence":2,"received":"0001-01-01T00:00:00Z","contentType":"text/plain","payload":"aGVsbG8="}]`, rec.Body.String())
+	assert.Equal(t, server.PeerChannelArgs{ChannelID: "ch1", Token: "abc", After: 1}, svc.PeerChannelMessagesCalls()[0].Args)
+}
+
+func TestPeerChannel_Notify(t *testing.T) {
+	svc := &mocks.PeerChannelServiceMock{
+		PeerChannelWatchFunc: func(ctx context.Context, args server.PeerChannelArgs) (<-chan server.PeerChannelMessage, error) {
+			msgs := make(chan server.PeerChannelMessage, 1)
+			msgs <- server.PeerChannelMessage{Sequence: 1, ContentType: "text/plain", Payload: []byte("hello")}
+			go func() {
+				<-ctx.Done()
+				close(msgs)
+			}()
+			return msgs, nil
+		},
+	}
+	e := echo.New()
+	e.HTTPErrorHandler = middleware.ErrorHandler(log.Noop{})
+	NewPeerChannelHandler(svc, middleware.CheckOrigin([]string{"https://wallet.example.com"})).RegisterRoutes(e.Group("/"))
+	s := httptest.NewServer(e)
+	defer s.Close()
+	url := "ws" + strings.TrimPrefix(s.URL, "http") + "/api/v1/channel/ch1/notify?token=abc"
+
+	// origins not in the allow list are rejected.
+	_, resp, err := websocket.DefaultDialer.Dial(url, http.Header{echo.HeaderOrigin: []string{"https://evil.example.com"}})
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Empty(t, svc.PeerChannelWatchCalls())
+
+	ws, _, err := websocket.DefaultDialer.Dial(url, http.Header{echo.HeaderOrigin: []string{"https://wallet.example.com"}})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		_ = ws.Close()
+	}()
+	var msg server.PeerChannelMessage
+	assert.NoError(t, ws.ReadJSON(&msg))
+	assert.Equal(t, uint64(1), msg.Sequence)
+	assert.Equal(t, "hello", string(msg.Payload))
+	assert.Equal(t, server.PeerChannelArgs{ChannelID: "ch1", Token: "abc"}, svc.PeerChannelWatchCalls()[0].Args)
+}
+
+func TestPeerChannelMessage_Create(t *testing.T) {
+	e := echo.New()
+	svc := &mocks.PeerChannelServiceMock{
+		PeerChannelMessageCreateFunc: func(ctx context.Context, args server.PeerChannelMessageArgs, payload []byte) (*server.PeerChannelMessage, error) {
+			return &server.PeerChannelMessage{Sequence: 1, ContentType: args.ContentType, Payload: payload}, nil
+		},
+	}
+	h := NewPeerChannelMessageHandler(svc)
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"receipt":1}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/api/v1/payment/:paymentID/messages")
+	ctx.SetParamNames("paymentID")
+	ctx.SetParamValues("abc123")
+
+	assert.NoError(t, h.create(ctx))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	call := svc.PeerChannelMessageCreateCalls()[0]
+	assert.Equal(t, server.PeerChannelMessageArgs{PaymentID: "abc123", ContentType: echo.MIMEApplicationJSON}, call.Args)
+	assert.Equal(t, `{"receipt":1}`, string(call.Payload))
+}
@@ -0,0 +1,69 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+)
+
+// proofLookup returns the merkle proofs kept by the proxy.
+type proofLookup struct {
+	svc server.ProofService
+}
+
+// NewProofLookup will setup and return a new proof lookup http handler.
+func NewProofLookup(svc server.ProofService) *proofLookup {
+	return &proofLookup{svc: svc}
+}
+
+// RegisterRoutes will setup all proof lookup routes with the supplied echo group.
+func (p *proofLookup) RegisterRoutes(g *echo.Group) {
+	g.GET(RouteV1Proofs, p.proof)
+	g.GET(RouteV1PaymentProofs, p.paymentProofs)
+}
+
+// proof godoc
+// @Summary Proof for a transaction
+// @Description Returns the latest merkle proof received for a transaction, in the envelope it was relayed to the merchant wallet in.
+// @Tags Proofs
+// @Produce json
+// @Param txid path string true "Transaction ID"
+// @Success 200 {object} server.Proof
+// @Failure 400 {object} server.Problem "returned if the user input is invalid"
+// @Failure 404 {object} server.Problem "returned if no proof has been received for the txid"
+// @Router /api/v1/proofs/{txid} [GET].
+func (p *proofLookup) proof(c echo.Context) error {
+	var args server.ProofArgs
+	if err := c.Bind(&args); err != nil {
+		return errors.Wrap(err, "failed to bind request")
+	}
+	resp, err := p.svc.Proof(c.Request().Context(), args)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// paymentProofs godoc
+// @Summary Proofs for a payment
+// @Description Returns the latest merkle proof received for each transaction of a payment, an empty list is returned if there are none yet.
+// @Tags Proofs
+// @Produce json
+// @Param paymentID path string true "Payment ID"
+// @Success 200 {array} server.Proof
+// @Failure 400 {object} server.Problem "returned if the user input is invalid"
+// @Router /api/v1/payment/{paymentID}/proofs [GET].
+func (p *proofLookup) paymentProofs(c echo.Context) error {
+	var args server.PaymentProofsArgs
+	if err := c.Bind(&args); err != nil {
+		return errors.Wrap(err, "failed to bind request")
+	}
+	resp, err := p.svc.PaymentProofs(c.Request().Context(), args)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,67 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/libsv/go-bk/envelope"
+	"github.com/stretchr/testify/assert"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/mocks"
+)
+
+func TestProofLookup_Proof(t *testing.T) {
+	e := echo.New()
+	svc := &mocks.ProofServiceMock{
+		ProofFunc: func(ctx context.Context, args server.ProofArgs) (*server.Proof, error) {
+			return &server.Proof{
+				TxID:      args.TxID,
+				PaymentID: "abc123",
+				Envelope:  &envelope.JSONEnvelope{Payload: "{}", Encoding: "UTF-8", MimeType: "application/json"},
+			}, nil
+		},
+	}
+	h := NewProofLookup(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/api/v1/proofs/:txid")
+	ctx.SetParamNames("txid")
+	ctx.SetParamValues("d21633ba23f70118185227be58a63527675641ad37967e2aa461559f577aec43")
+
+	assert.NoError(t, h.proof(ctx))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var resp server.Proof
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	assert.Equal(t, "d21633ba23f70118185227be58a63527675641ad37967e2aa461559f577aec43", resp.TxID)
+	assert.Equal(t, "abc123", resp.PaymentID)
+	assert.Equal(t, "{}", resp.Envelope.Payload)
+}
+
+func TestProofLookup_PaymentProofs(t *testing.T) {
+	e := echo.New()
+	svc := &mocks.ProofServiceMock{
+		PaymentProofsFunc: func(ctx context.Context, args server.PaymentProofsArgs) ([]server.Proof, error) {
+			return []server.Proof{}, nil
+		},
+	}
+	h := NewProofLookup(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/api/v1/payment/:paymentID/proofs")
+	ctx.SetParamNames("paymentID")
+	ctx.SetParamValues("abc123")
+
+	assert.NoError(t, h.paymentProofs(ctx))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, "[]", rec.Body.String())
+	assert.Equal(t, "abc123", svc.PaymentProofsCalls()[0].Args.PaymentID)
+}
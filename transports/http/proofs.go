@@ -1,22 +1,25 @@
 package http
 
 import (
+	"io"
 	"net/http"
+	"strings"
 
 	"github.com/labstack/echo/v4"
-	"github.com/libsv/go-bk/envelope"
 	"github.com/libsv/go-dpp"
 	"github.com/pkg/errors"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
 )
 
 // proofs is used to accept merkle proofs from transactions
 // submitted by the payment protocol server.
 type proofs struct {
-	svc dpp.ProofsService
+	svc server.ProofCallbackService
 }
 
 // NewProofs will setup and return a new proofs http handler.
-func NewProofs(svc dpp.ProofsService) *proofs {
+func NewProofs(svc server.ProofCallbackService) *proofs {
 	return &proofs{svc: svc}
 }
 
@@ -27,23 +30,36 @@ func (p *proofs) RegisterRoutes(g *echo.Group) {
 
 // create godoc
 // @Summary InvoiceCreate proof
-// @Description Creates a json envelope proof
+// @Description Accepts a callback from a miner or broadcaster. mAPI merkleProof, doubleSpend and doubleSpendAttempt callbacks
+// @Description are accepted as application/json, in a json envelope or unwrapped, and TSC merkle proofs as application/vnd.tsc.merkleproof+json.
 // @Tags Proofs
-// @Accept json
+// @Accept json,application/vnd.tsc.merkleproof+json
 // @Produce json
 // @Param txid path string true "Transaction ID"
+// @Param i query string false "Payment reference"
 // @Param body body envelope.JSONEnvelope true "JSON Envelope"
+// @Security BearerToken
 // @Success 201
+// @Failure 400 {object} server.Problem "returned if the callback is invalid or the content type not supported"
+// @Failure 401 {object} server.Problem "returned if the proof callback token is missing or invalid"
+// @Failure 404 {object} server.Problem "returned if no payment has been seen for the txid"
+// @Failure 413 {object} server.Problem "returned if the callback is larger than SERVER_PROOF_MAXBODY_BYTES"
 // @Router /api/v1/proofs/{txid} [POST].
 func (p *proofs) create(c echo.Context) error {
-	var req envelope.JSONEnvelope
-	if err := c.Bind(&req); err != nil {
-		return errors.WithStack(err)
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return errors.Wrap(err, "failed to read request body")
 	}
-	args := dpp.ProofCreateArgs{TxID: c.Param("txid"),
-		PaymentReference: c.QueryParam("i"),
+	args := server.ProofCallbackArgs{
+		ProofCreateArgs: dpp.ProofCreateArgs{
+			TxID:             c.Param("txid"),
+			PaymentReference: c.QueryParam("i"),
+		},
+		ContentType: c.Request().Header.Get(echo.HeaderContentType),
+		// mAPI sends the callback token as the whole header value, so the bearer prefix is optional.
+		Token: strings.TrimPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer "),
 	}
-	if err := p.svc.Create(c.Request().Context(), args, req); err != nil {
+	if err := p.svc.ProofCallback(c.Request().Context(), args, body); err != nil {
 		return errors.WithStack(err)
 	}
 	return c.NoContent(http.StatusCreated)
@@ -0,0 +1,52 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+)
+
+// refundHandler returns refund instructions to merchants.
+type refundHandler struct {
+	svc server.RefundService
+}
+
+// NewRefundHandler will create and return a new RefundHandler, routes should be
+// registered with a group authenticating the merchant.
+func NewRefundHandler(svc server.RefundService) *refundHandler {
+	return &refundHandler{
+		svc: svc,
+	}
+}
+
+// RegisterRoutes will setup all routes with an echo group.
+func (h *refundHandler) RegisterRoutes(g *echo.Group) {
+	g.GET(RouteV1PaymentRefund, h.refund)
+}
+
+// refund godoc
+// @Summary Refund instructions for a payment
+// @Description Returns where to send a refund for a payment, using the refundTo supplied by the customer with the payment.
+// @Tags Merchant
+// @Produce json
+// @Security BearerToken
+// @Param paymentID path string true "Payment ID"
+// @Success 200 {object} server.RefundInstructions
+// @Failure 400 {object} server.Problem "returned if the user input is invalid"
+// @Failure 401 {object} server.Problem "returned if the merchant bearer token is missing or invalid"
+// @Failure 410 {object} server.Problem "returned if the payment had no refund destination or it has expired"
+// @Router /api/v1/payment/{paymentID}/refund [GET].
+func (h *refundHandler) refund(e echo.Context) error {
+	var args server.RefundArgs
+	if err := e.Bind(&args); err != nil {
+		return errors.Wrap(err, "failed to bind request")
+	}
+	resp, err := h.svc.RefundInstructions(e.Request().Context(), args)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return e.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,45 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/mocks"
+)
+
+func TestRefundHandler_Refund(t *testing.T) {
+	e := echo.New()
+	svc := &mocks.RefundServiceMock{
+		RefundInstructionsFunc: func(ctx context.Context, args server.RefundArgs) (*server.RefundInstructions, error) {
+			return &server.RefundInstructions{
+				PaymentID: args.PaymentID,
+				TxID:      "txid123",
+				Type:      server.RefundTypePaymail,
+				Paymail:   "me@paymail.com",
+			}, nil
+		},
+	}
+	h := NewRefundHandler(svc)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetPath("/api/v1/payment/:paymentID/refund")
+	ctx.SetParamNames("paymentID")
+	ctx.SetParamValues("abc123")
+
+	assert.NoError(t, h.refund(ctx))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var resp map[string]interface{}
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	assert.Equal(t, "abc123", resp["paymentId"])
+	assert.Equal(t, "me@paymail.com", resp["paymail"])
+	assert.NotContains(t, resp, "lockingScript")
+}
@@ -2,7 +2,26 @@ package http
 
 // Routes used in the http handlers.
 const (
-	RouteV1PaymentRequest = "api/v1/payment/:paymentID"
-	RouteV1Payment        = "api/v1/payment/:paymentID"
-	RouteV1Proofs         = "api/v1/proofs/:txid"
+	RouteV1PaymentRequest  = "api/v1/payment/:paymentID"
+	RouteV1Payment         = "api/v1/payment/:paymentID"
+	RouteV1PaymentURI      = "api/v1/payment/:paymentID/uri"
+	RouteV1PaymentQR       = "api/v1/payment/:paymentID/qr"
+	RouteV1PaymentRefund   = "api/v1/payment/:paymentID/refund"
+	RouteV1PaymentProofs   = "api/v1/payment/:paymentID/proofs"
+	RouteV1PaymentStatus   = "api/v1/payment/:paymentID/status"
+	RouteV1PaymentStream   = "api/v1/payment/:paymentID/status/stream"
+	RouteV1PaymentMessage  = "api/v1/payment/:paymentID/messages"
+	RouteV1Proofs          = "api/v1/proofs/:txid"
+	RouteV1PeerChannel     = "api/v1/channel/:channelID"
+	RouteV1PeerChannelWS   = "api/v1/channel/:channelID/notify"
+	RouteV1AdminConfig     = "api/v1/admin/config"
+	RouteV1Invoices        = "api/v1/invoice"
+	RouteV1Invoice         = "api/v1/invoice/:paymentID"
+	RouteV1PaymentQueue    = "api/v1/queue"
+	RouteV1QueuedPayment   = "api/v1/queue/:paymentID"
+	RouteV1QueuedAck       = "api/v1/queue/:paymentID/ack"
+	RouteV1XPubInvoices    = "api/v1/xpub/invoice"
+	RouteV1XPubInvoice     = "api/v1/xpub/invoice/:paymentID"
+	RouteV1PaymailInvoices = "api/v1/paymail/invoice"
+	RouteV1PaymailInvoice  = "api/v1/paymail/invoice/:paymentID"
 )
@@ -0,0 +1,79 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+)
+
+// xpubInvoiceHandler lets merchants create invoices paid to their extended public key.
+type xpubInvoiceHandler struct {
+	svc server.XPubInvoiceService
+}
+
+// NewXPubInvoiceHandler will create and return a new XPubInvoiceHandler, routes should be
+// registered with a group authenticating the merchant.
+func NewXPubInvoiceHandler(svc server.XPubInvoiceService) *xpubInvoiceHandler {
+	return &xpubInvoiceHandler{
+		svc: svc,
+	}
+}
+
+// RegisterRoutes will setup all routes with an echo group.
+func (h *xpubInvoiceHandler) RegisterRoutes(g *echo.Group) {
+	g.POST(RouteV1XPubInvoices, h.createXPubInvoice)
+	g.GET(RouteV1XPubInvoice, h.xpubInvoice)
+}
+
+// createXPubInvoice godoc
+// @Summary Create an xpub invoice
+// @Description Creates an invoice paid to a new P2PKH output derived from the configured extended public key, customers are sent the paymentId to pay it.
+// @Tags Merchant
+// @Accept json
+// @Produce json
+// @Security BearerToken
+// @Param body body server.XPubInvoiceCreate true "the invoice to create"
+// @Success 201 {object} server.XPubInvoice
+// @Failure 400 {object} server.Problem "returned if the invoice is invalid"
+// @Failure 401 {object} server.Problem "returned if the merchant bearer token is missing or invalid"
+// @Failure 404 {object} server.Problem "returned if there is no exchange rate for the fiat currency"
+// @Failure 422 {object} server.Problem "returned if the invoice is priced in fiat and exchange rates aren't enabled"
+// @Router /api/v1/xpub/invoice [POST].
+func (h *xpubInvoiceHandler) createXPubInvoice(e echo.Context) error {
+	var req server.XPubInvoiceCreate
+	if err := e.Bind(&req); err != nil {
+		return errors.Wrap(err, "failed to bind request")
+	}
+	resp, err := h.svc.XPubInvoiceCreate(e.Request().Context(), req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return e.JSON(http.StatusCreated, resp)
+}
+
+// xpubInvoice godoc
+// @Summary An xpub invoice
+// @Description Returns an xpub invoice, the txid and paidAt are set once it is paid.
+// @Tags Merchant
+// @Produce json
+// @Security BearerToken
+// @Param paymentID path string true "Payment ID"
+// @Success 200 {object} server.XPubInvoice
+// @Failure 400 {object} server.Problem "returned if the user input is invalid"
+// @Failure 401 {object} server.Problem "returned if the merchant bearer token is missing or invalid"
+// @Failure 404 {object} server.Problem "returned if the invoice doesn't exist"
+// @Router /api/v1/xpub/invoice/{paymentID} [GET].
+func (h *xpubInvoiceHandler) xpubInvoice(e echo.Context) error {
+	var args server.XPubInvoiceArgs
+	if err := e.Bind(&args); err != nil {
+		return errors.Wrap(err, "failed to bind request")
+	}
+	resp, err := h.svc.XPubInvoice(e.Request().Context(), args)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return e.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,36 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	server "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/mocks"
+)
+
+func TestXPubInvoiceHandler_CreateXPubInvoice(t *testing.T) {
+	e := echo.New()
+	svc := &mocks.XPubInvoiceServiceMock{
+		XPubInvoiceCreateFunc: func(ctx context.Context, req server.XPubInvoiceCreate) (*server.XPubInvoice, error) {
+			return &server.XPubInvoice{PaymentID: "abc123", Satoshis: req.Satoshis, Memo: req.Memo, DerivationPath: "0/0"}, nil
+		},
+	}
+	h := NewXPubInvoiceHandler(svc)
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"satoshis":1000,"memo":"invoice 123"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+
+	assert.NoError(t, h.createXPubInvoice(ctx))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, server.XPubInvoiceCreate{Satoshis: 1000, Memo: "invoice 123"}, svc.XPubInvoiceCreateCalls()[0].Req)
+	assert.Contains(t, rec.Body.String(), `"paymentId":"abc123"`)
+	assert.Contains(t, rec.Body.String(), `"derivationPath":"0/0"`)
+}
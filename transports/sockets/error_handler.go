@@ -0,0 +1,31 @@
+package sockets
+
+import (
+	"github.com/theflyingcodr/lathos/errs"
+	"github.com/theflyingcodr/sockets"
+
+	dppProxy "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/log"
+)
+
+// ErrorHandler returns errors raised handling socket messages to the sender as
+// problems, with the same error codes as http responses. Internal errors are logged
+// and returned without their detail.
+func ErrorHandler(l log.Logger) sockets.ServerErrorHandlerFunc {
+	return func(msg *sockets.Message, err error) *sockets.ErrorMessage {
+		if err == nil {
+			return nil
+		}
+		p := dppProxy.NewProblem(err)
+		if p.Code == dppProxy.ErrCodeInternal {
+			internalErr := errs.NewErrInternal(err, "500")
+			l.Error(internalErr, "internal socket error")
+			p.ID = internalErr.ID()
+		}
+		if msg == nil {
+			return nil
+		}
+		p.Instance = msg.Key()
+		return msg.ToError(p)
+	}
+}
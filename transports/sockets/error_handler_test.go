@@ -0,0 +1,58 @@
+package sockets_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/theflyingcodr/lathos/errs"
+	"github.com/theflyingcodr/sockets"
+
+	dppProxy "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/log"
+	dppSoc "github.com/bitcoin-sv/dpp-proxy/transports/sockets"
+)
+
+func TestErrorHandler(t *testing.T) {
+	tests := map[string]struct {
+		err        error
+		expProblem dppProxy.Problem
+	}{
+		"client error is returned": {
+			err: errs.NewErrNotFound("404", "invoice not found"),
+			expProblem: dppProxy.Problem{
+				Type:     "urn:dpp-proxy:error:not_found",
+				Title:    "Not Found",
+				Status:   404,
+				Detail:   "invoice not found",
+				Instance: "payment",
+				Code:     dppProxy.ErrCodeNotFound,
+			},
+		},
+		"internal error detail is not returned": {
+			err: errors.New("database password is hunter2"),
+			expProblem: dppProxy.Problem{
+				Type:     "urn:dpp-proxy:error:internal",
+				Title:    "Internal Server Error",
+				Status:   500,
+				Detail:   "an unexpected error occurred",
+				Instance: "payment",
+				Code:     dppProxy.ErrCodeInternal,
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			msg := sockets.NewMessage("payment", "", "abc123")
+			errMsg := dppSoc.ErrorHandler(log.Noop{})(msg, test.err)
+			assert.Equal(t, sockets.MessageError, errMsg.Key)
+			assert.Equal(t, "payment", errMsg.OriginKey)
+
+			var p dppProxy.Problem
+			assert.NoError(t, errMsg.Bind(&p))
+			assert.NotEmpty(t, p.ID)
+			p.ID = ""
+			assert.Equal(t, test.expProblem, p)
+		})
+	}
+}
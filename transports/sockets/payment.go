@@ -1,33 +1,168 @@
 package sockets
 
 import (
+	"github.com/libsv/go-dpp"
 	"github.com/theflyingcodr/sockets"
 
 	"context"
+	"sync"
+	"time"
 
 	"github.com/theflyingcodr/sockets/server"
+
+	dppProxy "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/log"
 )
 
 type payment struct {
+	l         log.Logger
+	tokens    dppProxy.ProofTokenService
+	statusWtr dppProxy.PaymentStatusWriter
+	ttl       time.Duration
+	token     string
+
+	// pending holds the payments sent on each channel until the wallet acks them.
+	mu      sync.Mutex
+	pending map[string]pendingPayment
+}
+
+// pendingPayment is a payment waiting on the wallet ack.
+type pendingPayment struct {
+	payment    dpp.Payment
+	receivedAt time.Time
 }
 
-// NewPayment will setup and return a new instance of a payment handler.
-func NewPayment() *payment {
-	return &payment{}
+// NewPayment will setup and return a new instance of a payment handler, proof
+// callback tokens supplied with payments are recorded using tokens once the wallet
+// acks the payment and whether the payment was paid or rejected with statusWtr.
+// Payments the wallet doesn't respond to are forgotten after ttl.
+// If token is set, as customers can join invoice channels, only acks and errors supplying it
+// as a bearer token in the Authorization header are recorded, others are still forwarded.
+func NewPayment(l log.Logger, tokens dppProxy.ProofTokenService, statusWtr dppProxy.PaymentStatusWriter, ttl time.Duration,
+	token string) *payment {
+	return &payment{
+		l:         l,
+		tokens:    tokens,
+		statusWtr: statusWtr,
+		ttl:       ttl,
+		token:     token,
+		pending:   map[string]pendingPayment{},
+	}
 }
 
 // Register will register new handler/s with the socket server.
 func (p *payment) Register(s *server.SocketServer) {
 	s.RegisterChannelHandler("payment", p.payment)
 	s.RegisterChannelHandler("payment.ack", p.paymentAck)
+	s.RegisterChannelHandler("payment.error", p.paymentError)
 }
 
-// payment will forward a payment message to all connected clients.
+// payment will hold the payment until the wallet acks it and forward the payment
+// message to all connected clients.
 func (p *payment) payment(ctx context.Context, msg *sockets.Message) (*sockets.Message, error) {
+	var req dpp.Payment
+	if err := msg.Bind(&req); err != nil {
+		p.l.Errorf(err, "failed to read payment for channel %s", msg.ChannelID())
+		return msg, nil
+	}
+	now := time.Now()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for channelID, pp := range p.pending {
+		if now.Sub(pp.receivedAt) > p.ttl {
+			delete(p.pending, channelID)
+		}
+	}
+	p.pending[msg.ChannelID()] = pendingPayment{payment: req, receivedAt: now}
 	return msg, nil
 }
 
-// paymentAck will forward a payment.ack message to all connected clients.
+// takePending removes and returns the payment waiting on an ack for the channel.
+func (p *payment) takePending(channelID string) (dpp.Payment, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	pp, ok := p.pending[channelID]
+	delete(p.pending, channelID)
+	return pp.payment, ok
+}
+
+// paymentAck will record the payment as paid, or rejected if the ack has an error,
+// and forward the payment.ack message to all connected clients. The proof callback
+// tokens of a paid payment are recorded, the message is still forwarded if they can't
+// be, proof callbacks for it will be rejected. Acks not sent by the merchant wallet are
+// forwarded without being recorded.
 func (p *payment) paymentAck(ctx context.Context, msg *sockets.Message) (*sockets.Message, error) {
+	if !p.fromMerchant(msg) {
+		return msg, nil
+	}
+	var ack dpp.PaymentACK
+	if err := msg.Bind(&ack); err != nil {
+		p.l.Errorf(err, "failed to read payment ack for channel %s", msg.ChannelID())
+		return msg, nil
+	}
+	status := dppProxy.PaymentStatus{
+		PaymentID: msg.ChannelID(),
+		State:     dppProxy.PaymentStatePaid,
+		TxID:      ack.TxID,
+	}
+	req, ok := p.takePending(msg.ChannelID())
+	if ack.Error != 0 {
+		status.State = dppProxy.PaymentStateRejected
+		status.TxID = ""
+		status.Memo = ack.Memo
+	} else if ok {
+		if err := p.tokens.ProofTokenCreate(ctx, dpp.PaymentCreateArgs{PaymentID: msg.ChannelID()}, req); err != nil {
+			p.l.Errorf(err, "failed to record proof tokens for channel %s", msg.ChannelID())
+		}
+	}
+	p.recordStatus(ctx, status)
 	return msg, nil
 }
+
+// paymentError will record the payment as rejected and forward the payment.error
+// message to all connected clients. Errors not sent by the merchant wallet are forwarded
+// without being recorded.
+func (p *payment) paymentError(ctx context.Context, msg *sockets.Message) (*sockets.Message, error) {
+	if !p.fromMerchant(msg) {
+		return msg, nil
+	}
+	// wallets send a ClientError with a message or a problem with a detail.
+	var body struct {
+		dppProxy.Problem
+		Message string `json:"message"`
+	}
+	if err := msg.Bind(&body); err != nil {
+		p.l.Errorf(err, "failed to read payment error for channel %s", msg.ChannelID())
+	}
+	p.takePending(msg.ChannelID())
+	memo := body.Message
+	if memo == "" {
+		memo = body.Detail
+	}
+	p.recordStatus(ctx, dppProxy.PaymentStatus{
+		PaymentID: msg.ChannelID(),
+		State:     dppProxy.PaymentStateRejected,
+		Memo:      memo,
+	})
+	return msg, nil
+}
+
+// fromMerchant returns true if no merchant token is set or the message supplies it.
+func (p *payment) fromMerchant(msg *sockets.Message) bool {
+	if p.token == "" {
+		return true
+	}
+	if err := authenticate(msg, p.token); err != nil {
+		p.l.Infof("not recording %s for channel %s: %s", msg.Key(), msg.ChannelID(), err)
+		return false
+	}
+	return true
+}
+
+// recordStatus records a change of payment status, the message is still forwarded
+// if it can't be recorded.
+func (p *payment) recordStatus(ctx context.Context, status dppProxy.PaymentStatus) {
+	if err := p.statusWtr.PaymentStatusUpdate(ctx, status); err != nil {
+		p.l.Errorf(err, "failed to record payment status for channel %s", status.PaymentID)
+	}
+}
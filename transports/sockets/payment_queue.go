@@ -0,0 +1,82 @@
+package sockets
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+
+	"github.com/libsv/go-dpp"
+	"github.com/pkg/errors"
+	"github.com/theflyingcodr/lathos/errs"
+	"github.com/theflyingcodr/sockets"
+	"github.com/theflyingcodr/sockets/server"
+
+	dppProxy "github.com/bitcoin-sv/dpp-proxy"
+)
+
+type paymentQueue struct {
+	svc   dppProxy.PaymentQueueService
+	token string
+}
+
+// NewPaymentQueue will setup and return a new instance of a payment queue handler, letting
+// merchant wallets collect the payment queued for an invoice while they were offline.
+// Messages must supply the merchant token as a bearer token in the Authorization header,
+// as customers can join invoice channels.
+func NewPaymentQueue(svc dppProxy.PaymentQueueService, token string) *paymentQueue {
+	return &paymentQueue{
+		svc:   svc,
+		token: token,
+	}
+}
+
+// Register will register new handler/s with the socket server.
+func (p *paymentQueue) Register(s *server.SocketServer) {
+	s.RegisterDirectHandler("payment.queued", p.queuedPayment)
+	s.RegisterDirectHandler("payment.queued.ack", p.queuedPaymentAck)
+}
+
+// queuedPayment will reply with a payment.queued.response message containing the payment
+// queued for the invoice of the channel.
+func (p *paymentQueue) queuedPayment(ctx context.Context, msg *sockets.Message) (*sockets.Message, error) {
+	if err := authenticate(msg, p.token); err != nil {
+		return nil, err
+	}
+	qp, err := p.svc.QueuedPayment(ctx, dppProxy.QueuedPaymentArgs{PaymentID: msg.ChannelID()})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	resp := msg.NewFrom("payment.queued.response")
+	if err := resp.WithBody(qp); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return resp, nil
+}
+
+// queuedPaymentAck will remove the payment queued for the invoice of the channel, the
+// message body is the PaymentACK of the wallet.
+func (p *paymentQueue) queuedPaymentAck(ctx context.Context, msg *sockets.Message) (*sockets.Message, error) {
+	if err := authenticate(msg, p.token); err != nil {
+		return nil, err
+	}
+	var ack dpp.PaymentACK
+	if err := msg.Bind(&ack); err != nil {
+		return nil, errors.Wrap(err, "failed to read payment ack")
+	}
+	if err := p.svc.QueuedPaymentAck(ctx, dppProxy.QueuedPaymentArgs{PaymentID: msg.ChannelID()}, ack); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return nil, nil
+}
+
+// authenticate checks the message supplies the merchant token as a bearer token.
+func authenticate(msg *sockets.Message, token string) error {
+	auth := msg.Headers.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return errs.NewErrNotAuthenticated("401", "bearer token required")
+	}
+	if subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(auth, "Bearer ")), []byte(token)) != 1 {
+		return errs.NewErrNotAuthenticated("401", "invalid bearer token")
+	}
+	return nil
+}
@@ -1,19 +1,33 @@
 package sockets
 
 import (
+	"github.com/libsv/go-dpp"
+	"github.com/pkg/errors"
 	"github.com/theflyingcodr/sockets"
 
 	"context"
 
 	"github.com/theflyingcodr/sockets/server"
+
+	dppProxy "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/log"
 )
 
 type paymentRequest struct {
+	l         log.Logger
+	fees      dppProxy.FeeService
+	statusWtr dppProxy.PaymentStatusWriter
 }
 
-// NewPaymentRequest will setup a new instance of a paymentRequest handler.
-func NewPaymentRequest() *paymentRequest {
-	return &paymentRequest{}
+// NewPaymentRequest will setup a new instance of a paymentRequest handler, payments
+// are marked as requested with statusWtr when a payment request is returned. If fees
+// is not nil, missing fees are filled and fees kept within limits by it.
+func NewPaymentRequest(l log.Logger, fees dppProxy.FeeService, statusWtr dppProxy.PaymentStatusWriter) *paymentRequest {
+	return &paymentRequest{
+		l:         l,
+		fees:      fees,
+		statusWtr: statusWtr,
+	}
 }
 
 // Register will register new handler/s with the socket server.
@@ -27,7 +41,36 @@ func (p *paymentRequest) buildPaymentRequest(ctx context.Context, msg *sockets.M
 	return msg, nil
 }
 
-// buildPaymentRequest will forward a paymentrequest.response message to all connected clients.
+// paymentRequestResponse will record the payment as requested and forward a
+// paymentrequest.response message to all connected clients. If fees are enabled the
+// message is forwarded with the fees set by them, if they can't be set the error is
+// returned to the wallet and the message isn't forwarded.
 func (p *paymentRequest) paymentRequestResponse(ctx context.Context, msg *sockets.Message) (*sockets.Message, error) {
+	var pr dpp.PaymentRequest
+	if err := msg.Bind(&pr); err != nil {
+		p.l.Errorf(err, "failed to read payment request for channel %s", msg.ChannelID())
+		return msg, nil
+	}
+	if p.fees != nil {
+		fees, err := p.fees.Fees(ctx, pr.FeeRate)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to set fees for channel %s", msg.ChannelID())
+		}
+		pr.FeeRate = fees
+		if err := msg.WithBody(pr); err != nil {
+			return nil, errors.Wrapf(err, "failed to set fees for channel %s", msg.ChannelID())
+		}
+	}
+	status := dppProxy.PaymentStatus{
+		PaymentID: msg.ChannelID(),
+		State:     dppProxy.PaymentStateRequested,
+	}
+	if !pr.ExpirationTimestamp.IsZero() {
+		expires := pr.ExpirationTimestamp.UTC()
+		status.ExpiresAt = &expires
+	}
+	if err := p.statusWtr.PaymentStatusUpdate(ctx, status); err != nil {
+		p.l.Errorf(err, "failed to record payment status for channel %s", msg.ChannelID())
+	}
 	return msg, nil
 }
@@ -0,0 +1,91 @@
+package sockets
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/libsv/go-dpp"
+	"github.com/stretchr/testify/assert"
+	"github.com/theflyingcodr/sockets"
+
+	dppProxy "github.com/bitcoin-sv/dpp-proxy"
+	"github.com/bitcoin-sv/dpp-proxy/data/memory"
+	"github.com/bitcoin-sv/dpp-proxy/log"
+	"github.com/bitcoin-sv/dpp-proxy/mocks"
+	"github.com/bitcoin-sv/dpp-proxy/service"
+)
+
+func TestPayment_AcksAndErrors(t *testing.T) {
+	tests := map[string]struct {
+		token     string
+		auth      string
+		key       string
+		expStatus dppProxy.PaymentState
+	}{
+		"ack with the merchant token is recorded": {
+			token:     "merchant",
+			auth:      "Bearer merchant",
+			key:       "payment.ack",
+			expStatus: dppProxy.PaymentStatePaid,
+		},
+		"error with the merchant token is recorded": {
+			token:     "merchant",
+			auth:      "Bearer merchant",
+			key:       "payment.error",
+			expStatus: dppProxy.PaymentStateRejected,
+		},
+		"ack without the merchant token is forwarded but not recorded": {
+			token: "merchant",
+			key:   "payment.ack",
+		},
+		"error with the wrong token is forwarded but not recorded": {
+			token: "merchant",
+			auth:  "Bearer customer",
+			key:   "payment.error",
+		},
+		"ack is recorded if no merchant token is set": {
+			key:       "payment.ack",
+			expStatus: dppProxy.PaymentStatePaid,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			statusWtr := &mocks.PaymentStatusWriterMock{
+				PaymentStatusUpdateFunc: func(context.Context, dppProxy.PaymentStatus) error {
+					return nil
+				},
+			}
+			tokens := memory.NewProofTokens(time.Hour)
+			p := NewPayment(log.Noop{}, service.NewProofToken(tokens), statusWtr, time.Minute, test.token)
+
+			rawTx := "0100000000000000000000"
+			payment := sockets.NewMessage("payment", "customer", "abc123")
+			assert.NoError(t, payment.WithBody(dpp.Payment{RawTx: &rawTx, ProofCallbacks: map[string]dpp.ProofCallback{
+				"https://merchant.example.com/proofs": {Token: "abc"},
+			}}))
+			_, err := p.payment(context.Background(), payment)
+			assert.NoError(t, err)
+
+			msg := sockets.NewMessage(test.key, "wallet", "abc123")
+			if test.auth != "" {
+				msg.Headers.Set("Authorization", test.auth)
+			}
+			assert.NoError(t, msg.WithBody(dpp.PaymentACK{TxID: "d21633ba23f70118185227be58a63527675641ad37967e2aa461559f577aec43"}))
+			handler := p.paymentAck
+			if test.key == "payment.error" {
+				handler = p.paymentError
+			}
+			resp, err := handler(context.Background(), msg)
+			assert.NoError(t, err)
+			// the message always reaches the customer.
+			assert.Same(t, msg, resp)
+			if test.expStatus == "" {
+				assert.Empty(t, statusWtr.PaymentStatusUpdateCalls())
+				return
+			}
+			assert.Len(t, statusWtr.PaymentStatusUpdateCalls(), 1)
+			assert.Equal(t, test.expStatus, statusWtr.PaymentStatusUpdateCalls()[0].Req.State)
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/libsv/go-bt/v2/bscript"
+	"github.com/pkg/errors"
+	validator "github.com/theflyingcodr/govalidator"
+)
+
+// XPubInvoice is an invoice created by a merchant in standalone mode, paid to a P2PKH
+// output derived from the merchant's extended public key.
+type XPubInvoice struct {
+	PaymentID string `json:"paymentId" example:"e97970bf-2a88-4bc8-90e6-2f597a80b93d"`
+	// Satoshis is the amount of the invoice, zero if it is priced in Fiat.
+	Satoshis uint64 `json:"satoshis" example:"1000"`
+	// Fiat is the amount of an invoice priced in a fiat currency.
+	Fiat *FiatAmount `json:"fiat,omitempty"`
+	// Quote is the satoshi amount of a fiat invoice, locked when the payment request is served.
+	Quote *Quote `json:"quote,omitempty"`
+	Memo  string `json:"memo" example:"invoice 123456"`
+	// DerivationPath is the path of the output key from the extended public key.
+	DerivationPath string          `json:"derivationPath" example:"0/12"`
+	LockingScript  *bscript.Script `json:"lockingScript" swaggertype:"primitive,string" example:"76a91455b61be43392125d127f1780fb038437cd67ef9c88ac"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	ExpiresAt      time.Time       `json:"expiresAt"`
+	// TxID is the transaction paying the invoice, set once paid.
+	TxID   string     `json:"txid,omitempty" example:"d21633ba23f70118185227be58a63527675641ad37967e2aa461559f577aec43"`
+	PaidAt *time.Time `json:"paidAt,omitempty"`
+}
+
+// XPubInvoiceCreate is sent by a merchant to create an invoice in standalone mode.
+type XPubInvoiceCreate struct {
+	// Satoshis is the amount of the invoice, it isn't set if Fiat is.
+	Satoshis uint64 `json:"satoshis,omitempty" example:"1000"`
+	// Fiat prices the invoice in a fiat currency, converted to satoshis when the payment request is served.
+	Fiat *FiatAmount `json:"fiat,omitempty"`
+	// Memo is displayed to the customer, it can be at most 50 characters.
+	Memo string `json:"memo" example:"invoice 123456"`
+	// ExpiresAt is when the invoice expires, if empty it expires XPUB_EXPIRY after it is created.
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// Validate will ensure the invoice has an amount, a short memo and an expiry in the future.
+func (x XPubInvoiceCreate) Validate() error {
+	v := validator.New()
+	if x.Fiat != nil {
+		v = v.Validate("satoshis", func() error {
+			if x.Satoshis != 0 {
+				return errors.New("satoshis can't be set as the invoice is priced in fiat")
+			}
+			return nil
+		}).
+			Validate("fiat.currency", validator.MatchString(x.Fiat.Currency, reCurrency)).
+			Validate("fiat.amount", func() error {
+				if !(x.Fiat.Amount > 0) || math.IsInf(x.Fiat.Amount, 0) {
+					return errors.New("value should be greater than 0")
+				}
+				return nil
+			})
+	} else {
+		v = v.Validate("satoshis", func() error {
+			if x.Satoshis == 0 {
+				return errors.New("value 0 should be greater than 0")
+			}
+			return nil
+		})
+	}
+	return v.
+		Validate("memo", validator.StrLength(x.Memo, 0, 50)).
+		Validate("expiresAt", func() error {
+			if x.ExpiresAt != nil && !x.ExpiresAt.After(time.Now()) {
+				return errors.New("the expiry must be in the future")
+			}
+			return nil
+		}).Err()
+}
+
+// XPubInvoiceArgs identify an xpub invoice.
+type XPubInvoiceArgs struct {
+	PaymentID string `param:"paymentID"`
+}
+
+// Validate will ensure the XPubInvoiceArgs are supplied and correct.
+func (x XPubInvoiceArgs) Validate() error {
+	return validator.New().
+		Validate("paymentID", validator.NotEmpty(x.PaymentID)).
+		Err()
+}
+
+// XPubInvoiceService lets merchants create invoices in standalone mode.
+type XPubInvoiceService interface {
+	XPubInvoiceCreate(ctx context.Context, req XPubInvoiceCreate) (*XPubInvoice, error)
+	XPubInvoice(ctx context.Context, args XPubInvoiceArgs) (*XPubInvoice, error)
+}
+
+// XPubInvoiceReaderWriter creates invoices paid to outputs derived from the merchant's
+// extended public key.
+type XPubInvoiceReaderWriter interface {
+	// XPubInvoiceCreate derives an output for a new invoice and records it.
+	XPubInvoiceCreate(ctx context.Context, req XPubInvoiceCreate) (*XPubInvoice, error)
+	// XPubInvoice returns an invoice, a not found error is returned if it doesn't exist.
+	XPubInvoice(ctx context.Context, args XPubInvoiceArgs) (*XPubInvoice, error)
+}
+
+// XPubStore persists xpub invoices and the derivation index, so outputs are
+// never reused across restarts.
+type XPubStore interface {
+	// XPubIndexNext reserves and returns the next unused derivation index.
+	XPubIndexNext(ctx context.Context) (uint32, error)
+	// XPubInvoiceCreate records a new invoice.
+	XPubInvoiceCreate(ctx context.Context, req XPubInvoice) error
+	// XPubInvoiceUpdate replaces a recorded invoice, such as when it is paid.
+	XPubInvoiceUpdate(ctx context.Context, req XPubInvoice) error
+	// XPubInvoice returns a recorded invoice, a not found error is returned if it doesn't exist.
+	XPubInvoice(ctx context.Context, args XPubInvoiceArgs) (*XPubInvoice, error)
+}